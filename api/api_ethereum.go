@@ -669,19 +669,121 @@ func (diff *EthStateOverride) Apply(state *state.StateDB) error {
 	return nil
 }
 
+// EthBlockOverrides is a set of header fields to override during the execution
+// of a message call.
+// BlockOverrides in go-ethereum has been renamed to EthBlockOverrides.
+// BlockOverrides is defined in go-ethereum's internal package, so BlockOverrides is redefined here as EthBlockOverrides.
+type EthBlockOverrides struct {
+	Number     *hexutil.Big    `json:"number"`
+	Difficulty *hexutil.Big    `json:"difficulty"` // BlockScore in Kaia
+	Time       *hexutil.Uint64 `json:"time"`
+	GasLimit   *hexutil.Uint64 `json:"gasLimit"`
+	Coinbase   *common.Address `json:"coinbase"`
+	Rewardbase *common.Address `json:"rewardbase"`
+	Random     *common.Hash    `json:"random"`
+	BaseFee    *hexutil.Big    `json:"baseFee"`
+}
+
+// Apply overrides the given block context, built from the given header, with the
+// fields set in the overrides.
+func (diff *EthBlockOverrides) Apply(blockCtx *vm.BlockContext, header *types.Header) {
+	if diff == nil {
+		return
+	}
+	if diff.Number != nil {
+		blockCtx.BlockNumber = diff.Number.ToInt()
+		// Hashes of the blocks after the given header are unknown.
+		getHash, number, hash := blockCtx.GetHash, header.Number.Uint64(), header.Hash()
+		blockCtx.GetHash = func(n uint64) common.Hash {
+			if n == number {
+				return hash
+			} else if n > number {
+				return common.Hash{}
+			}
+			return getHash(n)
+		}
+	}
+	if diff.Difficulty != nil {
+		blockCtx.BlockScore = diff.Difficulty.ToInt()
+	}
+	if diff.Time != nil {
+		blockCtx.Time = new(big.Int).SetUint64(uint64(*diff.Time))
+	}
+	if diff.GasLimit != nil {
+		blockCtx.GasLimit = uint64(*diff.GasLimit)
+	}
+	if diff.Coinbase != nil {
+		blockCtx.Coinbase = *diff.Coinbase
+	}
+	if diff.Rewardbase != nil {
+		blockCtx.Rewardbase = *diff.Rewardbase
+	}
+	if diff.Random != nil {
+		blockCtx.Random = *diff.Random
+	}
+	if diff.BaseFee != nil {
+		blockCtx.BaseFee = diff.BaseFee.ToInt()
+	}
+}
+
+// MakeHeader returns a copy of the given header with the fields set in the
+// overrides replaced. It is used to derive the fee and the chain rules of a call.
+// Fields that only exist in the block context, such as coinbase and gas limit,
+// are applied by Apply.
+func (diff *EthBlockOverrides) MakeHeader(header *types.Header) *types.Header {
+	if diff == nil {
+		return header
+	}
+	h := types.CopyHeader(header)
+	if diff.Number != nil {
+		h.Number = new(big.Int).Set(diff.Number.ToInt())
+	}
+	if diff.Difficulty != nil {
+		h.BlockScore = new(big.Int).Set(diff.Difficulty.ToInt())
+	}
+	if diff.Time != nil {
+		h.Time = new(big.Int).SetUint64(uint64(*diff.Time))
+	}
+	if diff.Rewardbase != nil {
+		h.Rewardbase = *diff.Rewardbase
+	}
+	if diff.Random != nil {
+		h.MixHash = diff.Random.Bytes()
+	}
+	if diff.BaseFee != nil {
+		h.BaseFee = new(big.Int).Set(diff.BaseFee.ToInt())
+	}
+	return h
+}
+
+// getEVMWithOverrides returns the EVM of the backend for the given header with
+// the block overrides applied to its context. The header must be a real block
+// header since the coinbase is derived from its seal.
+func getEVMWithOverrides(ctx context.Context, b Backend, msg blockchain.Message, state *state.StateDB, header *types.Header, blockOverrides *EthBlockOverrides, vmCfg vm.Config) (*vm.EVM, func() error, error) {
+	evm, vmError, err := b.GetEVM(ctx, msg, state, header, vmCfg)
+	if err != nil || blockOverrides == nil {
+		return evm, vmError, err
+	}
+	blockCtx := evm.Context
+	blockOverrides.Apply(&blockCtx, header)
+	txCtx := blockchain.NewEVMTxContext(msg, blockOverrides.MakeHeader(header), b.ChainConfig())
+	return vm.NewEVM(blockCtx, txCtx, state, b.ChainConfig(), &vmCfg), vmError, nil
+}
+
 // Call executes the given transaction on the state for the given block number.
 //
-// Additionally, the caller can specify a batch of contract for fields overriding.
+// Additionally, the caller can specify a batch of contract for fields overriding
+// and the fields of the block to override.
 //
 // Note, this function doesn't make and changes in the state/blockchain and is
 // useful to execute and retrieve values.
-func (api *EthereumAPI) Call(ctx context.Context, args EthTransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *EthStateOverride) (hexutil.Bytes, error) {
+func (api *EthereumAPI) Call(ctx context.Context, args EthTransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *EthStateOverride, blockOverrides *EthBlockOverrides) (hexutil.Bytes, error) {
 	bcAPI := api.publicBlockChainAPI.b
 	gasCap := uint64(0)
 	if rpcGasCap := bcAPI.RPCGasCap(); rpcGasCap != nil {
 		gasCap = rpcGasCap.Uint64()
 	}
-	result, err := EthDoCall(ctx, bcAPI, args, blockNrOrHash, overrides, bcAPI.RPCEVMTimeout(), gasCap)
+	result, err := EthDoCall(ctx, bcAPI, args, blockNrOrHash, overrides, blockOverrides, bcAPI.RPCEVMTimeout(), gasCap)
 	if err != nil {
 		return nil, err
 	}
@@ -693,8 +795,9 @@ func (api *EthereumAPI) Call(ctx context.Context, args EthTransactionArgs, block
 }
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the
-// given transaction against the current pending block.
-func (api *EthereumAPI) EstimateGas(ctx context.Context, args EthTransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+// given transaction against the current pending block. The state and the
+// fields of the block may be overridden before the estimation.
+func (api *EthereumAPI) EstimateGas(ctx context.Context, args EthTransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *EthStateOverride, blockOverrides *EthBlockOverrides) (hexutil.Uint64, error) {
 	bcAPI := api.publicBlockChainAPI.b
 	bNrOrHash := rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
 	if blockNrOrHash != nil {
@@ -704,7 +807,7 @@ func (api *EthereumAPI) EstimateGas(ctx context.Context, args EthTransactionArgs
 	if rpcGasCap := bcAPI.RPCGasCap(); rpcGasCap != nil {
 		gasCap = rpcGasCap.Uint64()
 	}
-	return EthDoEstimateGas(ctx, bcAPI, args, bNrOrHash, gasCap)
+	return EthDoEstimateGas(ctx, bcAPI, args, bNrOrHash, overrides, blockOverrides, gasCap)
 }
 
 // GetBlockTransactionCountByNumber returns the number of transactions in the block with the given block number.
@@ -723,25 +826,26 @@ func (api *EthereumAPI) GetBlockTransactionCountByHash(ctx context.Context, bloc
 // RPCTransaction in go-ethereum has been renamed to EthRPCTransaction.
 // RPCTransaction is defined in go-ethereum's internal package, so RPCTransaction is redefined here as EthRPCTransaction.
 type EthRPCTransaction struct {
-	BlockHash        *common.Hash      `json:"blockHash"`
-	BlockNumber      *hexutil.Big      `json:"blockNumber"`
-	From             common.Address    `json:"from"`
-	Gas              hexutil.Uint64    `json:"gas"`
-	GasPrice         *hexutil.Big      `json:"gasPrice"`
-	GasFeeCap        *hexutil.Big      `json:"maxFeePerGas,omitempty"`
-	GasTipCap        *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
-	Hash             common.Hash       `json:"hash"`
-	Input            hexutil.Bytes     `json:"input"`
-	Nonce            hexutil.Uint64    `json:"nonce"`
-	To               *common.Address   `json:"to"`
-	TransactionIndex *hexutil.Uint64   `json:"transactionIndex"`
-	Value            *hexutil.Big      `json:"value"`
-	Type             hexutil.Uint64    `json:"type"`
-	Accesses         *types.AccessList `json:"accessList,omitempty"`
-	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
-	V                *hexutil.Big      `json:"v"`
-	R                *hexutil.Big      `json:"r"`
-	S                *hexutil.Big      `json:"s"`
+	BlockHash         *common.Hash            `json:"blockHash"`
+	BlockNumber       *hexutil.Big            `json:"blockNumber"`
+	From              common.Address          `json:"from"`
+	Gas               hexutil.Uint64          `json:"gas"`
+	GasPrice          *hexutil.Big            `json:"gasPrice"`
+	GasFeeCap         *hexutil.Big            `json:"maxFeePerGas,omitempty"`
+	GasTipCap         *hexutil.Big            `json:"maxPriorityFeePerGas,omitempty"`
+	Hash              common.Hash             `json:"hash"`
+	Input             hexutil.Bytes           `json:"input"`
+	Nonce             hexutil.Uint64          `json:"nonce"`
+	To                *common.Address         `json:"to"`
+	TransactionIndex  *hexutil.Uint64         `json:"transactionIndex"`
+	Value             *hexutil.Big            `json:"value"`
+	Type              hexutil.Uint64          `json:"type"`
+	Accesses          *types.AccessList       `json:"accessList,omitempty"`
+	ChainID           *hexutil.Big            `json:"chainId,omitempty"`
+	AuthorizationList types.AuthorizationList `json:"authorizationList,omitempty"`
+	V                 *hexutil.Big            `json:"v"`
+	R                 *hexutil.Big            `json:"r"`
+	S                 *hexutil.Big            `json:"s"`
 }
 
 // ethTxJSON is the JSON representation of Ethereum transaction.
@@ -771,6 +875,9 @@ type ethTxJSON struct {
 	ChainID    *hexutil.Big      `json:"chainId,omitempty"`
 	AccessList *types.AccessList `json:"accessList,omitempty"`
 
+	// Set code transaction fields:
+	AuthorizationList types.AuthorizationList `json:"authorizationList,omitempty"`
+
 	// Only used for encoding:
 	Hash common.Hash `json:"hash"`
 }
@@ -852,12 +959,13 @@ func newEthRPCTransaction(block *types.Block, tx *types.Transaction, blockHash c
 		al := tx.AccessList()
 		result.Accesses = &al
 		result.ChainID = (*hexutil.Big)(tx.ChainId())
-	case types.TxTypeEthereumDynamicFee:
+	case types.TxTypeEthereumDynamicFee, types.TxTypeEthereumSetCode:
 		al := tx.AccessList()
 		result.Accesses = &al
 		result.ChainID = (*hexutil.Big)(tx.ChainId())
 		result.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
 		result.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
+		result.AuthorizationList = tx.AuthorizationList()
 		if block != nil {
 			result.GasPrice = (*hexutil.Big)(tx.EffectiveGasPrice(block.Header(), config))
 		} else {
@@ -913,12 +1021,13 @@ func formatTxToEthTxJSON(tx *types.Transaction) *ethTxJSON {
 		enc.AccessList = &al
 		enc.ChainID = (*hexutil.Big)(tx.ChainId())
 		enc.GasPrice = (*hexutil.Big)(tx.GasPrice())
-	case types.TxTypeEthereumDynamicFee:
+	case types.TxTypeEthereumDynamicFee, types.TxTypeEthereumSetCode:
 		al := tx.AccessList()
 		enc.AccessList = &al
 		enc.ChainID = (*hexutil.Big)(tx.ChainId())
 		enc.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
 		enc.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+		enc.AuthorizationList = tx.AuthorizationList()
 	default:
 		enc.GasPrice = (*hexutil.Big)(tx.GasPrice())
 	}
@@ -992,8 +1101,8 @@ func (api *EthereumAPI) GetTransactionByHash(ctx context.Context, hash common.Ha
 	if tx := txpoolAPI.GetPoolTransaction(hash); tx != nil {
 		return newEthRPCPendingTransaction(tx, api.publicBlockChainAPI.b.ChainConfig()), nil
 	}
-	// Transaction unknown, return as such
-	return nil, nil
+	// Transaction unknown, its block body may have been pruned
+	return nil, checkPrunedTransaction(txpoolAPI, hash)
 }
 
 // GetRawTransactionByHash returns the bytes of the transaction for the given hash.
@@ -1016,9 +1125,15 @@ func (api *EthereumAPI) GetTransactionReceipt(ctx context.Context, hash common.H
 	tx, blockHash, blockNumber, index, receipt := txpoolAPI.GetTxLookupInfoAndReceipt(ctx, hash)
 
 	if tx == nil {
-		return nil, nil
+		return nil, checkPrunedTransaction(txpoolAPI, hash)
 	}
 	receipts := txpoolAPI.GetBlockReceipts(ctx, blockHash)
+	if receipt == nil || uint64(len(receipts)) <= index {
+		if err := CheckPrunedHistory(txpoolAPI, blockNumber); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
 	cumulativeGasUsed := uint64(0)
 	for i := uint64(0); i <= index; i++ {
 		cumulativeGasUsed += receipts[i].GasUsed
@@ -1053,6 +1168,9 @@ func (api *EthereumAPI) GetBlockReceipts(ctx context.Context, blockNrOrHash rpc.
 		outputList        = make([]map[string]interface{}, 0, len(receipts))
 	)
 	if receipts.Len() != txs.Len() {
+		if err := CheckPrunedHistory(b, blockNumber); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("the size of transactions and receipts is different in the block (%s)", blockHash.String())
 	}
 	for index, receipt := range receipts {
@@ -1361,7 +1479,7 @@ func (api *EthereumAPI) rpcMarshalBlock(block *types.Block, inclMiner, inclTx, f
 	return fields, nil
 }
 
-func EthDoCall(ctx context.Context, b Backend, args EthTransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *EthStateOverride, timeout time.Duration, globalGasCap uint64) (*blockchain.ExecutionResult, error) {
+func EthDoCall(ctx context.Context, b Backend, args EthTransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *EthStateOverride, blockOverrides *EthBlockOverrides, timeout time.Duration, globalGasCap uint64) (*blockchain.ExecutionResult, error) {
 	defer func(start time.Time) { logger.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
 	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
@@ -1371,6 +1489,8 @@ func EthDoCall(ctx context.Context, b Backend, args EthTransactionArgs, blockNrO
 	if err := overrides.Apply(state); err != nil {
 		return nil, err
 	}
+	blockHeader := header
+	header = blockOverrides.MakeHeader(header)
 	// Setup context so it may be cancelled the call has completed
 	// or, in case of unmetered gas, setup a context with a timeout.
 	var cancel context.CancelFunc
@@ -1409,7 +1529,7 @@ func EthDoCall(ctx context.Context, b Backend, args EthTransactionArgs, blockNrO
 	if msg.Gas() < intrinsicGas {
 		return nil, fmt.Errorf("%w: msg.gas %d, want %d", blockchain.ErrIntrinsicGas, msg.Gas(), intrinsicGas)
 	}
-	evm, vmError, err := b.GetEVM(ctx, msg, state, header, vm.Config{ComputationCostLimit: params.OpcodeComputationCostLimitInfinite})
+	evm, vmError, err := getEVMWithOverrides(ctx, b, msg, state, blockHeader, blockOverrides, vm.Config{ComputationCostLimit: params.OpcodeComputationCostLimitInfinite})
 	if err != nil {
 		return nil, err
 	}
@@ -1435,7 +1555,7 @@ func EthDoCall(ctx context.Context, b Backend, args EthTransactionArgs, blockNrO
 	return result, nil
 }
 
-func EthDoEstimateGas(ctx context.Context, b Backend, args EthTransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, gasCap uint64) (hexutil.Uint64, error) {
+func EthDoEstimateGas(ctx context.Context, b Backend, args EthTransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *EthStateOverride, blockOverrides *EthBlockOverrides, gasCap uint64) (hexutil.Uint64, error) {
 	// Use zero address if sender unspecified.
 	if args.From == nil {
 		args.From = new(common.Address)
@@ -1460,11 +1580,14 @@ func EthDoEstimateGas(ctx context.Context, b Backend, args EthTransactionArgs, b
 	if err != nil {
 		return 0, err
 	}
+	if err := overrides.Apply(state); err != nil {
+		return 0, err
+	}
 	balance := state.GetBalance(*args.From) // from can't be nil
 
 	executable := func(gas uint64) (bool, *blockchain.ExecutionResult, error) {
 		args.Gas = (*hexutil.Uint64)(&gas)
-		result, err := EthDoCall(ctx, b, args, rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber), nil, b.RPCEVMTimeout(), gasCap)
+		result, err := EthDoCall(ctx, b, args, blockNrOrHash, overrides, blockOverrides, b.RPCEVMTimeout(), gasCap)
 		if err != nil {
 			if errors.Is(err, blockchain.ErrIntrinsicGas) {
 				return true, nil, nil // Special case, raise gas limit
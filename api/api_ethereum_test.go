@@ -2600,6 +2600,79 @@ func TestEthereumAPI_EstimateGas(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	testEstimateGas(t, mockBackend, func(args EthTransactionArgs) (hexutil.Uint64, error) {
-		return api.EstimateGas(context.Background(), args, nil)
+		return api.EstimateGas(context.Background(), args, nil, nil, nil)
 	})
 }
+
+func TestEthereumAPI_CallWithOverrides(t *testing.T) {
+	mockCtrl, mockBackend, api := testInitForEthApi(t)
+	defer mockCtrl.Finish()
+
+	var (
+		account1 = common.HexToAddress("0xaaaa")
+		account2 = common.HexToAddress("0xbbbb")
+		latest   = rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		// NUMBER PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+		codeNumber = hexutil.Bytes(hexutil.MustDecode("0x4360005260206000f3"))
+		// TIMESTAMP PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+		codeTime = hexutil.Bytes(hexutil.MustDecode("0x4260005260206000f3"))
+		KAIA     = hexutil.Big(*big.NewInt(params.KAIA))
+	)
+	testCallBackend(t, mockBackend, blockchain.GenesisAlloc{account1: {Balance: common.Big0}})
+
+	// Without overrides, account2 has no code and account1 has no balance.
+	ret, err := api.Call(context.Background(), EthTransactionArgs{From: &account1, To: &account2}, latest, nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, ret)
+	_, err = api.Call(context.Background(), EthTransactionArgs{From: &account1, To: &account2, Value: &KAIA}, latest, nil, nil)
+	assert.Error(t, err)
+
+	balance := &KAIA
+	overrides := &EthStateOverride{
+		account1: EthOverrideAccount{Balance: &balance},
+		account2: EthOverrideAccount{Code: &codeNumber},
+	}
+	_, err = api.Call(context.Background(), EthTransactionArgs{From: &account1, To: &account2, Value: &KAIA}, latest, overrides, nil)
+	assert.NoError(t, err)
+
+	number := hexutil.Big(*big.NewInt(1000))
+	ret, err = api.Call(context.Background(), EthTransactionArgs{From: &account1, To: &account2}, latest, overrides, &EthBlockOverrides{Number: &number})
+	require.NoError(t, err)
+	assert.Equal(t, common.BigToHash(big.NewInt(1000)).Bytes(), []byte(ret))
+
+	timestamp := hexutil.Uint64(1234)
+	overrides = &EthStateOverride{account2: EthOverrideAccount{Code: &codeTime}}
+	ret, err = api.publicBlockChainAPI.Call(context.Background(), CallArgs{From: account1, To: &account2}, latest, overrides, &EthBlockOverrides{Time: &timestamp})
+	require.NoError(t, err)
+	assert.Equal(t, common.BigToHash(big.NewInt(1234)).Bytes(), []byte(ret))
+
+	// The state overrides are taken into account when estimating the gas.
+	_, err = api.EstimateGas(context.Background(), EthTransactionArgs{From: &account1, To: &account2, Value: &KAIA}, nil, nil, nil)
+	assert.Error(t, err)
+	gas, err := api.EstimateGas(context.Background(), EthTransactionArgs{From: &account1, To: &account2, Value: &KAIA}, nil, &EthStateOverride{
+		account1: EthOverrideAccount{Balance: &balance},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, hexutil.Uint64(params.TxGas), gas)
+
+	_, err = api.publicBlockChainAPI.EstimateGas(context.Background(), CallArgs{From: account1, To: &account2, Value: KAIA}, nil, nil, nil)
+	assert.Error(t, err)
+	gas, err = api.publicBlockChainAPI.EstimateGas(context.Background(), CallArgs{From: account1, To: &account2, Value: KAIA}, nil, &EthStateOverride{
+		account1: EthOverrideAccount{Balance: &balance},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, hexutil.Uint64(params.TxGas), gas)
+
+	// The block overrides are taken into account when estimating the gas.
+	// The code reverts unless the block number is 1000.
+	codeAssertNumber := hexutil.Bytes(hexutil.MustDecode("0x436103e814600c57600080fd5b00"))
+	overrides = &EthStateOverride{account2: EthOverrideAccount{Code: &codeAssertNumber}}
+	_, err = api.EstimateGas(context.Background(), EthTransactionArgs{From: &account1, To: &account2}, nil, overrides, nil)
+	assert.Error(t, err)
+	_, err = api.EstimateGas(context.Background(), EthTransactionArgs{From: &account1, To: &account2}, nil, overrides, &EthBlockOverrides{Number: &number})
+	assert.NoError(t, err)
+	_, err = api.publicBlockChainAPI.EstimateGas(context.Background(), CallArgs{From: account1, To: &account2}, nil, overrides, nil)
+	assert.Error(t, err)
+	_, err = api.publicBlockChainAPI.EstimateGas(context.Background(), CallArgs{From: account1, To: &account2}, nil, overrides, &EthBlockOverrides{Number: &number})
+	assert.NoError(t, err)
+}
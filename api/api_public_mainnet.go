@@ -54,7 +54,7 @@ func (s *PublicBlockChainAPI) callCypressCreditGetFunc(ctx context.Context, pars
 		To:   &mainnetCreditContractAddress,
 		Data: abiGet,
 	}
-	ret, err := s.Call(ctx, args, latestBlockNrOrHash)
+	ret, err := s.Call(ctx, args, latestBlockNrOrHash, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -123,7 +123,7 @@ func (s *PublicTransactionPoolAPI) GetTransactionCount(ctx context.Context, addr
 	return (*hexutil.Uint64)(&nonce), state.Error()
 }
 
-func (s *PublicTransactionPoolAPI) GetTransactionBySenderTxHash(ctx context.Context, senderTxHash common.Hash) map[string]interface{} {
+func (s *PublicTransactionPoolAPI) GetTransactionBySenderTxHash(ctx context.Context, senderTxHash common.Hash) (map[string]interface{}, error) {
 	txhash := s.b.ChainDB().ReadTxHashFromSenderTxHash(senderTxHash)
 	if common.EmptyHash(txhash) {
 		txhash = senderTxHash
@@ -132,17 +132,17 @@ func (s *PublicTransactionPoolAPI) GetTransactionBySenderTxHash(ctx context.Cont
 }
 
 // GetTransactionByHash returns the transaction for the given hash
-func (s *PublicTransactionPoolAPI) GetTransactionByHash(ctx context.Context, hash common.Hash) map[string]interface{} {
+func (s *PublicTransactionPoolAPI) GetTransactionByHash(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
 	// Try to return an already finalized transaction
 	if tx, blockHash, blockNumber, index := s.b.ChainDB().ReadTxAndLookupInfo(hash); tx != nil {
-		return newRPCTransaction(nil, tx, blockHash, blockNumber, index, s.b.ChainConfig())
+		return newRPCTransaction(nil, tx, blockHash, blockNumber, index, s.b.ChainConfig()), nil
 	}
 	// No finalized transaction, try to retrieve it from the pool
 	if tx := s.b.GetPoolTransaction(hash); tx != nil {
-		return newRPCPendingTransaction(tx, s.b.ChainConfig())
+		return newRPCPendingTransaction(tx, s.b.ChainConfig()), nil
 	}
-	// Transaction unknown, return as such
-	return nil
+	// Transaction unknown, its block body may have been pruned
+	return nil, checkPrunedTransaction(s.b, hash)
 }
 
 // GetDecodedAnchoringTransactionByHash returns the decoded anchoring data of anchoring transaction for the given hash
@@ -254,6 +254,14 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceiptBySenderTxHash(ctx conte
 // GetTransactionReceipt returns the transaction receipt for the given transaction hash.
 func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
 	tx, blockHash, blockNumber, index, receipt := s.b.GetTxLookupInfoAndReceipt(ctx, hash)
+	if tx == nil {
+		return nil, checkPrunedTransaction(s.b, hash)
+	}
+	if receipt == nil {
+		if err := CheckPrunedHistory(s.b, blockNumber); err != nil {
+			return nil, err
+		}
+	}
 	return s.getTransactionReceipt(ctx, tx, blockHash, blockNumber, index, receipt)
 }
 
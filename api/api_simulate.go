@@ -0,0 +1,394 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+)
+
+const (
+	// maxSimulateBlocks is the maximum number of blocks that can be simulated in a single request.
+	maxSimulateBlocks = 256
+	// maxSimulateCalls is the maximum number of calls in a single simulated block.
+	maxSimulateCalls = 1000
+)
+
+var (
+	errSimulateNoBlocks       = errors.New("empty input")
+	errSimulateTooManyBlocks  = fmt.Errorf("too many blocks to simulate (max %d)", maxSimulateBlocks)
+	errSimulateTooManyCalls   = fmt.Errorf("too many calls in a block (max %d)", maxSimulateCalls)
+	errSimulateBlockGasLimit  = errors.New("block gas limit reached")
+	errSimulateGasCap         = errors.New("gas cap of the request reached")
+	errSimulateBlockNumber    = errors.New("block numbers must be in order")
+	errSimulateBlockTimestamp = errors.New("block timestamps must be in order")
+	errSimulateSenderKey      = errors.New("account key of the sender cannot sign transactions")
+)
+
+// SimBlock is a single block of a simulation. The block header is derived from
+// the previous block and may be modified by BlockOverrides. StateOverrides are
+// applied before the calls of the block are executed.
+type SimBlock struct {
+	BlockOverrides *EthBlockOverrides   `json:"blockOverrides"`
+	StateOverrides *EthStateOverride    `json:"stateOverrides"`
+	Calls          []EthTransactionArgs `json:"calls"`
+}
+
+// SimOpts are the inputs to eth_simulateV1 and kaia_simulate.
+//
+// Calls are never signed, so no signature is verified in either mode. If
+// Validation is false, the nonce of the calls is not checked and the base fee
+// defaults to zero so that the senders don't need to pay for gas. If Validation
+// is true, the base fee of the parent block applies and every call is rejected
+// unless its nonce matches the state, its gas price is not below the base fee,
+// the sender can pay for the gas, and the account key of the sender is able to
+// sign transactions, e.g. it is not AccountKeyFail as for smart contracts.
+type SimOpts struct {
+	BlockStateCalls []SimBlock `json:"blockStateCalls"`
+	Validation      bool       `json:"validation"`
+}
+
+// SimCallResult is the receipt-like result of a simulated call. Status is the
+// receipt status of Kaia in kaia_simulate, and 0 (failure) or 1 (success) in
+// eth_simulateV1 as in the receipts of the eth namespace.
+type SimCallResult struct {
+	ReturnValue hexutil.Bytes  `json:"returnData"`
+	Logs        []*types.Log   `json:"logs"`
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+	Status      hexutil.Uint64 `json:"status"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// SimBlockResult is the result of a simulated block.
+type SimBlockResult struct {
+	Number        hexutil.Uint64  `json:"number"`
+	Hash          common.Hash     `json:"hash"`
+	ParentHash    common.Hash     `json:"parentHash"`
+	Timestamp     hexutil.Uint64  `json:"timestamp"`
+	GasUsed       hexutil.Uint64  `json:"gasUsed"`
+	BaseFeePerGas *hexutil.Big    `json:"baseFeePerGas,omitempty"`
+	Calls         []SimCallResult `json:"calls"`
+}
+
+// SimulateV1 executes a series of blocks of calls on top of the state of the given
+// block and returns the results of every call. Each block is executed on top of
+// the state left by the previous one, and nothing is written to the blockchain.
+func (api *EthereumAPI) SimulateV1(ctx context.Context, opts SimOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]*SimBlockResult, error) {
+	results, err := api.publicBlockChainAPI.Simulate(ctx, opts, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	// In Ethereum, status field can have 0(=Failure) or 1(=Success) only.
+	for _, result := range results {
+		for i := range result.Calls {
+			if result.Calls[i].Status != hexutil.Uint64(types.ReceiptStatusSuccessful) {
+				result.Calls[i].Status = hexutil.Uint64(types.ReceiptStatusFailed)
+			}
+		}
+	}
+	return results, nil
+}
+
+// Simulate executes a series of blocks of calls on top of the state of the given
+// block and returns the results of every call. Each block is executed on top of
+// the state left by the previous one, and nothing is written to the blockchain.
+func (s *PublicBlockChainAPI) Simulate(ctx context.Context, opts SimOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]*SimBlockResult, error) {
+	bNrOrHash := rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	gasCap := uint64(0)
+	if rpcGasCap := s.b.RPCGasCap(); rpcGasCap != nil {
+		gasCap = rpcGasCap.Uint64()
+	}
+	return DoSimulate(ctx, s.b, opts, bNrOrHash, s.b.RPCEVMTimeout(), gasCap)
+}
+
+// DoSimulate executes the simulated blocks of opts on top of the state of the
+// given block. The whole simulation is aborted if it takes longer than timeout,
+// or if its calls use more than globalGasCap gas in total.
+func DoSimulate(ctx context.Context, b Backend, opts SimOpts, blockNrOrHash rpc.BlockNumberOrHash, timeout time.Duration, globalGasCap uint64) ([]*SimBlockResult, error) {
+	defer func(start time.Time) { logger.Debug("Executing EVM simulation finished", "runtime", time.Since(start)) }(time.Now())
+
+	if len(opts.BlockStateCalls) == 0 {
+		return nil, errSimulateNoBlocks
+	}
+	if len(opts.BlockStateCalls) > maxSimulateBlocks {
+		return nil, errSimulateTooManyBlocks
+	}
+	for _, block := range opts.BlockStateCalls {
+		if len(block.Calls) > maxSimulateCalls {
+			return nil, errSimulateTooManyCalls
+		}
+	}
+	state, base, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	// Setup context so it may be cancelled the simulation has completed
+	// or, in case of unmetered gas, setup a context with a timeout.
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	var (
+		results = make([]*SimBlockResult, len(opts.BlockStateCalls))
+		hashes  = make(map[uint64]common.Hash, len(opts.BlockStateCalls))
+		parent  = base
+		budget  = globalGasCap
+	)
+	if budget == 0 {
+		budget = math.MaxUint64
+	}
+	for i, block := range opts.BlockStateCalls {
+		header, overrides, err := makeSimHeader(parent, block.BlockOverrides, opts.Validation)
+		if err != nil {
+			return nil, err
+		}
+		if err := block.StateOverrides.Apply(state); err != nil {
+			return nil, err
+		}
+		result, err := simulateBlock(ctx, b, state, base, header, overrides, block.Calls, hashes, opts.Validation, &budget)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", header.Number, err)
+		}
+		if timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		}
+		results[i] = result
+		hashes[header.Number.Uint64()] = result.Hash
+		parent = header
+	}
+	return results, nil
+}
+
+// makeSimHeader derives the header of the next simulated block from its parent.
+// It also returns the overrides turning the context of the base block into the
+// context of the simulated block.
+func makeSimHeader(parent *types.Header, overrides *EthBlockOverrides, validation bool) (*types.Header, *EthBlockOverrides, error) {
+	if overrides == nil {
+		overrides = new(EthBlockOverrides)
+	}
+	if overrides.Number != nil && overrides.Number.ToInt().Cmp(parent.Number) <= 0 {
+		return nil, nil, fmt.Errorf("%w: block %v, parent %v", errSimulateBlockNumber, overrides.Number.ToInt(), parent.Number)
+	}
+	if overrides.Time != nil && uint64(*overrides.Time) <= parent.Time.Uint64() {
+		return nil, nil, fmt.Errorf("%w: block %d, parent %v", errSimulateBlockTimestamp, uint64(*overrides.Time), parent.Time)
+	}
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Rewardbase: parent.Rewardbase,
+		BlockScore: new(big.Int).Set(parent.BlockScore),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Time:       new(big.Int).Add(parent.Time, common.Big1),
+		MixHash:    parent.MixHash,
+	}
+	// Without validation, calls are free unless the base fee is overridden.
+	if parent.BaseFee != nil {
+		if validation {
+			header.BaseFee = new(big.Int).Set(parent.BaseFee)
+		} else {
+			header.BaseFee = new(big.Int)
+		}
+	}
+	header = overrides.MakeHeader(header)
+
+	full := &EthBlockOverrides{
+		Number:     (*hexutil.Big)(header.Number),
+		Difficulty: (*hexutil.Big)(header.BlockScore),
+		Time:       (*hexutil.Uint64)(new(uint64)),
+		GasLimit:   overrides.GasLimit,
+		Coinbase:   overrides.Coinbase,
+		Rewardbase: &header.Rewardbase,
+		BaseFee:    (*hexutil.Big)(header.BaseFee),
+	}
+	*full.Time = hexutil.Uint64(header.Time.Uint64())
+	if header.MixHash != nil {
+		random := common.BytesToHash(header.MixHash)
+		full.Random = &random
+	}
+	return header, full, nil
+}
+
+// simulateBlock executes the calls of a simulated block on top of the given state.
+// The EVM context is derived from the base block, the block the simulation starts
+// from, with the overrides of the simulated block applied. The gas of each call is
+// capped by the gas left in the block and in the budget of the request, and the
+// gas used by the calls is deducted from the budget.
+func simulateBlock(ctx context.Context, b Backend, state *state.StateDB, base, header *types.Header, overrides *EthBlockOverrides, calls []EthTransactionArgs, hashes map[uint64]common.Hash, validation bool, budget *uint64) (*SimBlockResult, error) {
+	var (
+		blockHash = header.Hash()
+		gasLimit  = params.UpperGasLimit
+		gasUsed   uint64
+		results   = make([]SimCallResult, len(calls))
+	)
+	if overrides.GasLimit != nil {
+		gasLimit = uint64(*overrides.GasLimit)
+	}
+	baseFee := new(big.Int).SetUint64(params.ZeroBaseFee)
+	if header.BaseFee != nil {
+		baseFee = header.BaseFee
+	}
+	for i, args := range calls {
+		if *budget == 0 {
+			return nil, fmt.Errorf("call %d: %w", i, errSimulateGasCap)
+		}
+		remaining := gasLimit - gasUsed
+		if remaining == 0 {
+			return nil, fmt.Errorf("call %d: %w", i, errSimulateBlockGasLimit)
+		}
+		if args.Gas != nil && uint64(*args.Gas) > remaining {
+			return nil, fmt.Errorf("call %d: %w: gas %d, remaining %d", i, errSimulateBlockGasLimit, uint64(*args.Gas), remaining)
+		}
+		if remaining > *budget {
+			remaining = *budget
+		}
+		msg, err := simulateMessage(args, state, header, b.ChainConfig(), baseFee, validation, remaining)
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		// Calls are identified by their position since they have no signature.
+		txHash := crypto.Keccak256Hash(common.BigToHash(header.Number).Bytes(), common.Int64ToByteBigEndian(uint64(i)))
+		state.SetTxContext(txHash, blockHash, i)
+
+		evm, vmError, err := getEVMWithOverrides(ctx, b, msg, state, base, overrides, vm.Config{ComputationCostLimit: params.OpcodeComputationCostLimitInfinite})
+		if err != nil {
+			return nil, err
+		}
+		getHash := evm.Context.GetHash
+		evm.Context.GetHash = func(n uint64) common.Hash {
+			if hash, ok := hashes[n]; ok {
+				return hash
+			}
+			return getHash(n)
+		}
+		// Wait for the context to be done and cancel the evm. Even if the
+		// EVM has finished, cancelling may be done (repeatedly)
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				evm.Cancel(vm.CancelByCtxDone)
+			case <-done:
+			}
+		}()
+		result, err := blockchain.ApplyMessage(evm, msg)
+		close(done)
+		if err := vmError(); err != nil {
+			return nil, err
+		}
+		if evm.Cancelled() {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		state.Finalise(true, true)
+
+		logs := state.GetLogs(txHash)
+		for _, log := range logs {
+			log.BlockNumber = header.Number.Uint64()
+		}
+		if logs == nil {
+			logs = []*types.Log{}
+		}
+		results[i] = SimCallResult{
+			ReturnValue: result.ReturnData,
+			Logs:        logs,
+			GasUsed:     hexutil.Uint64(result.UsedGas),
+			Status:      hexutil.Uint64(result.VmExecutionStatus),
+		}
+		if len(result.Revert()) > 0 {
+			results[i].Error = blockchain.NewRevertError(result).Error()
+		} else if err := result.Unwrap(); err != nil {
+			results[i].Error = err.Error()
+		}
+		gasUsed += result.UsedGas
+		*budget -= result.UsedGas
+	}
+	res := &SimBlockResult{
+		Number:     hexutil.Uint64(header.Number.Uint64()),
+		Hash:       blockHash,
+		ParentHash: header.ParentHash,
+		Timestamp:  hexutil.Uint64(header.Time.Uint64()),
+		GasUsed:    hexutil.Uint64(gasUsed),
+		Calls:      results,
+	}
+	if header.BaseFee != nil {
+		res.BaseFeePerGas = (*hexutil.Big)(header.BaseFee)
+	}
+	return res, nil
+}
+
+// simulateMessage converts the arguments of a simulated call into a message.
+// With validation, the gas price and the account key of the sender are checked,
+// and the nonce of the message is checked against the state on execution.
+func simulateMessage(args EthTransactionArgs, state *state.StateDB, header *types.Header, config *params.ChainConfig, baseFee *big.Int, validation bool, globalGasCap uint64) (*types.Transaction, error) {
+	intrinsicGas, err := types.IntrinsicGas(args.data(), nil, args.To == nil, config.Rules(header.Number))
+	if err != nil {
+		return nil, err
+	}
+	msg, err := args.ToMessage(globalGasCap, baseFee, intrinsicGas)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Gas() < intrinsicGas {
+		return nil, fmt.Errorf("%w: msg.gas %d, want %d", blockchain.ErrIntrinsicGas, msg.Gas(), intrinsicGas)
+	}
+	if !validation {
+		return msg, nil
+	}
+	if msg.GasPrice().Cmp(baseFee) < 0 {
+		return nil, fmt.Errorf("%w: gas price %v, base fee %v", blockchain.ErrGasPriceBelowBaseFee, msg.GasPrice(), baseFee)
+	}
+	if !canSignTransactions(state.GetKey(msg.ValidatedSender())) {
+		return nil, fmt.Errorf("%w: %v", errSimulateSenderKey, msg.ValidatedSender())
+	}
+	nonce := state.GetNonce(msg.ValidatedSender())
+	if args.Nonce != nil {
+		nonce = uint64(*args.Nonce)
+	}
+	return types.NewMessage(msg.ValidatedSender(), msg.To(), nonce, msg.Value(), msg.Gas(), msg.GasPrice(), msg.Data(), true, intrinsicGas, msg.AccessList()), nil
+}
+
+// canSignTransactions reports whether the given account key, or its transaction
+// role if it is role-based, can validate the signature of a transaction at all.
+func canSignTransactions(key accountkey.AccountKey) bool {
+	if roleBased, ok := key.(*accountkey.AccountKeyRoleBased); ok && len(*roleBased) > 0 {
+		key = (*roleBased)[accountkey.RoleTransaction]
+	}
+	return key.Type() != accountkey.AccountKeyTypeFail
+}
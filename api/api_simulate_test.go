@@ -0,0 +1,215 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mock_api "github.com/klaytn/klaytn/api/mocks"
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCallBackend sets up mockBackend to execute calls on top of a genesis block
+// with the given accounts, and returns the genesis header.
+func testCallBackend(t *testing.T, mockBackend *mock_api.MockBackend, alloc blockchain.GenesisAlloc) *types.Header {
+	chainConfig := &params.ChainConfig{}
+	chainConfig.IstanbulCompatibleBlock = common.Big0
+	chainConfig.LondonCompatibleBlock = common.Big0
+	chainConfig.EthTxTypeCompatibleBlock = common.Big0
+	chainConfig.MagmaCompatibleBlock = common.Big0
+	chainConfig.KoreCompatibleBlock = common.Big0
+	chainConfig.ShanghaiCompatibleBlock = common.Big0
+	chainConfig.CancunCompatibleBlock = common.Big0
+	chainConfig.KaiaCompatibleBlock = common.Big0
+	var (
+		gspec  = &blockchain.Genesis{Alloc: alloc, Config: chainConfig}
+		dbm    = database.NewMemoryDBManager()
+		db     = state.NewDatabase(dbm)
+		block  = gspec.MustCommit(dbm)
+		header = block.Header()
+		chain  = &testChainContext{header: header}
+	)
+
+	any := gomock.Any()
+	getStateAndHeader := func(...interface{}) (*state.StateDB, *types.Header, error) {
+		state, err := state.New(block.Root(), db, nil, nil)
+		return state, header, err
+	}
+	getEVM := func(_ context.Context, msg blockchain.Message, state *state.StateDB, header *types.Header, vmConfig vm.Config) (*vm.EVM, func() error, error) {
+		vmError := func() error { return nil }
+		txContext := blockchain.NewEVMTxContext(msg, header, chainConfig)
+		blockContext := blockchain.NewEVMBlockContext(header, chain, nil)
+		return vm.NewEVM(blockContext, txContext, state, chainConfig, &vmConfig), vmError, nil
+	}
+	mockBackend.EXPECT().ChainConfig().Return(chainConfig).AnyTimes()
+	mockBackend.EXPECT().RPCGasCap().Return(common.Big0).AnyTimes()
+	mockBackend.EXPECT().RPCEVMTimeout().Return(5 * time.Second).AnyTimes()
+	mockBackend.EXPECT().StateAndHeaderByNumber(any, any).DoAndReturn(getStateAndHeader).AnyTimes()
+	mockBackend.EXPECT().StateAndHeaderByNumberOrHash(any, any).DoAndReturn(getStateAndHeader).AnyTimes()
+	mockBackend.EXPECT().GetEVM(any, any, any, any, any).DoAndReturn(getEVM).AnyTimes()
+	return header
+}
+
+func TestEthereumAPI_SimulateV1(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockBackend := mock_api.NewMockBackend(mockCtrl)
+	api := &EthereumAPI{publicBlockChainAPI: NewPublicBlockChainAPI(mockBackend)}
+
+	var (
+		account1 = common.HexToAddress("0xaaaa")
+		account2 = common.HexToAddress("0xbbbb")
+		account3 = common.HexToAddress("0xcccc")
+		account4 = common.HexToAddress("0xdddd")
+		header   = testCallBackend(t, mockBackend, blockchain.GenesisAlloc{
+			account1: {Balance: big.NewInt(params.KAIA * 2)},
+			account2: {Balance: common.Big0},
+			account3: {Balance: common.Big0, Code: hexutil.MustDecode(codeRevertHello)},
+		})
+
+		KAIA  = hexutil.Big(*big.NewInt(params.KAIA))
+		mKAIA = hexutil.Big(*big.NewInt(params.KAIA / 1000))
+		// PUSH1 0 PUSH1 0 LOG0
+		codeLog0 = hexutil.Bytes(hexutil.MustDecode("0x60006000a0"))
+	)
+
+	// account2 can only pay back account1 after receiving from it in the previous block.
+	number := hexutil.Big(*big.NewInt(10))
+	opts := SimOpts{BlockStateCalls: []SimBlock{
+		{
+			Calls: []EthTransactionArgs{{From: &account1, To: &account2, Value: &KAIA}},
+		},
+		{
+			BlockOverrides: &EthBlockOverrides{Number: &number},
+			StateOverrides: &EthStateOverride{account4: EthOverrideAccount{Code: &codeLog0}},
+			Calls: []EthTransactionArgs{
+				{From: &account2, To: &account1, Value: &KAIA},
+				{From: &account1, To: &account3},
+				{From: &account1, To: &account4},
+			},
+		},
+	}}
+	results, err := api.SimulateV1(context.Background(), opts, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, hexutil.Uint64(header.Number.Uint64()+1), results[0].Number)
+	assert.Equal(t, hexutil.Uint64(header.Time.Uint64()+1), results[0].Timestamp)
+	assert.Equal(t, header.Hash(), results[0].ParentHash)
+	assert.Equal(t, hexutil.Uint64(params.TxGas), results[0].GasUsed)
+	assert.Equal(t, hexutil.Uint64(types.ReceiptStatusSuccessful), results[0].Calls[0].Status)
+
+	assert.Equal(t, hexutil.Uint64(10), results[1].Number)
+	assert.Equal(t, results[0].Hash, results[1].ParentHash)
+	require.Len(t, results[1].Calls, 3)
+	assert.Equal(t, hexutil.Uint64(types.ReceiptStatusSuccessful), results[1].Calls[0].Status)
+	assert.Equal(t, hexutil.Uint64(types.ReceiptStatusFailed), results[1].Calls[1].Status)
+	assert.Equal(t, "execution reverted: hello", results[1].Calls[1].Error)
+	require.Len(t, results[1].Calls[2].Logs, 1)
+	assert.Equal(t, account4, results[1].Calls[2].Logs[0].Address)
+	assert.Equal(t, uint64(10), results[1].Calls[2].Logs[0].BlockNumber)
+	assert.Equal(t, results[1].Hash, results[1].Calls[2].Logs[0].BlockHash)
+
+	// kaia_simulate reports the receipt status of Kaia.
+	results, err = api.publicBlockChainAPI.Simulate(context.Background(), opts, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, hexutil.Uint64(types.ReceiptStatusSuccessful), results[1].Calls[0].Status)
+	assert.Equal(t, hexutil.Uint64(types.ReceiptStatusErrExecutionReverted), results[1].Calls[1].Status)
+
+	// With validation, the nonce and the gas fee are checked.
+	nonce := hexutil.Uint64(1)
+	_, err = api.SimulateV1(context.Background(), SimOpts{
+		BlockStateCalls: []SimBlock{{Calls: []EthTransactionArgs{{From: &account1, To: &account2, Nonce: &nonce}}}},
+		Validation:      true,
+	}, nil)
+	assert.ErrorIs(t, err, blockchain.ErrNonceTooHigh)
+	_, err = api.SimulateV1(context.Background(), SimOpts{
+		BlockStateCalls: []SimBlock{{Calls: []EthTransactionArgs{{From: &account2, To: &account1, GasPrice: &mKAIA}}}},
+		Validation:      true,
+	}, nil)
+	assert.Error(t, err)
+
+	// With validation, the gas price can't be below the base fee, and smart
+	// contracts can't send calls since their account key can't sign.
+	_, err = api.SimulateV1(context.Background(), SimOpts{
+		BlockStateCalls: []SimBlock{{
+			BlockOverrides: &EthBlockOverrides{BaseFee: &KAIA},
+			Calls:          []EthTransactionArgs{{From: &account1, To: &account2, GasPrice: &mKAIA}},
+		}},
+		Validation: true,
+	}, nil)
+	assert.ErrorIs(t, err, blockchain.ErrGasPriceBelowBaseFee)
+	_, err = api.SimulateV1(context.Background(), SimOpts{
+		BlockStateCalls: []SimBlock{{Calls: []EthTransactionArgs{{From: &account3, To: &account1}}}},
+		Validation:      true,
+	}, nil)
+	assert.ErrorIs(t, err, errSimulateSenderKey)
+	_, err = api.SimulateV1(context.Background(), SimOpts{
+		BlockStateCalls: []SimBlock{{Calls: []EthTransactionArgs{{From: &account3, To: &account1}}}},
+	}, nil)
+	assert.NoError(t, err)
+
+	// Block numbers must increase.
+	_, err = api.SimulateV1(context.Background(), SimOpts{BlockStateCalls: []SimBlock{
+		{BlockOverrides: &EthBlockOverrides{Number: &number}},
+		{BlockOverrides: &EthBlockOverrides{Number: &number}},
+	}}, nil)
+	assert.ErrorIs(t, err, errSimulateBlockNumber)
+
+	_, err = api.SimulateV1(context.Background(), SimOpts{}, nil)
+	assert.ErrorIs(t, err, errSimulateNoBlocks)
+
+	// The calls of a block can't use more gas than the block gas limit.
+	gasLimit, gas := hexutil.Uint64(2*params.TxGas), hexutil.Uint64(params.TxGas)
+	transfer := EthTransactionArgs{From: &account1, To: &account2, Gas: &gas}
+	_, err = api.SimulateV1(context.Background(), SimOpts{BlockStateCalls: []SimBlock{{
+		BlockOverrides: &EthBlockOverrides{GasLimit: &gasLimit},
+		Calls:          []EthTransactionArgs{transfer, transfer, transfer},
+	}}}, nil)
+	assert.ErrorIs(t, err, errSimulateBlockGasLimit)
+	_, err = api.SimulateV1(context.Background(), SimOpts{BlockStateCalls: []SimBlock{{
+		Calls: make([]EthTransactionArgs, maxSimulateCalls+1),
+	}}}, nil)
+	assert.ErrorIs(t, err, errSimulateTooManyCalls)
+
+	// The gas cap applies to all the calls of the request.
+	latest := rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	opts = SimOpts{BlockStateCalls: []SimBlock{
+		{Calls: []EthTransactionArgs{transfer}},
+		{Calls: []EthTransactionArgs{transfer}},
+	}}
+	_, err = DoSimulate(context.Background(), mockBackend, opts, latest, 5*time.Second, 2*params.TxGas)
+	assert.NoError(t, err)
+	opts.BlockStateCalls[1].Calls = append(opts.BlockStateCalls[1].Calls, transfer)
+	_, err = DoSimulate(context.Background(), mockBackend, opts, latest, 5*time.Second, 2*params.TxGas)
+	assert.ErrorIs(t, err, errSimulateGasCap)
+}
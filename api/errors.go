@@ -0,0 +1,59 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+// PrunedHistoryError is returned when the requested block body, receipts or
+// logs have been deleted by the history pruning (see --history.retention).
+type PrunedHistoryError struct {
+	Tail uint64 // The oldest block whose body and receipts are retained
+}
+
+func (e *PrunedHistoryError) Error() string {
+	return fmt.Sprintf("pruned history unavailable: bodies and receipts before block %d have been pruned", e.Tail)
+}
+
+// ErrorCode returns the JSON error code for the pruned history.
+func (e *PrunedHistoryError) ErrorCode() int {
+	return 4444
+}
+
+// CheckPrunedHistory returns a PrunedHistoryError if the body and receipts of
+// the given block number have been pruned, nil otherwise.
+func CheckPrunedHistory(b Backend, number uint64) error {
+	if tail := b.ChainDB().ReadHistoryTail(); number != 0 && number < tail {
+		return &PrunedHistoryError{Tail: tail}
+	}
+	return nil
+}
+
+// checkPrunedTransaction returns a PrunedHistoryError if the lookup entry of
+// the given transaction points to a block whose body has been pruned, nil
+// otherwise. A transaction without a lookup entry is reported as unknown, as
+// it may as well be pending or nonexistent.
+func checkPrunedTransaction(b Backend, hash common.Hash) error {
+	blockHash, blockNumber, _ := b.ChainDB().ReadTxLookupEntry(hash)
+	if blockHash == (common.Hash{}) {
+		return nil
+	}
+	return CheckPrunedHistory(b, blockNumber)
+}
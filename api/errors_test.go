@@ -0,0 +1,118 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mock_api "github.com/klaytn/klaytn/api/mocks"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPrunedHistory(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	dbm := database.NewMemoryDBManager()
+	mockBackend := mock_api.NewMockBackend(mockCtrl)
+	mockBackend.EXPECT().ChainDB().Return(dbm).AnyTimes()
+
+	// Nothing is pruned yet.
+	assert.NoError(t, CheckPrunedHistory(mockBackend, 1))
+
+	dbm.WriteHistoryTail(100)
+	assert.NoError(t, CheckPrunedHistory(mockBackend, 0)) // genesis is never pruned
+	assert.NoError(t, CheckPrunedHistory(mockBackend, 100))
+
+	err := CheckPrunedHistory(mockBackend, 99)
+	if assert.Error(t, err) {
+		prunedErr, ok := err.(*PrunedHistoryError)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(100), prunedErr.Tail)
+		assert.Equal(t, 4444, prunedErr.ErrorCode())
+	}
+}
+
+func TestPrunedTransactionsAndReceipts(t *testing.T) {
+	mockCtrl, mockBackend, api := testInitForEthApi(t)
+	defer mockCtrl.Finish()
+
+	var (
+		ctx     = context.Background()
+		any     = gomock.Any()
+		dbm     = database.NewMemoryDBManager()
+		txHash  = common.HexToHash("0x1234")
+		tx      = types.NewTransaction(0, common.Address{}, big.NewInt(0), params.TxGas, big.NewInt(0), nil)
+		block   = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10)}).WithBody([]*types.Transaction{tx})
+		blockNr = rpc.NewBlockNumberOrHashWithNumber(10)
+	)
+	mockBackend.EXPECT().ChainDB().Return(dbm).AnyTimes()
+	mockBackend.EXPECT().ChainConfig().Return(dummyChainConfigForEthereumAPITest).AnyTimes()
+	mockBackend.EXPECT().GetPoolTransaction(any).Return(nil).AnyTimes()
+	mockBackend.EXPECT().GetTxLookupInfoAndReceipt(any, any).Return(nil, common.Hash{}, uint64(0), uint64(0), nil).AnyTimes()
+	mockBackend.EXPECT().BlockByNumberOrHash(any, any).Return(block, nil).AnyTimes()
+	mockBackend.EXPECT().GetBlockReceipts(any, any).Return(nil).AnyTimes()
+
+	// Unknown transactions are reported as such.
+	kaiaTx, err := api.publicTransactionPoolAPI.GetTransactionByHash(ctx, txHash)
+	assert.NoError(t, err)
+	assert.Nil(t, kaiaTx)
+	ethTx, err := api.GetTransactionByHash(ctx, txHash)
+	assert.NoError(t, err)
+	assert.Nil(t, ethTx)
+	receipt, err := api.GetTransactionReceipt(ctx, txHash)
+	assert.NoError(t, err)
+	assert.Nil(t, receipt)
+
+	// Unknown transactions are still reported as such after some history is
+	// pruned, as they may be pending or nonexistent.
+	dbm.WriteHistoryTail(100)
+	kaiaTx, err = api.publicTransactionPoolAPI.GetTransactionByHash(ctx, txHash)
+	assert.NoError(t, err)
+	assert.Nil(t, kaiaTx)
+	ethTx, err = api.GetTransactionByHash(ctx, txHash)
+	assert.NoError(t, err)
+	assert.Nil(t, ethTx)
+	receipt, err = api.GetTransactionReceipt(ctx, txHash)
+	assert.NoError(t, err)
+	assert.Nil(t, receipt)
+
+	// Transactions whose lookup entry points below the history tail are
+	// reported as pruned.
+	dbm.WriteTxLookupEntries(block)
+	var prunedErr *PrunedHistoryError
+	_, err = api.publicTransactionPoolAPI.GetTransactionByHash(ctx, tx.Hash())
+	assert.ErrorAs(t, err, &prunedErr)
+	_, err = api.GetTransactionByHash(ctx, tx.Hash())
+	assert.ErrorAs(t, err, &prunedErr)
+	_, err = api.publicTransactionPoolAPI.GetTransactionReceipt(ctx, tx.Hash())
+	assert.ErrorAs(t, err, &prunedErr)
+	_, err = api.GetTransactionReceipt(ctx, tx.Hash())
+	assert.ErrorAs(t, err, &prunedErr)
+	_, err = api.publicBlockChainAPI.GetBlockReceipts(ctx, blockNr)
+	assert.ErrorAs(t, err, &prunedErr)
+	_, err = api.GetBlockReceipts(ctx, blockNr)
+	assert.ErrorAs(t, err, &prunedErr)
+}
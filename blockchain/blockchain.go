@@ -113,6 +113,7 @@ const (
 	DefaultBlockInterval        = 128
 	DefaultLivePruningRetention = 172800 // 2*params.DefaultStakeUpdateInterval
 	MaxPrefetchTxs              = 20000
+	historyPruneChunkSize       = 10000 // Maximum number of blocks pruned at once by the history pruning loop
 
 	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
 	// Changelog:
@@ -131,6 +132,7 @@ type CacheConfig struct {
 	BlockInterval        uint                         // Block interval to flush the trie. Each interval state trie will be flushed into disk
 	TriesInMemory        uint64                       // Maximum number of recent state tries according to its block number
 	LivePruningRetention uint64                       // Number of blocks before trie nodes in pruning marks to be deleted. If zero, obsolete nodes are not deleted.
+	HistoryRetention     uint64                       // Number of recent blocks whose bodies, receipts and tx lookup entries are retained. If zero, the whole history is retained.
 	SenderTxHashIndexing bool                         // Enables saving senderTxHash to txHash mapping information to database and cache
 	TrieNodeCacheConfig  *statedb.TrieNodeCacheConfig // Configures trie node cache
 	SnapshotCacheSize    int                          // Memory allowance (MB) to use for caching snapshot entries in memory
@@ -167,6 +169,8 @@ type BlockChain struct {
 	chBlock chan gcBlock       // chPushBlockGCPrque is a channel for delivering the gc item to gc loop.
 	chPrune chan uint64        // chPrune is a channel for delivering the current block number for pruning loop.
 
+	chHistoryPrune chan uint64 // chHistoryPrune is a channel for delivering the current block number for history pruning loop.
+
 	hc            *HeaderChain
 	rmLogsFeed    event.Feed
 	chainFeed     event.Feed
@@ -255,6 +259,7 @@ func NewBlockChain(db database.DBManager, cacheConfig *CacheConfig, chainConfig
 		triegc:             prque.New(),
 		chBlock:            make(chan gcBlock, 2048), // downloader.maxResultsProcess
 		chPrune:            make(chan uint64, 2048),  // downloader.maxResultsProcess
+		chHistoryPrune:     make(chan uint64, 1),
 		stateCache:         state.NewDatabaseWithNewCache(db, cacheConfig.TrieNodeCacheConfig),
 		quit:               make(chan struct{}),
 		futureBlocks:       futureBlocks,
@@ -361,10 +366,11 @@ func NewBlockChain(db database.DBManager, cacheConfig *CacheConfig, chainConfig
 	go bc.update()
 	bc.gcCachedNodeLoop()
 	bc.pruneTrieNodeLoop()
+	bc.pruneHistoryLoop()
 	bc.restartStateMigration()
 
 	if cacheConfig.TrieNodeCacheConfig.DumpPeriodically() {
-		logger.Info("LocalCache is used for trie node cache, start saving cache to file periodically",
+		logger.Info("LocalCache or DiskCache is used for trie node cache, start saving cache to file periodically",
 			"dir", bc.cacheConfig.TrieNodeCacheConfig.FastCacheFileDir,
 			"period", bc.cacheConfig.TrieNodeCacheConfig.FastCacheSavePeriod)
 		trieDB := bc.stateCache.TrieDB()
@@ -1504,6 +1510,47 @@ func (bc *BlockChain) pruneTrieNodeLoop() {
 	}()
 }
 
+// pruneHistoryLoop deletes the bodies, receipts and tx lookup entries of the
+// blocks older than HistoryRetention in the background.
+func (bc *BlockChain) pruneHistoryLoop() {
+	if bc.cacheConfig.HistoryRetention == 0 {
+		return
+	}
+
+	bc.wg.Add(1)
+	go func() {
+		defer bc.wg.Done()
+		for {
+			select {
+			case num := <-bc.chHistoryPrune:
+				if num <= bc.cacheConfig.HistoryRetention {
+					continue
+				}
+				limit := num - bc.cacheConfig.HistoryRetention // Prune [tail, latest - retention)
+
+				// Pruning a long range at once blocks the shutdown, so it is split into chunks.
+				tail := max(bc.db.ReadHistoryTail(), 1)
+				for tail < limit {
+					next := min(limit, tail+historyPruneChunkSize)
+
+					startTime := time.Now()
+					pruned := bc.db.PruneHistory(next)
+					logger.Info("Pruned block history", "number", num, "tail", next, "count", pruned, "elapsed", time.Since(startTime))
+
+					tail = next
+					select {
+					case <-bc.quit:
+						return
+					default:
+					}
+				}
+			case <-bc.quit:
+				return
+			}
+		}
+	}()
+}
+
 func (bc *BlockChain) IsLivePruningRequired() bool {
 	return bc.db.ReadPruningEnabled() && bc.cacheConfig.LivePruningRetention != 0
 }
@@ -1790,6 +1837,13 @@ func (bc *BlockChain) finalizeWriteBlockWithState(block *types.Block, status Wri
 	// Set new head.
 	if status == CanonStatTy {
 		bc.insert(block)
+		if bc.cacheConfig.HistoryRetention != 0 {
+			// The pruning loop catches up with the latest block, so a skipped notification is harmless.
+			select {
+			case bc.chHistoryPrune <- block.NumberU64():
+			default:
+			}
+		}
 		headBlockNumberGauge.Update(block.Number().Int64())
 		blockTxCountsGauge.Update(int64(block.Transactions().Len()))
 		blockTxCountsCounter.Inc(int64(block.Transactions().Len()))
@@ -23,6 +23,7 @@
 package blockchain
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
@@ -1716,6 +1717,332 @@ func BenchmarkBlockChain_1x1000Executions(b *testing.B) {
 	benchmarkLargeNumberOfValueToNonexisting(b, numTxs, numBlocks, recipientFn, dataFn)
 }
 
+// TestEIP7702 deploys two delegation designations and calls them. It writes one value to storage
+// which is verified afterwards.
+func TestEIP7702(t *testing.T) {
+	var (
+		aa     = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		bb     = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		engine = gxhash.NewFaker()
+		db     = database.NewMemoryDBManager()
+
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		key2, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+		funds   = new(big.Int).Mul(common.Big1, big.NewInt(params.KAIA))
+		gspec   = &Genesis{
+			Config: params.MainnetChainConfig.Copy(),
+			Alloc: GenesisAlloc{
+				addr1: {Balance: funds},
+				addr2: {Balance: funds},
+				// The address 0xAAAA calls into addr2 which is delegated to 0xBBBB
+				aa: {
+					Code: append(append([]byte{
+						byte(vm.PUSH1), 0, // out size
+						byte(vm.DUP1),   // out offset
+						byte(vm.DUP1),   // in size
+						byte(vm.DUP1),   // in offset
+						byte(vm.DUP1),   // value
+						byte(vm.PUSH20), // address
+					}, addr2.Bytes()...),
+						byte(vm.GAS), // gas
+						byte(vm.CALL),
+					),
+					Nonce:   0,
+					Balance: big.NewInt(0),
+				},
+				// The address 0xBBBB stores 42 at slot 42
+				bb: {
+					Code: []byte{
+						byte(vm.PUSH1), 0x42,
+						byte(vm.DUP1),
+						byte(vm.SSTORE),
+					},
+					Nonce:   0,
+					Balance: big.NewInt(0),
+				},
+			},
+		}
+	)
+	gspec.Config.SetDefaults()
+	gspec.Config.IstanbulCompatibleBlock = common.Big0
+	gspec.Config.LondonCompatibleBlock = common.Big0
+	gspec.Config.EthTxTypeCompatibleBlock = common.Big0
+	gspec.Config.MagmaCompatibleBlock = common.Big0
+	gspec.Config.KoreCompatibleBlock = common.Big0
+	gspec.Config.ShanghaiCompatibleBlock = common.Big0
+	gspec.Config.CancunCompatibleBlock = common.Big0
+	gspec.Config.RandaoCompatibleBlock = nil
+	gspec.Config.KaiaCompatibleBlock = common.Big0
+	gspec.Config.PragueCompatibleBlock = common.Big0
+
+	signer := types.LatestSigner(gspec.Config)
+	genesis := gspec.MustCommit(db)
+
+	// Sign authorizations: addr1 delegates to 0xAAAA, and addr2 delegates to 0xBBBB.
+	// The nonce of addr1 is increased by its own transaction before the authorization is applied.
+	auth1, err := types.SignSetCode(key1, types.SetCodeAuthorization{
+		ChainID: gspec.Config.ChainID,
+		Address: aa,
+		Nonce:   1,
+	})
+	assert.NoError(t, err)
+	auth2, err := types.SignSetCode(key2, types.SetCodeAuthorization{
+		Address: bb,
+		Nonce:   0,
+	})
+	assert.NoError(t, err)
+
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 1, func(i int, b *BlockGen) {
+		tx, err := types.SignTx(types.NewTx(&types.TxInternalDataEthereumSetCode{
+			ChainID:           gspec.Config.ChainID,
+			AccountNonce:      0,
+			Recipient:         addr1,
+			GasLimit:          500000,
+			GasFeeCap:         big.NewInt(750 * params.Gkei),
+			GasTipCap:         big.NewInt(750 * params.Gkei),
+			Amount:            big.NewInt(0),
+			AuthorizationList: types.AuthorizationList{auth1, auth2},
+		}), signer, key1)
+		assert.NoError(t, err)
+
+		b.AddTx(tx)
+	})
+	chain, err := NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+
+	receipts := chain.GetReceiptsByBlockHash(blocks[0].Hash())
+	assert.Equal(t, types.ReceiptStatusSuccessful, receipts[0].Status)
+
+	// Verify delegation designations were deployed.
+	state, _ := chain.State()
+	code, want := state.GetCode(addr1), types.AddressToDelegation(aa)
+	if !bytes.Equal(code, want) {
+		t.Fatalf("addr1 code incorrect: got %s, want %s", common.Bytes2Hex(code), common.Bytes2Hex(want))
+	}
+	code, want = state.GetCode(addr2), types.AddressToDelegation(bb)
+	if !bytes.Equal(code, want) {
+		t.Fatalf("addr2 code incorrect: got %s, want %s", common.Bytes2Hex(code), common.Bytes2Hex(want))
+	}
+	assert.Equal(t, uint64(2), state.GetNonce(addr1))
+	assert.Equal(t, uint64(1), state.GetNonce(addr2))
+
+	// Verify the value written by the delegated code is stored in the storage of the EOA.
+	actual := state.GetState(addr2, common.BytesToHash([]byte{0x42}))
+	if have, want := actual, common.BytesToHash([]byte{0x42}); have != want {
+		t.Fatalf("addr2 storage wrong: expected %x, got %x", want, have)
+	}
+}
+
+// TestEIP7702ValueTransfer checks a delegated EOA remains an EOA which can receive KAIA by
+// value transfer transactions. The delegated code runs as it does for a legacy transaction.
+func TestEIP7702ValueTransfer(t *testing.T) {
+	var (
+		bb     = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		engine = gxhash.NewFaker()
+		db     = database.NewMemoryDBManager()
+
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		key2, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		key3, _ = crypto.HexToECDSA("49a7b37aa6f6645917e7b807e9d1c00d4fa71f18343b0d4122a4d2df64dd6fee")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+		addr3   = crypto.PubkeyToAddress(key3.PublicKey)
+		funds   = new(big.Int).Mul(common.Big1, big.NewInt(params.KAIA))
+		gspec   = &Genesis{
+			Config: params.MainnetChainConfig.Copy(),
+			Alloc: GenesisAlloc{
+				addr1: {Balance: funds},
+				addr2: {Balance: funds},
+				addr3: {Balance: funds},
+				// The address 0xBBBB stores 42 at slot 42
+				bb: {
+					Code: []byte{
+						byte(vm.PUSH1), 0x42,
+						byte(vm.DUP1),
+						byte(vm.SSTORE),
+					},
+					Nonce:   0,
+					Balance: big.NewInt(0),
+				},
+			},
+		}
+		gasPrice = big.NewInt(750 * params.Gkei)
+		amount   = big.NewInt(1)
+	)
+	gspec.Config.SetDefaults()
+	gspec.Config.IstanbulCompatibleBlock = common.Big0
+	gspec.Config.LondonCompatibleBlock = common.Big0
+	gspec.Config.EthTxTypeCompatibleBlock = common.Big0
+	gspec.Config.MagmaCompatibleBlock = common.Big0
+	gspec.Config.KoreCompatibleBlock = common.Big0
+	gspec.Config.ShanghaiCompatibleBlock = common.Big0
+	gspec.Config.CancunCompatibleBlock = common.Big0
+	gspec.Config.RandaoCompatibleBlock = nil
+	gspec.Config.KaiaCompatibleBlock = common.Big0
+	gspec.Config.PragueCompatibleBlock = common.Big0
+
+	signer := types.LatestSigner(gspec.Config)
+	genesis := gspec.MustCommit(db)
+
+	// addr2 delegates to 0xBBBB.
+	auth, err := types.SignSetCode(key2, types.SetCodeAuthorization{
+		Address: bb,
+		Nonce:   0,
+	})
+	assert.NoError(t, err)
+
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 2, func(i int, b *BlockGen) {
+		if i == 0 {
+			tx, err := types.SignTx(types.NewTx(&types.TxInternalDataEthereumSetCode{
+				ChainID:           gspec.Config.ChainID,
+				AccountNonce:      b.TxNonce(addr1),
+				Recipient:         addr1,
+				GasLimit:          500000,
+				GasFeeCap:         gasPrice,
+				GasTipCap:         gasPrice,
+				Amount:            big.NewInt(0),
+				AuthorizationList: types.AuthorizationList{auth},
+			}), signer, key1)
+			assert.NoError(t, err)
+			b.AddTx(tx)
+			return
+		}
+
+		// Send KAIA to the delegated EOA by a value transfer and a fee delegated value transfer.
+		for _, txType := range []types.TxType{types.TxTypeValueTransfer, types.TxTypeFeeDelegatedValueTransfer} {
+			values := map[types.TxValueKeyType]interface{}{
+				types.TxValueKeyNonce:    b.TxNonce(addr1),
+				types.TxValueKeyFrom:     addr1,
+				types.TxValueKeyTo:       addr2,
+				types.TxValueKeyAmount:   amount,
+				types.TxValueKeyGasLimit: uint64(100000),
+				types.TxValueKeyGasPrice: gasPrice,
+			}
+			if txType.IsFeeDelegatedTransaction() {
+				values[types.TxValueKeyFeePayer] = addr3
+			}
+			tx, err := types.NewTransactionWithMap(txType, values)
+			assert.NoError(t, err)
+			assert.NoError(t, tx.SignWithKeys(signer, []*ecdsa.PrivateKey{key1}))
+			if txType.IsFeeDelegatedTransaction() {
+				assert.NoError(t, tx.SignFeePayerWithKeys(signer, []*ecdsa.PrivateKey{key3}))
+			}
+			b.AddTx(tx)
+		}
+	})
+	chain, err := NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks[:1]); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	state, _ := chain.State()
+	assert.Equal(t, types.AddressToDelegation(bb), state.GetCode(addr2))
+	assert.Equal(t, common.Hash{}, state.GetState(addr2, common.BytesToHash([]byte{0x42})))
+
+	// The value transfers to the delegated EOA pass the validation of the tx pool.
+	for _, tx := range blocks[1].Transactions() {
+		assert.NoError(t, tx.Validate(state, blocks[1].NumberU64()))
+	}
+
+	if n, err := chain.InsertChain(blocks[1:]); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	for _, receipt := range chain.GetReceiptsByBlockHash(blocks[1].Hash()) {
+		assert.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	}
+
+	// The delegated EOA received KAIA and ran the delegated code.
+	state, _ = chain.State()
+	assert.Equal(t, types.AddressToDelegation(bb), state.GetCode(addr2))
+	assert.Equal(t, common.BytesToHash([]byte{0x42}), state.GetState(addr2, common.BytesToHash([]byte{0x42})))
+	assert.Equal(t, new(big.Int).Add(funds, big.NewInt(2)), state.GetBalance(addr2))
+}
+
+// TestEIP7702AuthorizationRefund checks the new account cost of an authorization is refunded
+// if the authority exists, even if it is empty.
+func TestEIP7702AuthorizationRefund(t *testing.T) {
+	var (
+		engine = gxhash.NewFaker()
+		db     = database.NewMemoryDBManager()
+
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		key2, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+		funds   = new(big.Int).Mul(common.Big1, big.NewInt(params.KAIA))
+		gspec   = &Genesis{
+			Config: params.MainnetChainConfig.Copy(),
+			Alloc: GenesisAlloc{
+				addr1: {Balance: funds},
+				addr2: {Balance: big.NewInt(0)}, // exists, but empty
+			},
+		}
+	)
+	gspec.Config.SetDefaults()
+	gspec.Config.IstanbulCompatibleBlock = common.Big0
+	gspec.Config.LondonCompatibleBlock = common.Big0
+	gspec.Config.EthTxTypeCompatibleBlock = common.Big0
+	gspec.Config.MagmaCompatibleBlock = common.Big0
+	gspec.Config.KoreCompatibleBlock = common.Big0
+	gspec.Config.ShanghaiCompatibleBlock = common.Big0
+	gspec.Config.CancunCompatibleBlock = common.Big0
+	gspec.Config.RandaoCompatibleBlock = nil
+	gspec.Config.KaiaCompatibleBlock = common.Big0
+	gspec.Config.PragueCompatibleBlock = common.Big0
+
+	signer := types.LatestSigner(gspec.Config)
+	genesis := gspec.MustCommit(db)
+
+	statedb, _ := state.New(genesis.Root(), state.NewDatabase(db), nil, nil)
+	assert.True(t, statedb.Exist(addr2))
+	assert.True(t, statedb.Empty(addr2))
+
+	auth, err := types.SignSetCode(key2, types.SetCodeAuthorization{
+		Address: common.HexToAddress("0xbbbb"),
+		Nonce:   0,
+	})
+	assert.NoError(t, err)
+
+	blocks, _ := GenerateChain(gspec.Config, genesis, engine, db, 1, func(i int, b *BlockGen) {
+		tx, err := types.SignTx(types.NewTx(&types.TxInternalDataEthereumSetCode{
+			ChainID:           gspec.Config.ChainID,
+			AccountNonce:      0,
+			Recipient:         addr1,
+			GasLimit:          500000,
+			GasFeeCap:         big.NewInt(750 * params.Gkei),
+			GasTipCap:         big.NewInt(750 * params.Gkei),
+			Amount:            big.NewInt(0),
+			AuthorizationList: types.AuthorizationList{auth},
+		}), signer, key1)
+		assert.NoError(t, err)
+
+		b.AddTx(tx)
+	})
+	chain, err := NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+
+	receipts := chain.GetReceiptsByBlockHash(blocks[0].Hash())
+	assert.Equal(t, types.ReceiptStatusSuccessful, receipts[0].Status)
+	assert.Less(t, receipts[0].GasUsed, params.TxGas+params.CallNewAccountGas)
+}
+
 // TestCheckBlockChainVersion tests the functionality of CheckBlockChainVersion function.
 func TestCheckBlockChainVersion(t *testing.T) {
 	memDB := database.NewMemoryDBManager()
@@ -0,0 +1,533 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package era implements the export and import of the canonical chain history
+// as a set of fixed-size epoch archives.
+//
+// Each archive is a gzip compressed RLP stream holding the blocks of one epoch
+// together with their receipts and total blockscores. The archives are listed
+// in an index file with their checksums and accumulator roots, so that a copy
+// can be checked for corruption offline. As the index comes with the archives,
+// it does not authenticate them: the import requires the hash of the last
+// archived block from a trusted source, which every archived block is
+// hash-linked to.
+package era
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+const (
+	// version is the version of the archive format.
+	version = 1
+
+	// IndexFile is the name of the file listing the archives of an export.
+	IndexFile = "index.json"
+
+	// DefaultEpochSize is the default number of blocks stored in an archive.
+	DefaultEpochSize = 8192
+
+	// maxItemSize is the maximum size of an archive item, far above the size
+	// of any block with its receipts. The items of an archive are decoded
+	// only after its checksum is verified, but the index listing the checksums
+	// is not authenticated.
+	maxItemSize = 256 * 1024 * 1024
+)
+
+var (
+	logger = log.NewModuleLogger(log.Blockchain)
+
+	errExportExists = errors.New("history export already exists")
+	errEmptyRange   = errors.New("no blocks to export")
+	errEmptyImport  = errors.New("no blocks to import")
+)
+
+// PrunedRangeError is returned when the blocks to export have been deleted by
+// the history pruning.
+type PrunedRangeError struct {
+	Tail uint64 // The oldest block whose body and receipts are retained
+}
+
+func (e *PrunedRangeError) Error() string {
+	return fmt.Sprintf("bodies and receipts before block %d have been pruned", e.Tail)
+}
+
+// Index lists the archives of an exported chain history.
+type Index struct {
+	Version   uint64              `json:"version"`
+	EpochSize uint64              `json:"epochSize"`
+	Genesis   common.Hash         `json:"genesis"`
+	Config    *params.ChainConfig `json:"config"`
+	Epochs    []*Epoch            `json:"epochs"`
+}
+
+// Epoch describes a single archive holding the blocks [Start, Start+Count).
+type Epoch struct {
+	Name   string      `json:"name"`
+	Start  uint64      `json:"start"`
+	Count  uint64      `json:"count"`
+	Root   common.Hash `json:"root"`   // Accumulator root of the block hashes and blockscores
+	Size   uint64      `json:"size"`   // Size of the archive file
+	SHA256 string      `json:"sha256"` // Checksum of the archive file
+}
+
+// archiveHeader is the first item of an archive.
+type archiveHeader struct {
+	Version uint64
+	Start   uint64
+	Count   uint64
+}
+
+// archiveBlock is a single block item of an archive.
+type archiveBlock struct {
+	Block    *types.Block
+	Receipts []*types.ReceiptForStorage
+	TD       *big.Int
+}
+
+// ReadIndex reads the index of the chain history exported into dir.
+func ReadIndex(dir string) (*Index, error) {
+	blob, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if err != nil {
+		return nil, err
+	}
+	index := new(Index)
+	if err := json.Unmarshal(blob, index); err != nil {
+		return nil, fmt.Errorf("invalid history index: %v", err)
+	}
+	if index.Version != version {
+		return nil, fmt.Errorf("unsupported history archive version %d (want %d)", index.Version, version)
+	}
+	if index.Config == nil {
+		return nil, errors.New("missing chain config in history index")
+	}
+	return index, nil
+}
+
+func writeIndex(dir string, index *Index) error {
+	blob, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, IndexFile), blob, 0o644)
+}
+
+// accumulator computes the root of a binary merkle tree whose leaves commit to
+// the hash and the total blockscore of each block of an epoch.
+type accumulator struct {
+	leaves []common.Hash
+}
+
+func (a *accumulator) add(hash common.Hash, td *big.Int) {
+	a.leaves = append(a.leaves, crypto.Keccak256Hash(hash[:], common.BigToHash(td).Bytes()))
+}
+
+func (a *accumulator) root() common.Hash {
+	if len(a.leaves) == 0 {
+		return common.Hash{}
+	}
+	level := a.leaves
+	for len(level) > 1 {
+		next := make([]common.Hash, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			right := common.Hash{}
+			if i+1 < len(level) {
+				right = level[i+1]
+			}
+			next = append(next, crypto.Keccak256Hash(level[i][:], right[:]))
+		}
+		level = next
+	}
+	return level[0]
+}
+
+// Export writes the canonical blocks [first, last] with their receipts into
+// epoch archives of epochSize blocks in the given directory. The epochs are
+// aligned to multiples of epochSize, so the first and the last archives may
+// hold fewer blocks. The range must not contain the blocks whose bodies and
+// receipts have been pruned.
+func Export(db database.DBManager, dir string, first, last, epochSize uint64) (*Index, error) {
+	if epochSize == 0 {
+		epochSize = DefaultEpochSize
+	}
+	if first > last {
+		return nil, errEmptyRange
+	}
+	// The genesis block is never pruned, so only the blocks [1, tail) are missing.
+	if tail := db.ReadHistoryTail(); first < tail && last > 0 && tail > 1 {
+		return nil, &PrunedRangeError{Tail: tail}
+	}
+	genesis := db.ReadCanonicalHash(0)
+	config := db.ReadChainConfig(genesis)
+	if config == nil {
+		return nil, errors.New("missing chain config")
+	}
+	if _, err := os.Stat(filepath.Join(dir, IndexFile)); err == nil {
+		return nil, errExportExists
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	var (
+		index = &Index{Version: version, EpochSize: epochSize, Genesis: genesis, Config: config}
+		start = time.Now()
+	)
+	for from := first; from <= last; {
+		epoch := from / epochSize
+		to := min(last, (epoch+1)*epochSize-1)
+		e, err := exportEpoch(db, dir, epoch, from, to)
+		if err != nil {
+			return nil, err
+		}
+		index.Epochs = append(index.Epochs, e)
+		logger.Info("Exported history epoch", "epoch", epoch, "first", from, "last", to, "root", e.Root,
+			"elapsed", common.PrettyDuration(time.Since(start)))
+		from = to + 1
+	}
+	if err := writeIndex(dir, index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+func exportEpoch(db database.DBManager, dir string, epoch, from, to uint64) (*Epoch, error) {
+	tmp := filepath.Join(dir, fmt.Sprintf("epoch-%05d.tmp", epoch))
+	file, err := os.Create(tmp)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp)
+	defer file.Close()
+
+	var (
+		hasher = sha256.New()
+		buf    = bufio.NewWriter(io.MultiWriter(file, hasher))
+		gz     = gzip.NewWriter(buf)
+		acc    = new(accumulator)
+	)
+	if err := rlp.Encode(gz, &archiveHeader{Version: version, Start: from, Count: to - from + 1}); err != nil {
+		return nil, err
+	}
+	for number := from; number <= to; number++ {
+		hash := db.ReadCanonicalHash(number)
+		block := db.ReadBlock(hash, number)
+		if block == nil {
+			return nil, fmt.Errorf("missing block %d", number)
+		}
+		td := db.ReadTd(hash, number)
+		if td == nil {
+			return nil, fmt.Errorf("missing total blockscore of block %d", number)
+		}
+		receipts := db.ReadReceipts(hash, number)
+		if receipts == nil && block.Transactions().Len() > 0 {
+			return nil, fmt.Errorf("missing receipts of block %d", number)
+		}
+		storage := make([]*types.ReceiptForStorage, len(receipts))
+		for i, receipt := range receipts {
+			storage[i] = (*types.ReceiptForStorage)(receipt)
+		}
+		if err := rlp.Encode(gz, &archiveBlock{Block: block, Receipts: storage, TD: td}); err != nil {
+			return nil, err
+		}
+		acc.add(hash, td)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	if err := buf.Flush(); err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+	root := acc.root()
+	e := &Epoch{
+		Name:   fmt.Sprintf("kaia-%05d-%s.era", epoch, hex.EncodeToString(root[:4])),
+		Start:  from,
+		Count:  to - from + 1,
+		Root:   root,
+		Size:   uint64(info.Size()),
+		SHA256: hex.EncodeToString(hasher.Sum(nil)),
+	}
+	return e, os.Rename(tmp, filepath.Join(dir, e.Name))
+}
+
+// Verify checks the archives listed in the index of dir: their checksums and
+// accumulator roots, the continuity of the blocks and the transaction and
+// receipt roots of each block. types.DeriveSha should be initialized with
+// the chain config of the index beforehand.
+func Verify(dir string) (*Index, error) {
+	index, err := ReadIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+	var parent *archiveBlock
+	for _, e := range index.Epochs {
+		if err := readEpoch(dir, e, func(b *archiveBlock) error {
+			if err := verifyBlock(b, parent); err != nil {
+				return err
+			}
+			parent = b
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("invalid archive %s: %v", e.Name, err)
+		}
+	}
+	return index, nil
+}
+
+// verifyBlock checks the consistency of an archived block with its contents
+// and with its parent, if the parent is known.
+func verifyBlock(b *archiveBlock, parent *archiveBlock) error {
+	header, number := b.Block.Header(), b.Block.Number()
+	if hash := types.DeriveSha(b.Block.Transactions(), number); hash != header.TxHash {
+		return fmt.Errorf("transaction root mismatch in block %d: have %x, want %x", number, hash, header.TxHash)
+	}
+	receipts := make(types.Receipts, len(b.Receipts))
+	for i, receipt := range b.Receipts {
+		receipts[i] = (*types.Receipt)(receipt)
+	}
+	if hash := types.DeriveSha(receipts, number); hash != header.ReceiptHash {
+		return fmt.Errorf("receipt root mismatch in block %d: have %x, want %x", number, hash, header.ReceiptHash)
+	}
+	if parent == nil {
+		return nil
+	}
+	if header.ParentHash != parent.Block.Hash() || b.Block.NumberU64() != parent.Block.NumberU64()+1 {
+		return fmt.Errorf("block %d is not a child of block %d", number, parent.Block.NumberU64())
+	}
+	if td := new(big.Int).Add(parent.TD, header.BlockScore); td.Cmp(b.TD) != 0 {
+		return fmt.Errorf("total blockscore mismatch in block %d: have %v, want %v", number, b.TD, td)
+	}
+	return nil
+}
+
+// readEpoch checks the checksum of an archive, then decodes it, checking its
+// structure and accumulator root, and passes each block to fn. The accumulator
+// root is verified after the whole archive is read, so fn must not persist
+// anything.
+func readEpoch(dir string, e *Epoch, fn func(*archiveBlock) error) error {
+	file, err := os.Open(filepath.Join(dir, filepath.Base(e.Name)))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Reject a corrupted archive before decoding anything of it.
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return err
+	}
+	if uint64(size) != e.Size {
+		return fmt.Errorf("size mismatch: have %d, want %d", size, e.Size)
+	}
+	if sum := hex.EncodeToString(hasher.Sum(nil)); sum != e.SHA256 {
+		return fmt.Errorf("checksum mismatch: have %s, want %s", sum, e.SHA256)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	gz, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	var (
+		stream = rlp.NewStream(gz, 0)
+		acc    = new(accumulator)
+	)
+	var header archiveHeader
+	if err := decodeItem(stream, &header); err != nil {
+		return err
+	}
+	if header.Version != version || header.Start != e.Start || header.Count != e.Count {
+		return fmt.Errorf("archive header mismatch: have %d/%d/%d, want %d/%d/%d",
+			header.Version, header.Start, header.Count, version, e.Start, e.Count)
+	}
+	for i := uint64(0); i < header.Count; i++ {
+		b := new(archiveBlock)
+		if err := decodeItem(stream, b); err != nil {
+			return err
+		}
+		if b.Block.NumberU64() != header.Start+i {
+			return fmt.Errorf("unexpected block %d at %d", b.Block.NumberU64(), header.Start+i)
+		}
+		acc.add(b.Block.Hash(), b.TD)
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+	if _, _, err := stream.Kind(); err != io.EOF {
+		return errors.New("trailing data after the last block")
+	}
+	if root := acc.root(); root != e.Root {
+		return fmt.Errorf("accumulator root mismatch: have %x, want %x", root, e.Root)
+	}
+	return nil
+}
+
+// decodeItem decodes the next item of an archive into val, rejecting the items
+// larger than maxItemSize before reading them.
+func decodeItem(stream *rlp.Stream, val interface{}) error {
+	if _, size, err := stream.Kind(); err != nil {
+		return err
+	} else if size > maxItemSize {
+		return fmt.Errorf("archive item too large: %d bytes, limit %d", size, maxItemSize)
+	}
+	return stream.Decode(val)
+}
+
+// Import writes the blocks, receipts and tx lookup entries of the archives in
+// dir into the database, without executing them. The archives are trusted only
+// if the last archived block has the given checkpoint hash, obtained from a
+// trusted source: all the archives are verified and hash-linked to the
+// checkpoint and to the local chain before any block is written, so the
+// archives must not be modified during the import. The blocks already in the
+// database are skipped, while a block conflicting with a local canonical block
+// is rejected. The head header and the head fast block are advanced to the
+// last imported block, while the state of the imported blocks stays missing.
+// It returns the number of the imported blocks.
+func Import(db database.DBManager, dir string, checkpoint common.Hash) (uint64, error) {
+	index, err := ReadIndex(dir)
+	if err != nil {
+		return 0, err
+	}
+	if genesis := db.ReadCanonicalHash(0); genesis != index.Genesis {
+		return 0, fmt.Errorf("genesis mismatch: have %x, want %x", genesis, index.Genesis)
+	}
+	// Verify all the archives up to the checkpoint before writing anything.
+	var last *archiveBlock
+	for _, e := range index.Epochs {
+		if err := readEpoch(dir, e, func(b *archiveBlock) error {
+			if err := verifyBlock(b, last); err != nil {
+				return err
+			}
+			if last == nil {
+				if err := checkLocalParent(db, b.Block); err != nil {
+					return err
+				}
+			}
+			if err := checkCanonical(db, b.Block); err != nil {
+				return err
+			}
+			last = b
+			return nil
+		}); err != nil {
+			return 0, fmt.Errorf("invalid archive %s: %v", e.Name, err)
+		}
+	}
+	if last == nil {
+		return 0, errEmptyImport
+	}
+	if hash := last.Block.Hash(); hash != checkpoint {
+		return 0, fmt.Errorf("checkpoint mismatch: last archived block %d has hash %x, want %x", last.Block.NumberU64(), hash, checkpoint)
+	}
+	var (
+		imported uint64
+		parent   *archiveBlock
+		start    = time.Now()
+	)
+	for _, e := range index.Epochs {
+		var head *types.Block
+		if err := readEpoch(dir, e, func(b *archiveBlock) error {
+			// Check again what is written, as the archives are read twice.
+			if err := verifyBlock(b, parent); err != nil {
+				return err
+			}
+			if err := checkCanonical(db, b.Block); err != nil {
+				return err
+			}
+			block := b.Block
+			if db.HasBlock(block.Hash(), block.NumberU64()) {
+				parent = b
+				return nil
+			}
+			receipts := make(types.Receipts, len(b.Receipts))
+			for i, receipt := range b.Receipts {
+				receipts[i] = (*types.Receipt)(receipt)
+			}
+			db.WriteBlock(block)
+			db.WriteReceipts(block.Hash(), block.NumberU64(), receipts)
+			db.WriteTd(block.Hash(), block.NumberU64(), b.TD)
+			db.WriteCanonicalHash(block.Hash(), block.NumberU64())
+			db.WriteTxLookupEntries(block)
+
+			parent, head = b, block
+			imported++
+			return nil
+		}); err != nil {
+			return imported, fmt.Errorf("failed to import %s: %v", e.Name, err)
+		}
+		if head != nil {
+			if current := db.ReadHeaderNumber(db.ReadHeadHeaderHash()); current == nil || *current < head.NumberU64() {
+				db.WriteHeadHeaderHash(head.Hash())
+			}
+			if current := db.ReadHeaderNumber(db.ReadHeadFastBlockHash()); current == nil || *current < head.NumberU64() {
+				db.WriteHeadFastBlockHash(head.Hash())
+			}
+		}
+		logger.Info("Imported history epoch", "archive", e.Name, "first", e.Start, "count", e.Count,
+			"imported", imported, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return imported, nil
+}
+
+// checkLocalParent checks that the first archived block is the child of a
+// local canonical block, so the imported history extends the local chain.
+func checkLocalParent(db database.DBManager, block *types.Block) error {
+	if block.NumberU64() == 0 {
+		return nil
+	}
+	if canon := db.ReadCanonicalHash(block.NumberU64() - 1); canon != block.ParentHash() {
+		return fmt.Errorf("missing parent of block %d", block.NumberU64())
+	}
+	return nil
+}
+
+// checkCanonical checks that the given block does not conflict with the local
+// canonical block of the same number, if any.
+func checkCanonical(db database.DBManager, block *types.Block) error {
+	canon := db.ReadCanonicalHash(block.NumberU64())
+	if canon != (common.Hash{}) && canon != block.Hash() {
+		return fmt.Errorf("block %d conflicts with the canonical block: have %x, want %x", block.NumberU64(), block.Hash(), canon)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestChain returns a database holding a chain of the given length with a
+// transaction in every block, along with the genesis spec.
+func newTestChain(t *testing.T, length int) (database.DBManager, *blockchain.Genesis, []*types.Block) {
+	var (
+		key, _  = crypto.GenerateKey()
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &blockchain.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  blockchain.GenesisAlloc{address: {Balance: big.NewInt(params.KAIA)}},
+		}
+		signer = types.LatestSignerForChainID(gspec.Config.ChainID)
+		gendb  = database.NewMemoryDBManager()
+		db     = database.NewMemoryDBManager()
+	)
+	genesis := gspec.MustCommit(gendb)
+	gspec.MustCommit(db)
+
+	chain, err := blockchain.NewBlockChain(db, nil, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	require.NoError(t, err)
+	defer chain.Stop()
+
+	blocks, _ := blockchain.GenerateChain(gspec.Config, genesis, gxhash.NewFaker(), gendb, length, func(i int, block *blockchain.BlockGen) {
+		to := common.BigToAddress(big.NewInt(int64(0x10000 + i)))
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), to, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+		require.NoError(t, err)
+		block.AddTx(tx)
+	})
+	_, err = chain.InsertChain(blocks)
+	require.NoError(t, err)
+	return db, gspec, blocks
+}
+
+func TestExportImport(t *testing.T) {
+	src, gspec, blocks := newTestChain(t, 20)
+	dir := t.TempDir()
+
+	index, err := Export(src, dir, 0, 20, 8)
+	require.NoError(t, err)
+	require.Len(t, index.Epochs, 3)
+	assert.Equal(t, uint64(8), index.Epochs[0].Count)
+	assert.Equal(t, uint64(16), index.Epochs[2].Start)
+	assert.Equal(t, uint64(5), index.Epochs[2].Count)
+
+	// Exporting into the same directory twice is rejected.
+	_, err = Export(src, dir, 0, 20, 8)
+	assert.Equal(t, errExportExists, err)
+
+	_, err = Verify(dir)
+	require.NoError(t, err)
+
+	db := database.NewMemoryDBManager()
+	gspec.MustCommit(db)
+	head := blocks[len(blocks)-1]
+	imported, err := Import(db, dir, head.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(len(blocks)), imported)
+
+	assert.Equal(t, head.Hash(), db.ReadHeadHeaderHash())
+	assert.Equal(t, head.Hash(), db.ReadHeadFastBlockHash())
+	for _, block := range blocks {
+		hash, number := block.Hash(), block.NumberU64()
+		assert.Equal(t, hash, db.ReadCanonicalHash(number))
+		assert.Equal(t, src.ReadTd(hash, number), db.ReadTd(hash, number))
+		assert.Equal(t, len(src.ReadReceipts(hash, number)), len(db.ReadReceipts(hash, number)))
+
+		lookupHash, _, _ := db.ReadTxLookupEntry(block.Transactions()[0].Hash())
+		assert.Equal(t, hash, lookupHash)
+	}
+
+	// Importing again skips the existing blocks.
+	imported, err = Import(db, dir, head.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), imported)
+}
+
+func TestImportUntrusted(t *testing.T) {
+	src, gspec, blocks := newTestChain(t, 10)
+	dir := t.TempDir()
+
+	_, err := Export(src, dir, 0, 10, 4)
+	require.NoError(t, err)
+	head := blocks[len(blocks)-1]
+
+	// Nothing is written unless the archives lead to the trusted checkpoint.
+	db := database.NewMemoryDBManager()
+	gspec.MustCommit(db)
+	_, err = Import(db, dir, blocks[len(blocks)-2].Hash())
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "checkpoint mismatch"), err.Error())
+	assert.Equal(t, common.Hash{}, db.ReadCanonicalHash(1))
+
+	// A block conflicting with the local canonical chain is not overwritten.
+	conflict := common.HexToHash("0x01")
+	db.WriteCanonicalHash(conflict, 5)
+	_, err = Import(db, dir, head.Hash())
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "conflicts with the canonical block"), err.Error())
+	assert.Equal(t, common.Hash{}, db.ReadCanonicalHash(1))
+	assert.Equal(t, conflict, db.ReadCanonicalHash(5))
+}
+
+func TestVerifyCorruptedArchive(t *testing.T) {
+	src, _, _ := newTestChain(t, 10)
+	dir := t.TempDir()
+
+	index, err := Export(src, dir, 0, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, index.Epochs, 1)
+
+	path := filepath.Join(dir, index.Epochs[0].Name)
+	blob, err := os.ReadFile(path)
+	require.NoError(t, err)
+	blob[len(blob)-10] ^= 0xff
+	require.NoError(t, os.WriteFile(path, blob, 0o644))
+
+	_, err = Verify(dir)
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), index.Epochs[0].Name), err.Error())
+
+	db := database.NewMemoryDBManager()
+	_, err = Import(db, dir, common.Hash{})
+	assert.Error(t, err)
+}
+
+func TestVerifyOversizedItem(t *testing.T) {
+	src, _, _ := newTestChain(t, 10)
+	dir := t.TempDir()
+
+	index, err := Export(src, dir, 0, 10, 0)
+	require.NoError(t, err)
+	e := index.Epochs[0]
+
+	// Replace the blocks by a list header claiming 4 GiB, with a valid checksum.
+	header, err := rlp.EncodeToBytes(&archiveHeader{Version: version, Start: e.Start, Count: e.Count})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err = gz.Write(append(header, 0xfb, 0xff, 0xff, 0xff, 0xff))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, e.Name), buf.Bytes(), 0o644))
+
+	sum := sha256.Sum256(buf.Bytes())
+	e.Size, e.SHA256 = uint64(buf.Len()), hex.EncodeToString(sum[:])
+	require.NoError(t, writeIndex(dir, index))
+
+	_, err = Verify(dir)
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "archive item too large"), err.Error())
+}
+
+func TestExportPrunedHistory(t *testing.T) {
+	src, _, _ := newTestChain(t, 10)
+	src.PruneHistory(5)
+
+	// The range containing the pruned blocks is rejected, except for the genesis.
+	_, err := Export(src, t.TempDir(), 0, 10, 0)
+	assert.Equal(t, &PrunedRangeError{Tail: 5}, err)
+	_, err = Export(src, t.TempDir(), 4, 10, 0)
+	assert.Equal(t, &PrunedRangeError{Tail: 5}, err)
+
+	index, err := Export(src, t.TempDir(), 0, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), index.Epochs[0].Count)
+
+	index, err = Export(src, t.TempDir(), 5, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), index.Epochs[0].Start)
+	assert.Equal(t, uint64(6), index.Epochs[0].Count)
+}
@@ -147,4 +147,18 @@ var (
 
 	// ErrGasPriceBelowBaseFee is returned if gas price of transaction is lower than gas unit price.
 	ErrGasPriceBelowBaseFee = errors.New("invalid gas price. It must be set to value greater than or equal to baseFee")
+
+	// ErrEmptyAuthorizationList is returned if a set code transaction has no authorization.
+	ErrEmptyAuthorizationList = types.ErrEmptyAuthorizationList
+)
+
+// Errors of EIP-7702 authorizations. An invalid authorization is skipped
+// without failing the set code transaction which carries it.
+var (
+	ErrAuthorizationWrongChainID       = errors.New("EIP-7702 authorization chain ID mismatch")
+	ErrAuthorizationNonceOverflow      = errors.New("EIP-7702 authorization nonce > 64 bit")
+	ErrAuthorizationInvalidSignature   = errors.New("EIP-7702 authorization has invalid signature")
+	ErrAuthorizationDestinationHasCode = errors.New("EIP-7702 authorization destination is a smart contract account")
+	ErrAuthorizationNotLegacyKey       = errors.New("EIP-7702 authorization authority has a non-legacy account key")
+	ErrAuthorizationNonceMismatch      = errors.New("EIP-7702 authorization nonce does not match current account nonce")
 )
@@ -0,0 +1,95 @@
+// Modifications Copyright 2024 The Kaia Authors
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+//
+// This file is derived from core/state/pruner/bloom.go.
+// Modified and improved for the Kaia development.
+
+package pruner
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/steakknife/bloomfilter"
+)
+
+// stateBloomHasher is a wrapper around a byte blob to satisfy the interface API
+// requirements of the bloom library used. It's used to convert a trie hash or
+// contract code hash into a 64 bit mini hash.
+type stateBloomHasher []byte
+
+func (f stateBloomHasher) Write(p []byte) (n int, err error) { panic("not implemented") }
+func (f stateBloomHasher) Sum(b []byte) []byte               { panic("not implemented") }
+func (f stateBloomHasher) Reset()                            { panic("not implemented") }
+func (f stateBloomHasher) BlockSize() int                    { panic("not implemented") }
+func (f stateBloomHasher) Size() int                         { return 8 }
+func (f stateBloomHasher) Sum64() uint64                     { return binary.BigEndian.Uint64(f) }
+
+// stateBloom is a bloom filter used during the state pruning to record all
+// the trie nodes and contract codes belonging to the pruning target. Anything
+// not contained in the bloom is regarded as stale and can be deleted. A false
+// positive only leaves a stale entry on disk, so the pruning is always safe.
+type stateBloom struct {
+	bloom *bloomfilter.Filter
+}
+
+// newStateBloomWithSize creates a brand new state bloom for state generation.
+// The bloom filter will be created by the passing bloom filter size. According
+// to the https://hur.st/bloomfilter/?n=600000000&p=&m=2048MB&k=4, the parameters
+// are picked so that the false-positive rate for mainnet is low enough.
+func newStateBloomWithSize(size uint64) (*stateBloom, error) {
+	bloom, err := bloomfilter.New(size*1024*1024*8, 4)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Initialized state bloom", "size", common.StorageSize(float64(bloom.M()/8)))
+	return &stateBloom{bloom: bloom}, nil
+}
+
+// add records the given trie node hash or code hash in the bloom.
+func (b *stateBloom) add(hash common.Hash) {
+	b.bloom.Add(stateBloomHasher(hash[:]))
+}
+
+// contain is the wrapper of the underlying contains function which
+// reports whether the key is contained.
+// - If it says yes, the key may be contained
+// - If it says no, the key is definitely not contained.
+func (b *stateBloom) contain(key []byte) bool {
+	return b.bloom.Contains(stateBloomHasher(key))
+}
+
+// bloomWriter is a database.DBManager which redirects the trie nodes and the
+// contract codes written by the trie generator into the state bloom. All the
+// other methods are served by the wrapped database.
+type bloomWriter struct {
+	database.DBManager
+	bloom *stateBloom
+}
+
+func (w *bloomWriter) WriteTrieNode(hash common.ExtHash, node []byte) {
+	if !hash.IsZeroExtended() {
+		panic(fmt.Sprintf("unexpected extended trie node hash %x", hash))
+	}
+	w.bloom.add(hash.Unextend())
+}
+
+func (w *bloomWriter) WriteCode(hash common.Hash, code []byte) {
+	w.bloom.add(hash)
+}
@@ -0,0 +1,266 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/snapshot"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+const (
+	// DefaultBloomFilterSize is the default size of the state bloom in megabytes.
+	DefaultBloomFilterSize = 2048
+
+	// minBloomFilterSize is the minimum size of the state bloom in megabytes.
+	// Anything smaller raises the false-positive rate too much to be useful.
+	minBloomFilterSize = 256
+
+	// targetLayers is the number of snapshot diff layers searched for the
+	// pruning target when no target root is given.
+	targetLayers = 128
+)
+
+var logger = log.NewModuleLogger(log.BlockchainState)
+
+// Pruner is an offline tool to prune the stale state with the help of the
+// snapshot. The workflow of pruner is very simple:
+//
+//   - regenerate the trie of the target state from the snapshot and record
+//     every trie node and contract code in a bloom filter
+//   - iterate the state trie database and delete every trie node (and legacy
+//     contract code) which is not contained in the bloom filter
+//   - flatten the snapshot into a single disk layer rooted at the target state
+//   - compact the state trie database to reclaim the disk space
+//
+// The pruning can be interrupted and restarted at any time, since the target
+// state is never deleted. The live pruning databases and the databases under
+// state migration are not supported.
+type Pruner struct {
+	db         database.DBManager
+	bloomSize  uint64
+	headHeader *types.Header
+	snaptree   *snapshot.Tree
+}
+
+// NewPruner creates the pruner instance.
+func NewPruner(db database.DBManager, bloomSize uint64) (*Pruner, error) {
+	if db.ReadPruningEnabled() {
+		return nil, errors.New("live pruning is enabled, offline pruning is not needed")
+	}
+	if db.InMigration() {
+		return nil, errors.New("state migration is in progress")
+	}
+	headBlock := db.ReadBlockByHash(db.ReadHeadBlockHash())
+	if headBlock == nil {
+		return nil, errors.New("failed to load head block")
+	}
+	snaptree, err := snapshot.New(db, statedb.NewDatabase(db), 256, headBlock.Root(), false, false, false)
+	if err != nil {
+		return nil, err // The relevant snapshot(s) might not exist
+	}
+	// Sanitize the bloom filter size if it's too small.
+	if bloomSize < minBloomFilterSize {
+		logger.Warn("Sanitizing bloomfilter size", "provided(MB)", bloomSize, "updated(MB)", minBloomFilterSize)
+		bloomSize = minBloomFilterSize
+	}
+	return &Pruner{
+		db:         db,
+		bloomSize:  bloomSize,
+		headHeader: headBlock.Header(),
+		snaptree:   snaptree,
+	}, nil
+}
+
+// Prune deletes all the trie nodes not belonging to the state of the given
+// root. If the root is empty, the bottom-most snapshot layer within the latest
+// 128 blocks whose state is available on disk is picked as the target.
+//
+// After the pruning, the state of the blocks above the target is gone, so the
+// node rewinds its head to the target block on the next start.
+func (p *Pruner) Prune(root common.Hash) error {
+	layers := p.snaptree.Snapshots(p.headHeader.Root, targetLayers, true)
+	if root == (common.Hash{}) {
+		for i := len(layers) - 1; i >= 0; i-- {
+			if ok, _ := p.db.HasTrieNode(layers[i].Root().ExtendZero()); ok {
+				root = layers[i].Root()
+				break
+			}
+		}
+		if root == (common.Hash{}) {
+			return errors.New("no snapshot paired state")
+		}
+	} else {
+		if p.snaptree.Snapshot(root) == nil {
+			return fmt.Errorf("snapshot of the target state [%x] is missing", root)
+		}
+		if ok, _ := p.db.HasTrieNode(root.ExtendZero()); !ok {
+			return fmt.Errorf("associated state [%x] is not present", root)
+		}
+	}
+	logger.Info("Selecting pruning target", "root", root)
+
+	// The root nodes of the layers above the target are deleted forcibly even
+	// if they're contained in the bloom, so that the node doesn't mistake their
+	// partially pruned state as an available one.
+	middleRoots := make(map[common.Hash]struct{})
+	for _, layer := range layers {
+		if layer.Root() == root {
+			break
+		}
+		middleRoots[layer.Root()] = struct{}{}
+	}
+
+	// Traverse the target state, re-construct the whole state trie and
+	// commit to the given bloom filter.
+	start := time.Now()
+	bloom, err := newStateBloomWithSize(p.bloomSize)
+	if err != nil {
+		return err
+	}
+	if err := snapshot.GenerateTrie(p.snaptree, root, p.db, &bloomWriter{DBManager: p.db, bloom: bloom}); err != nil {
+		return err
+	}
+	// The genesis state is retained as well.
+	if err := p.extractGenesis(bloom); err != nil {
+		return err
+	}
+	if err := p.prune(bloom, middleRoots, start); err != nil {
+		return err
+	}
+
+	// Pruning is done, now drop the "useless" layers from the snapshot.
+	// Firstly, flushing the target layer into the disk. After that all
+	// diff layers below the target will all be merged into the disk.
+	if root != p.snaptree.DiskRoot() {
+		if err := p.snaptree.Cap(root, 0); err != nil {
+			return err
+		}
+	}
+	// Secondly, flushing the snapshot journal into the disk. All diff
+	// layers upon the target are dropped silently. Eventually the entire
+	// snapshot tree is converted into a single disk layer with the pruning
+	// target as the root.
+	if _, err := p.snaptree.Journal(root); err != nil {
+		return err
+	}
+	return p.compact()
+}
+
+// extractGenesis records all the trie nodes and contract codes of the genesis
+// state in the given bloom.
+func (p *Pruner) extractGenesis(bloom *stateBloom) error {
+	genesisHash := p.db.ReadCanonicalHash(0)
+	if genesisHash == (common.Hash{}) {
+		return errors.New("missing genesis hash")
+	}
+	genesis := p.db.ReadBlock(genesisHash, 0)
+	if genesis == nil {
+		return errors.New("missing genesis block")
+	}
+	genesisState, err := state.New(genesis.Root(), state.NewDatabase(p.db), nil, nil)
+	if err != nil {
+		return err
+	}
+	it := state.NewNodeIterator(genesisState)
+	for it.Next() {
+		if it.Hash != (common.Hash{}) {
+			bloom.add(it.Hash)
+		}
+	}
+	return it.Error
+}
+
+// prune deletes all the trie nodes and legacy contract codes of the state
+// trie database which are not contained in the bloom.
+func (p *Pruner) prune(bloom *stateBloom, middleRoots map[common.Hash]struct{}, start time.Time) error {
+	var (
+		count  int
+		size   common.StorageSize
+		pstart = time.Now()
+		logged = time.Now()
+		batch  = p.db.NewBatch(database.StateTrieDB)
+		iter   = p.db.GetStateTrieDB().NewIterator(nil, nil)
+	)
+	defer batch.Release()
+	defer iter.Release()
+
+	for iter.Next() {
+		key := iter.Key()
+
+		// All the trie nodes and the legacy contract codes share the 32 byte
+		// hash keys, the other entries of the database are left untouched.
+		if len(key) != common.HashLength {
+			continue
+		}
+		if _, exist := middleRoots[common.BytesToHash(key)]; exist {
+			logger.Debug("Forcibly delete the middle state roots", "hash", common.BytesToHash(key))
+		} else if bloom.contain(key) {
+			continue
+		}
+		count++
+		size += common.StorageSize(len(key) + len(iter.Value()))
+		if err := batch.Delete(key); err != nil {
+			return err
+		}
+		if _, err := database.WriteBatchesOverThreshold(batch); err != nil {
+			return err
+		}
+		if time.Since(logged) > 8*time.Second {
+			logger.Info("Pruning state data", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(pstart)))
+			logged = time.Now()
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	logger.Info("Pruned state data", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(pstart)), "total", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// compact compacts the state trie database range by range, so that the deleted
+// data is removed from the disk immediately.
+func (p *Pruner) compact() error {
+	cstart := time.Now()
+	for b := 0x00; b <= 0xf0; b += 0x10 {
+		var (
+			start = []byte{byte(b)}
+			end   = []byte{byte(b + 0x10)}
+		)
+		if b == 0xf0 {
+			end = nil
+		}
+		logger.Info("Compacting database", "range", fmt.Sprintf("%#x-%#x", start, end), "elapsed", common.PrettyDuration(time.Since(cstart)))
+		if err := p.db.GetStateTrieDB().Compact(start, end); err != nil {
+			logger.Error("Database compaction failed", "err", err)
+			return err
+		}
+	}
+	logger.Info("Database compaction finished", "elapsed", common.PrettyDuration(time.Since(cstart)))
+	return nil
+}
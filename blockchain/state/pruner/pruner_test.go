@@ -0,0 +1,178 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/snapshot"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countTrieNodes returns the number of the 32 byte keys in the state trie database.
+func countTrieNodes(db database.DBManager) int {
+	it := db.GetStateTrieDB().NewIterator(nil, nil)
+	defer it.Release()
+
+	count := 0
+	for it.Next() {
+		if len(it.Key()) == common.HashLength {
+			count++
+		}
+	}
+	return count
+}
+
+// checkState iterates the whole state of the given root and fails if any node is missing.
+func checkState(t *testing.T, db database.DBManager, root common.Hash) {
+	sdb, err := state.New(root, state.NewDatabase(db), nil, nil)
+	require.NoError(t, err)
+	it := state.NewNodeIterator(sdb)
+	for it.Next() {
+	}
+	require.NoError(t, it.Error)
+}
+
+func TestPrune(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		delegate = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		slot     = common.BytesToHash([]byte{0x42})
+		gasPrice = big.NewInt(750 * params.Gkei)
+		gspec    = &blockchain.Genesis{
+			Config: params.MainnetChainConfig.Copy(),
+			Alloc: blockchain.GenesisAlloc{
+				address: {Balance: new(big.Int).Mul(big.NewInt(params.KAIA), big.NewInt(100))},
+				// The address 0xBBBB stores 42 at slot 42
+				delegate: {
+					Code:    []byte{byte(vm.PUSH1), 0x42, byte(vm.DUP1), byte(vm.SSTORE)},
+					Balance: big.NewInt(0),
+				},
+			},
+		}
+		gendb = database.NewMemoryDBManager()
+		db    = database.NewMemoryDBManager()
+	)
+	gspec.Config.SetDefaults()
+	gspec.Config.IstanbulCompatibleBlock = common.Big0
+	gspec.Config.LondonCompatibleBlock = common.Big0
+	gspec.Config.EthTxTypeCompatibleBlock = common.Big0
+	gspec.Config.MagmaCompatibleBlock = common.Big0
+	gspec.Config.KoreCompatibleBlock = common.Big0
+	gspec.Config.ShanghaiCompatibleBlock = common.Big0
+	gspec.Config.CancunCompatibleBlock = common.Big0
+	gspec.Config.RandaoCompatibleBlock = nil
+	gspec.Config.KaiaCompatibleBlock = common.Big0
+	gspec.Config.PragueCompatibleBlock = common.Big0
+
+	signer := types.LatestSigner(gspec.Config)
+	genesis := gspec.MustCommit(gendb)
+	gspec.MustCommit(db)
+
+	// Every state is written to the database, so the stale trie nodes of the
+	// old blocks are accumulated.
+	cacheConfig := &blockchain.CacheConfig{
+		ArchiveMode:         true,
+		CacheSize:           512,
+		BlockInterval:       blockchain.DefaultBlockInterval,
+		TriesInMemory:       blockchain.DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+		SnapshotCacheSize:   512,
+	}
+	chain, err := blockchain.NewBlockChain(db, cacheConfig, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	require.NoError(t, err)
+
+	blocks, _ := blockchain.GenerateChain(gspec.Config, genesis, gxhash.NewFaker(), gendb, targetLayers+2, func(i int, block *blockchain.BlockGen) {
+		if i == 0 {
+			// Delegate the sender to 0xBBBB and call itself, so that the storage
+			// of the EOA is written by the delegated code.
+			nonce := block.TxNonce(address)
+			auth, err := types.SignSetCode(key, types.SetCodeAuthorization{
+				ChainID: gspec.Config.ChainID,
+				Address: delegate,
+				Nonce:   nonce + 1,
+			})
+			require.NoError(t, err)
+			tx, err := types.SignTx(types.NewTx(&types.TxInternalDataEthereumSetCode{
+				ChainID:           gspec.Config.ChainID,
+				AccountNonce:      nonce,
+				Recipient:         address,
+				GasLimit:          500000,
+				GasFeeCap:         gasPrice,
+				GasTipCap:         gasPrice,
+				Amount:            big.NewInt(0),
+				AuthorizationList: types.AuthorizationList{auth},
+			}), signer, key)
+			require.NoError(t, err)
+			block.AddTx(tx)
+			return
+		}
+		to := common.BigToAddress(big.NewInt(int64(0x10000 + i)))
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), to, big.NewInt(1000), params.TxGas, gasPrice, nil), signer, key)
+		require.NoError(t, err)
+		block.AddTx(tx)
+	})
+	_, err = chain.InsertChain(blocks)
+	require.NoError(t, err)
+	chain.Stop() // The snapshot is journaled
+
+	var (
+		head   = blocks[len(blocks)-1]
+		target = blocks[len(blocks)-targetLayers] // The bottom-most diff layer
+		before = countTrieNodes(db)
+	)
+	pruner, err := NewPruner(db, 0)
+	require.NoError(t, err)
+	require.NoError(t, pruner.Prune(common.Hash{}))
+
+	// The stale nodes are deleted while the target and the genesis state are retained.
+	assert.Less(t, countTrieNodes(db), before)
+	checkState(t, db, target.Root())
+	checkState(t, db, genesis.Root())
+	for _, block := range blocks[:len(blocks)-targetLayers] {
+		if ok, _ := db.HasTrieNode(block.Root().ExtendZero()); ok {
+			checkState(t, db, block.Root())
+		}
+	}
+
+	// The storage of the delegated EOA is retained.
+	sdb, err := state.New(target.Root(), state.NewDatabase(db), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, slot, sdb.GetState(address, slot))
+
+	// The state above the target is gone.
+	_, err = state.New(head.Root(), state.NewDatabase(db), nil, nil)
+	assert.Error(t, err)
+
+	// The snapshot is flattened into a single disk layer of the target.
+	snaptree, err := snapshot.New(db, statedb.NewDatabase(db), 256, target.Root(), false, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, target.Root(), snaptree.DiskRoot())
+}
@@ -320,10 +320,28 @@ func (s *stateObject) IsContractAvailable() bool {
 }
 
 // IsProgramAccount returns true if the account implements ProgramAccount.
+// An EOA with a delegation designator as its code (EIP-7702) remains an EOA, but it is
+// regarded as a program account so that the EVM runs the delegated code when it is called.
 func (s *stateObject) IsProgramAccount() bool {
+	if eoa, ok := s.account.(*account.ExternallyOwnedAccount); ok {
+		return eoa.HasCode()
+	}
 	return account.GetProgramAccount(s.account) != nil
 }
 
+// hasCodeOrStorageChanges returns true if the code or the storage trie of the account
+// has to be committed. Every EOA can hold code and storage since EIP-7702, so an EOA
+// needs it only if its code was set or its storage was accessed.
+func (s *stateObject) hasCodeOrStorageChanges() bool {
+	if account.GetProgramAccount(s.account) == nil {
+		return false
+	}
+	if _, ok := s.account.(*account.ExternallyOwnedAccount); ok {
+		return s.dirtyCode || s.storageTrie != nil || len(s.dirtyStorage) > 0
+	}
+	return true
+}
+
 func (s *stateObject) GetKey() accountkey.AccountKey {
 	if ak := account.GetAccountWithKey(s.account); ak != nil {
 		return ak.GetKey()
@@ -380,7 +380,7 @@ func (s *StateDB) IsValidCodeFormat(addr common.Address) bool {
 // GetVmVersion return false when getStateObject(addr) or GetProgramAccount(stateObject.account) is failed.
 func (s *StateDB) GetVmVersion(addr common.Address) (params.VmVersion, bool) {
 	stateObject := s.getStateObject(addr)
-	if stateObject != nil {
+	if stateObject != nil && stateObject.IsProgramAccount() {
 		pa := account.GetProgramAccount(stateObject.account)
 		if pa != nil {
 			return pa.GetVmVersion(), true
@@ -1044,7 +1044,9 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 			// and just mark it for deletion in the trie.
 			s.deleteStateObject(stateObject)
 		case isDirty:
-			if stateObject.IsProgramAccount() {
+			// An EOA may hold storage even after its delegation is cleared, so its storage is
+			// committed regardless of whether it has code or not, but only if it was touched.
+			if stateObject.hasCodeOrStorageChanges() {
 				// Write any contract code associated with the state object.
 				if stateObject.code != nil && stateObject.dirtyCode {
 					s.db.TrieDB().DiskDB().WriteCode(common.BytesToHash(stateObject.CodeHash()), stateObject.code)
@@ -834,3 +834,33 @@ func TestStateDBTransientStorage(t *testing.T) {
 		t.Fatalf("transient storage mismatch: have %x, want %x", got, value)
 	}
 }
+
+// TestCommitEOAStorage checks the storage trie of an EOA is committed only if its code was
+// set or its storage was accessed, and that a delegated EOA keeps its code and storage.
+func TestCommitEOAStorage(t *testing.T) {
+	db := NewDatabase(database.NewMemoryDBManager())
+	state, _ := New(common.Hash{}, db, nil, nil)
+
+	var (
+		plain     = common.HexToAddress("0x1")
+		delegated = common.HexToAddress("0x2")
+		code      = types.AddressToDelegation(common.HexToAddress("0xbbbb"))
+		key       = common.Hash{0x01}
+		value     = common.Hash{0x02}
+	)
+	state.AddBalance(plain, big.NewInt(1))
+	state.AddBalance(delegated, big.NewInt(1))
+	state.SetCode(delegated, code)
+	state.SetState(delegated, key, value)
+
+	root, err := state.Commit(false)
+	assert.NoError(t, err)
+	assert.Nil(t, state.getStateObject(plain).storageTrie)
+	assert.NoError(t, db.TrieDB().Commit(root, false, 0))
+
+	state, _ = New(root, db, nil, nil)
+	assert.False(t, state.IsProgramAccount(plain))
+	assert.True(t, state.IsProgramAccount(delegated))
+	assert.Equal(t, code, state.GetCode(delegated))
+	assert.Equal(t, value, state.GetState(delegated, key))
+}
@@ -28,6 +28,7 @@ import (
 	"math/big"
 
 	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
 	"github.com/klaytn/klaytn/blockchain/vm"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/kerrors"
@@ -123,6 +124,9 @@ type Message interface {
 	Execute(vm types.VM, stateDB types.StateDB, currentBlockNumber uint64, gas uint64, value *big.Int) ([]byte, uint64, error)
 
 	AccessList() types.AccessList
+
+	// For TxTypeEthereumSetCode
+	AuthorizationList() types.AuthorizationList
 }
 
 // ExecutionResult includes all output after executing given evm
@@ -357,6 +361,16 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 		return nil, fmt.Errorf("%w: code size %v limit %v", ErrMaxInitCodeSizeExceeded, len(st.data), params.MaxInitCodeSize)
 	}
 
+	// Apply EIP-7702 authorizations before the execution. The sender's nonce is increased in advance,
+	// so that an authorization signed by the sender itself is checked against the increased nonce.
+	if msg.Type() == types.TxTypeEthereumSetCode {
+		st.state.IncNonce(msg.ValidatedSender())
+		for _, auth := range msg.AuthorizationList() {
+			// Note errors are ignored, we simply skip invalid authorizations here.
+			st.applyAuthorization(&auth)
+		}
+	}
+
 	var (
 		ret   []byte
 		vmerr error
@@ -393,6 +407,68 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	}, nil
 }
 
+// validateAuthorization validates an EIP-7702 authorization against the state.
+func (st *StateTransition) validateAuthorization(auth *types.SetCodeAuthorization) (authority common.Address, err error) {
+	// Verify chain ID is zero or equals the current chain ID.
+	if auth.ChainID != nil && auth.ChainID.Sign() != 0 && auth.ChainID.Cmp(st.evm.ChainConfig().ChainID) != 0 {
+		return authority, ErrAuthorizationWrongChainID
+	}
+	// Limit nonce to 2^64-1 per EIP-2681.
+	if auth.Nonce+1 < auth.Nonce {
+		return authority, ErrAuthorizationNonceOverflow
+	}
+	// Validate signature values and recover authority.
+	authority, err = auth.Authority()
+	if err != nil {
+		return authority, fmt.Errorf("%w: %v", ErrAuthorizationInvalidSignature, err)
+	}
+	// Check the authority account
+	//  1) is not a smart contract account, but an EOA without code or with an existing delegation
+	//  2) has the legacy account key, since a decoupled or role-based key must not be bypassed by an ECDSA signature
+	//  3) matches the auth's nonce
+	//
+	// Note it is added to the access list even if the authorization is invalid.
+	st.state.AddAddressToAccessList(authority)
+	if st.state.IsProgramAccount(authority) {
+		if _, ok := types.ParseDelegation(st.state.GetCode(authority)); !ok {
+			return authority, ErrAuthorizationDestinationHasCode
+		}
+	}
+	if !st.state.GetKey(authority).Type().IsLegacyAccountKey() {
+		return authority, ErrAuthorizationNotLegacyKey
+	}
+	if have := st.state.GetNonce(authority); have != auth.Nonce {
+		return authority, ErrAuthorizationNonceMismatch
+	}
+	return authority, nil
+}
+
+// applyAuthorization applies an EIP-7702 code delegation to the state.
+func (st *StateTransition) applyAuthorization(auth *types.SetCodeAuthorization) error {
+	authority, err := st.validateAuthorization(auth)
+	if err != nil {
+		return err
+	}
+
+	// If the account already exists in state, refund the new account cost
+	// charged in the intrinsic calculation.
+	if st.state.Exist(authority) {
+		st.state.AddRefund(params.CallNewAccountGas - params.TxAuthTupleGas)
+	} else {
+		st.state.CreateEOA(authority, false, accountkey.NewAccountKeyLegacy())
+	}
+
+	// Update nonce and account code.
+	st.state.SetNonce(authority, auth.Nonce+1)
+	if auth.Address == (common.Address{}) {
+		// Delegation to zero address means clear.
+		return st.state.SetCode(authority, nil)
+	}
+
+	// Otherwise install delegation to auth.Address.
+	return st.state.SetCode(authority, types.AddressToDelegation(auth.Address))
+}
+
 var errTxFailed2receiptstatus = map[error]uint{
 	nil:                                             types.ReceiptStatusSuccessful,
 	vm.ErrDepth:                                     types.ReceiptStatusErrDepth,
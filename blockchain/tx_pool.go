@@ -678,6 +678,10 @@ func (pool *TxPool) validateTx(tx *types.Transaction) error {
 	if !pool.rules.IsEthTxType && tx.Type() == types.TxTypeEthereumDynamicFee {
 		return ErrTxTypeNotSupported
 	}
+	// Reject set code transactions until EIP-7702 activates.
+	if !pool.rules.IsPrague && tx.Type() == types.TxTypeEthereumSetCode {
+		return ErrTxTypeNotSupported
+	}
 
 	// Check whether the init code size has been exceeded
 	if pool.rules.IsShanghai && tx.To() == nil && len(tx.Data()) > params.MaxInitCodeSize {
@@ -690,8 +694,8 @@ func (pool *TxPool) validateTx(tx *types.Transaction) error {
 	}
 
 	// NOTE-Kaia Drop transactions with unexpected gasPrice
-	// If the transaction type is DynamicFee tx, Compare transaction's GasFeeCap(MaxFeePerGas) and GasTipCap with tx pool's gasPrice to check to have same value.
-	if tx.Type() == types.TxTypeEthereumDynamicFee {
+	// If the transaction type is DynamicFee or SetCode tx, Compare transaction's GasFeeCap(MaxFeePerGas) and GasTipCap with tx pool's gasPrice to check to have same value.
+	if tx.Type() == types.TxTypeEthereumDynamicFee || tx.Type() == types.TxTypeEthereumSetCode {
 		// Sanity check for extremely large numbers
 		if tx.GasTipCap().BitLen() > 256 {
 			return ErrTipVeryHigh
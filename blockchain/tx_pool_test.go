@@ -56,6 +56,9 @@ var (
 
 	// kip71Config is a chain config with Magma enabled at block 0.
 	kip71Config *params.ChainConfig
+
+	// pragueConfig is a chain config with EIP-1559 and EIP-7702 enabled at block 0.
+	pragueConfig *params.ChainConfig
 )
 
 func init() {
@@ -75,6 +78,9 @@ func init() {
 	kip71Config.EthTxTypeCompatibleBlock = common.Big0
 	kip71Config.Governance = &params.GovernanceConfig{KIP71: params.GetDefaultKIP71Config()}
 
+	pragueConfig = eip1559Config.Copy()
+	pragueConfig.PragueCompatibleBlock = common.Big0
+
 	InitDeriveSha(params.TestChainConfig)
 }
 
@@ -140,6 +146,28 @@ func dynamicFeeTx(nonce uint64, gaslimit uint64, gasFee *big.Int, tip *big.Int,
 	return signedTx
 }
 
+func setCodeTx(nonce uint64, gaslimit uint64, gasFee *big.Int, tip *big.Int, key *ecdsa.PrivateKey) *types.Transaction {
+	authKey, _ := crypto.GenerateKey()
+	auth, _ := types.SignSetCode(authKey, types.SetCodeAuthorization{
+		ChainID: params.TestChainConfig.ChainID,
+		Address: common.HexToAddress("0x000000000000000000000000000000000000aaaa"),
+		Nonce:   0,
+	})
+	setCodeTx := types.NewTx(&types.TxInternalDataEthereumSetCode{
+		ChainID:           params.TestChainConfig.ChainID,
+		AccountNonce:      nonce,
+		GasTipCap:         tip,
+		GasFeeCap:         gasFee,
+		GasLimit:          gaslimit,
+		Recipient:         crypto.PubkeyToAddress(authKey.PublicKey),
+		Amount:            big.NewInt(100),
+		AuthorizationList: types.AuthorizationList{auth},
+	})
+
+	signedTx, _ := types.SignTx(setCodeTx, types.LatestSignerForChainID(params.TestChainConfig.ChainID), key)
+	return signedTx
+}
+
 func cancelTx(nonce uint64, gasLimit uint64, gasPrice *big.Int, from common.Address, key *ecdsa.PrivateKey) *types.Transaction {
 	d, err := types.NewTxInternalDataWithMap(types.TxTypeCancel, map[types.TxValueKeyType]interface{}{
 		types.TxValueKeyNonce:    nonce,
@@ -2157,6 +2185,39 @@ func TestDynamicFeeTransactionNotAcceptedNotEnableHardfork(t *testing.T) {
 	}
 }
 
+// TestSetCodeTransactionNotAcceptedNotEnableHardfork tests that the pool didn't accept set code tx if the pool didn't enable prague hardfork.
+func TestSetCodeTransactionNotAcceptedNotEnableHardfork(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPoolWithConfig(eip1559Config)
+	defer pool.Stop()
+
+	tx := setCodeTx(0, 100000, big.NewInt(1), big.NewInt(1), key)
+	if err := pool.AddRemote(tx); err != ErrTxTypeNotSupported {
+		t.Error("expected", ErrTxTypeNotSupported, "got", err)
+	}
+}
+
+func TestSetCodeTransactionAccepted(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPoolWithConfig(pragueConfig)
+	defer pool.Stop()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+
+	tx := setCodeTx(0, 100000, big.NewInt(1), big.NewInt(1), key)
+	if err := pool.AddRemote(tx); err != nil {
+		t.Error("error", "got", err)
+	}
+
+	// The GasTipCap is equal to gasPrice that config at TxPool.
+	tx2 := setCodeTx(1, 100000, big.NewInt(2), big.NewInt(2), key)
+	if err := pool.AddRemote(tx2); err != ErrInvalidGasTipCap {
+		t.Error("expected", ErrInvalidGasTipCap, "got", err)
+	}
+}
+
 func TestDynamicFeeTransactionAcceptedEip1559(t *testing.T) {
 	t.Parallel()
 	baseFee := big.NewInt(30)
@@ -71,6 +71,9 @@ var (
 	// TODO-Kaia-Accounts: make one single instance emptyCodeHash. It is placed in several locations for now.
 	emptyCodeHash = crypto.Keccak256(nil)
 
+	// emptyRoot is the known root hash of an empty trie.
+	emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
 	logger = log.NewModuleLogger(log.BlockchainState)
 )
 
@@ -109,7 +112,8 @@ type Account interface {
 }
 
 // ProgramAccount is an interface of an account having a program (code + storage).
-// This interface is implemented by LegacyAccount and SmartContractAccount.
+// This interface is implemented by LegacyAccount, ExternallyOwnedAccount and SmartContractAccount.
+// An ExternallyOwnedAccount has a program only if it delegates its code by EIP-7702.
 type ProgramAccount interface {
 	Account
 
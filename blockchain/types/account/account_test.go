@@ -41,6 +41,7 @@ var (
 	_ Account = (*ExternallyOwnedAccount)(nil)
 	_ Account = (*SmartContractAccount)(nil)
 
+	_ ProgramAccount = (*ExternallyOwnedAccount)(nil)
 	_ ProgramAccount = (*SmartContractAccount)(nil)
 
 	_ AccountWithKey = (*ExternallyOwnedAccount)(nil)
@@ -55,6 +56,7 @@ func TestAccountSerialization(t *testing.T) {
 	}{
 		{"EOA", genEOA()},
 		{"EOAWithPublic", genEOAWithPublicKey()},
+		{"EOAWithCode", genEOAWithCode()},
 		{"SCA", genSCA()},
 		{"SCAWithPublic", genSCAWithPublicKey()},
 	}
@@ -155,6 +157,20 @@ func genEOAWithPublicKey() *ExternallyOwnedAccount {
 	})
 }
 
+func genEOAWithCode() *ExternallyOwnedAccount {
+	humanReadable := false
+
+	return newExternallyOwnedAccountWithMap(map[AccountValueKeyType]interface{}{
+		AccountValueKeyNonce:         rand.Uint64(),
+		AccountValueKeyBalance:       big.NewInt(rand.Int63n(10000)),
+		AccountValueKeyHumanReadable: humanReadable,
+		AccountValueKeyAccountKey:    accountkey.NewAccountKeyLegacy(),
+		AccountValueKeyStorageRoot:   genRandomHash(),
+		AccountValueKeyCodeHash:      genRandomHash().Bytes(),
+		AccountValueKeyCodeInfo:      params.CodeInfo(0x10),
+	})
+}
+
 func genSCA() *SmartContractAccount {
 	humanReadable := false
 
@@ -262,6 +278,76 @@ func TestSmartContractAccountExt(t *testing.T) {
 	checkDecodeExt(scaExtRLP, scaExt)
 }
 
+// Tests that an EOA without code keeps the legacy encoding and an EOA with code
+// is encoded in the same layout as an SCA.
+func TestExternallyOwnedAccountCode(t *testing.T) {
+	var (
+		commonFields = &AccountCommon{
+			nonce:         0x1234,
+			balance:       big.NewInt(0x5678),
+			humanReadable: false,
+			key:           accountkey.NewAccountKeyLegacy(),
+		}
+		hash     = common.HexToHash("00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff")
+		exthash  = common.HexToExtHash("00112233445566778899aabbccddeeff00112233445566778899aabbccddeeffccccddddeeee01")
+		codehash = common.HexToHash("aaaaaaaabbbbbbbbccccccccddddddddaaaaaaaabbbbbbbbccccccccdddddddd").Bytes()
+		codeinfo = params.CodeInfo(0x10)
+
+		eoaRLP = "0x01c98212348256788001c0"
+		eoa    = &ExternallyOwnedAccount{
+			AccountCommon: commonFields,
+			storageRoot:   emptyRoot.ExtendZero(),
+			codeHash:      emptyCodeHash,
+		}
+		eoaCodeUnextRLP = "0x01f84dc98212348256788001c0a000112233445566778899aabbccddeeff00112233445566778899aabbccddeeffa0aaaaaaaabbbbbbbbccccccccddddddddaaaaaaaabbbbbbbbccccccccdddddddd10"
+		eoaCodeUnext    = &ExternallyOwnedAccount{
+			AccountCommon: commonFields,
+			storageRoot:   hash.ExtendZero(),
+			codeHash:      codehash,
+			codeInfo:      codeinfo,
+		}
+		eoaCodeExtRLP = "0x01f854c98212348256788001c0a700112233445566778899aabbccddeeff00112233445566778899aabbccddeeffccccddddeeee01a0aaaaaaaabbbbbbbbccccccccddddddddaaaaaaaabbbbbbbbccccccccdddddddd10"
+		eoaCodeExt    = &ExternallyOwnedAccount{
+			AccountCommon: commonFields,
+			storageRoot:   exthash,
+			codeHash:      codehash,
+			codeInfo:      codeinfo,
+		}
+	)
+
+	checkEncode := func(account Account, encoded string) {
+		b, err := rlp.EncodeToBytes(NewAccountSerializerWithAccount(account))
+		assert.Nil(t, err)
+		assert.Equal(t, encoded, hexutil.Encode(b))
+	}
+	checkEncodeExt := func(account Account, encoded string) {
+		b, err := rlp.EncodeToBytes(NewAccountSerializerExtWithAccount(account))
+		assert.Nil(t, err)
+		assert.Equal(t, encoded, hexutil.Encode(b))
+	}
+	checkDecode := func(encoded string, account Account) {
+		dec := NewAccountSerializerExt()
+		assert.Nil(t, rlp.DecodeBytes(common.FromHex(encoded), &dec))
+		assert.True(t, dec.GetAccount().Equal(account))
+	}
+
+	checkEncode(eoa, eoaRLP)
+	checkEncodeExt(eoa, eoaRLP)
+	checkDecode(eoaRLP, eoa)
+	assert.False(t, eoa.HasCode())
+	assert.True(t, eoa.Empty() == commonFields.Empty())
+
+	checkEncode(eoaCodeUnext, eoaCodeUnextRLP)
+	checkEncodeExt(eoaCodeUnext, eoaCodeUnextRLP)
+	checkDecode(eoaCodeUnextRLP, eoaCodeUnext)
+
+	checkEncode(eoaCodeExt, eoaCodeUnextRLP)
+	checkEncodeExt(eoaCodeExt, eoaCodeExtRLP)
+	checkDecode(eoaCodeExtRLP, eoaCodeExt)
+	assert.True(t, eoaCodeExt.HasCode())
+	assert.False(t, eoaCodeExt.Empty())
+}
+
 func TestUnextendRLP(t *testing.T) {
 	// storage slot
 	testcases := []struct {
@@ -19,43 +19,321 @@
 package account
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
 )
 
+// type ExternallyOwnedAccount:                 Primary in-memory representation. storageRoot is ExtHash
+// type accountCommonSerializable:              RLP encoding spec of an EOA without code and storage.
+// type externallyOwnedAccountSerializable:     RLP encoding spec of an EOA with code or storage. StorageRoot is Hash
+// type externallyOwnedAccountSerializableExt:  RLP encoding spec of an EOA with code or storage. StorageRoot is ExtHash
+// type externallyOwnedAccountSerializableJSON: JSON encoding spec. StorageRoot is Hash
+
 // ExternallyOwnedAccount represents a Kaia account used by a user.
+// An EOA may carry an EIP-7702 delegation designator as its code, and a storage
+// trie written while the delegated code was running. An EOA without code and
+// storage is encoded in the same way as before the introduction of delegations.
 type ExternallyOwnedAccount struct {
 	*AccountCommon
+	storageRoot common.ExtHash // merkle root plus optional sequence of the storage trie
+	codeHash    []byte
+	codeInfo    params.CodeInfo // consists of two information, vmVersion and codeFormat
+}
+
+// externallyOwnedAccountSerializable is an internal data structure for RLP serialization.
+// This structure inherits accountCommonSerializable.
+type externallyOwnedAccountSerializable struct {
+	CommonSerializable *accountCommonSerializable
+	StorageRoot        common.Hash
+	CodeHash           []byte
+	CodeInfo           params.CodeInfo
+}
+
+// externallyOwnedAccountSerializableExt is an internal data structure for RLP serialization.
+// This structure inherits accountCommonSerializable.
+// nolint: maligned  // Because it is a temporary struct, memory footprint is not important.
+type externallyOwnedAccountSerializableExt struct {
+	CommonSerializable *accountCommonSerializable
+	StorageRoot        common.ExtHash
+	CodeHash           []byte
+	CodeInfo           params.CodeInfo
+}
+
+type externallyOwnedAccountSerializableJSON struct {
+	Nonce         uint64                           `json:"nonce"`
+	Balance       *hexutil.Big                     `json:"balance"`
+	HumanReadable bool                             `json:"humanReadable"`
+	Key           *accountkey.AccountKeySerializer `json:"key"`
+	StorageRoot   *common.Hash                     `json:"storageRoot,omitempty"`
+	CodeHash      []byte                           `json:"codeHash,omitempty"`
+	CodeFormat    params.CodeFormat                `json:"codeFormat,omitempty"`
+	VmVersion     params.VmVersion                 `json:"vmVersion,omitempty"`
 }
 
 // newExternallyOwnedAccount creates an ExternallyOwnedAccount object with default values.
 func newExternallyOwnedAccount() *ExternallyOwnedAccount {
 	return &ExternallyOwnedAccount{
 		newAccountCommon(),
+		emptyRoot.ExtendZero(),
+		emptyCodeHash,
+		params.CodeInfo(0),
 	}
 }
 
 // newExternallyOwnedAccountWithMap creates an ExternallyOwnedAccount object initialized with the given values.
 func newExternallyOwnedAccountWithMap(values map[AccountValueKeyType]interface{}) *ExternallyOwnedAccount {
-	return &ExternallyOwnedAccount{
+	eoa := &ExternallyOwnedAccount{
 		newAccountCommonWithMap(values),
+		emptyRoot.ExtendZero(),
+		emptyCodeHash,
+		params.CodeInfo(0),
+	}
+
+	if v, ok := values[AccountValueKeyStorageRoot].(common.Hash); ok {
+		eoa.storageRoot = v.ExtendZero()
+	}
+	if v, ok := values[AccountValueKeyStorageRoot].(common.ExtHash); ok {
+		eoa.storageRoot = v
+	}
+
+	if v, ok := values[AccountValueKeyCodeHash].([]byte); ok {
+		eoa.codeHash = v
+	}
+
+	if v, ok := values[AccountValueKeyCodeInfo].(params.CodeInfo); ok {
+		eoa.codeInfo = v
+	}
+
+	return eoa
+}
+
+// isCompact returns true if the account has neither code nor storage,
+// so that it can be encoded in the legacy EOA format.
+func (e *ExternallyOwnedAccount) isCompact() bool {
+	root := e.storageRoot.Unextend()
+	return (root == emptyRoot || root == common.Hash{}) &&
+		bytes.Equal(e.codeHash, emptyCodeHash) &&
+		e.codeInfo == params.CodeInfo(0)
+}
+
+func (e *ExternallyOwnedAccount) toSerializable() *externallyOwnedAccountSerializable {
+	return &externallyOwnedAccountSerializable{
+		CommonSerializable: e.AccountCommon.toSerializable(),
+		StorageRoot:        e.storageRoot.Unextend(),
+		CodeHash:           e.codeHash,
+		CodeInfo:           e.codeInfo,
+	}
+}
+
+func (e *ExternallyOwnedAccount) toSerializableExt() *externallyOwnedAccountSerializableExt {
+	return &externallyOwnedAccountSerializableExt{
+		CommonSerializable: e.AccountCommon.toSerializable(),
+		StorageRoot:        e.storageRoot,
+		CodeHash:           e.codeHash,
+		CodeInfo:           e.codeInfo,
+	}
+}
+
+func (e *ExternallyOwnedAccount) fromSerializable(o *externallyOwnedAccountSerializable) {
+	e.AccountCommon.fromSerializable(o.CommonSerializable)
+	e.storageRoot = o.StorageRoot.ExtendZero()
+	e.codeHash = o.CodeHash
+	e.codeInfo = o.CodeInfo
+}
+
+func (e *ExternallyOwnedAccount) fromSerializableExt(o *externallyOwnedAccountSerializableExt) {
+	e.AccountCommon.fromSerializable(o.CommonSerializable)
+	e.storageRoot = o.StorageRoot
+	e.codeHash = o.CodeHash
+	e.codeInfo = o.CodeInfo
+}
+
+func (e *ExternallyOwnedAccount) EncodeRLP(w io.Writer) error {
+	if e.isCompact() {
+		return e.AccountCommon.EncodeRLP(w)
+	}
+	return rlp.Encode(w, e.toSerializable())
+}
+
+func (e *ExternallyOwnedAccount) EncodeRLPExt(w io.Writer) error {
+	if e.isCompact() {
+		return e.AccountCommon.EncodeRLP(w)
+	}
+	if e.storageRoot.IsZeroExtended() {
+		return rlp.Encode(w, e.toSerializable())
+	} else {
+		return rlp.Encode(w, e.toSerializableExt())
 	}
 }
 
+func (e *ExternallyOwnedAccount) DecodeRLP(s *rlp.Stream) error {
+	savedStream, err := s.Raw()
+	if err != nil {
+		return err
+	}
+
+	// s.Raw() has consumed the stream. Refill with original data.
+	s.Reset(bytes.NewReader(savedStream), 0)
+	// Try decode into accountCommonSerializable, the format of an EOA without code and storage.
+	serializedCommon := newAccountCommonSerializable()
+	if err := s.Decode(serializedCommon); err == nil {
+		e.AccountCommon.fromSerializable(serializedCommon)
+		e.storageRoot = emptyRoot.ExtendZero()
+		e.codeHash = emptyCodeHash
+		e.codeInfo = params.CodeInfo(0)
+		return nil
+	}
+
+	// s.Decode() may have consumed the stream. Refill with original data.
+	s.Reset(bytes.NewReader(savedStream), 0)
+	// Retry with externallyOwnedAccountSerializableExt
+	serializedExt := &externallyOwnedAccountSerializableExt{
+		CommonSerializable: newAccountCommonSerializable(),
+	}
+	if err := s.Decode(serializedExt); err == nil {
+		e.fromSerializableExt(serializedExt)
+		return nil
+	}
+
+	// s.Decode() may have consumed the stream. Refill with original data.
+	s.Reset(bytes.NewReader(savedStream), 0)
+	// Retry with externallyOwnedAccountSerializable
+	serialized := &externallyOwnedAccountSerializable{
+		CommonSerializable: newAccountCommonSerializable(),
+	}
+	if err := s.Decode(serialized); err == nil {
+		e.fromSerializable(serialized)
+		return nil
+	} else {
+		return err
+	}
+}
+
+func (e *ExternallyOwnedAccount) MarshalJSON() ([]byte, error) {
+	serialized := &externallyOwnedAccountSerializableJSON{
+		Nonce:         e.nonce,
+		Balance:       (*hexutil.Big)(e.balance),
+		HumanReadable: e.humanReadable,
+		Key:           accountkey.NewAccountKeySerializerWithAccountKey(e.key),
+	}
+	// Program fields are omitted for an EOA without code and storage for API compatibility.
+	if !e.isCompact() {
+		root := e.storageRoot.Unextend() // Unextend for API compatibility
+		serialized.StorageRoot = &root
+		serialized.CodeHash = e.codeHash
+		serialized.CodeFormat = e.codeInfo.GetCodeFormat()
+		serialized.VmVersion = e.codeInfo.GetVmVersion()
+	}
+	return json.Marshal(serialized)
+}
+
+func (e *ExternallyOwnedAccount) UnmarshalJSON(b []byte) error {
+	serialized := &externallyOwnedAccountSerializableJSON{}
+
+	if err := json.Unmarshal(b, serialized); err != nil {
+		return err
+	}
+
+	e.AccountCommon = newAccountCommon()
+	e.nonce = serialized.Nonce
+	e.balance = (*big.Int)(serialized.Balance)
+	e.humanReadable = serialized.HumanReadable
+	e.key = serialized.Key.GetKey()
+	e.storageRoot = emptyRoot.ExtendZero()
+	e.codeHash = emptyCodeHash
+	e.codeInfo = params.CodeInfo(0)
+	if serialized.StorageRoot != nil {
+		e.storageRoot = serialized.StorageRoot.ExtendZero() // API inputs should contain merkle hash
+	}
+	if len(serialized.CodeHash) > 0 {
+		e.codeHash = serialized.CodeHash
+		e.codeInfo = params.NewCodeInfo(serialized.CodeFormat, serialized.VmVersion)
+	}
+
+	return nil
+}
+
 func (e *ExternallyOwnedAccount) Type() AccountType {
 	return ExternallyOwnedAccountType
 }
 
+func (e *ExternallyOwnedAccount) GetStorageRoot() common.ExtHash {
+	return e.storageRoot
+}
+
+func (e *ExternallyOwnedAccount) GetCodeHash() []byte {
+	return e.codeHash
+}
+
+func (e *ExternallyOwnedAccount) GetCodeFormat() params.CodeFormat {
+	return e.codeInfo.GetCodeFormat()
+}
+
+func (e *ExternallyOwnedAccount) GetVmVersion() params.VmVersion {
+	return e.codeInfo.GetVmVersion()
+}
+
+func (e *ExternallyOwnedAccount) SetStorageRoot(h common.ExtHash) {
+	e.storageRoot = h
+}
+
+// SetCodeHash sets the code hash of the account. Since the code of an EOA is always
+// a delegation designator installed after Istanbul, the code info follows the code hash.
+func (e *ExternallyOwnedAccount) SetCodeHash(h []byte) {
+	e.codeHash = h
+	if bytes.Equal(h, emptyCodeHash) {
+		e.codeInfo = params.CodeInfo(0)
+	} else {
+		e.codeInfo = params.NewCodeInfo(params.CodeFormatEVM, params.VmVersion1)
+	}
+}
+
+func (e *ExternallyOwnedAccount) SetCodeInfo(ci params.CodeInfo) {
+	e.codeInfo = ci
+}
+
+// HasCode returns true if the account has a delegation designator as its code.
+func (e *ExternallyOwnedAccount) HasCode() bool {
+	return !bytes.Equal(e.codeHash, emptyCodeHash)
+}
+
+func (e *ExternallyOwnedAccount) Empty() bool {
+	return e.AccountCommon.Empty() && !e.HasCode()
+}
+
 func (e *ExternallyOwnedAccount) Dump() {
 	fmt.Println(e.String())
 }
 
 func (e *ExternallyOwnedAccount) String() string {
-	return fmt.Sprintf("EOA: %s", e.AccountCommon.String())
+	if e.isCompact() {
+		return fmt.Sprintf("EOA: %s", e.AccountCommon.String())
+	}
+	return fmt.Sprintf(`EOA: %s
+	StorageRoot: %s
+	CodeHash: %s
+	CodeInfo: %s`,
+		e.AccountCommon.String(),
+		e.storageRoot.String(),
+		common.Bytes2Hex(e.codeHash),
+		e.codeInfo.String())
 }
 
 func (e *ExternallyOwnedAccount) DeepCopy() Account {
 	return &ExternallyOwnedAccount{
 		AccountCommon: e.AccountCommon.DeepCopy(),
+		storageRoot:   e.storageRoot,
+		codeHash:      common.CopyBytes(e.codeHash),
+		codeInfo:      e.codeInfo,
 	}
 }
 
@@ -65,5 +343,8 @@ func (e *ExternallyOwnedAccount) Equal(a Account) bool {
 		return false
 	}
 
-	return e.AccountCommon.Equal(e2.AccountCommon)
+	return e.AccountCommon.Equal(e2.AccountCommon) &&
+		e.storageRoot == e2.storageRoot &&
+		bytes.Equal(e.codeHash, e2.codeHash) &&
+		e.codeInfo == e2.codeInfo
 }
@@ -285,8 +285,7 @@ func (tx *Transaction) setDecoded(inner TxInternalData, size int) {
 func (tx *Transaction) Gas() uint64        { return tx.data.GetGasLimit() }
 func (tx *Transaction) GasPrice() *big.Int { return new(big.Int).Set(tx.data.GetPrice()) }
 func (tx *Transaction) GasTipCap() *big.Int {
-	if tx.Type() == TxTypeEthereumDynamicFee {
-		te := tx.GetTxInternalData().(TxInternalDataBaseFee)
+	if te, ok := tx.GetTxInternalData().(TxInternalDataBaseFee); ok {
 		return te.GetGasTipCap()
 	}
 
@@ -294,8 +293,7 @@ func (tx *Transaction) GasTipCap() *big.Int {
 }
 
 func (tx *Transaction) GasFeeCap() *big.Int {
-	if tx.Type() == TxTypeEthereumDynamicFee {
-		te := tx.GetTxInternalData().(TxInternalDataBaseFee)
+	if te, ok := tx.GetTxInternalData().(TxInternalDataBaseFee); ok {
 		return te.GetGasFeeCap()
 	}
 
@@ -333,6 +331,15 @@ func (tx *Transaction) AccessList() AccessList {
 	return nil
 }
 
+// AuthorizationList returns the EIP-7702 authorization list of the transaction.
+// It returns nil if the transaction is not a set code transaction.
+func (tx *Transaction) AuthorizationList() AuthorizationList {
+	if te, ok := tx.GetTxInternalData().(TxInternalDataSetCode); ok {
+		return te.GetAuthorizationList()
+	}
+	return nil
+}
+
 func (tx *Transaction) Value() *big.Int { return new(big.Int).Set(tx.data.GetAmount()) }
 func (tx *Transaction) Nonce() uint64   { return tx.data.GetAccountNonce() }
 func (tx *Transaction) CheckNonce() bool {
@@ -115,6 +115,76 @@ func TestLondonSigningWithNoBitChainID(t *testing.T) {
 	}
 }
 
+func TestPragueSigningSetCode(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	authKey, _ := crypto.GenerateKey()
+	authAddr := crypto.PubkeyToAddress(authKey.PublicKey)
+	target := common.HexToAddress("0x0000000000000000000000000000000000001234")
+
+	auth, err := SignSetCode(authKey, SetCodeAuthorization{
+		ChainID: big.NewInt(10),
+		Address: target,
+		Nonce:   3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	authority, err := auth.Authority()
+	assert.NoError(t, err)
+	assert.Equal(t, authAddr, authority)
+
+	signer := NewPragueSigner(big.NewInt(10))
+	tx, err := SignTx(NewTx(&TxInternalDataEthereumSetCode{
+		AccountNonce:      1,
+		Amount:            big.NewInt(10),
+		GasFeeCap:         big.NewInt(10),
+		GasTipCap:         big.NewInt(10),
+		GasLimit:          100000,
+		Recipient:         authAddr,
+		ChainID:           big.NewInt(10),
+		AuthorizationList: AuthorizationList{auth},
+	}), signer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from, err := Sender(signer, tx)
+	assert.NoError(t, err)
+	assert.Equal(t, addr, from)
+	assert.Equal(t, AuthorizationList{auth}, tx.AuthorizationList())
+
+	// A set code transaction cannot be recovered by a signer of other chain.
+	_, err = Sender(NewPragueSigner(big.NewInt(11)), tx)
+	assert.ErrorIs(t, err, ErrInvalidChainId)
+
+	// The authority cannot be recovered once the authorization is altered.
+	auth.Nonce = 4
+	authority, err = auth.Authority()
+	assert.True(t, err != nil || authority != authAddr)
+}
+
+func TestDelegationDesignator(t *testing.T) {
+	target := common.HexToAddress("0x0000000000000000000000000000000000001234")
+
+	code := AddressToDelegation(target)
+	assert.Equal(t, 23, len(code))
+	parsed, ok := ParseDelegation(code)
+	assert.True(t, ok)
+	assert.Equal(t, target, parsed)
+
+	for _, invalid := range [][]byte{
+		nil,
+		{},
+		code[:22],
+		append(common.CopyBytes(code), 0x00),
+		append([]byte{0xef, 0x01, 0x01}, target.Bytes()...),
+	} {
+		_, ok := ParseDelegation(invalid)
+		assert.False(t, ok, "code: %x", invalid)
+	}
+}
+
 func TestEIP2930SigningWithoutChainID(t *testing.T) {
 	key, _ := crypto.GenerateKey()
 	addr := crypto.PubkeyToAddress(key.PublicKey)
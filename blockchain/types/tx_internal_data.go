@@ -58,7 +58,8 @@ const (
 	TxTypeKaiaLast, _, _
 	TxTypeEthereumAccessList = TxType(0x7801)
 	TxTypeEthereumDynamicFee = TxType(0x7802)
-	TxTypeEthereumLast       = TxType(0x7803)
+	TxTypeEthereumSetCode    = TxType(0x7804)
+	TxTypeEthereumLast       = TxType(0x7805)
 )
 
 type TxValueKeyType uint
@@ -83,6 +84,7 @@ const (
 	TxValueKeyChainID
 	TxValueKeyGasTipCap
 	TxValueKeyGasFeeCap
+	TxValueKeyAuthorizationList
 )
 
 type TxTypeMask uint8
@@ -118,6 +120,7 @@ var (
 	errValueKeyChainIDInvalid            = errors.New("ChainID must be a type of ChainID")
 	errValueKeyGasTipCapMustBigInt       = errors.New("GasTipCap must be a type of *big.Int")
 	errValueKeyGasFeeCapMustBigInt       = errors.New("GasFeeCap must be a type of *big.Int")
+	errValueKeyAuthorizationListInvalid  = errors.New("AuthorizationList must be a type of AuthorizationList")
 
 	ErrTxTypeNotSupported         = errors.New("transaction type not supported")
 	ErrSenderPubkeyNotSupported   = errors.New("SenderPubkey is not supported for this signer")
@@ -164,6 +167,8 @@ func (t TxValueKeyType) String() string {
 		return "TxValueKeyGasTipCap"
 	case TxValueKeyGasFeeCap:
 		return "TxValueKeyGasFeeCap"
+	case TxValueKeyAuthorizationList:
+		return "TxValueKeyAuthorizationList"
 	}
 
 	return "UndefinedTxValueKeyType"
@@ -223,6 +228,8 @@ func (t TxType) String() string {
 		return "TxTypeEthereumAccessList"
 	case TxTypeEthereumDynamicFee:
 		return "TxTypeEthereumDynamicFee"
+	case TxTypeEthereumSetCode:
+		return "TxTypeEthereumSetCode"
 	}
 
 	return "UndefinedTxType"
@@ -406,6 +413,11 @@ type TxInternalDataBaseFee interface {
 	GetGasFeeCap() *big.Int
 }
 
+// TxInternalDataSetCode has a function related to EIP-7702 Ethereum typed transaction.
+type TxInternalDataSetCode interface {
+	GetAuthorizationList() AuthorizationList
+}
+
 // Since we cannot access the package `blockchain/vm` directly, an interface `VM` is introduced.
 // TODO-Kaia-Refactoring: Transaction and related data structures should be a new package.
 type VM interface {
@@ -427,6 +439,7 @@ type StateDB interface {
 	IsContractAvailable(addr common.Address) bool
 	IsValidCodeFormat(addr common.Address) bool
 	GetKey(addr common.Address) accountkey.AccountKey
+	GetCode(addr common.Address) []byte
 }
 
 func NewTxInternalData(t TxType) (TxInternalData, error) {
@@ -481,6 +494,8 @@ func NewTxInternalData(t TxType) (TxInternalData, error) {
 		return newTxInternalDataEthereumAccessList(), nil
 	case TxTypeEthereumDynamicFee:
 		return newTxInternalDataEthereumDynamicFee(), nil
+	case TxTypeEthereumSetCode:
+		return newTxInternalDataEthereumSetCode(), nil
 	}
 
 	return nil, errUndefinedTxType
@@ -538,6 +553,8 @@ func NewTxInternalDataWithMap(t TxType, values map[TxValueKeyType]interface{}) (
 		return newTxInternalDataEthereumAccessListWithMap(values)
 	case TxTypeEthereumDynamicFee:
 		return newTxInternalDataEthereumDynamicFeeWithMap(values)
+	case TxTypeEthereumSetCode:
+		return newTxInternalDataEthereumSetCodeWithMap(values)
 	}
 
 	return nil, errUndefinedTxType
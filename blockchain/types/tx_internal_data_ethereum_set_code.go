@@ -0,0 +1,664 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/kerrors"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// DelegationPrefix is used by EIP-7702 to designate that an EOA delegates its code to another account.
+var DelegationPrefix = []byte{0xef, 0x01, 0x00}
+
+var (
+	ErrEmptyAuthorizationList = errors.New("set code transaction with empty authorization list")
+	errInvalidAuthorization   = errors.New("invalid authorization signature")
+)
+
+// setCodeAuthorizationMagic is the prefix of the message signed by an authority.
+const setCodeAuthorizationMagic = 0x05
+
+// ParseDelegation tries to parse the address from a delegation designator.
+func ParseDelegation(b []byte) (common.Address, bool) {
+	if len(b) != len(DelegationPrefix)+common.AddressLength || !bytes.HasPrefix(b, DelegationPrefix) {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(b[len(DelegationPrefix):]), true
+}
+
+// AddressToDelegation adds the delegation prefix to the given address to make a delegation designator.
+func AddressToDelegation(addr common.Address) []byte {
+	return append(common.CopyBytes(DelegationPrefix), addr.Bytes()...)
+}
+
+// validateValueTransferRecipient rejects a program account as the recipient of a KAIA
+// value transfer. An EOA with a delegation designator is not rejected, since it is
+// still an EOA which only borrows the code of another account; a transfer to it runs
+// the delegated code as a legacy transaction does.
+func validateValueTransferRecipient(stateDB StateDB, recipient common.Address) error {
+	if stateDB.IsProgramAccount(recipient) {
+		if _, ok := ParseDelegation(stateDB.GetCode(recipient)); !ok {
+			return kerrors.ErrNotForProgramAccount
+		}
+	}
+	return nil
+}
+
+// AuthorizationList is an EIP-7702 authorization list.
+type AuthorizationList []SetCodeAuthorization
+
+// SetCodeAuthorization is an authorization from an account to deploy code at its address.
+// The authority is recovered from the signature, so that only an account whose key is
+// AccountKeyLegacy can be an authority.
+type SetCodeAuthorization struct {
+	ChainID *big.Int
+	Address common.Address
+	Nonce   uint64
+	V       uint8 // signature y parity
+	R       *big.Int
+	S       *big.Int
+}
+
+type setCodeAuthorizationJSON struct {
+	ChainID *hexutil.Big   `json:"chainId"`
+	Address common.Address `json:"address"`
+	Nonce   hexutil.Uint64 `json:"nonce"`
+	V       hexutil.Uint64 `json:"yParity"`
+	R       *hexutil.Big   `json:"r"`
+	S       *hexutil.Big   `json:"s"`
+}
+
+// SignSetCode creates a signed SetCodeAuthorization with the given private key.
+func SignSetCode(prv *ecdsa.PrivateKey, auth SetCodeAuthorization) (SetCodeAuthorization, error) {
+	sighash := auth.SigHash()
+	sig, err := crypto.Sign(sighash[:], prv)
+	if err != nil {
+		return SetCodeAuthorization{}, err
+	}
+	r, s, _ := decodeSignature(sig)
+	return SetCodeAuthorization{
+		ChainID: auth.ChainID,
+		Address: auth.Address,
+		Nonce:   auth.Nonce,
+		V:       sig[crypto.RecoveryIDOffset],
+		R:       r,
+		S:       s,
+	}, nil
+}
+
+// SigHash returns the hash of the authorization to be signed by the authority.
+func (a *SetCodeAuthorization) SigHash() common.Hash {
+	chainID := a.ChainID
+	if chainID == nil {
+		chainID = new(big.Int)
+	}
+	return prefixedRlpHash(setCodeAuthorizationMagic, []interface{}{
+		chainID,
+		a.Address,
+		a.Nonce,
+	})
+}
+
+// Authority recovers the address of the account which signed the authorization.
+func (a *SetCodeAuthorization) Authority() (common.Address, error) {
+	if a.R == nil || a.S == nil || a.V > 1 {
+		return common.Address{}, errInvalidAuthorization
+	}
+	return recoverPlain(a.SigHash(), a.R, a.S, new(big.Int).SetUint64(uint64(a.V)+27), true)
+}
+
+func (a SetCodeAuthorization) MarshalJSON() ([]byte, error) {
+	return json.Marshal(setCodeAuthorizationJSON{
+		(*hexutil.Big)(a.ChainID),
+		a.Address,
+		hexutil.Uint64(a.Nonce),
+		hexutil.Uint64(a.V),
+		(*hexutil.Big)(a.R),
+		(*hexutil.Big)(a.S),
+	})
+}
+
+func (a *SetCodeAuthorization) UnmarshalJSON(input []byte) error {
+	dec := &setCodeAuthorizationJSON{}
+	if err := json.Unmarshal(input, dec); err != nil {
+		return err
+	}
+	if dec.ChainID == nil || dec.R == nil || dec.S == nil {
+		return errors.New("missing required field in authorization")
+	}
+	if uint64(dec.V) > 1 {
+		return errors.New("invalid yParity in authorization")
+	}
+
+	a.ChainID = (*big.Int)(dec.ChainID)
+	a.Address = dec.Address
+	a.Nonce = uint64(dec.Nonce)
+	a.V = uint8(dec.V)
+	a.R = (*big.Int)(dec.R)
+	a.S = (*big.Int)(dec.S)
+	return nil
+}
+
+// TxInternalDataEthereumSetCode is the data of EIP-7702 set code transactions.
+// It has no fee-delegated variant: an EOA with a delegation designator can still send
+// and pay for Kaia fee-delegated transactions with its own account key.
+type TxInternalDataEthereumSetCode struct {
+	ChainID           *big.Int
+	AccountNonce      uint64
+	GasTipCap         *big.Int // a.k.a. maxPriorityFeePerGas
+	GasFeeCap         *big.Int // a.k.a. maxFeePerGas
+	GasLimit          uint64
+	Recipient         common.Address // a set code transaction cannot create a contract
+	Amount            *big.Int
+	Payload           []byte
+	AccessList        AccessList
+	AuthorizationList AuthorizationList
+
+	// Signature values
+	V *big.Int `json:"v" gencodec:"required"`
+	R *big.Int `json:"r" gencodec:"required"`
+	S *big.Int `json:"s" gencodec:"required"`
+
+	// This is only used when marshaling to JSON.
+	Hash *common.Hash `json:"hash" rlp:"-"`
+}
+
+type TxInternalDataEthereumSetCodeJSON struct {
+	Type                 TxType            `json:"typeInt"`
+	TypeStr              string            `json:"type"`
+	ChainID              *hexutil.Big      `json:"chainId"`
+	AccountNonce         hexutil.Uint64    `json:"nonce"`
+	MaxPriorityFeePerGas *hexutil.Big      `json:"maxPriorityFeePerGas"`
+	MaxFeePerGas         *hexutil.Big      `json:"maxFeePerGas"`
+	GasLimit             hexutil.Uint64    `json:"gas"`
+	Recipient            common.Address    `json:"to"`
+	Amount               *hexutil.Big      `json:"value"`
+	Payload              hexutil.Bytes     `json:"input"`
+	AccessList           AccessList        `json:"accessList"`
+	AuthorizationList    AuthorizationList `json:"authorizationList"`
+	TxSignatures         TxSignaturesJSON  `json:"signatures"`
+	Hash                 *common.Hash      `json:"hash"`
+}
+
+func newEmptyTxInternalDataEthereumSetCode() *TxInternalDataEthereumSetCode {
+	return &TxInternalDataEthereumSetCode{}
+}
+
+func newTxInternalDataEthereumSetCode() *TxInternalDataEthereumSetCode {
+	return &TxInternalDataEthereumSetCode{
+		ChainID:           new(big.Int),
+		AccountNonce:      0,
+		GasTipCap:         new(big.Int),
+		GasFeeCap:         new(big.Int),
+		GasLimit:          0,
+		Recipient:         common.Address{},
+		Amount:            new(big.Int),
+		Payload:           []byte{},
+		AccessList:        AccessList{},
+		AuthorizationList: AuthorizationList{},
+		V:                 new(big.Int),
+		R:                 new(big.Int),
+		S:                 new(big.Int),
+	}
+}
+
+func newTxInternalDataEthereumSetCodeWithValues(nonce uint64, to common.Address, amount *big.Int, gasLimit uint64, gasTipCap *big.Int, gasFeeCap *big.Int, data []byte, accessList AccessList, authList AuthorizationList, chainID *big.Int) *TxInternalDataEthereumSetCode {
+	d := newTxInternalDataEthereumSetCode()
+
+	d.AccountNonce = nonce
+	d.Recipient = to
+	d.GasLimit = gasLimit
+
+	if chainID != nil {
+		d.ChainID.Set(chainID)
+	}
+
+	if gasTipCap != nil {
+		d.GasTipCap.Set(gasTipCap)
+	}
+
+	if gasFeeCap != nil {
+		d.GasFeeCap.Set(gasFeeCap)
+	}
+
+	if amount != nil {
+		d.Amount.Set(amount)
+	}
+
+	if len(data) > 0 {
+		d.Payload = common.CopyBytes(data)
+	}
+
+	if accessList != nil {
+		d.AccessList = make(AccessList, len(accessList))
+		copy(d.AccessList, accessList)
+	}
+
+	if authList != nil {
+		d.AuthorizationList = make(AuthorizationList, len(authList))
+		copy(d.AuthorizationList, authList)
+	}
+
+	return d
+}
+
+func newTxInternalDataEthereumSetCodeWithMap(values map[TxValueKeyType]interface{}) (*TxInternalDataEthereumSetCode, error) {
+	d := newTxInternalDataEthereumSetCode()
+
+	if v, ok := values[TxValueKeyChainID].(*big.Int); ok {
+		d.ChainID.Set(v)
+		delete(values, TxValueKeyChainID)
+	} else {
+		return nil, errValueKeyChainIDInvalid
+	}
+
+	if v, ok := values[TxValueKeyNonce].(uint64); ok {
+		d.AccountNonce = v
+		delete(values, TxValueKeyNonce)
+	} else {
+		return nil, errValueKeyNonceMustUint64
+	}
+
+	if v, ok := values[TxValueKeyTo].(common.Address); ok {
+		d.Recipient = v
+		delete(values, TxValueKeyTo)
+	} else {
+		return nil, errValueKeyToMustAddress
+	}
+
+	if v, ok := values[TxValueKeyAmount].(*big.Int); ok {
+		d.Amount.Set(v)
+		delete(values, TxValueKeyAmount)
+	} else {
+		return nil, errValueKeyAmountMustBigInt
+	}
+
+	if v, ok := values[TxValueKeyData].([]byte); ok {
+		d.Payload = common.CopyBytes(v)
+		delete(values, TxValueKeyData)
+	} else {
+		return nil, errValueKeyDataMustByteSlice
+	}
+
+	if v, ok := values[TxValueKeyGasLimit].(uint64); ok {
+		d.GasLimit = v
+		delete(values, TxValueKeyGasLimit)
+	} else {
+		return nil, errValueKeyGasLimitMustUint64
+	}
+
+	if v, ok := values[TxValueKeyGasFeeCap].(*big.Int); ok {
+		d.GasFeeCap.Set(v)
+		delete(values, TxValueKeyGasFeeCap)
+	} else {
+		return nil, errValueKeyGasFeeCapMustBigInt
+	}
+	if v, ok := values[TxValueKeyGasTipCap].(*big.Int); ok {
+		d.GasTipCap.Set(v)
+		delete(values, TxValueKeyGasTipCap)
+	} else {
+		return nil, errValueKeyGasTipCapMustBigInt
+	}
+	if v, ok := values[TxValueKeyAccessList].(AccessList); ok {
+		d.AccessList = make(AccessList, len(v))
+		copy(d.AccessList, v)
+		delete(values, TxValueKeyAccessList)
+	} else {
+		return nil, errValueKeyAccessListInvalid
+	}
+	if v, ok := values[TxValueKeyAuthorizationList].(AuthorizationList); ok {
+		d.AuthorizationList = make(AuthorizationList, len(v))
+		copy(d.AuthorizationList, v)
+		delete(values, TxValueKeyAuthorizationList)
+	} else {
+		return nil, errValueKeyAuthorizationListInvalid
+	}
+
+	if len(values) != 0 {
+		for k := range values {
+			logger.Warn("unnecessary key", k.String())
+		}
+		return nil, errUndefinedKeyRemains
+	}
+
+	return d, nil
+}
+
+func (t *TxInternalDataEthereumSetCode) Type() TxType {
+	return TxTypeEthereumSetCode
+}
+
+func (t *TxInternalDataEthereumSetCode) GetRoleTypeForValidation() accountkey.RoleType {
+	return accountkey.RoleTransaction
+}
+
+func (t *TxInternalDataEthereumSetCode) GetAccountNonce() uint64 {
+	return t.AccountNonce
+}
+
+func (t *TxInternalDataEthereumSetCode) GetPrice() *big.Int {
+	return t.GasFeeCap
+}
+
+func (t *TxInternalDataEthereumSetCode) GetGasLimit() uint64 {
+	return t.GasLimit
+}
+
+func (t *TxInternalDataEthereumSetCode) GetRecipient() *common.Address {
+	to := t.Recipient
+	return &to
+}
+
+func (t *TxInternalDataEthereumSetCode) GetAmount() *big.Int {
+	return new(big.Int).Set(t.Amount)
+}
+
+func (t *TxInternalDataEthereumSetCode) GetHash() *common.Hash {
+	return t.Hash
+}
+
+func (t *TxInternalDataEthereumSetCode) GetPayload() []byte {
+	return t.Payload
+}
+
+func (t *TxInternalDataEthereumSetCode) GetAccessList() AccessList {
+	return t.AccessList
+}
+
+func (t *TxInternalDataEthereumSetCode) GetAuthorizationList() AuthorizationList {
+	return t.AuthorizationList
+}
+
+func (t *TxInternalDataEthereumSetCode) GetGasTipCap() *big.Int {
+	return t.GasTipCap
+}
+
+func (t *TxInternalDataEthereumSetCode) GetGasFeeCap() *big.Int {
+	return t.GasFeeCap
+}
+
+func (t *TxInternalDataEthereumSetCode) SetHash(hash *common.Hash) {
+	t.Hash = hash
+}
+
+func (t *TxInternalDataEthereumSetCode) SetSignature(signatures TxSignatures) {
+	if len(signatures) != 1 {
+		logger.Crit("TxTypeEthereumSetCode can receive only single signature!")
+	}
+
+	t.V = signatures[0].V
+	t.R = signatures[0].R
+	t.S = signatures[0].S
+}
+
+func (t *TxInternalDataEthereumSetCode) RawSignatureValues() TxSignatures {
+	return TxSignatures{&TxSignature{t.V, t.R, t.S}}
+}
+
+func (t *TxInternalDataEthereumSetCode) ValidateSignature() bool {
+	v := byte(t.V.Uint64())
+	return crypto.ValidateSignatureValues(v, t.R, t.S, false)
+}
+
+func (t *TxInternalDataEthereumSetCode) RecoverAddress(txhash common.Hash, homestead bool, vfunc func(*big.Int) *big.Int) (common.Address, error) {
+	V := vfunc(t.V)
+	return recoverPlain(txhash, t.R, t.S, V, homestead)
+}
+
+func (t *TxInternalDataEthereumSetCode) RecoverPubkey(txhash common.Hash, homestead bool, vfunc func(*big.Int) *big.Int) ([]*ecdsa.PublicKey, error) {
+	V := vfunc(t.V)
+
+	pk, err := recoverPlainPubkey(txhash, t.R, t.S, V, homestead)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*ecdsa.PublicKey{pk}, nil
+}
+
+func (t *TxInternalDataEthereumSetCode) IntrinsicGas(currentBlockNumber uint64) (uint64, error) {
+	gas, err := IntrinsicGas(t.Payload, t.AccessList, false, *fork.Rules(big.NewInt(int64(currentBlockNumber))))
+	if err != nil {
+		return 0, err
+	}
+	// Each authorization is charged as if it creates a new account.
+	// The difference is refunded during execution if the authority already exists.
+	authGas := uint64(len(t.AuthorizationList)) * params.CallNewAccountGas
+	if gas+authGas < gas {
+		return 0, ErrGasUintOverflow
+	}
+	return gas + authGas, nil
+}
+
+func (t *TxInternalDataEthereumSetCode) ChainId() *big.Int {
+	return t.ChainID
+}
+
+func (t *TxInternalDataEthereumSetCode) Equal(a TxInternalData) bool {
+	ta, ok := a.(*TxInternalDataEthereumSetCode)
+	if !ok {
+		return false
+	}
+
+	return t.ChainID.Cmp(ta.ChainID) == 0 &&
+		t.AccountNonce == ta.AccountNonce &&
+		t.GasFeeCap.Cmp(ta.GasFeeCap) == 0 &&
+		t.GasTipCap.Cmp(ta.GasTipCap) == 0 &&
+		t.GasLimit == ta.GasLimit &&
+		t.Recipient == ta.Recipient &&
+		t.Amount.Cmp(ta.Amount) == 0 &&
+		reflect.DeepEqual(t.AccessList, ta.AccessList) &&
+		reflect.DeepEqual(t.AuthorizationList, ta.AuthorizationList) &&
+		t.V.Cmp(ta.V) == 0 &&
+		t.R.Cmp(ta.R) == 0 &&
+		t.S.Cmp(ta.S) == 0
+}
+
+func (t *TxInternalDataEthereumSetCode) String() string {
+	var from string
+	tx := &Transaction{data: t}
+
+	v, r, s := t.V, t.R, t.S
+	if v != nil {
+		signer := LatestSignerForChainID(t.ChainId())
+		if f, err := Sender(signer, tx); err != nil { // derive but don't cache
+			from = "[invalid sender: invalid sig]"
+		} else {
+			from = fmt.Sprintf("%x", f[:])
+		}
+	} else {
+		from = "[invalid sender: nil V field]"
+	}
+
+	enc, _ := rlp.EncodeToBytes(tx)
+	return fmt.Sprintf(`
+		TX(%x)
+		Chaind:   %#x
+		From:     %s
+		To:       %x
+		Nonce:    %v
+		GasTipCap: %#x
+		GasFeeCap: %#x
+		GasLimit  %#x
+		Value:    %#x
+		Data:     0x%x
+		AccessList: %x
+		AuthorizationList: %v
+		V:        %#x
+		R:        %#x
+		S:        %#x
+		Hex:      %x
+	`,
+		tx.Hash(),
+		t.ChainId(),
+		from,
+		t.Recipient.Bytes(),
+		t.GetAccountNonce(),
+		t.GetGasTipCap(),
+		t.GetGasFeeCap(),
+		t.GetGasLimit(),
+		t.GetAmount(),
+		t.GetPayload(),
+		t.AccessList,
+		t.AuthorizationList,
+		v,
+		r,
+		s,
+		enc,
+	)
+}
+
+func (t *TxInternalDataEthereumSetCode) SerializeForSign() []interface{} {
+	// If the chainId has nil or empty value, It will be set signer's chainId.
+	return []interface{}{
+		t.ChainID,
+		t.AccountNonce,
+		t.GasTipCap,
+		t.GasFeeCap,
+		t.GasLimit,
+		t.Recipient,
+		t.Amount,
+		t.Payload,
+		t.AccessList,
+		t.AuthorizationList,
+	}
+}
+
+func (t *TxInternalDataEthereumSetCode) TxHash() common.Hash {
+	return prefixedRlpHash(byte(t.Type()), []interface{}{
+		t.ChainID,
+		t.AccountNonce,
+		t.GasTipCap,
+		t.GasFeeCap,
+		t.GasLimit,
+		t.Recipient,
+		t.Amount,
+		t.Payload,
+		t.AccessList,
+		t.AuthorizationList,
+		t.V,
+		t.R,
+		t.S,
+	})
+}
+
+func (t *TxInternalDataEthereumSetCode) SenderTxHash() common.Hash {
+	return t.TxHash()
+}
+
+func (t *TxInternalDataEthereumSetCode) Validate(stateDB StateDB, currentBlockNumber uint64) error {
+	if common.IsPrecompiledContractAddress(t.Recipient) {
+		return kerrors.ErrPrecompiledContractAddress
+	}
+	if len(t.AuthorizationList) == 0 {
+		return ErrEmptyAuthorizationList
+	}
+	return t.ValidateMutableValue(stateDB, currentBlockNumber)
+}
+
+func (t *TxInternalDataEthereumSetCode) ValidateMutableValue(stateDB StateDB, currentBlockNumber uint64) error {
+	return nil
+}
+
+func (t *TxInternalDataEthereumSetCode) IsLegacyTransaction() bool {
+	return false
+}
+
+func (t *TxInternalDataEthereumSetCode) Execute(sender ContractRef, vm VM, stateDB StateDB, currentBlockNumber uint64, gas uint64, value *big.Int) (ret []byte, usedGas uint64, err error) {
+	// Sender's nonce has been increased in the state transition before the authorizations are
+	// applied, since an authorization signed by the sender itself must see the increased nonce.
+	return vm.Call(sender, t.Recipient, t.Payload, gas, value)
+}
+
+func (t *TxInternalDataEthereumSetCode) MakeRPCOutput() map[string]interface{} {
+	return map[string]interface{}{
+		"typeInt":              t.Type(),
+		"type":                 t.Type().String(),
+		"chainId":              (*hexutil.Big)(t.ChainId()),
+		"nonce":                hexutil.Uint64(t.AccountNonce),
+		"maxPriorityFeePerGas": (*hexutil.Big)(t.GasTipCap),
+		"maxFeePerGas":         (*hexutil.Big)(t.GasFeeCap),
+		"gas":                  hexutil.Uint64(t.GasLimit),
+		"to":                   t.Recipient,
+		"input":                hexutil.Bytes(t.Payload),
+		"value":                (*hexutil.Big)(t.Amount),
+		"accessList":           t.AccessList,
+		"authorizationList":    t.AuthorizationList,
+		"signatures":           TxSignaturesJSON{&TxSignatureJSON{(*hexutil.Big)(t.V), (*hexutil.Big)(t.R), (*hexutil.Big)(t.S)}},
+	}
+}
+
+func (t *TxInternalDataEthereumSetCode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(TxInternalDataEthereumSetCodeJSON{
+		t.Type(),
+		t.Type().String(),
+		(*hexutil.Big)(t.ChainID),
+		(hexutil.Uint64)(t.AccountNonce),
+		(*hexutil.Big)(t.GasTipCap),
+		(*hexutil.Big)(t.GasFeeCap),
+		(hexutil.Uint64)(t.GasLimit),
+		t.Recipient,
+		(*hexutil.Big)(t.Amount),
+		t.Payload,
+		t.AccessList,
+		t.AuthorizationList,
+		TxSignaturesJSON{&TxSignatureJSON{(*hexutil.Big)(t.V), (*hexutil.Big)(t.R), (*hexutil.Big)(t.S)}},
+		t.Hash,
+	})
+}
+
+func (t *TxInternalDataEthereumSetCode) UnmarshalJSON(bytes []byte) error {
+	js := &TxInternalDataEthereumSetCodeJSON{}
+	if err := json.Unmarshal(bytes, js); err != nil {
+		return err
+	}
+
+	t.ChainID = (*big.Int)(js.ChainID)
+	t.AccountNonce = uint64(js.AccountNonce)
+	t.GasTipCap = (*big.Int)(js.MaxPriorityFeePerGas)
+	t.GasFeeCap = (*big.Int)(js.MaxFeePerGas)
+	t.GasLimit = uint64(js.GasLimit)
+	t.Recipient = js.Recipient
+	t.Amount = (*big.Int)(js.Amount)
+	t.Payload = js.Payload
+	t.AccessList = js.AccessList
+	t.AuthorizationList = js.AuthorizationList
+	t.V = (*big.Int)(js.TxSignatures[0].V)
+	t.R = (*big.Int)(js.TxSignatures[0].R)
+	t.S = (*big.Int)(js.TxSignatures[0].S)
+	t.Hash = js.Hash
+
+	return nil
+}
+
+func (t *TxInternalDataEthereumSetCode) setSignatureValues(chainID, v, r, s *big.Int) {
+	t.ChainID, t.V, t.R, t.S = chainID, v, r, s
+}
@@ -350,10 +350,7 @@ func (t *TxInternalDataFeeDelegatedValueTransferMemo) Validate(stateDB StateDB,
 }
 
 func (t *TxInternalDataFeeDelegatedValueTransferMemo) ValidateMutableValue(stateDB StateDB, currentBlockNumber uint64) error {
-	if stateDB.IsProgramAccount(t.Recipient) {
-		return kerrors.ErrNotForProgramAccount
-	}
-	return nil
+	return validateValueTransferRecipient(stateDB, t.Recipient)
 }
 
 func (t *TxInternalDataFeeDelegatedValueTransferMemo) Execute(sender ContractRef, vm VM, stateDB StateDB, currentBlockNumber uint64, gas uint64, value *big.Int) (ret []byte, usedGas uint64, err error) {
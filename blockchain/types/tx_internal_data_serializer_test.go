@@ -75,6 +75,7 @@ func TestTransactionSerialization(t *testing.T) {
 		{"FeeDelegatedCancelWithRatio", genFeeDelegatedCancelWithRatioTransaction()},
 		{"AccessList", genAccessListTransaction()},
 		{"DynamicFee", genDynamicFeeTransaction()},
+		{"SetCode", genSetCodeTransaction()},
 	}
 
 	testcases := []struct {
@@ -288,6 +289,35 @@ func genDynamicFeeTransaction() TxInternalData {
 	return tx
 }
 
+func genSetCodeTransaction() TxInternalData {
+	auth, err := SignSetCode(key, SetCodeAuthorization{
+		ChainID: big.NewInt(2),
+		Address: to,
+		Nonce:   nonce,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	tx, err := NewTxInternalDataWithMap(TxTypeEthereumSetCode, map[TxValueKeyType]interface{}{
+		TxValueKeyNonce:             nonce,
+		TxValueKeyTo:                to,
+		TxValueKeyAmount:            amount,
+		TxValueKeyGasLimit:          gasLimit,
+		TxValueKeyGasFeeCap:         gasFeeCap,
+		TxValueKeyGasTipCap:         gasTipCap,
+		TxValueKeyData:              []byte("1234"),
+		TxValueKeyAccessList:        accesses,
+		TxValueKeyAuthorizationList: AuthorizationList{auth},
+		TxValueKeyChainID:           big.NewInt(2),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return tx
+}
+
 func genValueTransferTransaction() TxInternalData {
 	d, err := NewTxInternalDataWithMap(TxTypeValueTransfer, map[TxValueKeyType]interface{}{
 		TxValueKeyNonce:    nonce,
@@ -55,6 +55,8 @@ func EnableEIP(eipNum int, jt *JumpTable) error {
 		enable1344(jt)
 	case 1153:
 		enable1153(jt)
+	case 7702:
+		enable7702(jt)
 	default:
 		return fmt.Errorf("undefined eip %d", eipNum)
 	}
@@ -360,6 +362,15 @@ func enable1052(jt *JumpTable) {
 	}
 }
 
+// enable7702 applies EIP-7702 (set EOA account code) to the gas calculation
+// of the call variants, charging the access to the delegation target.
+func enable7702(jt *JumpTable) {
+	jt[CALL].dynamicGas = gasCallEIP7702
+	jt[CALLCODE].dynamicGas = gasCallCodeEIP7702
+	jt[STATICCALL].dynamicGas = gasStaticCallEIP7702
+	jt[DELEGATECALL].dynamicGas = gasDelegateCallEIP7702
+}
+
 // As the cpu performance has been improved a lot, and as the storage size has increased a lot
 // recalculated the computation cost of some opcodes
 func enableCancunComputationCostModification(jt *JumpTable) {
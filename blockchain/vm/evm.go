@@ -62,6 +62,34 @@ func isProgramAccount(evm *EVM, caller common.Address, addr common.Address, db S
 	return exists || db.IsProgramAccount(addr)
 }
 
+// resolveCode returns the code associated with the provided account. After Prague,
+// it can also resolve code pointed to by a delegation designator.
+func (evm *EVM) resolveCode(addr common.Address) []byte {
+	code := evm.StateDB.GetCode(addr)
+	if !evm.chainRules.IsPrague {
+		return code
+	}
+	if target, ok := types.ParseDelegation(code); ok {
+		// Note we only follow one level of delegation.
+		return evm.StateDB.GetCode(target)
+	}
+	return code
+}
+
+// resolveCodeHash returns the code hash associated with the provided address.
+// After Prague, it can also resolve code hash of the account pointed to by a
+// delegation designator.
+func (evm *EVM) resolveCodeHash(addr common.Address) common.Hash {
+	if evm.chainRules.IsPrague {
+		code := evm.StateDB.GetCode(addr)
+		if target, ok := types.ParseDelegation(code); ok {
+			// Note we only follow one level of delegation.
+			return evm.StateDB.GetCodeHash(target)
+		}
+	}
+	return evm.StateDB.GetCodeHash(addr)
+}
+
 // run runs the given contract and takes care of running precompiles with a fallback to the byte code interpreter.
 func run(evm *EVM, contract *Contract, input []byte) ([]byte, error) {
 	if contract.CodeAddr != nil {
@@ -303,7 +331,7 @@ func (evm *EVM) Call(caller types.ContractRef, addr common.Address, input []byte
 		// Initialise a new contract and set the code that is to be used by the EVM.
 		// The contract is a scoped environment for this execution context only.
 		contract := NewContract(caller, to, value, gas)
-		contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
+		contract.SetCallCode(&addr, evm.resolveCodeHash(addr), evm.resolveCode(addr))
 		ret, err = run(evm, contract, input)
 		gas = contract.Gas
 	}
@@ -365,7 +393,7 @@ func (evm *EVM) CallCode(caller types.ContractRef, addr common.Address, input []
 	// Initialise a new contract and set the code that is to be used by the EVM.
 	// The contract is a scoped environment for this execution context only.
 	contract := NewContract(caller, to, value, gas)
-	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
+	contract.SetCallCode(&addr, evm.resolveCodeHash(addr), evm.resolveCode(addr))
 
 	ret, err = run(evm, contract, input)
 	if err != nil {
@@ -415,7 +443,7 @@ func (evm *EVM) DelegateCall(caller types.ContractRef, addr common.Address, inpu
 
 	// Initialise a new contract and make initialise the delegate values
 	contract := NewContract(caller, to, nil, gas).AsDelegate()
-	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
+	contract.SetCallCode(&addr, evm.resolveCodeHash(addr), evm.resolveCode(addr))
 
 	ret, err = run(evm, contract, input)
 	if err != nil {
@@ -468,7 +496,7 @@ func (evm *EVM) StaticCall(caller types.ContractRef, addr common.Address, input
 	// Initialise a new contract and set the code that is to be used by the EVM.
 	// The contract is a scoped environment for this execution context only.
 	contract := NewContract(caller, to, new(big.Int), gas)
-	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
+	contract.SetCallCode(&addr, evm.resolveCodeHash(addr), evm.resolveCode(addr))
 
 	// When an error was returned by the EVM or when setting the creation code
 	// above we revert to the snapshot and consume any gas remaining. Additionally
@@ -653,6 +681,10 @@ func (evm *EVM) GetPrecompiledContractMap(addr common.Address) map[common.Addres
 	}
 
 	switch {
+	case evm.chainRules.IsOsaka:
+		return PrecompiledContractsOsaka
+	case evm.chainRules.IsPrague:
+		return PrecompiledContractsPrague
 	case evm.chainRules.IsCancun:
 		return PrecompiledContractsCancun
 	case evm.chainRules.IsKore:
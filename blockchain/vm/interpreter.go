@@ -102,6 +102,8 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 	if cfg.JumpTable[STOP] == nil {
 		var jt JumpTable
 		switch {
+		case evm.chainRules.IsPrague:
+			jt = PragueInstructionSet
 		case evm.chainRules.IsCancun:
 			jt = CancunInstructionSet
 		case evm.chainRules.IsShanghai:
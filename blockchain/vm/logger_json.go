@@ -39,6 +39,9 @@ type JSONLogger struct {
 // NewJSONLogger creates a new EVM tracer that prints execution steps as JSON objects
 // into the provided stream.
 func NewJSONLogger(cfg *LogConfig, writer io.Writer) *JSONLogger {
+	if cfg == nil {
+		cfg = &LogConfig{}
+	}
 	return &JSONLogger{json.NewEncoder(writer), cfg}
 }
 
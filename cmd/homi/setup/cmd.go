@@ -131,6 +131,8 @@ var HomiFlags = []cli.Flag{
 	altsrc.NewInt64Flag(shanghaiCompatibleBlockNumberFlag),
 	altsrc.NewInt64Flag(cancunCompatibleBlockNumberFlag),
 	altsrc.NewInt64Flag(kaiaCompatibleBlockNumberFlag),
+	altsrc.NewInt64Flag(pragueCompatibleBlockNumberFlag),
+	altsrc.NewInt64Flag(osakaCompatibleBlockNumberFlag),
 	altsrc.NewInt64Flag(kip103CompatibleBlockNumberFlag),
 	altsrc.NewStringFlag(kip103ContractAddressFlag),
 	altsrc.NewInt64Flag(kip160CompatibleBlockNumberFlag),
@@ -780,6 +782,8 @@ func Gen(ctx *cli.Context) error {
 	genesisJson.Config.ShanghaiCompatibleBlock = big.NewInt(ctx.Int64(shanghaiCompatibleBlockNumberFlag.Name))
 	genesisJson.Config.CancunCompatibleBlock = big.NewInt(ctx.Int64(cancunCompatibleBlockNumberFlag.Name))
 	genesisJson.Config.KaiaCompatibleBlock = big.NewInt(ctx.Int64(kaiaCompatibleBlockNumberFlag.Name))
+	genesisJson.Config.PragueCompatibleBlock = big.NewInt(ctx.Int64(pragueCompatibleBlockNumberFlag.Name))
+	genesisJson.Config.OsakaCompatibleBlock = big.NewInt(ctx.Int64(osakaCompatibleBlockNumberFlag.Name))
 
 	// KIP103 hardfork is optional
 	genesisJson.Config.Kip103CompatibleBlock = big.NewInt(ctx.Int64(kip103CompatibleBlockNumberFlag.Name))
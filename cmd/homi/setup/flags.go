@@ -519,6 +519,20 @@ var (
 		Aliases: []string{"genesis.hardfork.kaia-compatible-blocknumber"},
 	}
 
+	pragueCompatibleBlockNumberFlag = &cli.Int64Flag{
+		Name:    "prague-compatible-blocknumber",
+		Usage:   "pragueCompatible blockNumber",
+		Value:   0,
+		Aliases: []string{"genesis.hardfork.prague-compatible-blocknumber"},
+	}
+
+	osakaCompatibleBlockNumberFlag = &cli.Int64Flag{
+		Name:    "osaka-compatible-blocknumber",
+		Usage:   "osakaCompatible blockNumber",
+		Value:   0,
+		Aliases: []string{"genesis.hardfork.osaka-compatible-blocknumber"},
+	}
+
 	// KIP103 hardfork is optional
 	kip103CompatibleBlockNumberFlag = &cli.Int64Flag{
 		Name:    "kip103-compatible-blocknumber",
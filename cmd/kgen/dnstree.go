@@ -0,0 +1,147 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/networks/p2p/discover"
+	"github.com/klaytn/klaytn/networks/p2p/dnsdisc"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	dnsNodesFlag = &cli.StringFlag{
+		Name:     "nodes",
+		Usage:    "File containing the kni URLs of the nodes, one per line",
+		Required: true,
+	}
+	dnsKeyFlag = &cli.StringFlag{
+		Name:     "key",
+		Usage:    "Nodekey file of the key signing the tree",
+		Required: true,
+	}
+	dnsDomainFlag = &cli.StringFlag{
+		Name:     "domain",
+		Usage:    "Domain name where the tree is published",
+		Required: true,
+	}
+	dnsSeqFlag = &cli.UintFlag{
+		Name:  "seq",
+		Usage: "Sequence number of the tree (default: current unix time)",
+	}
+	dnsLinkFlag = &cli.StringSliceFlag{
+		Name:  "link",
+		Usage: "URL of another tree to link from the tree (kntree://<key>@<domain>)",
+	}
+	dnsOutputFlag = &cli.StringFlag{
+		Name:  "output",
+		Usage: "File to write the TXT records as JSON (default: stdout)",
+	}
+
+	dnsTreeCommand = &cli.Command{
+		Name:      "dnstree",
+		Usage:     "Build and sign a DNS discovery tree of the given nodes",
+		ArgsUsage: " ",
+		Action:    dnsTree,
+		Flags: []cli.Flag{
+			dnsNodesFlag,
+			dnsKeyFlag,
+			dnsDomainFlag,
+			dnsSeqFlag,
+			dnsLinkFlag,
+			dnsOutputFlag,
+		},
+		Description: `
+Builds a merkle tree of the node URLs in the --nodes file, signs it with the
+--key nodekey, and outputs the TXT records to publish under --domain as a JSON
+object mapping record names to record contents. Nodes pick up the tree with
+--discovery.dns using the printed tree URL.`,
+	}
+)
+
+// dnsTree builds and signs a DNS discovery tree, and writes its TXT records.
+func dnsTree(ctx *cli.Context) error {
+	nodes, err := loadNodeList(ctx.String(dnsNodesFlag.Name))
+	if err != nil {
+		return err
+	}
+	key, err := crypto.LoadECDSA(ctx.String(dnsKeyFlag.Name))
+	if err != nil {
+		return fmt.Errorf("failed to load nodekey: %v", err)
+	}
+	seq := ctx.Uint(dnsSeqFlag.Name)
+	if seq == 0 {
+		seq = uint(time.Now().Unix())
+	}
+
+	tree, err := dnsdisc.MakeTree(seq, nodes, ctx.StringSlice(dnsLinkFlag.Name))
+	if err != nil {
+		return err
+	}
+	domain := ctx.String(dnsDomainFlag.Name)
+	url, err := tree.Sign(key, domain)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(tree.ToTXT(domain), "", "\t")
+	if err != nil {
+		return err
+	}
+	if path := ctx.String(dnsOutputFlag.Name); path != "" {
+		if err := os.WriteFile(path, append(out, '\n'), 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "Created : ", path)
+	} else {
+		fmt.Println(string(out))
+	}
+	fmt.Fprintf(os.Stderr, "Tree URL (seq %d, %d nodes): %s\n", seq, len(nodes), url)
+	return nil
+}
+
+// loadNodeList reads kni URLs from the file. Empty lines and lines starting
+// with '#' are skipped.
+func loadNodeList(path string) ([]*discover.Node, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []*discover.Node
+	for i, line := range strings.Split(string(text), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		n, err := discover.ParseNode(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, i+1, err)
+		}
+		nodes = append(nodes, n)
+	}
+	if len(nodes) == 0 {
+		return nil, errors.New("no nodes in " + path)
+	}
+	return nodes, nil
+}
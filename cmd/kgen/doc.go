@@ -27,5 +27,13 @@ All available options are as follows.
 	--ip value    Specify an IP address (default: "0.0.0.0")
 	--port value  Specify a tcp port number (default: 32323)
 	--help, -h    Show help
+
+# Commands
+
+	dnstree    Build and sign a DNS discovery tree of the given nodes
+
+`kgen dnstree --nodes nodes.txt --key nodekey --domain nodes.example.org` prints
+the TXT records of the tree as JSON, and the tree URL to use with
+--discovery.dns.
 */
 package main
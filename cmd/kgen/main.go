@@ -82,6 +82,7 @@ func main() {
 	}
 	app.Commands = []*cli.Command{
 		nodecmd.VersionCommand,
+		dnsTreeCommand,
 	}
 	app.HideVersion = true
 	// app.CustomAppHelpTemplate = kgenHelper
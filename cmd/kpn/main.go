@@ -73,6 +73,12 @@ func init() {
 
 		// See utils/nodecmd/snapshot.go:
 		nodecmd.SnapshotCommand,
+
+		// See utils/nodecmd/dbcmd.go:
+		nodecmd.DBCommand,
+		nodecmd.ExportHistoryCommand,
+		nodecmd.ImportHistoryCommand,
+		nodecmd.VerifyHistoryCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -192,6 +192,24 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	}
 
 	cfg.NoDiscovery = ctx.Bool(NoDiscoverFlag.Name)
+	cfg.DiscoveryV5 = ctx.Bool(DiscoveryV5Flag.Name)
+	if urls := ctx.String(DNSDiscoveryFlag.Name); urls != "" {
+		cfg.DNSDiscovery = SplitAndTrim(urls)
+	}
+	if limits := ctx.String(IngressLimitFlag.Name); limits != "" {
+		parsed, err := p2p.ParseBandwidthLimits(limits)
+		if err != nil {
+			log.Fatalf("Option %q: %v", IngressLimitFlag.Name, err)
+		}
+		cfg.IngressLimits = parsed
+	}
+	if limits := ctx.String(EgressLimitFlag.Name); limits != "" {
+		parsed, err := p2p.ParseBandwidthLimits(limits)
+		if err != nil {
+			log.Fatalf("Option %q: %v", EgressLimitFlag.Name, err)
+		}
+		cfg.EgressLimits = parsed
+	}
 
 	cfg.RWTimerConfig = p2p.RWTimerConfig{}
 	cfg.RWTimerConfig.Interval = ctx.Uint64(RWTimerIntervalFlag.Name)
@@ -562,6 +580,8 @@ func (kCfg *KaiaConfig) SetKaiaConfig(ctx *cli.Context, stack *node.Node) {
 	cfg.LevelDBBufferPool = !ctx.Bool(LevelDBNoBufferPoolFlag.Name)
 	cfg.EnableDBPerfMetrics = !ctx.Bool(DBNoPerformanceMetricsFlag.Name)
 	cfg.LevelDBCacheSize = ctx.Int(LevelDBCacheSizeFlag.Name)
+	cfg.AncientThreshold = ctx.Uint64(AncientThresholdFlag.Name)
+	cfg.HistoryRetention = ctx.Uint64(HistoryRetentionFlag.Name)
 
 	cfg.RocksDBConfig.Secondary = ctx.Bool(RocksDBSecondaryFlag.Name)
 	cfg.RocksDBConfig.MaxOpenFiles = ctx.Int(RocksDBMaxOpenFilesFlag.Name)
@@ -572,6 +592,24 @@ func (kCfg *KaiaConfig) SetKaiaConfig(ctx *cli.Context, stack *node.Node) {
 		cfg.RocksDBConfig.MaxOpenFiles = -1
 		logger.Info("Secondary rocksdb is enabled, disabling fetcher, downloader, worker. MaxOpenFiles is forced to unlimited")
 	}
+	cfg.ReadOnly = ctx.Bool(ReadOnlyFlag.Name)
+	if cfg.ReadOnly {
+		if cfg.DBType != database.LevelDB && cfg.DBType != database.RocksDB && cfg.DBType != database.DynamoDB {
+			log.Fatalf("--%s is not supported by %v", ReadOnlyFlag.Name, cfg.DBType)
+		}
+		cfg.FetcherDisable = true
+		cfg.DownloaderDisable = true
+		cfg.WorkerDisable = true
+		cfg.AncientThreshold = 0
+		cfg.HistoryRetention = 0
+		if cfg.DBType == database.RocksDB {
+			cfg.RocksDBConfig.MaxOpenFiles = -1
+		}
+		logger.Info("Read-only mode is enabled, disabling fetcher, downloader, worker and history pruning")
+	}
+	if err := checkHistoryRetention(cfg.HistoryRetention, cfg.AncientThreshold, stack.ResolvePath("chaindata")); err != nil {
+		log.Fatalf("%v", err)
+	}
 	cfg.RocksDBConfig.CacheSize = ctx.Uint64(RocksDBCacheSizeFlag.Name)
 	cfg.RocksDBConfig.DumpMallocStat = ctx.Bool(RocksDBDumpMallocStatFlag.Name)
 	cfg.RocksDBConfig.CompressionType = ctx.String(RocksDBCompressionTypeFlag.Name)
@@ -639,6 +677,8 @@ func (kCfg *KaiaConfig) SetKaiaConfig(ctx *cli.Context, stack *node.Node) {
 		LocalCacheSizeMiB:         ctx.Int(TrieNodeCacheLimitFlag.Name),
 		FastCacheFileDir:          ctx.String(DataDirFlag.Name) + "/fastcache",
 		FastCacheSavePeriod:       ctx.Duration(TrieNodeCacheSavePeriodFlag.Name),
+		DiskCacheDir:              ctx.String(DataDirFlag.Name) + "/diskcache",
+		DiskCacheSizeMiB:          ctx.Int(TrieNodeCacheDiskSizeFlag.Name),
 		RedisEndpoints:            ctx.StringSlice(TrieNodeCacheRedisEndpointsFlag.Name),
 		RedisClusterEnable:        ctx.Bool(TrieNodeCacheRedisClusterFlag.Name),
 		RedisPublishBlockEnable:   ctx.Bool(TrieNodeCacheRedisPublishBlockFlag.Name),
@@ -875,6 +915,26 @@ func setTxResendConfig(ctx *cli.Context, cfg *cn.Config) {
 	logger.Debug("TxResend config", "Interval", cfg.TxResendInterval, "TxResendCount", cfg.TxResendCount, "UseLegacy", cfg.TxResendUseLegacy)
 }
 
+// checkHistoryRetention checks that the history retention can be applied to
+// the database in chainDataDir. The blocks frozen in the ancient store are not
+// pruned, so the retention is not allowed along with an ancient store, even
+// one created by an earlier run.
+func checkHistoryRetention(retention, ancientThreshold uint64, chainDataDir string) error {
+	if retention == 0 {
+		return nil
+	}
+	if ancientThreshold != 0 {
+		return fmt.Errorf("--%s cannot be used with --%s", HistoryRetentionFlag.Name, AncientThresholdFlag.Name)
+	}
+	if retention < blockchain.DefaultTriesInMemory {
+		return fmt.Errorf("--%s should be at least %d", HistoryRetentionFlag.Name, blockchain.DefaultTriesInMemory)
+	}
+	if chainDataDir != "" && database.AncientStoreExists(chainDataDir) {
+		return fmt.Errorf("--%s cannot be used with the ancient store in %s", HistoryRetentionFlag.Name, chainDataDir)
+	}
+	return nil
+}
+
 func (kCfg *KaiaConfig) SetChainDataFetcherConfig(ctx *cli.Context) {
 	cfg := &kCfg.ChainDataFetcher
 	if ctx.Bool(EnableChainDataFetcherFlag.Name) {
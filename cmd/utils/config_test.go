@@ -16,8 +16,11 @@
 package utils
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/klaytn/klaytn/blockchain"
 	"github.com/stretchr/testify/assert"
 	"github.com/urfave/cli/v2"
 	"github.com/urfave/cli/v2/altsrc"
@@ -290,3 +293,19 @@ func TestLoadYaml(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestCheckHistoryRetention(t *testing.T) {
+	dir := t.TempDir()
+	retention := uint64(blockchain.DefaultTriesInMemory)
+
+	assert.NoError(t, checkHistoryRetention(0, 0, dir))
+	assert.NoError(t, checkHistoryRetention(retention, 0, dir))
+	assert.NoError(t, checkHistoryRetention(retention, 0, ""))
+	assert.Error(t, checkHistoryRetention(retention, 100, dir))
+	assert.Error(t, checkHistoryRetention(retention-1, 0, dir))
+
+	// The blocks frozen by an earlier run with the ancient store are not pruned.
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "ancient"), 0o755))
+	assert.Error(t, checkHistoryRetention(retention, 0, dir))
+	assert.NoError(t, checkHistoryRetention(0, 0, dir))
+}
@@ -93,8 +93,11 @@ var FlagGroups = []FlagGroup{
 			LevelDBCacheSizeFlag,
 			SingleDBFlag,
 			NumStateTrieShardsFlag,
+			AncientThresholdFlag,
+			HistoryRetentionFlag,
 			LevelDBCompressionTypeFlag,
 			LevelDBNoBufferPoolFlag,
+			ReadOnlyFlag,
 			RocksDBSecondaryFlag,
 			RocksDBCacheSizeFlag,
 			RocksDBDumpMallocStatFlag,
@@ -215,6 +218,7 @@ var FlagGroups = []FlagGroup{
 			UseSnapshotForPrefetchFlag,
 			TrieNodeCacheLimitFlag,
 			TrieNodeCacheSavePeriodFlag,
+			TrieNodeCacheDiskSizeFlag,
 			TrieNodeCacheRedisEndpointsFlag,
 			TrieNodeCacheRedisClusterFlag,
 			TrieNodeCacheRedisPublishBlockFlag,
@@ -240,6 +244,10 @@ var FlagGroups = []FlagGroup{
 			TargetGasLimitFlag,
 			NATFlag,
 			NoDiscoverFlag,
+			DiscoveryV5Flag,
+			DNSDiscoveryFlag,
+			IngressLimitFlag,
+			EgressLimitFlag,
 			RWTimerWaitTimeFlag,
 			RWTimerIntervalFlag,
 			NetrestrictFlag,
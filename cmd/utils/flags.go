@@ -96,7 +96,7 @@ var (
 	}
 	DbTypeFlag = &cli.StringFlag{
 		Name:     "dbtype",
-		Usage:    `Blockchain storage database type ("LevelDB", "BadgerDB", "MemoryDB", "DynamoDBS3")`,
+		Usage:    `Blockchain storage database type ("LevelDB", "BadgerDB", "PebbleDB", "MemoryDB", "DynamoDBS3")`,
 		Value:    "LevelDB",
 		Aliases:  []string{"db.type", "migration.src.dbtype"},
 		EnvVars:  []string{"KLAYTN_DBTYPE", "KAIA_DBTYPE"},
@@ -328,9 +328,25 @@ var (
 		EnvVars:  []string{"KLAYTN_DB_NUM_STATETRIE_SHARDS", "KAIA_DB_NUM_STATETRIE_SHARDS"},
 		Category: "DATABASE",
 	}
+	AncientThresholdFlag = &cli.Uint64Flag{
+		Name:     "db.ancient.threshold",
+		Usage:    "Number of recent blocks kept in the key-value databases. Older block headers, bodies and receipts are moved into the append-only ancient store (0 = disabled)",
+		Value:    0,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_ANCIENT_THRESHOLD", "KAIA_DB_ANCIENT_THRESHOLD"},
+		Category: "DATABASE",
+	}
+	HistoryRetentionFlag = &cli.Uint64Flag{
+		Name:     "history.retention",
+		Usage:    "Number of recent blocks whose bodies, receipts and tx lookup entries are kept. Older ones are deleted (0 = entire chain). Not allowed with an ancient store",
+		Value:    0,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_HISTORY_RETENTION", "KAIA_HISTORY_RETENTION"},
+		Category: "DATABASE",
+	}
 	LevelDBCacheSizeFlag = &cli.IntFlag{
 		Name:     "db.leveldb.cache-size",
-		Usage:    "Size of in-memory cache in LevelDB (MiB)",
+		Usage:    "Size of in-memory cache in LevelDB and PebbleDB (MiB)",
 		Value:    768,
 		Aliases:  []string{"migration.src.db.leveldb.cache-size"},
 		EnvVars:  []string{"KLAYTN_DB_LEVELDB_CACHE_SIZE", "KAIA_DB_LEVELDB_CACHE_SIZE"},
@@ -352,6 +368,12 @@ var (
 		EnvVars:  []string{"KLAYTN_DB_LEVELDB_NO_BUFFER_POOL", "KAIA_DB_LEVELDB_NO_BUFFER_POOL"},
 		Category: "DATABASE",
 	}
+	ReadOnlyFlag = &cli.BoolFlag{
+		Name:     "readonly",
+		Usage:    "Open the chaindata of a running node read-only (LevelDB, RocksDB or DynamoDB) and serve RPC from it, following the writes of the node. Point --chaindatadir to the datadir of the running node",
+		EnvVars:  []string{"KLAYTN_READONLY", "KAIA_READONLY"},
+		Category: "DATABASE",
+	}
 	RocksDBSecondaryFlag = &cli.BoolFlag{
 		Name:     "db.rocksdb.secondary",
 		Usage:    "Enable rocksdb secondary mode (read-only and catch-up with primary node dynamically)",
@@ -574,7 +596,7 @@ var (
 	TrieNodeCacheTypeFlag = &cli.StringFlag{
 		Name: "statedb.cache.type",
 		Usage: "Set trie node cache type ('LocalCache', 'RemoteCache', " +
-			"'HybridCache') (default = 'LocalCache')",
+			"'HybridCache', 'DiskCache') (default = 'LocalCache')",
 		Value:    string(statedb.CacheTypeLocal),
 		Aliases:  []string{},
 		EnvVars:  []string{"KLAYTN_STATEDB_CACHE_TYPE", "KAIA_STATEDB_CACHE_TYPE"},
@@ -631,9 +653,17 @@ var (
 		EnvVars:  []string{"KLAYTN_STATE_TRIE_CACHE_LIMIT", "KAIA_STATE_TRIE_CACHE_LIMIT"},
 		Category: "CACHE",
 	}
+	TrieNodeCacheDiskSizeFlag = &cli.IntFlag{
+		Name:     "statedb.cache.disk.size",
+		Usage:    "Disk allowance (MiB) to use for caching trie nodes on disk if DiskCache is used",
+		Value:    4096,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_STATEDB_CACHE_DISK_SIZE", "KAIA_STATEDB_CACHE_DISK_SIZE"},
+		Category: "CACHE",
+	}
 	TrieNodeCacheSavePeriodFlag = &cli.DurationFlag{
 		Name:     "state.trie-cache-save-period",
-		Usage:    "Period of saving in memory trie cache to file if fastcache is used, 0 means disabled",
+		Usage:    "Period of saving in memory trie cache to file if fastcache is used, or flushing disk cache if DiskCache is used, 0 means disabled",
 		Value:    0,
 		Aliases:  []string{},
 		EnvVars:  []string{"KLAYTN_STATE_TRIE_CACHE_SAVE_PERIOD", "KAIA_STATE_TRIE_CACHE_SAVE_PERIOD"},
@@ -1184,6 +1214,34 @@ var (
 		EnvVars:  []string{"KLAYTN_NODISCOVER", "KAIA_NODISCOVER"},
 		Category: "NETWORK",
 	}
+	DiscoveryV5Flag = &cli.BoolFlag{
+		Name:     "discv5",
+		Usage:    "Enables the discovery v5 protocol with signed node records alongside the legacy one",
+		Aliases:  []string{"p2p.discv5"},
+		EnvVars:  []string{"KLAYTN_DISCV5", "KAIA_DISCV5"},
+		Category: "NETWORK",
+	}
+	DNSDiscoveryFlag = &cli.StringFlag{
+		Name:     "discovery.dns",
+		Usage:    "Comma separated URLs of DNS trees (kntree://<key>@<domain>) providing dial candidates",
+		Aliases:  []string{"p2p.discovery-dns"},
+		EnvVars:  []string{"KLAYTN_DISCOVERY_DNS", "KAIA_DISCOVERY_DNS"},
+		Category: "NETWORK",
+	}
+	IngressLimitFlag = &cli.StringFlag{
+		Name:     "bandwidth.ingress",
+		Usage:    "Comma separated ingress caps per connection in bytes per second by peer type (e.g. en=1048576,pn=8388608)",
+		Aliases:  []string{"p2p.bandwidth-ingress"},
+		EnvVars:  []string{"KLAYTN_BANDWIDTH_INGRESS", "KAIA_BANDWIDTH_INGRESS"},
+		Category: "NETWORK",
+	}
+	EgressLimitFlag = &cli.StringFlag{
+		Name:     "bandwidth.egress",
+		Usage:    "Comma separated egress caps per connection in bytes per second by peer type (e.g. en=1048576,pn=8388608)",
+		Aliases:  []string{"p2p.bandwidth-egress"},
+		EnvVars:  []string{"KLAYTN_BANDWIDTH_EGRESS", "KAIA_BANDWIDTH_EGRESS"},
+		Category: "NETWORK",
+	}
 	NetrestrictFlag = &cli.StringFlag{
 		Name:     "netrestrict",
 		Usage:    "Restricts network communication to the given IP network (CIDR masks)",
@@ -1786,7 +1844,7 @@ var (
 	// db migration vars
 	DstDbTypeFlag = &cli.StringFlag{
 		Name:     "dst.dbtype",
-		Usage:    `Blockchain storage database type ("LevelDB", "BadgerDB", "DynamoDBS3")`,
+		Usage:    `Blockchain storage database type ("LevelDB", "BadgerDB", "PebbleDB", "DynamoDBS3")`,
 		Value:    "LevelDB",
 		Aliases:  []string{"migration.dst.dbtype"},
 		EnvVars:  []string{"KLAYTN_DST_DBTYPE", "KAIA_DST_DBTYPE"},
@@ -1808,7 +1866,7 @@ var (
 	}
 	DstLevelDBCacheSizeFlag = &cli.IntFlag{
 		Name:     "db.dst.leveldb.cache-size",
-		Usage:    "Size of in-memory cache in LevelDB (MiB)",
+		Usage:    "Size of in-memory cache in LevelDB and PebbleDB (MiB)",
 		Value:    768,
 		Aliases:  []string{"migration.dst.db.leveldb.cache-size"},
 		EnvVars:  []string{"KLAYTN_DB_DST_LEVELDB_CACHE_SIZE", "KAIA_DB_DST_LEVELDB_CACHE_SIZE"},
@@ -0,0 +1,119 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package nodecmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/klaytn/klaytn/cmd/utils"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/urfave/cli/v2"
+)
+
+var inspectJSONFlag = &cli.BoolFlag{
+	Name:  "json",
+	Usage: "Print the inspection result in JSON",
+}
+
+var DBCommand = &cli.Command{
+	Name:        "db",
+	Usage:       "Low level database operations",
+	Description: "",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "inspect",
+			Usage:  "Inspect the storage size for each type of data in the database",
+			Action: utils.MigrateFlags(inspect),
+			Flags:  append([]cli.Flag{inspectJSONFlag}, utils.SnapshotFlags...),
+			Description: `
+Kaia db inspect
+walks every database (header, body, receipts, txlookup, statetrie shards,
+misc, snapshot and the others) and reports the number of keys and their
+total size for each type of data defined in the database schema.
+`,
+		},
+		{
+			Name:      "checkpoint",
+			Usage:     "Copy the databases into a new data directory",
+			ArgsUsage: "<dir>",
+			Action:    utils.MigrateFlags(checkpoint),
+			Flags:     utils.SnapshotFlags,
+			Description: `
+Kaia db checkpoint <dir>
+copies every database and the ancient store into the given directory, which
+must not exist, with the same layout as the chaindata directory. The copy
+starts at the current head block. Use debug.createCheckpoint on the console
+to take a checkpoint of a running node instead.
+`,
+		},
+	},
+}
+
+// inspect iterates all databases and prints the storage size of each type of data.
+func inspect(ctx *cli.Context) error {
+	if ctx.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", ctx.Args().Slice())
+	}
+	stack := MakeFullNode(ctx)
+	dbm := stack.OpenDatabase(getConfig(ctx))
+	defer dbm.Close()
+
+	stats, err := database.InspectDatabase(dbm)
+	if err != nil {
+		return err
+	}
+	if ctx.Bool(inspectJSONFlag.Name) {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+
+	var (
+		totalCount uint64
+		totalSize  common.StorageSize
+		w          = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	)
+	fmt.Fprintln(w, "Database\tCategory\tCount\tSize\t")
+	for _, stat := range stats {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t\n", stat.Database, stat.Category, stat.Count, stat.Size)
+		totalCount += stat.Count
+		totalSize += stat.Size
+	}
+	fmt.Fprintf(w, "Total\t\t%d\t%s\t\n", totalCount, totalSize)
+	return w.Flush()
+}
+
+// checkpoint copies all databases into the directory given as the argument.
+func checkpoint(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("expected the checkpoint directory as the only argument, got %v", ctx.Args().Slice())
+	}
+	stack := MakeFullNode(ctx)
+	dbm := stack.OpenDatabase(getConfig(ctx))
+	defer dbm.Close()
+
+	info, err := dbm.CreateCheckpoint(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created checkpoint in %s at block #%d (%s)\n", info.Dir, info.HeadNumber, info.HeadHash.Hex())
+	return nil
+}
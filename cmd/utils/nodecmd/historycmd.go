@@ -0,0 +1,185 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package nodecmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/era"
+	"github.com/klaytn/klaytn/cmd/utils"
+	"github.com/klaytn/klaytn/common"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	epochSizeFlag = &cli.Uint64Flag{
+		Name:  "epoch.size",
+		Usage: "Number of blocks stored in a single history archive",
+		Value: era.DefaultEpochSize,
+	}
+	checkpointFlag = &cli.StringFlag{
+		Name:     "checkpoint",
+		Usage:    "Hash of the last archived block, obtained from a trusted source",
+		Required: true,
+	}
+)
+
+var (
+	ExportHistoryCommand = &cli.Command{
+		Action:    utils.MigrateFlags(exportHistory),
+		Name:      "export-history",
+		Usage:     "Export the chain history into epoch archives",
+		ArgsUsage: "<dir> [<first> <last>]",
+		Flags:     append([]cli.Flag{epochSizeFlag}, utils.SnapshotFlags...),
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The export-history command writes the canonical blocks with their receipts and
+total blockscores into fixed-size epoch archives in the given directory, along
+with an index.json listing the checksum and the accumulator root of each archive.
+
+The whole chain up to the head block is exported unless a range is given.`,
+	}
+
+	ImportHistoryCommand = &cli.Command{
+		Action:    utils.MigrateFlags(importHistory),
+		Name:      "import-history",
+		Usage:     "Import the chain history from epoch archives",
+		ArgsUsage: "--checkpoint <hash> <dir>",
+		Flags:     append([]cli.Flag{checkpointFlag}, utils.SnapshotFlags...),
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The import-history command verifies the epoch archives exported by
+export-history and writes their blocks, receipts and tx lookup entries into
+the database without executing them. The database must be initialized with
+the same genesis block.
+
+The index of the archives is not trusted, so the hash of the last archived
+block must be given with --checkpoint from a trusted source. Nothing is
+written unless every archived block is hash-linked to the checkpoint and to
+the local chain, and the blocks conflicting with the local canonical chain
+are rejected.`,
+	}
+
+	VerifyHistoryCommand = &cli.Command{
+		Action:    utils.MigrateFlags(verifyHistory),
+		Name:      "verify-history",
+		Usage:     "Verify the epoch archives offline",
+		ArgsUsage: "<dir>",
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The verify-history command checks the checksums and the accumulator roots of
+the epoch archives exported by export-history, as well as the continuity and
+the transaction and receipt roots of the archived blocks. No database is needed.
+It detects corrupted archives, but does not authenticate them.`,
+	}
+)
+
+// exportHistory writes the canonical chain into epoch archives.
+func exportHistory(ctx *cli.Context) error {
+	if ctx.NArg() != 1 && ctx.NArg() != 3 {
+		return errors.New("usage: export-history <dir> [<first> <last>]")
+	}
+	stack := MakeFullNode(ctx)
+	db := stack.OpenDatabase(getConfig(ctx))
+	defer db.Close()
+
+	head := db.ReadHeaderNumber(db.ReadHeadBlockHash())
+	if head == nil {
+		return errors.New("empty database")
+	}
+	first, last := uint64(0), *head
+	if ctx.NArg() == 1 {
+		if tail := db.ReadHistoryTail(); tail > 1 {
+			// Export the retained history by default. The genesis block is kept,
+			// but it is provided by the genesis file on the importing node anyway.
+			logger.Info("History has been pruned, exporting from the history tail", "tail", tail)
+			first = tail
+		}
+	} else {
+		var err error
+		if first, err = strconv.ParseUint(ctx.Args().Get(1), 10, 64); err != nil {
+			return fmt.Errorf("invalid first block: %v", err)
+		}
+		if last, err = strconv.ParseUint(ctx.Args().Get(2), 10, 64); err != nil {
+			return fmt.Errorf("invalid last block: %v", err)
+		}
+		if last > *head {
+			return fmt.Errorf("last block %d is beyond the head block %d", last, *head)
+		}
+	}
+	index, err := era.Export(db, ctx.Args().First(), first, last, ctx.Uint64(epochSizeFlag.Name))
+	if err != nil {
+		logger.Error("Failed to export history", "err", err)
+		return err
+	}
+	logger.Info("Exported history", "first", first, "last", last, "archives", len(index.Epochs))
+	return nil
+}
+
+// importHistory writes the blocks of epoch archives into the database.
+func importHistory(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("usage: import-history --checkpoint <hash> <dir>")
+	}
+	var checkpoint common.Hash
+	if err := checkpoint.UnmarshalText([]byte(ctx.String(checkpointFlag.Name))); err != nil {
+		return fmt.Errorf("invalid checkpoint: %v", err)
+	}
+	stack := MakeFullNode(ctx)
+	db := stack.OpenDatabase(getConfig(ctx))
+	defer db.Close()
+
+	genesis := db.ReadCanonicalHash(0)
+	if genesis == (common.Hash{}) {
+		return errors.New("database is not initialized")
+	}
+	config := db.ReadChainConfig(genesis)
+	if config == nil {
+		return errors.New("missing chain config")
+	}
+	blockchain.InitDeriveSha(config)
+
+	imported, err := era.Import(db, ctx.Args().First(), checkpoint)
+	if err != nil {
+		logger.Error("Failed to import history", "imported", imported, "err", err)
+		return err
+	}
+	logger.Info("Imported history", "blocks", imported)
+	return nil
+}
+
+// verifyHistory checks the epoch archives without a database.
+func verifyHistory(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("usage: verify-history <dir>")
+	}
+	index, err := era.ReadIndex(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	blockchain.InitDeriveSha(index.Config)
+
+	if _, err := era.Verify(ctx.Args().First()); err != nil {
+		logger.Error("Failed to verify history", "err", err)
+		return err
+	}
+	logger.Info("Verified history", "genesis", index.Genesis, "archives", len(index.Epochs))
+	return nil
+}
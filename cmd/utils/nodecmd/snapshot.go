@@ -26,10 +26,14 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/state/pruner"
+	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/cmd/utils"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/snapshot"
@@ -38,6 +42,24 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
+var bloomFilterSizeFlag = &cli.Uint64Flag{
+	Name:  "bloomfilter.size",
+	Usage: "Megabytes of memory allocated to bloom-filter for pruning",
+	Value: pruner.DefaultBloomFilterSize,
+}
+
+var exportChunkSizeFlag = &cli.Uint64Flag{
+	Name:  "chunk.size",
+	Usage: "Megabytes of uncompressed state data written into a single exported chunk file",
+	Value: snapshot.DefaultExportChunkSize / 1024 / 1024,
+}
+
+var importCheckpointFlag = &cli.StringFlag{
+	Name:     "checkpoint",
+	Usage:    "Hash of the exported block, obtained from a trusted source",
+	Required: true,
+}
+
 var SnapshotCommand = &cli.Command{
 	Name:        "snapshot",
 	Usage:       "A set of commands based on the snapshot",
@@ -54,6 +76,64 @@ Kaia snapshot verify-state <state-root>
 will traverse the whole accounts and storages set based on the specified
 snapshot and recalculate the root hash of state for verification.
 In other words, this command does the snapshot to trie conversion.
+`,
+		},
+		{
+			Name:      "prune-state",
+			Usage:     "Prune stale state data based on the snapshot",
+			ArgsUsage: "<root>",
+			Action:    utils.MigrateFlags(pruneState),
+			Flags:     append([]cli.Flag{bloomFilterSizeFlag}, utils.SnapshotFlags...),
+			Description: `
+Kaia snapshot prune-state <state-root>
+will prune historical state data with the help of the state snapshot.
+All trie nodes and contract codes that do not belong to the specified
+version state will be deleted from the database. After pruning, only
+two version states are available: genesis and the specific one.
+
+The default pruning target is the HEAD-127 state.
+
+WARNING: it's not supported for the database with live pruning enabled
+and it's a non-reversible operation. The node must be stopped while pruning.
+`,
+		},
+		{
+			Name:      "export",
+			Usage:     "Export the state snapshot into portable files",
+			ArgsUsage: "<dir> [<block-number or block-hash>]",
+			Action:    utils.MigrateFlags(exportSnapshot),
+			Flags:     append([]cli.Flag{exportChunkSizeFlag}, utils.SnapshotFlags...),
+			Description: `
+Kaia snapshot export <dir> [<block-number or block-hash>]
+will dump all accounts, storage slots and contract codes of the state of the
+specified block into a set of chunked, checksummed and gzip compressed files
+in the given directory, along with the block itself and a manifest.json
+describing them.
+
+The default export target is the state of the head block.
+`,
+		},
+		{
+			Name:      "import",
+			Usage:     "Import the state snapshot from exported files",
+			ArgsUsage: "--checkpoint <hash> <dir>",
+			Action:    utils.MigrateFlags(importSnapshot),
+			Flags:     append([]cli.Flag{importCheckpointFlag}, utils.SnapshotFlags...),
+			Description: `
+Kaia snapshot import --checkpoint <hash> <dir>
+will load the state snapshot exported by "snapshot export" into the
+database, rebuild the state trie out of it and verify that the rebuilt
+state root matches the exported one.
+
+The exported files are not trusted, so the hash of the exported block must
+be given with --checkpoint from a trusted source. Nothing is imported unless
+the exported block has that hash. The exported block is then written as a
+canonical block. It becomes the head block only if its parent is already in
+the local canonical chain, e.g. imported by "import-history", so the node
+starts from the imported state and syncs the chain onward from there.
+
+The database must be initialized with the same genesis block and must not
+contain a state snapshot yet. A failed import can be retried.
 `,
 		},
 		{
@@ -180,6 +260,138 @@ func verifyState(ctx *cli.Context) error {
 	return nil
 }
 
+// pruneState deletes all the trie nodes not belonging to the target state.
+// if a root hash isn't given, the HEAD-127 state is pruned to.
+func pruneState(ctx *cli.Context) error {
+	stack := MakeFullNode(ctx)
+	db := stack.OpenDatabase(getConfig(ctx))
+	defer db.Close()
+
+	if ctx.NArg() > 1 {
+		logger.Error("Too many arguments given")
+		return errors.New("too many arguments")
+	}
+	var (
+		root common.Hash
+		err  error
+	)
+	if ctx.NArg() == 1 {
+		root, err = parseRoot(ctx.Args().First())
+		if err != nil {
+			logger.Error("Failed to resolve state root", "err", err)
+			return err
+		}
+	}
+	p, err := pruner.NewPruner(db, ctx.Uint64(bloomFilterSizeFlag.Name))
+	if err != nil {
+		logger.Error("Failed to open snapshot tree", "err", err)
+		return err
+	}
+	if err := p.Prune(root); err != nil {
+		logger.Error("Failed to prune state", "err", err)
+		return err
+	}
+	return nil
+}
+
+// exportSnapshot dumps the state snapshot into portable files.
+// if a block isn't given, the state of the head block is exported.
+func exportSnapshot(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		logger.Error("Missing export directory")
+		return errors.New("missing export directory")
+	}
+	if ctx.NArg() > 2 {
+		logger.Error("Too many arguments given")
+		return errors.New("too many arguments")
+	}
+	stack := MakeFullNode(ctx)
+	db := stack.OpenDatabase(getConfig(ctx))
+	defer db.Close()
+
+	head := db.ReadHeadBlockHash()
+	if head == (common.Hash{}) {
+		// Corrupt or empty database, init from scratch
+		return errors.New("empty database")
+	}
+	// Make sure the entire head block is available
+	headBlock := db.ReadBlockByHash(head)
+	if headBlock == nil {
+		return fmt.Errorf("head block missing: %v", head.String())
+	}
+	snaptree, err := snapshot.New(db, statedb.NewDatabase(db), 256, headBlock.Root(), false, false, false)
+	if err != nil {
+		logger.Error("Failed to open snapshot tree", "err", err)
+		return err
+	}
+	block := headBlock
+	if ctx.NArg() == 2 {
+		block, err = parseBlock(db, ctx.Args().Get(1))
+		if err != nil {
+			logger.Error("Failed to resolve block", "err", err)
+			return err
+		}
+	}
+	chunkSize := ctx.Uint64(exportChunkSizeFlag.Name) * 1024 * 1024
+	if _, err := snapshot.Export(snaptree, block, db, ctx.Args().First(), chunkSize); err != nil {
+		logger.Error("Failed to export snapshot", "number", block.NumberU64(), "root", block.Root(), "err", err)
+		return err
+	}
+	return nil
+}
+
+// parseBlock resolves the canonical block of the given number or the block of
+// the given hash.
+func parseBlock(db database.DBManager, input string) (*types.Block, error) {
+	var block *types.Block
+	if number, err := strconv.ParseUint(input, 10, 64); err == nil {
+		block = db.ReadBlockByNumber(number)
+	} else {
+		var hash common.Hash
+		if err := hash.UnmarshalText([]byte(input)); err != nil {
+			return nil, err
+		}
+		block = db.ReadBlockByHash(hash)
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", input)
+	}
+	return block, nil
+}
+
+// importSnapshot loads the exported state snapshot and rebuilds the state trie.
+func importSnapshot(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		logger.Error("Export directory is required as the only argument")
+		return errors.New("invalid arguments")
+	}
+	var checkpoint common.Hash
+	if err := checkpoint.UnmarshalText([]byte(ctx.String(importCheckpointFlag.Name))); err != nil {
+		return fmt.Errorf("invalid checkpoint: %v", err)
+	}
+	stack := MakeFullNode(ctx)
+	db := stack.OpenDatabase(getConfig(ctx))
+	defer db.Close()
+
+	genesis := db.ReadCanonicalHash(0)
+	if genesis == (common.Hash{}) {
+		return errors.New("database is not initialized")
+	}
+	config := db.ReadChainConfig(genesis)
+	if config == nil {
+		return errors.New("missing chain config")
+	}
+	blockchain.InitDeriveSha(config)
+
+	root, err := snapshot.Import(db, ctx.Args().First(), checkpoint)
+	if err != nil {
+		logger.Error("Failed to import snapshot", "err", err)
+		return err
+	}
+	logger.Info("Imported the state", "root", root)
+	return nil
+}
+
 func traceTrie(ctx *cli.Context) error {
 	var childWait, logWait sync.WaitGroup
 
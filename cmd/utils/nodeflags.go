@@ -198,6 +198,7 @@ var CommonNodeFlags = []cli.Flag{
 	altsrc.NewIntFlag(LevelDBCompressionTypeFlag),
 	altsrc.NewBoolFlag(LevelDBNoBufferPoolFlag),
 	altsrc.NewBoolFlag(DBNoPerformanceMetricsFlag),
+	altsrc.NewBoolFlag(ReadOnlyFlag),
 	altsrc.NewBoolFlag(RocksDBSecondaryFlag),
 	altsrc.NewUint64Flag(RocksDBCacheSizeFlag),
 	altsrc.NewBoolFlag(RocksDBDumpMallocStatFlag),
@@ -214,6 +215,8 @@ var CommonNodeFlags = []cli.Flag{
 	altsrc.NewInt64Flag(DynamoDBWriteCapacityFlag),
 	altsrc.NewBoolFlag(DynamoDBReadOnlyFlag),
 	altsrc.NewIntFlag(LevelDBCacheSizeFlag),
+	altsrc.NewUint64Flag(AncientThresholdFlag),
+	altsrc.NewUint64Flag(HistoryRetentionFlag),
 	altsrc.NewBoolFlag(NoParallelDBWriteFlag),
 	altsrc.NewBoolFlag(SenderTxHashIndexingFlag),
 	altsrc.NewIntFlag(TrieMemoryCacheSizeFlag),
@@ -230,6 +233,7 @@ var CommonNodeFlags = []cli.Flag{
 	altsrc.NewBoolFlag(UseSnapshotForPrefetchFlag),
 	altsrc.NewIntFlag(TrieNodeCacheLimitFlag),
 	altsrc.NewDurationFlag(TrieNodeCacheSavePeriodFlag),
+	altsrc.NewIntFlag(TrieNodeCacheDiskSizeFlag),
 	altsrc.NewStringSliceFlag(TrieNodeCacheRedisEndpointsFlag),
 	altsrc.NewBoolFlag(TrieNodeCacheRedisClusterFlag),
 	altsrc.NewBoolFlag(TrieNodeCacheRedisPublishBlockFlag),
@@ -243,6 +247,10 @@ var CommonNodeFlags = []cli.Flag{
 	altsrc.NewUint64Flag(TargetGasLimitFlag),
 	altsrc.NewStringFlag(NATFlag),
 	altsrc.NewBoolFlag(NoDiscoverFlag),
+	altsrc.NewBoolFlag(DiscoveryV5Flag),
+	altsrc.NewStringFlag(DNSDiscoveryFlag),
+	altsrc.NewStringFlag(IngressLimitFlag),
+	altsrc.NewStringFlag(EgressLimitFlag),
 	altsrc.NewDurationFlag(RWTimerWaitTimeFlag),
 	altsrc.NewUint64Flag(RWTimerIntervalFlag),
 	altsrc.NewStringFlag(NetrestrictFlag),
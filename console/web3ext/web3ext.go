@@ -184,6 +184,12 @@ web3._extend({
 			params: 3,
 			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null]
 		}),
+		new web3._extend.Method({
+			name: 'simulateV1',
+			call: 'eth_simulateV1',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -428,6 +434,27 @@ web3._extend({
 			call: 'admin_removePeer',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'banPeer',
+			call: 'admin_banPeer',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'unbanPeer',
+			call: 'admin_unbanPeer',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'listBans',
+			call: 'admin_listBans',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'reloadPeerLists',
+			call: 'admin_reloadPeerLists',
+			params: 0
+		}),
 		new web3._extend.Method({
 			name: 'exportChain',
 			call: 'admin_exportChain',
@@ -850,6 +877,12 @@ web3._extend({
 			params: 3,
 			inputFormatter: [null, null, null]
 		}),
+		new web3._extend.Method({
+			name: 'traceCallMany',
+			call: 'debug_traceCallMany',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'preimage',
 			call: 'debug_preimage',
@@ -861,6 +894,11 @@ web3._extend({
 			call: 'debug_storageRangeAt',
 			params: 5,
 		}),
+		new web3._extend.Method({
+			name: 'createCheckpoint',
+			call: 'debug_createCheckpoint',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'setVMLogTarget',
 			call: 'debug_setVMLogTarget',
@@ -1158,6 +1196,12 @@ var klayMethods = [
 		params: 3,
 		inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null]
 	}),
+	new web3._extend.Method({
+		name: 'simulate',
+		call: 'klay_simulate',
+		params: 2,
+		inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter]
+	}),
 	new web3._extend.Method({
 		name: 'getTotalSupply',
 		call: 'klay_getTotalSupply',
@@ -0,0 +1,49 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package secp256r1 implements signature verification on the NIST P-256 curve
+// used by the P256VERIFY precompiled contract (EIP-7951).
+package secp256r1
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"math/big"
+)
+
+// Verify checks the given signature (r, s) for the given hash and public key (x, y).
+// It returns false if the public key is not a valid point on the P-256 curve.
+func Verify(hash []byte, r, s, x, y *big.Int) bool {
+	publicKey := newPublicKey(x, y)
+	if publicKey == nil {
+		return false
+	}
+	return ecdsa.Verify(publicKey, hash, r, s)
+}
+
+// newPublicKey creates a P-256 public key from the given coordinates.
+// It returns nil if the point is not on the curve.
+func newPublicKey(x, y *big.Int) *ecdsa.PublicKey {
+	// The point at infinity (0, 0) and coordinates out of the field range are rejected as well.
+	if x == nil || y == nil || !elliptic.P256().IsOnCurve(x, y) {
+		return nil
+	}
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     x,
+		Y:     y,
+	}
+}
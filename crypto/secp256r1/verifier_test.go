@@ -0,0 +1,52 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package secp256r1
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	hash := sha256.Sum256([]byte("kaia"))
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	assert.NoError(t, err)
+
+	assert.True(t, Verify(hash[:], r, s, key.X, key.Y))
+
+	// wrong message
+	wrong := sha256.Sum256([]byte("klaytn"))
+	assert.False(t, Verify(wrong[:], r, s, key.X, key.Y))
+
+	// wrong signature
+	assert.False(t, Verify(hash[:], s, r, key.X, key.Y))
+
+	// public key not on the curve
+	assert.False(t, Verify(hash[:], r, s, key.X, new(big.Int).Add(key.Y, big.NewInt(1))))
+
+	// point at infinity
+	assert.False(t, Verify(hash[:], r, s, new(big.Int), new(big.Int)))
+}
@@ -97,6 +97,12 @@ type Peer interface {
 	RequestNodeData([]common.Hash) error
 }
 
+// historyPeer is implemented by peers which may have pruned the bodies and
+// receipts of old blocks.
+type historyPeer interface {
+	HistoryTail() uint64
+}
+
 // lightPeerWrapper wraps a LightPeer struct, stubbing out the Peer-only methods.
 type lightPeerWrapper struct {
 	peer LightPeer
@@ -403,6 +409,15 @@ func (p *peerConnection) Lacks(hash common.Hash) bool {
 	return ok
 }
 
+// LacksHistory retrieves whether the peer has announced that it pruned the
+// bodies and receipts of the given block.
+func (p *peerConnection) LacksHistory(number uint64) bool {
+	if hp, ok := p.peer.(historyPeer); ok {
+		return number < hp.HistoryTail()
+	}
+	return false
+}
+
 // peerSet represents the collection of active peer participating in the chain
 // download procedure.
 type peerSet struct {
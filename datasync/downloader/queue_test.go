@@ -300,6 +300,43 @@ func TestScheduleAfterKaia(t *testing.T) {
 	}
 }
 
+// historyTesterPeer is a peer announcing that it pruned the history below tail.
+type historyTesterPeer struct {
+	Peer
+	tail uint64
+}
+
+func (p *historyTesterPeer) HistoryTail() uint64 { return p.tail }
+
+func TestReserveBodiesSkipsPrunedHistory(t *testing.T) {
+	// set test staking update interval
+	orig := params.StakingUpdateInterval()
+	params.SetStakingUpdateInterval(testInterval)
+	defer params.SetStakingUpdateInterval(orig)
+
+	q := newQueue(10, 10, uint64(istanbul.WeightedRandom), nil)
+	q.Prepare(1, FastSync)
+	q.Schedule(chain.headers(), 1)
+
+	peer := dummyPeer("peer-1")
+	peer.peer = &historyTesterPeer{tail: 5}
+	fetchReq, _, _ := q.ReserveBodies(peer, 50)
+	if fetchReq == nil {
+		t.Fatal("expected body fetch tasks above the history tail")
+	}
+	for _, header := range fetchReq.Headers {
+		if header.Number.Uint64() < 5 {
+			t.Fatalf("requested pruned block %d from peer", header.Number.Uint64())
+		}
+	}
+	// The skipped headers are left to the other peers
+	other := dummyPeer("peer-2")
+	fetchReq, _, _ = q.ReserveBodies(other, 50)
+	if fetchReq == nil || fetchReq.Headers[0].Number.Uint64() >= 5 {
+		t.Fatal("expected the pruned blocks to be requested from a peer keeping them")
+	}
+}
+
 func TestEmptyBlocks(t *testing.T) {
 	// set test staking update interval
 	orig := params.StakingUpdateInterval()
@@ -428,6 +428,8 @@ func getChainConfig(governance Engine, num *rpc.BlockNumber) *params.ChainConfig
 	config.ShanghaiCompatibleBlock = latestConfig.ShanghaiCompatibleBlock
 	config.CancunCompatibleBlock = latestConfig.CancunCompatibleBlock
 	config.KaiaCompatibleBlock = latestConfig.KaiaCompatibleBlock
+	config.PragueCompatibleBlock = latestConfig.PragueCompatibleBlock
+	config.OsakaCompatibleBlock = latestConfig.OsakaCompatibleBlock
 	config.Kip103CompatibleBlock = latestConfig.Kip103CompatibleBlock
 	config.Kip103ContractAddress = latestConfig.Kip103ContractAddress
 	config.Kip160CompatibleBlock = latestConfig.Kip160CompatibleBlock
@@ -0,0 +1,314 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/mclock"
+	metricutils "github.com/klaytn/klaytn/metrics/utils"
+	"github.com/klaytn/klaytn/networks/p2p/msgrate"
+	"github.com/rcrowley/go-metrics"
+)
+
+// trafficMeterWindow is the window the traffic rates of peers are averaged over.
+const trafficMeterWindow = 10 * time.Second
+
+// ParseBandwidthLimits parses a comma separated list of <nodetype>=<bytes/s>
+// pairs, e.g. "en=1048576,pn=8388608", as used by Config.IngressLimits and
+// Config.EgressLimits.
+func ParseBandwidthLimits(s string) (map[string]uint64, error) {
+	limits := make(map[string]uint64)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid bandwidth limit %q, want <nodetype>=<bytes/s>", item)
+		}
+		nodeType := strings.ToLower(strings.TrimSpace(kv[0]))
+		if !validNodeTypeName(nodeType) {
+			return nil, fmt.Errorf("invalid node type %q in bandwidth limit", kv[0])
+		}
+		limit, err := strconv.ParseUint(strings.TrimSpace(kv[1]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bandwidth limit %q: %v", item, err)
+		}
+		limits[nodeType] = limit
+	}
+	return limits, nil
+}
+
+// connTypeName returns the short name of a connection type used in the peer
+// information and the bandwidth limits.
+func connTypeName(ct common.ConnType) string {
+	switch ct {
+	case common.CONSENSUSNODE:
+		return "cn"
+	case common.ENDPOINTNODE:
+		return "en"
+	case common.PROXYNODE:
+		return "pn"
+	case common.BOOTNODE:
+		return "bn"
+	default:
+		return "unknown"
+	}
+}
+
+func validNodeTypeName(name string) bool {
+	switch name {
+	case "cn", "en", "pn", "bn", "unknown":
+		return true
+	}
+	return false
+}
+
+// rateLimiter is a token bucket capping the throughput of a connection in one
+// direction. Messages larger than the bucket are let through by going into
+// debt, which delays the following messages instead.
+type rateLimiter struct {
+	rate   float64 // Bytes per second
+	tokens float64 // Available bytes, negative if in debt
+	last   mclock.AbsTime
+
+	throttled metrics.Counter
+	lock      sync.Mutex
+}
+
+func newRateLimiter(rate uint64, throttled metrics.Counter) *rateLimiter {
+	return &rateLimiter{
+		rate:      float64(rate),
+		tokens:    float64(rate),
+		last:      mclock.Now(),
+		throttled: throttled,
+	}
+}
+
+// reserve takes size bytes from the bucket and returns how long the caller has
+// to wait before sending or receiving them.
+func (l *rateLimiter) reserve(size uint32, now mclock.AbsTime) time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if now > l.last {
+		l.tokens += time.Duration(now-l.last).Seconds() * l.rate
+		if l.tokens > l.rate {
+			l.tokens = l.rate
+		}
+		l.last = now
+	}
+	l.tokens -= float64(size)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// wait blocks until size bytes may pass the limiter or closed is closed.
+// A nil limiter never blocks.
+func (l *rateLimiter) wait(size uint32, closed <-chan struct{}) error {
+	if l == nil {
+		return nil
+	}
+	delay := l.reserve(size, mclock.Now())
+	if delay <= 0 {
+		return nil
+	}
+	l.throttled.Inc(1)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-closed:
+		return io.EOF
+	}
+}
+
+// TrafficStats is the amount and the rate of traffic exchanged with a peer.
+// Rates are in units per second averaged over the last few seconds.
+type TrafficStats struct {
+	IngressBytes    uint64  `json:"ingressBytes"`
+	IngressMsgs     uint64  `json:"ingressMsgs"`
+	IngressByteRate float64 `json:"ingressByteRate"`
+	IngressMsgRate  float64 `json:"ingressMsgRate"`
+	EgressBytes     uint64  `json:"egressBytes"`
+	EgressMsgs      uint64  `json:"egressMsgs"`
+	EgressByteRate  float64 `json:"egressByteRate"`
+	EgressMsgRate   float64 `json:"egressMsgRate"`
+}
+
+// ProtocolTraffic is the traffic of a sub-protocol broken down by message code.
+type ProtocolTraffic struct {
+	TrafficStats
+	Messages map[uint64]*TrafficStats `json:"messages"`
+}
+
+// TrafficInfo is the traffic exchanged with a peer over its sub-protocols,
+// reported as a part of PeerInfo.
+type TrafficInfo struct {
+	TrafficStats
+	IngressLimit uint64                      `json:"ingressLimit,omitempty"` // Per connection cap in bytes per second
+	EgressLimit  uint64                      `json:"egressLimit,omitempty"`  // Per connection cap in bytes per second
+	Protocols    map[string]*ProtocolTraffic `json:"protocols"`
+}
+
+// trafficMeter meters the bytes and messages exchanged in both directions.
+type trafficMeter struct {
+	ingressBytes, ingressMsgs *msgrate.Meter
+	egressBytes, egressMsgs   *msgrate.Meter
+
+	// Registered meters of the message code, nil if metrics are disabled
+	ingressMeter, egressMeter metrics.Meter
+}
+
+func newTrafficMeter() *trafficMeter {
+	return &trafficMeter{
+		ingressBytes: msgrate.NewMeter(trafficMeterWindow),
+		ingressMsgs:  msgrate.NewMeter(trafficMeterWindow),
+		egressBytes:  msgrate.NewMeter(trafficMeterWindow),
+		egressMsgs:   msgrate.NewMeter(trafficMeterWindow),
+	}
+}
+
+func (m *trafficMeter) markIngress(size uint32) {
+	m.ingressBytes.Mark(uint64(size))
+	m.ingressMsgs.Mark(1)
+	if m.ingressMeter != nil {
+		m.ingressMeter.Mark(int64(size))
+	}
+}
+
+func (m *trafficMeter) markEgress(size uint32) {
+	m.egressBytes.Mark(uint64(size))
+	m.egressMsgs.Mark(1)
+	if m.egressMeter != nil {
+		m.egressMeter.Mark(int64(size))
+	}
+}
+
+func (m *trafficMeter) stats() *TrafficStats {
+	return &TrafficStats{
+		IngressBytes:    m.ingressBytes.Total(),
+		IngressMsgs:     m.ingressMsgs.Total(),
+		IngressByteRate: m.ingressBytes.Rate(),
+		IngressMsgRate:  m.ingressMsgs.Rate(),
+		EgressBytes:     m.egressBytes.Total(),
+		EgressMsgs:      m.egressMsgs.Total(),
+		EgressByteRate:  m.egressBytes.Rate(),
+		EgressMsgRate:   m.egressMsgs.Rate(),
+	}
+}
+
+// protocolTraffic meters the traffic of a sub-protocol and its messages.
+type protocolTraffic struct {
+	total *trafficMeter
+	msgs  map[uint64]*trafficMeter
+}
+
+// peerTraffic meters the sub-protocol traffic exchanged with a peer in total,
+// per protocol and per message code.
+type peerTraffic struct {
+	total     *trafficMeter
+	protocols map[string]*protocolTraffic
+	lock      sync.Mutex
+}
+
+func newPeerTraffic() *peerTraffic {
+	return &peerTraffic{
+		total:     newTrafficMeter(),
+		protocols: make(map[string]*protocolTraffic),
+	}
+}
+
+// meters returns the meters of a protocol and one of its message codes,
+// creating them on first use.
+func (t *peerTraffic) meters(protocol string, code uint64) (*trafficMeter, *trafficMeter) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	proto := t.protocols[protocol]
+	if proto == nil {
+		proto = &protocolTraffic{total: newTrafficMeter(), msgs: make(map[uint64]*trafficMeter)}
+		t.protocols[protocol] = proto
+	}
+	msg := proto.msgs[code]
+	if msg == nil {
+		msg = newTrafficMeter()
+		if metricutils.Enabled {
+			prefix := fmt.Sprintf("p2p/%s/%d/", protocol, code)
+			msg.ingressMeter = metrics.GetOrRegisterMeter(prefix+"InboundTraffic", nil)
+			msg.egressMeter = metrics.GetOrRegisterMeter(prefix+"OutboundTraffic", nil)
+		}
+		proto.msgs[code] = msg
+	}
+	return proto.total, msg
+}
+
+// markIngress records a received message of a sub-protocol. The code is
+// relative to the protocol offset.
+func (t *peerTraffic) markIngress(protocol string, code uint64, size uint32) {
+	if t == nil {
+		return
+	}
+	proto, msg := t.meters(protocol, code)
+	t.total.markIngress(size)
+	proto.markIngress(size)
+	msg.markIngress(size)
+}
+
+// markEgress records a sent message of a sub-protocol. The code is relative
+// to the protocol offset.
+func (t *peerTraffic) markEgress(protocol string, code uint64, size uint32) {
+	if t == nil {
+		return
+	}
+	proto, msg := t.meters(protocol, code)
+	t.total.markEgress(size)
+	proto.markEgress(size)
+	msg.markEgress(size)
+}
+
+func (t *peerTraffic) info() *TrafficInfo {
+	info := &TrafficInfo{
+		TrafficStats: *t.total.stats(),
+		Protocols:    make(map[string]*ProtocolTraffic),
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for name, proto := range t.protocols {
+		protoInfo := &ProtocolTraffic{
+			TrafficStats: *proto.total.stats(),
+			Messages:     make(map[uint64]*TrafficStats, len(proto.msgs)),
+		}
+		for code, msg := range proto.msgs {
+			protoInfo.Messages[code] = msg.stats()
+		}
+		info.Protocols[name] = protoInfo
+	}
+	return info
+}
@@ -0,0 +1,147 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/mclock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBandwidthLimits(t *testing.T) {
+	limits, err := ParseBandwidthLimits(" en=1048576, PN = 8388608,,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]uint64{"en": 1048576, "pn": 8388608}, limits)
+
+	for _, input := range []string{"en", "xn=100", "en=-1", "en=1MB"} {
+		_, err := ParseBandwidthLimits(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(1000, egressThrottleCounter)
+	start := l.last
+
+	// The bucket starts full and messages may go into debt.
+	assert.Equal(t, time.Duration(0), l.reserve(600, start))
+	assert.Equal(t, 100*time.Millisecond, l.reserve(500, start))
+	assert.Equal(t, 2100*time.Millisecond, l.reserve(2000, start))
+
+	// The debt is paid off over time, and the bucket never exceeds a second.
+	now := start + mclock.AbsTime(2100*time.Millisecond)
+	assert.Equal(t, time.Duration(0), l.reserve(0, now))
+	now += mclock.AbsTime(time.Hour)
+	assert.Equal(t, time.Duration(0), l.reserve(1000, now))
+	assert.Equal(t, time.Millisecond, l.reserve(1, now))
+
+	// A nil limiter doesn't block and a closed one returns.
+	assert.NoError(t, (*rateLimiter)(nil).wait(1<<30, nil))
+	closed := make(chan struct{})
+	close(closed)
+	assert.Error(t, l.wait(1000, closed))
+}
+
+func TestPeerTraffic(t *testing.T) {
+	proto := Protocol{
+		Name:   "a",
+		Length: 5,
+		Run: func(peer *Peer, rw MsgReadWriter) error {
+			for i := 0; i < 2; i++ {
+				if err := ExpectMsg(rw, 2, []uint{1}); err != nil {
+					t.Error(err)
+				}
+			}
+			return SendItems(rw, 3, "foo")
+		},
+	}
+	closer, rw, peer, errc := testPeer([]Protocol{proto})
+	defer closer()
+
+	Send(rw, baseProtocolLength+2, []uint{1})
+	Send(rw, baseProtocolLength+2, []uint{1})
+	if err := ExpectMsg(rw, baseProtocolLength+3, []string{"foo"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-errc:
+	case <-time.After(2 * time.Second):
+		t.Fatal("protocol did not return")
+	}
+
+	info := peer.Info().Traffic
+	require.NotNil(t, info)
+	assert.Equal(t, uint64(2), info.IngressMsgs)
+	assert.Equal(t, uint64(1), info.EgressMsgs)
+	assert.Greater(t, info.IngressByteRate, 0.0)
+	assert.Zero(t, info.IngressLimit)
+
+	require.Contains(t, info.Protocols, "a")
+	protoInfo := info.Protocols["a"]
+	assert.Equal(t, info.TrafficStats.IngressBytes, protoInfo.IngressBytes)
+	require.Contains(t, protoInfo.Messages, uint64(2))
+	require.Contains(t, protoInfo.Messages, uint64(3))
+	assert.Equal(t, uint64(2), protoInfo.Messages[2].IngressMsgs)
+	assert.Equal(t, uint64(0), protoInfo.Messages[2].EgressMsgs)
+	assert.Equal(t, uint64(1), protoInfo.Messages[3].EgressMsgs)
+	assert.Equal(t, info.EgressBytes, protoInfo.Messages[3].EgressBytes)
+}
+
+func TestServerSetupPeerLimits(t *testing.T) {
+	newTestPeer := func(ct common.ConnType) *Peer {
+		fd, _ := net.Pipe()
+		p, err := newPeer([]*conn{{fd: fd, id: randomID(), conntype: ct}}, nil, defaultRWTimerConfig)
+		require.NoError(t, err)
+		return p
+	}
+	srv := &BaseServer{Config: Config{
+		ConnectionType: common.CONSENSUSNODE,
+		IngressLimits:  map[string]uint64{"cn": 100, "en": 200},
+		EgressLimits:   map[string]uint64{"en": 300},
+	}, reputation: newReputation(nil)}
+
+	// Connections between consensus nodes are never limited nor penalized.
+	cn := newTestPeer(common.CONSENSUSNODE)
+	srv.setupPeer(cn)
+	assert.Nil(t, cn.rws[ConnDefault].ingress)
+	assert.Nil(t, cn.rws[ConnDefault].egress)
+	assert.Nil(t, cn.reputation)
+
+	en := newTestPeer(common.ENDPOINTNODE)
+	srv.setupPeer(en)
+	assert.Equal(t, srv.reputation, en.reputation)
+	info := en.Info().Traffic
+	assert.Equal(t, uint64(200), info.IngressLimit)
+	assert.Equal(t, uint64(300), info.EgressLimit)
+
+	pn := newTestPeer(common.PROXYNODE)
+	srv.setupPeer(pn)
+	assert.Nil(t, pn.rws[ConnDefault].ingress)
+	assert.Nil(t, pn.rws[ConnDefault].egress)
+
+	// A proxy node limits the consensus nodes as configured.
+	srv.ConnectionType = common.PROXYNODE
+	cn = newTestPeer(common.CONSENSUSNODE)
+	srv.setupPeer(cn)
+	assert.Equal(t, uint64(100), cn.Info().Traffic.IngressLimit)
+	assert.Equal(t, srv.reputation, cn.reputation)
+}
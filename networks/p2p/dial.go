@@ -97,6 +97,7 @@ type dialstate struct {
 	maxDynDials int
 	ntab        discover.Discovery
 	netrestrict *netutil.Netlist
+	reputation  *reputation
 
 	lookupRunning      bool
 	typedLookupRunning map[dialType]bool
@@ -436,6 +437,7 @@ var (
 	errAlreadyConnected   = errors.New("already connected")
 	errRecentlyDialed     = errors.New("recently dialed")
 	errNotWhitelisted     = errors.New("not contained in netrestrict whitelist")
+	errBanned             = errors.New("is banned")
 	errExpired            = errors.New("is expired")
 	errExceedMaxTypedDial = errors.New("exceeded max typed dial")
 	errUpdateDial         = errors.New("updated to be multichannel peer")
@@ -452,6 +454,8 @@ func (s *dialstate) checkDial(n *discover.Node, peers map[discover.NodeID]*Peer)
 		return errSelf
 	case s.netrestrict != nil && !s.netrestrict.Contains(n.IP):
 		return errNotWhitelisted
+	case s.reputation != nil && s.reputation.isBanned(n.ID, n.IP):
+		return errBanned
 	case s.hist.contains(n.ID):
 		return errRecentlyDialed
 	}
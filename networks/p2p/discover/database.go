@@ -33,6 +33,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/rlp"
@@ -63,6 +64,7 @@ type nodeDB struct {
 var (
 	nodeDBVersionKey = []byte("version") // Version of the database to flush if changes
 	nodeDBItemPrefix = []byte("n:")      // Identifier to prefix node entries with
+	nodeDBBanPrefix  = []byte("ban:")    // Identifier to prefix peer bans with
 
 	nodeDBDiscoverRoot      = ":discover"
 	nodeDBDiscoverPing      = nodeDBDiscoverRoot + ":lastping"
@@ -372,6 +374,72 @@ func nextNode(db *nodeDB, it iterator.Iterator) *Node {
 	return nil
 }
 
+// makeBanKey generates the leveldb key-blob of the ban of a target.
+func makeBanKey(target string) []byte {
+	key := make([]byte, 0, len(nodeDBBanPrefix)+len(target))
+	return append(append(key, nodeDBBanPrefix...), target...)
+}
+
+// bans retrieves all peer bans, keyed by the banned target. The bans are not
+// expired with the nodes, so they survive restarts.
+func (db *nodeDB) bans() map[string][]byte {
+	it := db.lvl.NewIterator(util.BytesPrefix(nodeDBBanPrefix), nil)
+	defer it.Release()
+
+	bans := make(map[string][]byte)
+	for it.Next() {
+		target := string(it.Key()[len(nodeDBBanPrefix):])
+		bans[target] = common.CopyBytes(it.Value())
+	}
+	return bans
+}
+
+// updateBan inserts - potentially overwriting - the ban of a target.
+func (db *nodeDB) updateBan(target string, blob []byte) error {
+	return db.lvl.Put(makeBanKey(target), blob, nil)
+}
+
+// deleteBan deletes the ban of a target.
+func (db *nodeDB) deleteBan(target string) error {
+	return db.lvl.Delete(makeBanKey(target), nil)
+}
+
+// BanDB is the node database opened without a discovery table, which persists
+// the peer bans when the discovery is disabled.
+type BanDB struct {
+	db *nodeDB
+}
+
+// OpenBanDB opens the node database in the given path to persist the peer bans.
+// If no path is given, an in-memory, temporary database is constructed.
+func OpenBanDB(path string, self NodeID) (*BanDB, error) {
+	db, err := newNodeDB(path, Version, self)
+	if err != nil {
+		return nil, err
+	}
+	return &BanDB{db: db}, nil
+}
+
+// GetBans returns the peer bans in the database, keyed by the banned target.
+func (b *BanDB) GetBans() map[string][]byte {
+	return b.db.bans()
+}
+
+// PutBan inserts - potentially overwriting - the ban of a target into the database.
+func (b *BanDB) PutBan(target string, blob []byte) error {
+	return b.db.updateBan(target, blob)
+}
+
+// DeleteBan deletes the ban of a target from the database.
+func (b *BanDB) DeleteBan(target string) error {
+	return b.db.deleteBan(target)
+}
+
+// Close flushes and closes the database files.
+func (b *BanDB) Close() {
+	b.db.close()
+}
+
 // close flushes and closes the database files.
 func (db *nodeDB) close() {
 	close(db.quit)
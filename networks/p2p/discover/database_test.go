@@ -400,3 +400,43 @@ func TestNodeDBSelfExpiration(t *testing.T) {
 		t.Errorf("self not evacuated")
 	}
 }
+
+func TestNodeDBBans(t *testing.T) {
+	root, err := os.MkdirTemp("", "nodedb-")
+	if err != nil {
+		t.Fatalf("failed to create temporary data folder: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	db, err := newNodeDB(filepath.Join(root, "database"), Version, NodeID{})
+	if err != nil {
+		t.Fatalf("failed to create persistent database: %v", err)
+	}
+	bans := map[string][]byte{"10.0.0.1": []byte("ip"), "0a1b": []byte("id")}
+	for target, blob := range bans {
+		if err := db.updateBan(target, blob); err != nil {
+			t.Fatalf("failed to store ban: %v", err)
+		}
+	}
+	// Bans are not expired with the nodes.
+	if err := db.expireNodes(); err != nil {
+		t.Fatalf("failed to expire nodes: %v", err)
+	}
+	db.close()
+
+	db, err = newNodeDB(filepath.Join(root, "database"), Version, NodeID{})
+	if err != nil {
+		t.Fatalf("failed to open persistent database: %v", err)
+	}
+	defer db.close()
+	if have := db.bans(); !reflect.DeepEqual(have, bans) {
+		t.Fatalf("bans mismatch: have %v, want %v", have, bans)
+	}
+	if err := db.deleteBan("10.0.0.1"); err != nil {
+		t.Fatalf("failed to delete ban: %v", err)
+	}
+	delete(bans, "10.0.0.1")
+	if have := db.bans(); !reflect.DeepEqual(have, bans) {
+		t.Fatalf("bans mismatch: have %v, want %v", have, bans)
+	}
+}
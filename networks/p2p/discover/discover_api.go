@@ -110,3 +110,18 @@ func (tab *Table) DeleteAuthorizedNodes(nodes []*Node) {
 		}
 	}
 }
+
+// GetBans returns the peer bans in peer database, keyed by the banned target.
+func (tab *Table) GetBans() map[string][]byte {
+	return tab.db.bans()
+}
+
+// PutBan inserts - potentially overwriting - the ban of a target into the peer database.
+func (tab *Table) PutBan(target string, blob []byte) error {
+	return tab.db.updateBan(target, blob)
+}
+
+// DeleteBan deletes the ban of a target from the peer database.
+func (tab *Table) DeleteBan(target string) error {
+	return tab.db.deleteBan(target)
+}
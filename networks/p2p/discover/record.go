@@ -0,0 +1,197 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"crypto/ecdsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// recordMaxSize is the maximum encoded size of a node record.
+const recordMaxSize = 300
+
+var (
+	errRecordTooBig     = fmt.Errorf("node record larger than %d bytes", recordMaxSize)
+	errRecordUnsigned   = errors.New("node record is not signed")
+	errRecordBadPrefix  = errors.New("node record should start with \"knr:\"")
+	errRecordIncomplete = errors.New("node record has no IP or ports")
+)
+
+// Record is a signed node record. It carries what other nodes need to dial the
+// node: the endpoint, the TCP subports used for multichannel, the node type and
+// the chain ID. A node publishes a new record with an increased sequence
+// number whenever any of them changes, so peers can tell which one is newer.
+//
+// The fields of Record may not be modified after it's signed.
+type Record struct {
+	Seq     uint64
+	ChainID uint64
+	IP      net.IP   // len 4 for IPv4 or 16 for IPv6
+	UDP     uint16   // discovery port number
+	TCP     uint16   // main TCP listening port number
+	TCPs    []uint16 // TCP listening port numbers including both main port and subports
+	NType   NodeType // the node's type (cn, pn, en, bn)
+
+	id        NodeID // recovered from the signature
+	signature []byte
+	raw       []byte // signed content, kept verbatim to preserve unknown fields
+}
+
+// recordContent is the signed part of a record.
+type recordContent struct {
+	Seq     uint64
+	ChainID uint64
+	IP      net.IP
+	UDP     uint16
+	TCP     uint16
+	TCPs    []uint16
+	NType   NodeType
+	// Ignore additional fields (for forward compatibility).
+	Rest []rlp.RawValue `rlp:"tail"`
+}
+
+// recordRLP is the encoding of a record.
+type recordRLP struct {
+	Signature []byte
+	Content   rlp.RawValue
+}
+
+func (r *Record) content() recordContent {
+	return recordContent{Seq: r.Seq, ChainID: r.ChainID, IP: r.IP, UDP: r.UDP, TCP: r.TCP, TCPs: r.TCPs, NType: r.NType}
+}
+
+// Sign signs the record with the given key, which determines the node ID.
+func (r *Record) Sign(priv *ecdsa.PrivateKey) error {
+	if ipv4 := r.IP.To4(); ipv4 != nil {
+		r.IP = ipv4
+	}
+	content, err := rlp.EncodeToBytes(r.content())
+	if err != nil {
+		return err
+	}
+	sig, err := crypto.Sign(crypto.Keccak256(content), priv)
+	if err != nil {
+		return err
+	}
+	enc, err := rlp.EncodeToBytes(&recordRLP{Signature: sig, Content: content})
+	if err != nil {
+		return err
+	}
+	if len(enc) > recordMaxSize {
+		return errRecordTooBig
+	}
+	r.id, r.signature, r.raw = PubkeyID(&priv.PublicKey), sig, content
+	return nil
+}
+
+// ID returns the ID of the node which signed the record.
+func (r *Record) ID() NodeID {
+	return r.id
+}
+
+// Signed returns true if the record has been signed or decoded.
+func (r *Record) Signed() bool {
+	return r.signature != nil
+}
+
+// Node returns the node described by the record.
+func (r *Record) Node() *Node {
+	var tcps []uint16
+	if len(r.TCPs) > 0 {
+		tcps = append(tcps, r.TCPs...)
+	}
+	return NewNode(r.id, r.IP, r.UDP, r.TCP, tcps, r.NType)
+}
+
+// validateComplete checks whether the record describes a node which can be dialed.
+func (r *Record) validateComplete() error {
+	if r.IP == nil || r.UDP == 0 || r.TCP == 0 {
+		return errRecordIncomplete
+	}
+	return r.Node().validateComplete()
+}
+
+// EncodeRLP implements rlp.Encoder.
+func (r *Record) EncodeRLP(w io.Writer) error {
+	if !r.Signed() {
+		return errRecordUnsigned
+	}
+	return rlp.Encode(w, &recordRLP{Signature: r.signature, Content: r.raw})
+}
+
+// DecodeRLP implements rlp.Decoder. It verifies the signature of the record.
+func (r *Record) DecodeRLP(s *rlp.Stream) error {
+	raw, err := s.Raw()
+	if err != nil {
+		return err
+	}
+	if len(raw) > recordMaxSize {
+		return errRecordTooBig
+	}
+	var dec recordRLP
+	if err := rlp.DecodeBytes(raw, &dec); err != nil {
+		return err
+	}
+	var content recordContent
+	if err := rlp.DecodeBytes(dec.Content, &content); err != nil {
+		return err
+	}
+	id, err := recoverNodeID(crypto.Keccak256(dec.Content), dec.Signature)
+	if err != nil {
+		return err
+	}
+	*r = Record{
+		Seq: content.Seq, ChainID: content.ChainID,
+		IP: content.IP, UDP: content.UDP, TCP: content.TCP, TCPs: content.TCPs, NType: content.NType,
+		id: id, signature: dec.Signature, raw: dec.Content,
+	}
+	return nil
+}
+
+// String returns the textual form of the record, "knr:" followed by the
+// base64 encoding of the record.
+func (r *Record) String() string {
+	enc, err := rlp.EncodeToBytes(r)
+	if err != nil {
+		return "knr:invalid"
+	}
+	return "knr:" + base64.RawURLEncoding.EncodeToString(enc)
+}
+
+// ParseRecord decodes the textual form of a record and verifies its signature.
+func ParseRecord(s string) (*Record, error) {
+	if !strings.HasPrefix(s, "knr:") {
+		return nil, errRecordBadPrefix
+	}
+	enc, err := base64.RawURLEncoding.DecodeString(s[4:])
+	if err != nil {
+		return nil, err
+	}
+	r := new(Record)
+	if err := rlp.DecodeBytes(enc, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"net"
+	"testing"
+
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord_SignAndParse(t *testing.T) {
+	key := newkey()
+	rec := &Record{
+		Seq:     3,
+		ChainID: 8217,
+		IP:      net.ParseIP("10.3.58.6"),
+		UDP:     32323,
+		TCP:     32323,
+		TCPs:    []uint16{32323, 32324},
+		NType:   NodeTypePN,
+	}
+	_, err := rlp.EncodeToBytes(rec)
+	assert.Equal(t, errRecordUnsigned, err)
+	require.NoError(t, rec.Sign(key))
+
+	parsed, err := ParseRecord(rec.String())
+	require.NoError(t, err)
+	assert.Equal(t, PubkeyID(&key.PublicKey), parsed.ID())
+	assert.Equal(t, rec.Seq, parsed.Seq)
+	assert.Equal(t, rec.ChainID, parsed.ChainID)
+	assert.Equal(t, rec.TCPs, parsed.TCPs)
+	assert.Equal(t, NodeTypePN, parsed.NType)
+
+	n := parsed.Node()
+	assert.Equal(t, rec.ID(), n.ID)
+	assert.Equal(t, "10.3.58.6", n.IP.String())
+	assert.Equal(t, []uint16{32323, 32324}, n.TCPs)
+	assert.NoError(t, parsed.validateComplete())
+
+	_, err = ParseRecord("kni://" + rec.String()[4:])
+	assert.Equal(t, errRecordBadPrefix, err)
+}
+
+// TestRecord_Tampered tests whether a record modified after signing is
+// attributed to another node.
+func TestRecord_Tampered(t *testing.T) {
+	key := newkey()
+	rec := &Record{Seq: 1, IP: net.ParseIP("10.3.58.6"), UDP: 32323, TCP: 32323, NType: NodeTypeEN}
+	require.NoError(t, rec.Sign(key))
+
+	var enc recordRLP
+	require.NoError(t, rlp.DecodeBytes(mustEncode(t, rec), &enc))
+	content := rec.content()
+	content.NType = NodeTypeCN
+	enc.Content = mustEncode(t, content)
+
+	var tampered Record
+	if err := rlp.DecodeBytes(mustEncode(t, &enc), &tampered); err == nil {
+		assert.NotEqual(t, rec.ID(), tampered.ID())
+	}
+}
+
+// TestRecord_ExtraFields tests whether a record with fields unknown to this
+// version is re-encoded as signed.
+func TestRecord_ExtraFields(t *testing.T) {
+	key := newkey()
+	content := recordContent{Seq: 1, IP: net.ParseIP("10.3.58.6").To4(), UDP: 32323, TCP: 32323, NType: NodeTypeEN}
+	content.Rest = []rlp.RawValue{mustEncode(t, "future field")}
+	raw := mustEncode(t, content)
+	sig, err := crypto.Sign(crypto.Keccak256(raw), key)
+	require.NoError(t, err)
+	enc := mustEncode(t, &recordRLP{Signature: sig, Content: raw})
+
+	var rec Record
+	require.NoError(t, rlp.DecodeBytes(enc, &rec))
+	assert.Equal(t, PubkeyID(&key.PublicKey), rec.ID())
+	assert.Equal(t, enc, mustEncode(t, &rec))
+
+	parsed, err := ParseRecord(rec.String())
+	require.NoError(t, err)
+	assert.Equal(t, rec.ID(), parsed.ID())
+}
+
+func TestRecord_TooBig(t *testing.T) {
+	rec := &Record{IP: net.ParseIP("10.3.58.6"), UDP: 32323, TCP: 32323}
+	for i := 0; i < 100; i++ {
+		rec.TCPs = append(rec.TCPs, uint16(32323+i))
+	}
+	assert.Equal(t, errRecordTooBig, rec.Sign(newkey()))
+}
+
+func mustEncode(t *testing.T, val interface{}) []byte {
+	enc, err := rlp.EncodeToBytes(val)
+	require.NoError(t, err)
+	return enc
+}
@@ -0,0 +1,209 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	mrand "math/rand"
+	"sort"
+	"sync"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+)
+
+const (
+	nBucketsV5           = common.HashLength * 8 // one bucket per log distance except zero
+	maxRevalidateFailure = 3                     // records failing to answer this many pings in a row are dropped
+)
+
+// tableV5 keeps the node records known to the discovery v5 protocol in
+// buckets of log distances from the local node. Unlike Table, it keeps the
+// records of all node types in the same buckets.
+type tableV5 struct {
+	self    common.Hash
+	buckets [nBucketsV5][]*recordEntry
+	rand    *mrand.Rand
+	mu      sync.Mutex
+}
+
+type recordEntry struct {
+	rec      *Record
+	sha      common.Hash
+	failures int // number of revalidation failures in a row
+}
+
+func newTableV5(self NodeID, seed int64) *tableV5 {
+	return &tableV5{
+		self: crypto.Keccak256Hash(self[:]),
+		rand: mrand.New(mrand.NewSource(seed)),
+	}
+}
+
+func (tab *tableV5) bucket(sha common.Hash) *[]*recordEntry {
+	d := logdist(tab.self, sha)
+	if d == 0 {
+		return nil
+	}
+	return &tab.buckets[d-1]
+}
+
+// add inserts the record, or replaces the known record of the node if the
+// given one is newer. It returns false if the bucket of the node is full.
+func (tab *tableV5) add(rec *Record) bool {
+	id := rec.ID()
+	sha := crypto.Keccak256Hash(id[:])
+
+	tab.mu.Lock()
+	defer tab.mu.Unlock()
+
+	b := tab.bucket(sha)
+	if b == nil {
+		return false
+	}
+	for _, e := range *b {
+		if e.rec.ID() == id {
+			if rec.Seq > e.rec.Seq {
+				e.rec = rec
+			}
+			return true
+		}
+	}
+	if len(*b) >= bucketSize {
+		return false
+	}
+	*b = append(*b, &recordEntry{rec: rec, sha: sha})
+	return true
+}
+
+// get returns the known record of the node, or nil if it's not in the table.
+func (tab *tableV5) get(id NodeID) *Record {
+	tab.mu.Lock()
+	defer tab.mu.Unlock()
+
+	if e := tab.find(id); e != nil {
+		return e.rec
+	}
+	return nil
+}
+
+func (tab *tableV5) find(id NodeID) *recordEntry {
+	b := tab.bucket(crypto.Keccak256Hash(id[:]))
+	if b == nil {
+		return nil
+	}
+	for _, e := range *b {
+		if e.rec.ID() == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// revalidated records the result of pinging the node, and drops the node if
+// it has failed too many times in a row.
+func (tab *tableV5) revalidated(id NodeID, alive bool) {
+	tab.mu.Lock()
+	defer tab.mu.Unlock()
+
+	e := tab.find(id)
+	if e == nil {
+		return
+	}
+	if alive {
+		e.failures = 0
+		return
+	}
+	if e.failures++; e.failures >= maxRevalidateFailure {
+		b := tab.bucket(e.sha)
+		for i := range *b {
+			if (*b)[i] == e {
+				*b = append((*b)[:i], (*b)[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// atDistances returns at most max records at the given log distances.
+func (tab *tableV5) atDistances(distances []uint, max int) []*Record {
+	tab.mu.Lock()
+	defer tab.mu.Unlock()
+
+	var recs []*Record
+	for _, d := range distances {
+		if d == 0 || d > nBucketsV5 {
+			continue
+		}
+		for _, e := range tab.buckets[d-1] {
+			if len(recs) >= max {
+				return recs
+			}
+			recs = append(recs, e.rec)
+		}
+	}
+	return recs
+}
+
+// closest returns at most max records closest to the target.
+func (tab *tableV5) closest(target common.Hash, max int) []*Record {
+	tab.mu.Lock()
+	var entries []*recordEntry
+	for _, b := range tab.buckets {
+		entries = append(entries, b...)
+	}
+	tab.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return distcmp(target, entries[i].sha, entries[j].sha) < 0
+	})
+	if len(entries) > max {
+		entries = entries[:max]
+	}
+	recs := make([]*Record, len(entries))
+	for i, e := range entries {
+		recs[i] = e.rec
+	}
+	return recs
+}
+
+// randomRecords returns the records of the given node type in random order.
+// NodeTypeUnknown matches all node types.
+func (tab *tableV5) randomRecords(nType NodeType) []*Record {
+	tab.mu.Lock()
+	defer tab.mu.Unlock()
+
+	var recs []*Record
+	for _, b := range tab.buckets {
+		for _, e := range b {
+			if nType == NodeTypeUnknown || e.rec.NType == nType {
+				recs = append(recs, e.rec)
+			}
+		}
+	}
+	tab.rand.Shuffle(len(recs), func(i, j int) { recs[i], recs[j] = recs[j], recs[i] })
+	return recs
+}
+
+func (tab *tableV5) len() (n int) {
+	tab.mu.Lock()
+	defer tab.mu.Unlock()
+
+	for _, b := range tab.buckets {
+		n += len(b)
+	}
+	return n
+}
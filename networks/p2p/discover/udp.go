@@ -51,6 +51,7 @@ var (
 	errClosed           = errors.New("socket closed")
 	errUnauthorized     = errors.New("unauthorized node")
 	errMismatchNetwork  = errors.New("mismatch network id")
+	errPacketV5         = errors.New("discovery v5 packet")
 )
 
 // Timeouts
@@ -281,6 +282,10 @@ type Config struct {
 	// These settings are required for discovery packet control
 	MaxNeighborsNode uint
 	AuthorizedNodes  []*Node
+
+	// These settings are announced in the node record of discovery v5
+	ChainID uint64   // chain ID of the node
+	TCPs    []uint16 // TCP listening ports including both main port and subports
 }
 
 // ListenUDP returns a new table that listens for UDP packets on laddr.
@@ -618,8 +623,11 @@ func (t *udp) readLoop(unhandled chan<- ReadPacket) {
 			return
 		}
 		if t.handlePacket(from, buf[:nbytes]) != nil && unhandled != nil {
+			// Copy the packet since buf is reused for the next one.
+			data := make([]byte, nbytes)
+			copy(data, buf[:nbytes])
 			select {
-			case unhandled <- ReadPacket{buf[:nbytes], from}:
+			case unhandled <- ReadPacket{data, from}:
 			default:
 			}
 		}
@@ -627,6 +635,10 @@ func (t *udp) readLoop(unhandled chan<- ReadPacket) {
 }
 
 func (t *udp) handlePacket(from *net.UDPAddr, buf []byte) error {
+	if isPacketV5(buf) {
+		// Pass the packet to discovery v5 through the unhandled channel.
+		return errPacketV5
+	}
 	packet, fromID, hash, err := decodePacket(buf)
 	if err != nil {
 		logger.Warn("Bad discv4 packet", "addr", from, "err", err)
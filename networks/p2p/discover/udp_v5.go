@@ -0,0 +1,883 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	crand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/networks/p2p/netutil"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// Discovery v5 packets share the UDP socket with the legacy protocol. They
+// start with a magic instead of a hash, so the legacy protocol passes them to
+// the discovery v5 protocol through Config.Unhandled.
+var packetMagicV5 = []byte("kaiadsv5")
+
+const (
+	headSizeV5 = 8 + sigSize // magic and signature
+
+	maxRecordsPerPacket = (1280 - headSizeV5 - 32) / recordMaxSize // records in a nodes packet
+	bondExpirationV5    = 24 * time.Hour                           // time after which an endpoint proof should be renewed
+)
+
+var (
+	errNotPacketV5    = errors.New("not a discovery v5 packet")
+	errBadRecord      = errors.New("invalid node record")
+	errMismatchChain  = errors.New("mismatch chain id")
+	errUnknownRequest = errors.New("unknown request id")
+)
+
+// RPC packet types of discovery v5
+const (
+	pingPacketV5 = iota + 1 // zero is 'reserved'
+	pongPacketV5
+	findnodePacketV5
+	nodesPacketV5
+)
+
+// RPC request structures of discovery v5. Each request carries a request ID
+// which is echoed in the reply.
+type (
+	pingV5 struct {
+		ReqID      uint64
+		Seq        uint64 // sequence number of the sender's record
+		NetworkID  uint64
+		Expiration uint64
+		// Ignore additional fields (for forward compatibility).
+		Rest []rlp.RawValue `rlp:"tail"`
+	}
+
+	// pongV5 is the reply to pingV5.
+	pongV5 struct {
+		ReqID      uint64
+		Seq        uint64 // sequence number of the sender's record
+		ToIP       net.IP // the UDP envelope address of the ping packet
+		ToPort     uint16
+		Expiration uint64
+		// Ignore additional fields (for forward compatibility).
+		Rest []rlp.RawValue `rlp:"tail"`
+	}
+
+	// findnodeV5 is a query for the records at the given log distances from
+	// the recipient. Distance zero means the record of the recipient itself.
+	findnodeV5 struct {
+		ReqID      uint64
+		Distances  []uint
+		Expiration uint64
+		// Ignore additional fields (for forward compatibility).
+		Rest []rlp.RawValue `rlp:"tail"`
+	}
+
+	// nodesV5 is the reply to findnodeV5. The records are sent across Total packets.
+	nodesV5 struct {
+		ReqID      uint64
+		Total      uint8
+		Records    []*Record
+		Expiration uint64
+		// Ignore additional fields (for forward compatibility).
+		Rest []rlp.RawValue `rlp:"tail"`
+	}
+)
+
+// UDPv5 implements the discovery v5 protocol. Nodes exchange signed node
+// records instead of endpoints, and look up nodes by log distances.
+type UDPv5 struct {
+	conn        conn
+	priv        *ecdsa.PrivateKey
+	self        NodeID
+	networkID   uint64
+	netrestrict *netutil.Netlist
+	bootnodes   []*Node
+	tab         *tableV5
+
+	recordMu sync.RWMutex
+	record   *Record
+
+	pendingMu sync.Mutex
+	pending   map[uint64]*pendingV5
+
+	// Endpoint proofs. A node is verified once it answers our ping from its
+	// address, and findnode requests are answered only for verified nodes so
+	// the protocol can't be used to amplify traffic to a spoofed address.
+	bondMu      sync.Mutex
+	verified    map[NodeID]endpointProof
+	pingedBy    map[NodeID]time.Time
+	pingWaiters map[NodeID][]chan struct{}
+	bondslots   chan struct{}
+
+	closing   chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+type pendingV5 struct {
+	from  NodeID
+	ptype byte
+	ch    chan interface{}
+}
+
+type endpointProof struct {
+	addr string
+	time time.Time
+}
+
+// ListenV5 starts the discovery v5 protocol on the given connection, which is
+// usually shared with the legacy protocol. Config.ChainID and Config.TCPs are
+// announced in the local node record.
+func ListenV5(c conn, cfg *Config) (*UDPv5, error) {
+	realaddr := c.LocalAddr().(*net.UDPAddr)
+	if cfg.AnnounceAddr != nil {
+		realaddr = cfg.AnnounceAddr
+	}
+	rec := &Record{
+		Seq:     uint64(time.Now().Unix()),
+		ChainID: cfg.ChainID,
+		IP:      realaddr.IP,
+		UDP:     uint16(realaddr.Port),
+		TCP:     uint16(realaddr.Port),
+		NType:   cfg.NodeType,
+	}
+	if len(cfg.TCPs) > 0 {
+		rec.TCP, rec.TCPs = cfg.TCPs[0], cfg.TCPs
+	}
+	if err := rec.Sign(cfg.PrivateKey); err != nil {
+		return nil, err
+	}
+	for _, n := range cfg.Bootnodes {
+		if err := n.validateComplete(); err != nil {
+			return nil, fmt.Errorf("bad bootstrap node %q (%v)", n, err)
+		}
+	}
+
+	var seed [8]byte
+	crand.Read(seed[:])
+	t := &UDPv5{
+		conn:        c,
+		priv:        cfg.PrivateKey,
+		self:        rec.ID(),
+		networkID:   cfg.NetworkID,
+		netrestrict: cfg.NetRestrict,
+		bootnodes:   cfg.Bootnodes,
+		tab:         newTableV5(rec.ID(), int64(binary.BigEndian.Uint64(seed[:]))),
+		record:      rec,
+		pending:     make(map[uint64]*pendingV5),
+		verified:    make(map[NodeID]endpointProof),
+		pingedBy:    make(map[NodeID]time.Time),
+		pingWaiters: make(map[NodeID][]chan struct{}),
+		bondslots:   make(chan struct{}, maxBondingPingPongs),
+		closing:     make(chan struct{}),
+	}
+	for i := 0; i < cap(t.bondslots); i++ {
+		t.bondslots <- struct{}{}
+	}
+	t.wg.Add(1)
+	go t.loop()
+	go t.readLoop()
+	logger.Info("Discovery v5 listener up", "self", t.Self(), "record", rec)
+	return t, nil
+}
+
+// Close stops the protocol. The shared connection is not closed.
+func (t *UDPv5) Close() {
+	t.closeOnce.Do(func() {
+		close(t.closing)
+		t.wg.Wait()
+	})
+}
+
+// Self returns the local node.
+func (t *UDPv5) Self() *Node {
+	return t.LocalRecord().Node()
+}
+
+// LocalRecord returns the current record of the local node.
+func (t *UDPv5) LocalRecord() *Record {
+	t.recordMu.RLock()
+	defer t.recordMu.RUnlock()
+	return t.record
+}
+
+// UpdateRecord applies fn to a copy of the local record, and publishes it with
+// an increased sequence number. Peers fetch the new record when they notice
+// the sequence number in the next ping or pong.
+func (t *UDPv5) UpdateRecord(fn func(r *Record)) error {
+	t.recordMu.Lock()
+	defer t.recordMu.Unlock()
+
+	rec := *t.record
+	rec.TCPs = append([]uint16(nil), t.record.TCPs...)
+	fn(&rec)
+	rec.Seq = t.record.Seq + 1
+	if err := rec.Sign(t.priv); err != nil {
+		return err
+	}
+	t.record = &rec
+	return nil
+}
+
+// AllNodes returns all nodes in the table.
+func (t *UDPv5) AllNodes() []*Node {
+	return recordsToNodes(t.tab.randomRecords(NodeTypeUnknown), NodeTypeUnknown)
+}
+
+// ReadRandomNodes fills the given slice with random nodes of the given type
+// from the table. It returns the number of nodes filled.
+func (t *UDPv5) ReadRandomNodes(buf []*Node, nType NodeType) int {
+	return copy(buf, recordsToNodes(t.tab.randomRecords(nType), nType))
+}
+
+// GetNodes returns at most max nodes of the given type. It looks up the
+// network if the table doesn't have enough of them.
+func (t *UDPv5) GetNodes(nType NodeType, max int) []*Node {
+	nodes := recordsToNodes(t.tab.randomRecords(nType), nType)
+	if len(nodes) < max {
+		var target NodeID
+		crand.Read(target[:])
+		t.lookup(crypto.Keccak256Hash(target[:]))
+		nodes = recordsToNodes(t.tab.randomRecords(nType), nType)
+	}
+	if len(nodes) > max {
+		nodes = nodes[:max]
+	}
+	return nodes
+}
+
+// Lookup performs a network search for nodes close to the given target, and
+// returns the nodes of the given type among them. NodeTypeUnknown matches all
+// node types.
+func (t *UDPv5) Lookup(target NodeID, nType NodeType) []*Node {
+	return recordsToNodes(t.lookup(crypto.Keccak256Hash(target[:])), nType)
+}
+
+// Resolve searches for the newest record of the node with the given ID. It
+// returns nil if the node could not be found.
+func (t *UDPv5) Resolve(id NodeID, nType NodeType) *Node {
+	rec := t.tab.get(id)
+	if rec != nil {
+		if n, err := t.requestRecord(id, rec.Node().addr()); err == nil && n.Seq > rec.Seq {
+			t.tab.add(n)
+			rec = n
+		}
+	} else {
+		for _, r := range t.lookup(crypto.Keccak256Hash(id[:])) {
+			if r.ID() == id {
+				rec = r
+				break
+			}
+		}
+	}
+	if rec == nil || (nType != NodeTypeUnknown && rec.NType != nType) {
+		return nil
+	}
+	return rec.Node()
+}
+
+func recordsToNodes(recs []*Record, nType NodeType) []*Node {
+	nodes := make([]*Node, 0, len(recs))
+	for _, r := range recs {
+		if nType == NodeTypeUnknown || r.NType == nType {
+			nodes = append(nodes, r.Node())
+		}
+	}
+	return nodes
+}
+
+// lookup iteratively queries the nodes closest to the target, and returns at
+// most bucketSize records closest to it.
+func (t *UDPv5) lookup(target common.Hash) []*Record {
+	result := t.tab.closest(target, bucketSize)
+	if len(result) == 0 {
+		t.bootstrap()
+		result = t.tab.closest(target, bucketSize)
+	}
+	asked := map[NodeID]bool{t.self: true}
+	seen := map[NodeID]bool{t.self: true}
+	for _, r := range result {
+		seen[r.ID()] = true
+	}
+
+	reply := make(chan []*Record, alpha)
+	for {
+		pending := 0
+		for _, r := range result {
+			if pending >= alpha {
+				break
+			}
+			if asked[r.ID()] {
+				continue
+			}
+			asked[r.ID()] = true
+			pending++
+			go func(r *Record) {
+				sha := crypto.Keccak256Hash(r.id[:])
+				recs, err := t.findnode(r.ID(), r.Node().addr(), lookupDistances(target, sha))
+				if err != nil {
+					logger.Trace("Discovery v5 findnode failed", "id", r.ID(), "err", err)
+				}
+				reply <- recs
+			}(r)
+		}
+		if pending == 0 {
+			return result
+		}
+		for ; pending > 0; pending-- {
+			for _, r := range <-reply {
+				if seen[r.ID()] {
+					continue
+				}
+				seen[r.ID()] = true
+				t.tab.add(r)
+				result = append(result, r)
+			}
+		}
+		sort.Slice(result, func(i, j int) bool {
+			return distcmp(target, crypto.Keccak256Hash(result[i].id[:]), crypto.Keccak256Hash(result[j].id[:])) < 0
+		})
+		if len(result) > bucketSize {
+			result = result[:bucketSize]
+		}
+	}
+}
+
+// lookupDistances returns the log distances to query a node whose hash is
+// dest for the target.
+func lookupDistances(target, dest common.Hash) []uint {
+	td := logdist(target, dest)
+	dists := []uint{uint(td)}
+	for i := 1; len(dists) < 3; i++ {
+		if td+i <= nBucketsV5 {
+			dists = append(dists, uint(td+i))
+		}
+		if td-i > 0 {
+			dists = append(dists, uint(td-i))
+		}
+		if td+i > nBucketsV5 && td-i <= 0 {
+			break
+		}
+	}
+	return dists
+}
+
+// bootstrap fetches the records of the bootstrap nodes, and looks up the
+// local node to fill the table.
+func (t *UDPv5) bootstrap() {
+	var wg sync.WaitGroup
+	for _, n := range t.bootnodes {
+		wg.Add(1)
+		go func(n *Node) {
+			defer wg.Done()
+			rec, err := t.requestRecord(n.ID, n.addr())
+			if err != nil {
+				logger.Debug("Failed to fetch discovery v5 record of bootnode", "node", n, "err", err)
+				return
+			}
+			t.tab.add(rec)
+		}(n)
+	}
+	wg.Wait()
+}
+
+// loop runs in its own goroutine. It revalidates the table and refreshes it
+// periodically.
+func (t *UDPv5) loop() {
+	defer t.wg.Done()
+
+	var (
+		revalidate = time.NewTimer(revalidateInterval)
+		refresh    = time.NewTicker(refreshInterval)
+		refreshing = make(chan struct{}, 1)
+	)
+	defer revalidate.Stop()
+	defer refresh.Stop()
+
+	doRefresh := func() {
+		select {
+		case refreshing <- struct{}{}:
+		default:
+			return // already refreshing
+		}
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			defer func() { <-refreshing }()
+			t.lookup(crypto.Keccak256Hash(t.self[:]))
+		}()
+	}
+	doRefresh()
+
+	for {
+		select {
+		case <-revalidate.C:
+			t.expireBonds()
+			if recs := t.tab.randomRecords(NodeTypeUnknown); len(recs) > 0 {
+				t.wg.Add(1)
+				go func(rec *Record) {
+					defer t.wg.Done()
+					t.revalidate(rec)
+				}(recs[0])
+			}
+			revalidate.Reset(revalidateInterval)
+		case <-refresh.C:
+			doRefresh()
+		case <-t.closing:
+			return
+		}
+	}
+}
+
+// revalidate pings the node, and fetches its new record if the node has
+// published one.
+func (t *UDPv5) revalidate(rec *Record) {
+	pong, err := t.ping(rec.ID(), rec.Node().addr())
+	t.tab.revalidated(rec.ID(), err == nil)
+	if err == nil && pong.Seq > rec.Seq {
+		if n, err := t.requestRecord(rec.ID(), rec.Node().addr()); err == nil {
+			t.tab.add(n)
+		}
+	}
+}
+
+func (t *UDPv5) expireBonds() {
+	t.bondMu.Lock()
+	defer t.bondMu.Unlock()
+
+	for id, p := range t.verified {
+		if time.Since(p.time) > bondExpirationV5 {
+			delete(t.verified, id)
+		}
+	}
+	for id, tm := range t.pingedBy {
+		if time.Since(tm) > bondExpirationV5 {
+			delete(t.pingedBy, id)
+		}
+	}
+}
+
+func (t *UDPv5) isVerified(id NodeID, addr *net.UDPAddr) bool {
+	t.bondMu.Lock()
+	defer t.bondMu.Unlock()
+
+	p, ok := t.verified[id]
+	return ok && p.addr == addr.String() && time.Since(p.time) < bondExpirationV5
+}
+
+// ensureBond makes sure that the node has verified the local endpoint, by
+// pinging it and waiting for its ping back, so it answers findnode requests.
+func (t *UDPv5) ensureBond(id NodeID, addr *net.UDPAddr) error {
+	t.bondMu.Lock()
+	if tm, ok := t.pingedBy[id]; ok && time.Since(tm) < bondExpirationV5 {
+		t.bondMu.Unlock()
+		return nil
+	}
+	wait := make(chan struct{})
+	t.pingWaiters[id] = append(t.pingWaiters[id], wait)
+	t.bondMu.Unlock()
+
+	if _, err := t.ping(id, addr); err != nil {
+		t.bondMu.Lock()
+		delete(t.pingWaiters, id)
+		t.bondMu.Unlock()
+		return err
+	}
+	select {
+	case <-wait:
+	case <-time.After(respTimeout):
+		// The node might have verified the local endpoint before.
+	case <-t.closing:
+		return errClosed
+	}
+	return nil
+}
+
+// requestRecord fetches the record of the node.
+func (t *UDPv5) requestRecord(id NodeID, addr *net.UDPAddr) (*Record, error) {
+	recs, err := t.findnode(id, addr, []uint{0})
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) != 1 {
+		return nil, errBadRecord
+	}
+	return recs[0], nil
+}
+
+// ping sends a ping to the node and waits for its pong.
+func (t *UDPv5) ping(id NodeID, addr *net.UDPAddr) (*pongV5, error) {
+	req := &pingV5{
+		ReqID:      newReqID(),
+		Seq:        t.LocalRecord().Seq,
+		NetworkID:  t.networkID,
+		Expiration: uint64(time.Now().Add(expiration).Unix()),
+	}
+	p := t.addPending(id, req.ReqID, pongPacketV5)
+	defer t.removePending(req.ReqID)
+
+	pingMeter.Mark(1)
+	if err := t.send(addr, pingPacketV5, req); err != nil {
+		return nil, err
+	}
+	r, err := t.waitReply(p)
+	if err != nil {
+		return nil, err
+	}
+	pongMeter.Mark(1)
+	return r.(*pongV5), nil
+}
+
+// findnode sends a findnode request to the node and waits for all nodes
+// packets of the reply. Invalid records are dropped from the result. The node
+// only answers to verified endpoints, so the bond is ensured first for any
+// distance.
+func (t *UDPv5) findnode(id NodeID, addr *net.UDPAddr, distances []uint) ([]*Record, error) {
+	if err := t.ensureBond(id, addr); err != nil {
+		return nil, err
+	}
+	req := &findnodeV5{
+		ReqID:      newReqID(),
+		Distances:  distances,
+		Expiration: uint64(time.Now().Add(expiration).Unix()),
+	}
+	p := t.addPending(id, req.ReqID, nodesPacketV5)
+	defer t.removePending(req.ReqID)
+
+	findNodesMeter.Mark(1)
+	if err := t.send(addr, findnodePacketV5, req); err != nil {
+		return nil, err
+	}
+	var (
+		recs     []*Record
+		received int
+		sha      = crypto.Keccak256Hash(id[:])
+	)
+	for {
+		r, err := t.waitReply(p)
+		if err != nil {
+			return recs, err
+		}
+		reply := r.(*nodesV5)
+		for _, rec := range reply.Records {
+			if err := t.checkRecord(addr, sha, distances, rec); err != nil {
+				logger.Trace("Invalid discovery v5 record received", "addr", addr, "record", rec, "err", err)
+				continue
+			}
+			recs = append(recs, rec)
+		}
+		neighborsMeter.Mark(1)
+		if received++; received >= int(reply.Total) {
+			return recs, nil
+		}
+	}
+}
+
+// checkRecord checks whether the record received from sender is at one of
+// the requested distances and can be dialed.
+func (t *UDPv5) checkRecord(sender *net.UDPAddr, senderSha common.Hash, distances []uint, rec *Record) error {
+	id := rec.ID()
+	d := uint(logdist(senderSha, crypto.Keccak256Hash(id[:])))
+	found := false
+	for _, want := range distances {
+		found = found || d == want
+	}
+	if !found {
+		return fmt.Errorf("unrequested distance %d", d)
+	}
+	if local := t.LocalRecord(); rec.ChainID != local.ChainID {
+		return errMismatchChain
+	}
+	if rec.UDP <= 1024 {
+		return errors.New("low port")
+	}
+	if err := netutil.CheckRelayIP(sender.IP, rec.IP); err != nil {
+		return err
+	}
+	if t.netrestrict != nil && !t.netrestrict.Contains(rec.IP) {
+		return errors.New("not contained in netrestrict whitelist")
+	}
+	return rec.validateComplete()
+}
+
+func newReqID() uint64 {
+	var b [8]byte
+	crand.Read(b[:])
+	return binary.BigEndian.Uint64(b[:])
+}
+
+func (t *UDPv5) addPending(from NodeID, reqID uint64, ptype byte) *pendingV5 {
+	p := &pendingV5{from: from, ptype: ptype, ch: make(chan interface{}, 16)}
+	t.pendingMu.Lock()
+	t.pending[reqID] = p
+	t.pendingMu.Unlock()
+	return p
+}
+
+func (t *UDPv5) removePending(reqID uint64) {
+	t.pendingMu.Lock()
+	delete(t.pending, reqID)
+	t.pendingMu.Unlock()
+}
+
+func (t *UDPv5) waitReply(p *pendingV5) (interface{}, error) {
+	timer := time.NewTimer(respTimeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-p.ch:
+		return r, nil
+	case <-timer.C:
+		return nil, errTimeout
+	case <-t.closing:
+		return nil, errClosed
+	}
+}
+
+// handleReply passes the reply to the matching pending request.
+func (t *UDPv5) handleReply(from NodeID, ptype byte, reqID uint64, reply interface{}) error {
+	t.pendingMu.Lock()
+	p := t.pending[reqID]
+	t.pendingMu.Unlock()
+
+	if p == nil || p.from != from || p.ptype != ptype {
+		return errUnknownRequest
+	}
+	select {
+	case p.ch <- reply:
+	default:
+	}
+	return nil
+}
+
+func (t *UDPv5) send(toaddr *net.UDPAddr, ptype byte, req interface{}) error {
+	packet, err := encodePacketV5(t.priv, ptype, req)
+	if err != nil {
+		return err
+	}
+	_, err = t.conn.WriteToUDP(packet, toaddr)
+	logger.Trace(">> discv5", "type", ptype, "addr", toaddr, "err", err)
+	return err
+}
+
+func encodePacketV5(priv *ecdsa.PrivateKey, ptype byte, req interface{}) ([]byte, error) {
+	b := new(bytes.Buffer)
+	b.Write(packetMagicV5)
+	b.Write(headSpace[:sigSize])
+	b.WriteByte(ptype)
+	if err := rlp.Encode(b, req); err != nil {
+		logger.Error("Can't encode discv5 packet", "err", err)
+		return nil, err
+	}
+	packet := b.Bytes()
+	sig, err := crypto.Sign(crypto.Keccak256(packet[headSizeV5:]), priv)
+	if err != nil {
+		logger.Error("Can't sign discv5 packet", "err", err)
+		return nil, err
+	}
+	copy(packet[len(packetMagicV5):], sig)
+	return packet, nil
+}
+
+// isPacketV5 returns true if the packet is a discovery v5 packet.
+func isPacketV5(buf []byte) bool {
+	return bytes.HasPrefix(buf, packetMagicV5)
+}
+
+func decodePacketV5(buf []byte) (interface{}, NodeID, error) {
+	if !isPacketV5(buf) {
+		return nil, NodeID{}, errNotPacketV5
+	}
+	if len(buf) < headSizeV5+1 {
+		return nil, NodeID{}, errPacketTooSmall
+	}
+	sig, sigdata := buf[len(packetMagicV5):headSizeV5], buf[headSizeV5:]
+	fromID, err := recoverNodeID(crypto.Keccak256(sigdata), sig)
+	if err != nil {
+		return nil, NodeID{}, err
+	}
+	var req interface{}
+	switch ptype := sigdata[0]; ptype {
+	case pingPacketV5:
+		req = new(pingV5)
+	case pongPacketV5:
+		req = new(pongV5)
+	case findnodePacketV5:
+		req = new(findnodeV5)
+	case nodesPacketV5:
+		req = new(nodesV5)
+	default:
+		return nil, fromID, fmt.Errorf("unknown type: %d", ptype)
+	}
+	err = rlp.DecodeBytes(sigdata[1:], req)
+	return req, fromID, err
+}
+
+// readLoop runs in its own goroutine. It handles incoming UDP packets.
+func (t *UDPv5) readLoop() {
+	buf := make([]byte, 1280)
+	for {
+		nbytes, from, err := t.conn.ReadFromUDP(buf)
+		if netutil.IsTemporaryError(err) {
+			logger.Debug("Temporary UDP read error", "err", err)
+			continue
+		} else if err != nil {
+			logger.Debug("Discovery v5 read loop stopped", "err", err)
+			return
+		}
+		if !isPacketV5(buf[:nbytes]) {
+			continue
+		}
+		if err := t.handlePacket(from, buf[:nbytes]); err != nil {
+			logger.Trace("Failed to handle discv5 packet", "addr", from, "err", err)
+		}
+	}
+}
+
+func (t *UDPv5) handlePacket(from *net.UDPAddr, buf []byte) error {
+	packet, fromID, err := decodePacketV5(buf)
+	if err != nil {
+		return err
+	}
+	udpPacketCounter.Inc(1)
+	switch req := packet.(type) {
+	case *pingV5:
+		return t.handlePing(from, fromID, req)
+	case *pongV5:
+		if expired(req.Expiration) {
+			return errExpired
+		}
+		if err := t.handleReply(fromID, pongPacketV5, req.ReqID, req); err != nil {
+			return err
+		}
+		// Mark the endpoint verified before handling the next packet, which
+		// may be a findnode request following the ping.
+		t.bondMu.Lock()
+		t.verified[fromID] = endpointProof{addr: from.String(), time: time.Now()}
+		t.bondMu.Unlock()
+		return nil
+	case *findnodeV5:
+		return t.handleFindnode(from, fromID, req)
+	case *nodesV5:
+		if expired(req.Expiration) {
+			return errExpired
+		}
+		return t.handleReply(fromID, nodesPacketV5, req.ReqID, req)
+	}
+	return nil
+}
+
+func (t *UDPv5) handlePing(from *net.UDPAddr, fromID NodeID, req *pingV5) error {
+	if req.NetworkID != t.networkID {
+		mismatchNetworkCounter.Mark(1)
+		return errMismatchNetwork
+	}
+	if expired(req.Expiration) {
+		return errExpired
+	}
+	t.send(from, pongPacketV5, &pongV5{
+		ReqID:      req.ReqID,
+		Seq:        t.LocalRecord().Seq,
+		ToIP:       from.IP,
+		ToPort:     uint16(from.Port),
+		Expiration: uint64(time.Now().Add(expiration).Unix()),
+	})
+
+	t.bondMu.Lock()
+	t.pingedBy[fromID] = time.Now()
+	for _, wait := range t.pingWaiters[fromID] {
+		close(wait)
+	}
+	delete(t.pingWaiters, fromID)
+	t.bondMu.Unlock()
+
+	// Verify the endpoint of the node, and fetch its record if it's new.
+	rec := t.tab.get(fromID)
+	if t.isVerified(fromID, from) && rec != nil && rec.Seq >= req.Seq {
+		return nil
+	}
+	select {
+	case <-t.bondslots:
+	default:
+		return nil // too many nodes are being verified
+	}
+	go func() {
+		defer func() { t.bondslots <- struct{}{} }()
+
+		if _, err := t.ping(fromID, from); err != nil {
+			return
+		}
+		n, err := t.requestRecord(fromID, from)
+		if err != nil {
+			logger.Trace("Failed to fetch discovery v5 record", "id", fromID, "addr", from, "err", err)
+			return
+		}
+		t.tab.add(n)
+	}()
+	return nil
+}
+
+func (t *UDPv5) handleFindnode(from *net.UDPAddr, fromID NodeID, req *findnodeV5) error {
+	if expired(req.Expiration) {
+		return errExpired
+	}
+	if !t.isVerified(fromID, from) {
+		// The endpoint is not verified, so the packet is not processed. This
+		// prevents the protocol from being used to amplify traffic to a
+		// spoofed address.
+		return errUnknownNode
+	}
+	var recs []*Record
+	for _, d := range req.Distances {
+		if d == 0 {
+			recs = append(recs, t.LocalRecord())
+			break
+		}
+	}
+	for _, rec := range t.tab.atDistances(req.Distances, bucketSize) {
+		if netutil.CheckRelayIP(from.IP, rec.IP) == nil {
+			recs = append(recs, rec)
+		}
+	}
+
+	// Send the records in chunks to stay below the 1280 byte limit.
+	total := (len(recs) + maxRecordsPerPacket - 1) / maxRecordsPerPacket
+	if total == 0 {
+		total = 1
+	}
+	for i := 0; i < total; i++ {
+		chunk := recs[i*maxRecordsPerPacket:]
+		if len(chunk) > maxRecordsPerPacket {
+			chunk = chunk[:maxRecordsPerPacket]
+		}
+		t.send(from, nodesPacketV5, &nodesV5{
+			ReqID:      req.ReqID,
+			Total:      uint8(total),
+			Records:    chunk,
+			Expiration: uint64(time.Now().Add(expiration).Unix()),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startTestV5(t *testing.T, nType NodeType, chainID uint64, bootnodes ...*Node) *UDPv5 {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IP{127, 0, 0, 1}})
+	require.NoError(t, err)
+	port := uint16(conn.LocalAddr().(*net.UDPAddr).Port)
+	udp, err := ListenV5(conn, &Config{
+		PrivateKey: newkey(),
+		NodeType:   nType,
+		NetworkID:  1,
+		ChainID:    chainID,
+		TCPs:       []uint16{port, port + 1},
+		Bootnodes:  bootnodes,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		udp.Close()
+		conn.Close()
+	})
+	return udp
+}
+
+func TestUDPv5_PingAndRecord(t *testing.T) {
+	a := startTestV5(t, NodeTypeEN, 1)
+	b := startTestV5(t, NodeTypePN, 1)
+
+	pong, err := a.ping(b.self, b.Self().addr())
+	require.NoError(t, err)
+	assert.Equal(t, b.LocalRecord().Seq, pong.Seq)
+	assert.True(t, pong.ToIP.IsLoopback())
+
+	rec, err := a.requestRecord(b.self, b.Self().addr())
+	require.NoError(t, err)
+	assert.Equal(t, b.self, rec.ID())
+	assert.Equal(t, NodeTypePN, rec.NType)
+	assert.Equal(t, b.LocalRecord().TCPs, rec.TCPs)
+
+	// An updated record is fetched by Resolve.
+	require.NoError(t, b.UpdateRecord(func(r *Record) { r.NType = NodeTypeCN }))
+	a.tab.add(rec)
+	n := a.Resolve(b.self, NodeTypeCN)
+	require.NotNil(t, n)
+	assert.Equal(t, NodeTypeCN, n.NType)
+	assert.Equal(t, b.LocalRecord().Seq, a.tab.get(b.self).Seq)
+}
+
+// TestUDPv5_Unverified tests whether findnode requests from nodes whose
+// endpoint is not verified are not answered.
+func TestUDPv5_Unverified(t *testing.T) {
+	a := startTestV5(t, NodeTypeEN, 1)
+	b := startTestV5(t, NodeTypePN, 1)
+
+	// Send the request without bonding first.
+	req := &findnodeV5{ReqID: newReqID(), Distances: []uint{0}, Expiration: uint64(time.Now().Add(expiration).Unix())}
+	p := a.addPending(b.self, req.ReqID, nodesPacketV5)
+	defer a.removePending(req.ReqID)
+	require.NoError(t, a.send(b.Self().addr(), findnodePacketV5, req))
+	_, err := a.waitReply(p)
+	assert.Equal(t, errTimeout, err)
+
+	// findnode bonds before sending the request, even for its own record.
+	rec, err := a.requestRecord(b.self, b.Self().addr())
+	require.NoError(t, err)
+	assert.Equal(t, b.self, rec.ID())
+}
+
+// TestUDPv5_Lookup tests whether ENs find PNs through a bootnode, and records
+// of other chains are not accepted.
+func TestUDPv5_Lookup(t *testing.T) {
+	boot := startTestV5(t, NodeTypeBN, 1)
+	var pns []*UDPv5
+	for i := 0; i < 3; i++ {
+		pn := startTestV5(t, NodeTypePN, 1, boot.Self())
+		pns = append(pns, pn)
+		pn.lookup(pn.tab.self)
+	}
+	other := startTestV5(t, NodeTypePN, 2, boot.Self())
+	other.lookup(other.tab.self)
+
+	// Wait for the bootnode to verify the PNs.
+	deadline := time.Now().Add(5 * time.Second)
+	for boot.tab.len() < len(pns) && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// GetNodes looks up a random target, so it may not find all PNs.
+	en := startTestV5(t, NodeTypeEN, 1, boot.Self())
+	found := en.GetNodes(NodeTypePN, 10)
+	for _, n := range found {
+		assert.Equal(t, NodeTypePN, n.NType)
+		assert.Len(t, n.TCPs, 2)
+		assert.NotEqual(t, other.self, n.ID)
+	}
+	for _, pn := range pns {
+		n := en.Resolve(pn.self, NodeTypePN)
+		if assert.NotNil(t, n, "PN %x not found", pn.self[:8]) {
+			assert.Equal(t, pn.self, n.ID)
+		}
+	}
+	assert.Nil(t, en.Resolve(other.self, NodeTypeUnknown))
+	assert.Nil(t, boot.tab.get(other.self))
+	assert.Nil(t, en.tab.get(other.self))
+}
+
+func TestLookupDistances(t *testing.T) {
+	key := newkey()
+	id := PubkeyID(&key.PublicKey)
+	sha := newTableV5(id, 0).self
+	assert.Equal(t, []uint{256, 255, 254}, lookupDistances(sha, hashAtDistance(sha, 256)))
+	assert.Equal(t, []uint{10, 11, 9}, lookupDistances(sha, hashAtDistance(sha, 10)))
+	assert.Equal(t, []uint{1, 2, 3}, lookupDistances(sha, hashAtDistance(sha, 1)))
+}
@@ -0,0 +1,354 @@
+// Modifications Copyright 2024 The Kaia Authors
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+//
+// This file is derived from p2p/dnsdisc/client.go.
+// Modified and improved for the Kaia development.
+
+package dnsdisc
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/networks/p2p/discover"
+)
+
+var logger = log.NewModuleLogger(log.NetworksP2PDNSDisc)
+
+const (
+	maxEntries = 10000 // maximum number of entries of a tree
+	maxTrees   = 64    // maximum number of trees followed by a node set
+)
+
+// Config holds the configuration of a Client.
+type Config struct {
+	Timeout         time.Duration // timeout used for DNS lookups (default 5s)
+	RecheckInterval time.Duration // time between tree root update checks (default 30min)
+	CacheLimit      int           // maximum number of cached entries (default 1000)
+	Resolver        Resolver      // the DNS resolver to use (defaults to system DNS)
+}
+
+// Resolver is a DNS resolver that can query TXT records.
+type Resolver interface {
+	LookupTXT(ctx context.Context, domain string) ([]string, error)
+}
+
+func (cfg Config) withDefaults() Config {
+	const (
+		defaultTimeout = 5 * time.Second
+		defaultRecheck = 30 * time.Minute
+		defaultCache   = 1000
+	)
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.RecheckInterval == 0 {
+		cfg.RecheckInterval = defaultRecheck
+	}
+	if cfg.CacheLimit == 0 {
+		cfg.CacheLimit = defaultCache
+	}
+	if cfg.Resolver == nil {
+		cfg.Resolver = new(net.Resolver)
+	}
+	return cfg
+}
+
+// Client discovers nodes by querying DNS servers.
+type Client struct {
+	cfg     Config
+	entries *lru.Cache
+}
+
+// NewClient creates a client.
+func NewClient(cfg Config) *Client {
+	cfg = cfg.withDefaults()
+	cache, err := lru.New(cfg.CacheLimit)
+	if err != nil {
+		panic(err)
+	}
+	return &Client{cfg: cfg, entries: cache}
+}
+
+// SyncTree downloads the entire node tree at the given URL.
+func (c *Client) SyncTree(url string) (*Tree, error) {
+	le, err := parseLink(url)
+	if err != nil {
+		return nil, err
+	}
+	return c.syncTree(context.Background(), le, nil)
+}
+
+// syncTree downloads the tree at the given location. The previous tree is
+// returned as it is if its root is not changed.
+func (c *Client) syncTree(ctx context.Context, loc *linkEntry, prev *Tree) (*Tree, error) {
+	root, err := c.resolveRoot(ctx, loc)
+	if err != nil {
+		return nil, err
+	}
+	if prev != nil && prev.root.seq == root.seq && prev.root.nroot == root.nroot && prev.root.lroot == root.lroot {
+		return prev, nil
+	}
+	t := &Tree{root: &root, entries: make(map[string]entry)}
+	if err := c.syncAll(ctx, loc.domain, root.nroot, t.entries, false); err != nil {
+		return nil, err
+	}
+	if err := c.syncAll(ctx, loc.domain, root.lroot, t.entries, true); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// syncAll downloads the subtree of the given hash into entries.
+func (c *Client) syncAll(ctx context.Context, domain, hash string, entries map[string]entry, linkTree bool) error {
+	if _, ok := entries[hash]; ok {
+		return nil
+	}
+	if len(entries) >= maxEntries {
+		return errTooManyEntries
+	}
+	e, err := c.resolveEntry(ctx, domain, hash)
+	if err != nil {
+		return err
+	}
+	entries[hash] = e
+	switch e := e.(type) {
+	case *branchEntry:
+		for _, child := range e.children {
+			if err := c.syncAll(ctx, domain, child, entries, linkTree); err != nil {
+				return err
+			}
+		}
+	case *nodeEntry:
+		if linkTree {
+			return nameError{hash + "." + domain, errNodeInLinkTree}
+		}
+	case *linkEntry:
+		if !linkTree {
+			return nameError{hash + "." + domain, errLinkInNodeTree}
+		}
+	}
+	return nil
+}
+
+// resolveRoot retrieves a root entry via DNS and verifies its signature.
+func (c *Client) resolveRoot(ctx context.Context, loc *linkEntry) (rootEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	txts, err := c.cfg.Resolver.LookupTXT(ctx, loc.domain)
+	if err != nil {
+		return rootEntry{}, err
+	}
+	for _, txt := range txts {
+		if strings.HasPrefix(txt, rootPrefix) {
+			e, err := parseRoot(txt)
+			if err != nil {
+				return e, nameError{loc.domain, err}
+			}
+			if !e.verifySignature(loc.pubkey) {
+				return e, nameError{loc.domain, entryError{typ: "root", err: errInvalidSig}}
+			}
+			return e, nil
+		}
+	}
+	return rootEntry{}, nameError{loc.domain, errNoRoot}
+}
+
+// resolveEntry retrieves an entry from the cache or fetches it from the
+// network if it isn't cached.
+func (c *Client) resolveEntry(ctx context.Context, domain, hash string) (entry, error) {
+	cacheKey := hash + "@" + domain
+	if e, ok := c.entries.Get(cacheKey); ok {
+		return e.(entry), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	name := hash + "." + domain
+	wantHash, err := b32format.DecodeString(hash)
+	if err != nil {
+		return nil, nameError{name, errInvalidChild}
+	}
+	txts, err := c.cfg.Resolver.LookupTXT(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, txt := range txts {
+		e, err := parseEntry(txt)
+		if err == errUnknownEntry {
+			continue
+		}
+		if !bytes.HasPrefix(crypto.Keccak256([]byte(txt)), wantHash) {
+			err = nameError{name, errHashMismatch}
+		} else if err != nil {
+			err = nameError{name, err}
+		}
+		if err == nil {
+			c.entries.Add(cacheKey, e)
+		}
+		return e, err
+	}
+	return nil, nameError{name, errNoEntry}
+}
+
+// NodeSet keeps the nodes of the trees at the given URLs, and the trees linked
+// from them, up to date.
+type NodeSet struct {
+	client *Client
+	links  []*linkEntry
+
+	mu    sync.RWMutex
+	trees map[string]*Tree // keyed by tree URL
+	nodes []*discover.Node
+
+	closing chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewNodeSet creates a node set of the trees at the given URLs. The trees are
+// synced in the background until the set is closed.
+func (c *Client) NewNodeSet(urls ...string) (*NodeSet, error) {
+	s := &NodeSet{
+		client:  c,
+		trees:   make(map[string]*Tree),
+		closing: make(chan struct{}),
+	}
+	for _, url := range urls {
+		le, err := parseLink(url)
+		if err != nil {
+			return nil, err
+		}
+		s.links = append(s.links, le)
+	}
+	s.wg.Add(1)
+	go s.loop()
+	return s, nil
+}
+
+// Close stops syncing the trees.
+func (s *NodeSet) Close() {
+	close(s.closing)
+	s.wg.Wait()
+}
+
+func (s *NodeSet) loop() {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.closing
+		cancel()
+	}()
+
+	recheck := time.NewTicker(s.client.cfg.RecheckInterval)
+	defer recheck.Stop()
+	for {
+		s.sync(ctx)
+		select {
+		case <-recheck.C:
+		case <-s.closing:
+			return
+		}
+	}
+}
+
+// sync updates the trees of the set and the trees linked from them.
+func (s *NodeSet) sync(ctx context.Context) {
+	s.mu.RLock()
+	prevTrees := s.trees
+	s.mu.RUnlock()
+
+	trees := make(map[string]*Tree)
+	queue := append([]*linkEntry{}, s.links...)
+	for len(queue) > 0 && len(trees) < maxTrees {
+		loc := queue[0]
+		queue = queue[1:]
+		url := loc.String()
+		if _, ok := trees[url]; ok {
+			continue
+		}
+		prev := prevTrees[url]
+		t, err := s.client.syncTree(ctx, loc, prev)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Debug("Failed to sync DNS discovery tree", "url", url, "err", err)
+			if prev == nil {
+				continue
+			}
+			t = prev
+		} else if t != prev {
+			logger.Info("Synced DNS discovery tree", "url", url, "seq", t.Seq(), "nodes", len(t.Nodes()))
+		}
+		trees[url] = t
+		queue = append(queue, t.linkEntries()...)
+	}
+
+	var (
+		nodes []*discover.Node
+		seen  = make(map[discover.NodeID]bool)
+	)
+	for _, t := range trees {
+		for _, n := range t.Nodes() {
+			if !seen[n.ID] {
+				seen[n.ID] = true
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	s.mu.Lock()
+	s.trees = trees
+	s.nodes = nodes
+	s.mu.Unlock()
+}
+
+// Nodes returns all nodes of the given type in the set. NodeTypeUnknown
+// matches all node types.
+func (s *NodeSet) Nodes(nType discover.NodeType) []*discover.Node {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	nodes := make([]*discover.Node, 0, len(s.nodes))
+	for _, n := range s.nodes {
+		if nType == discover.NodeTypeUnknown || n.NType == nType {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// GetNodes returns at most max random nodes of the given type in the set.
+func (s *NodeSet) GetNodes(nType discover.NodeType, max int) []*discover.Node {
+	nodes := s.Nodes(nType)
+	rand.Shuffle(len(nodes), func(i, j int) { nodes[i], nodes[j] = nodes[j], nodes[i] })
+	if len(nodes) > max {
+		nodes = nodes[:max]
+	}
+	return nodes
+}
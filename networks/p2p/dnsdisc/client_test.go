@@ -0,0 +1,179 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package dnsdisc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/networks/p2p/discover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapResolver is an in-process DNS resolver serving the records in the map.
+type mapResolver struct {
+	mu      sync.Mutex
+	records map[string]string
+	queries int
+}
+
+func newMapResolver(maps ...map[string]string) *mapResolver {
+	r := &mapResolver{records: make(map[string]string)}
+	r.add(maps...)
+	return r
+}
+
+func (r *mapResolver) add(maps ...map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range maps {
+		for k, v := range m {
+			r.records[k] = v
+		}
+	}
+}
+
+func (r *mapResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries++
+	if record, ok := r.records[name]; ok {
+		return []string{record}, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func makeTestTree(t *testing.T, domain string, seq uint, nodes []*discover.Node, links []string) (*Tree, string) {
+	key, _ := crypto.GenerateKey()
+	tree := mustMakeTree(t, seq, nodes, links)
+	return tree, signTree(t, tree, key, domain)
+}
+
+func TestClient_SyncTree(t *testing.T) {
+	nodes := testNodes(30, discover.NodeTypePN)
+	tree, url := makeTestTree(t, "n", 1, nodes, nil)
+	r := newMapResolver(tree.ToTXT("n"))
+
+	c := NewClient(Config{Resolver: r})
+	synced, err := c.SyncTree(url)
+	require.NoError(t, err)
+	assert.Equal(t, nodeIDs(tree.Nodes()), nodeIDs(synced.Nodes()))
+	assert.Equal(t, tree.ToTXT("n"), synced.ToTXT("n"))
+	assert.Equal(t, tree.Signature(), synced.Signature())
+
+	// Entries are cached.
+	queries := r.queries
+	_, err = c.SyncTree(url)
+	require.NoError(t, err)
+	assert.Equal(t, queries+1, r.queries)
+}
+
+func TestClient_SyncTreeErrors(t *testing.T) {
+	nodes := testNodes(3, discover.NodeTypePN)
+	tree, url := makeTestTree(t, "n", 1, nodes, nil)
+
+	// A tree signed by another key is rejected.
+	_, otherURL := makeTestTree(t, "n", 1, nodes, nil)
+	c := NewClient(Config{Resolver: newMapResolver(tree.ToTXT("n"))})
+	_, err := c.SyncTree(otherURL)
+	assert.Equal(t, nameError{"n", entryError{"root", errInvalidSig}}, err)
+
+	// A record not matching its subdomain is rejected.
+	records := tree.ToTXT("n")
+	var tampered string
+	for name, txt := range records {
+		if strings.HasPrefix(txt, nodePrefix) {
+			records[name] = strings.Replace(txt, "10.0.0.", "10.0.1.", 1)
+			tampered = name
+			break
+		}
+	}
+	c = NewClient(Config{Resolver: newMapResolver(records)})
+	_, err = c.SyncTree(url)
+	assert.Equal(t, nameError{tampered, errHashMismatch}, err)
+
+	// A missing root is reported.
+	c = NewClient(Config{Resolver: newMapResolver()})
+	_, err = c.SyncTree(url)
+	assert.Error(t, err)
+}
+
+func TestNodeSet(t *testing.T) {
+	var (
+		ens      = testNodes(4, discover.NodeTypeEN)
+		pns      = testNodes(3, discover.NodeTypePN)
+		key, _   = crypto.GenerateKey()
+		linked   = mustMakeTree(t, 1, pns, nil)
+		linkedTo = signTree(t, linked, key, "pn.example.org")
+	)
+	rootKey, _ := crypto.GenerateKey()
+	root := mustMakeTree(t, 1, ens[:2], []string{linkedTo})
+	rootURL := signTree(t, root, rootKey, "example.org")
+
+	r := newMapResolver(root.ToTXT("example.org"), linked.ToTXT("pn.example.org"))
+	c := NewClient(Config{Resolver: r, RecheckInterval: time.Hour})
+	s, err := c.NewNodeSet(rootURL)
+	require.NoError(t, err)
+	defer s.Close()
+
+	s.sync(context.Background())
+	assert.ElementsMatch(t, nodeIDs(ens[:2]), nodeIDs(s.Nodes(discover.NodeTypeEN)))
+	assert.ElementsMatch(t, nodeIDs(pns), nodeIDs(s.Nodes(discover.NodeTypePN)))
+	assert.Len(t, s.Nodes(discover.NodeTypeUnknown), 5)
+	assert.Len(t, s.GetNodes(discover.NodeTypePN, 2), 2)
+
+	// An updated tree replaces the old one.
+	root = mustMakeTree(t, 2, ens, []string{linkedTo})
+	signTree(t, root, rootKey, "example.org")
+	r.add(root.ToTXT("example.org"))
+	s.sync(context.Background())
+	assert.ElementsMatch(t, nodeIDs(ens), nodeIDs(s.Nodes(discover.NodeTypeEN)))
+
+	// The nodes of a tree are kept if it can't be synced.
+	r.mu.Lock()
+	delete(r.records, "pn.example.org")
+	r.mu.Unlock()
+	s.sync(context.Background())
+	assert.ElementsMatch(t, nodeIDs(pns), nodeIDs(s.Nodes(discover.NodeTypePN)))
+}
+
+func mustMakeTree(t *testing.T, seq uint, nodes []*discover.Node, links []string) *Tree {
+	tree, err := MakeTree(seq, nodes, links)
+	require.NoError(t, err)
+	return tree
+}
+
+func signTree(t *testing.T, tree *Tree, key *ecdsa.PrivateKey, domain string) string {
+	url, err := tree.Sign(key, domain)
+	require.NoError(t, err)
+	return url
+}
+
+func nodeIDs(nodes []*discover.Node) []discover.NodeID {
+	ids := make([]discover.NodeID, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+	return ids
+}
@@ -0,0 +1,35 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package dnsdisc implements node discovery via DNS, in the style of EIP-1459.
+//
+// A list of node URLs is published as a merkle tree of TXT records. The root
+// record at the tree's domain is signed by the publisher, and links the roots
+// of the node subtree and the link subtree:
+//
+//	kntree-root:v1 e=<node-root> l=<link-root> seq=<sequence-number> sig=<signature>
+//
+// Intermediate records list the subdomains of their children, and leaves are
+// either kni URLs of nodes or links to other trees:
+//
+//	kntree-branch:<h1>,<h2>,...,<hN>
+//	kni://<hex node id>@10.3.58.6:32323?discport=32323&ntype=pn
+//	kntree://<base32 compressed public key>@<domain>
+//
+// The subdomain of a record is the base32 encoding of the first 16 bytes of
+// the keccak256 hash of its content, so the whole tree is authenticated by the
+// root signature. The URL of a tree is the link to it.
+package dnsdisc
@@ -0,0 +1,145 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package dnsdisc
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/networks/p2p/discover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testNodes(n int, nType discover.NodeType) []*discover.Node {
+	nodes := make([]*discover.Node, n)
+	for i := range nodes {
+		key, _ := crypto.GenerateKey()
+		ip := net.IP{10, 0, byte(i >> 8), byte(i)}
+		nodes[i] = discover.NewNode(discover.PubkeyID(&key.PublicKey), ip, 32323, 32323, []uint16{32323, 32324}, nType)
+	}
+	return nodes
+}
+
+func TestMakeTree(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	link := newLinkEntry("other.example.org", &key.PublicKey).String()
+	nodes := testNodes(50, discover.NodeTypePN)
+
+	tree, err := MakeTree(3, nodes, []string{link})
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), tree.Seq())
+	assert.Equal(t, []string{link}, tree.Links())
+	require.Len(t, tree.Nodes(), len(nodes))
+	for _, n := range tree.Nodes() {
+		assert.Equal(t, discover.NodeTypePN, n.NType)
+	}
+
+	// The tree doesn't depend on the order of the nodes.
+	reversed := make([]*discover.Node, len(nodes))
+	for i, n := range nodes {
+		reversed[len(nodes)-1-i] = n
+	}
+	tree2, err := MakeTree(3, reversed, []string{link})
+	require.NoError(t, err)
+	assert.Equal(t, tree.ToTXT("n"), tree2.ToTXT("n"))
+
+	// All records fit in a TXT record, and can be parsed.
+	url, err := tree.Sign(key, "nodes.example.org")
+	require.NoError(t, err)
+	for name, txt := range tree.ToTXT("nodes.example.org") {
+		assert.LessOrEqual(t, len(txt), 370, name)
+		if name == "nodes.example.org" {
+			root, err := parseRoot(txt)
+			require.NoError(t, err)
+			assert.True(t, root.verifySignature(&key.PublicKey))
+			continue
+		}
+		_, err := parseEntry(txt)
+		assert.NoError(t, err, name)
+	}
+
+	domain, pubkey, err := ParseURL(url)
+	require.NoError(t, err)
+	assert.Equal(t, "nodes.example.org", domain)
+	assert.Equal(t, key.PublicKey, *pubkey)
+
+	// The signature can be set again.
+	other, _ := crypto.GenerateKey()
+	assert.NoError(t, tree2.SetSignature(&key.PublicKey, tree.Signature()))
+	assert.Equal(t, errInvalidSig, tree2.SetSignature(&other.PublicKey, tree.Signature()))
+}
+
+func TestMakeTree_IncompleteNode(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	n := discover.NewNode(discover.PubkeyID(&key.PublicKey), nil, 0, 0, nil, discover.NodeTypeEN)
+	_, err := MakeTree(1, []*discover.Node{n}, nil)
+	assert.Error(t, err)
+}
+
+func TestParseEntry(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	link := newLinkEntry("nodes.example.org", &key.PublicKey)
+	node := testNodes(1, discover.NodeTypeEN)[0]
+
+	tests := []struct {
+		input string
+		e     entry
+		err   error
+	}{
+		{input: branchPrefix, e: &branchEntry{}},
+		{
+			input: branchPrefix + "AAAAAAAAAAAAAAAAAAAA,BBBBBBBBBBBBBBBBBBBB",
+			e:     &branchEntry{[]string{"AAAAAAAAAAAAAAAAAAAA", "BBBBBBBBBBBBBBBBBBBB"}},
+		},
+		{input: branchPrefix + "AAAA", err: entryError{"branch", errInvalidChild}},
+		{input: branchPrefix + "AAAAAAAAAAAAAAAAAAAA,1", err: entryError{"branch", errInvalidChild}},
+		{input: link.String(), e: link},
+		{input: linkPrefix + "nodes.example.org", err: entryError{"link", errNoPubkey}},
+		{input: linkPrefix + "AAAA@nodes.example.org", err: entryError{"link", errBadPubkey}},
+		{input: node.String(), e: &nodeEntry{node}},
+		{input: "kni://" + node.ID.String(), err: entryError{"node", errIncompleteNode}},
+		{input: "foo:bar", err: errUnknownEntry},
+	}
+	for _, tt := range tests {
+		e, err := parseEntry(tt.input)
+		assert.Equal(t, tt.err, err, tt.input)
+		if tt.err == nil {
+			assert.Equal(t, tt.input, e.String())
+		}
+	}
+}
+
+func TestParseRoot(t *testing.T) {
+	sig := strings.Repeat("A", b64format.EncodedLen(crypto.SignatureLength))
+	tests := []struct {
+		input string
+		err   error
+	}{
+		{input: fmt.Sprintf(rootPrefix+" e=AAAAAAAAAAAAAAAAAAAA l=BBBBBBBBBBBBBBBBBBBB seq=3 sig=%s", sig)},
+		{input: rootPrefix + " e=AAAAAAAAAAAAAAAAAAAA l=BBBBBBBBBBBBBBBBBBBB seq=3", err: entryError{"root", errSyntax}},
+		{input: fmt.Sprintf(rootPrefix+" e=AAAA l=BBBBBBBBBBBBBBBBBBBB seq=3 sig=%s", sig), err: entryError{"root", errInvalidChild}},
+		{input: rootPrefix + " e=AAAAAAAAAAAAAAAAAAAA l=BBBBBBBBBBBBBBBBBBBB seq=3 sig=AAAA", err: entryError{"root", errInvalidSig}},
+	}
+	for _, tt := range tests {
+		_, err := parseRoot(tt.input)
+		assert.Equal(t, tt.err, err, tt.input)
+	}
+}
@@ -53,6 +53,12 @@ var (
 	dialFailCounter = metrics.NewRegisteredCounter("p2p/DialFailCounter", nil)
 
 	writeMsgTimeOutCounter = metrics.NewRegisteredCounter("p2p/WriteMsgTimeOutCounter", nil)
+
+	peerBanCounter      = metrics.NewRegisteredCounter("p2p/PeerBanCounter", nil)
+	bannedRejectCounter = metrics.NewRegisteredCounter("p2p/BannedRejectCounter", nil)
+
+	ingressThrottleCounter = metrics.NewRegisteredCounter("p2p/IngressThrottleCounter", nil)
+	egressThrottleCounter  = metrics.NewRegisteredCounter("p2p/EgressThrottleCounter", nil)
 )
 
 // meteredConn is a wrapper around a network TCP connection that meters both the
@@ -0,0 +1,89 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package msgrate
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/common/mclock"
+)
+
+// Meter estimates the rate of a stream of events, e.g. the bytes or messages
+// exchanged with a peer. Marked amounts decay exponentially with the window as
+// time constant, so the rate reacts within a window to sudden changes but is
+// stable against short bursts.
+type Meter struct {
+	window float64 // Time constant of the decay in seconds
+	total  uint64  // Sum of all the marked amounts
+	value  float64 // Decayed sum of the marked amounts
+	last   mclock.AbsTime
+
+	lock sync.Mutex
+}
+
+// NewMeter creates a rate meter averaging over the given window.
+func NewMeter(window time.Duration) *Meter {
+	return &Meter{window: window.Seconds(), last: mclock.Now()}
+}
+
+// Mark records an amount of events that happened now.
+func (m *Meter) Mark(n uint64) {
+	m.mark(n, mclock.Now())
+}
+
+// Total returns the sum of all the amounts marked so far.
+func (m *Meter) Total() uint64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.total
+}
+
+// Rate returns the estimated number of events per second.
+func (m *Meter) Rate() float64 {
+	return m.rate(mclock.Now())
+}
+
+func (m *Meter) mark(n uint64, now mclock.AbsTime) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.decay(now)
+	m.total += n
+	m.value += float64(n)
+}
+
+func (m *Meter) rate(now mclock.AbsTime) float64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.decay(now)
+	return m.value / m.window
+}
+
+// decay applies the exponential decay elapsed since the last update. It is
+// expected to be called with the lock held.
+func (m *Meter) decay(now mclock.AbsTime) {
+	if now <= m.last {
+		return
+	}
+	elapsed := time.Duration(now - m.last).Seconds()
+	m.value *= math.Exp(-elapsed / m.window)
+	m.last = now
+}
@@ -0,0 +1,48 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package msgrate
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/common/mclock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeter(t *testing.T) {
+	var (
+		m     = NewMeter(10 * time.Second)
+		start = m.last
+	)
+	// A steady stream converges to its rate.
+	for i := 1; i <= 1000; i++ {
+		m.mark(100, start+mclock.AbsTime(time.Duration(i)*100*time.Millisecond))
+	}
+	now := start + mclock.AbsTime(100*time.Second)
+	assert.InDelta(t, 1000, m.rate(now), 50)
+	assert.Equal(t, uint64(100000), m.Total())
+
+	// The rate fades out once the stream stops, but the total is kept.
+	assert.InDelta(t, 1000/math.E, m.rate(now+mclock.AbsTime(10*time.Second)), 20)
+	assert.Less(t, m.rate(now+mclock.AbsTime(time.Minute)), 5.0)
+	assert.Equal(t, uint64(100000), m.Total())
+
+	// Going back in time doesn't change the estimate.
+	assert.Less(t, m.rate(now), 5.0)
+}
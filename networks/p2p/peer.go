@@ -124,6 +124,12 @@ type Peer struct {
 
 	// events receives message send / receive events if set
 	events *event.Feed
+
+	// reputation receives the misbehaviors of the peer if set
+	reputation *reputation
+
+	// traffic meters the sub-protocol messages exchanged with the peer
+	traffic *peerTraffic
 }
 
 // NewPeer returns a peer for testing purposes.
@@ -184,6 +190,25 @@ func (p *Peer) Disconnect(reason DiscReason) {
 	}
 }
 
+// Penalize reports a misbehavior of the peer, which is disconnected if it gets
+// banned as a result. Trusted and static peers are not penalized.
+func (p *Peer) Penalize(m Misbehavior) {
+	if p.reputation == nil || p.rws[ConnDefault].is(trustedConn|staticDialedConn) {
+		return
+	}
+	p.logger.Debug("Penalizing peer", "reason", m)
+	if p.reputation.penalize(p.ID(), m) {
+		p.Disconnect(DiscUselessPeer)
+	}
+}
+
+// setTrusted sets or clears the trusted flag of all the connections of the peer.
+func (p *Peer) setTrusted(trusted bool) {
+	for _, rw := range p.rws {
+		rw.set(trustedConn, trusted)
+	}
+}
+
 // String implements fmt.Stringer.
 func (p *Peer) String() string {
 	return fmt.Sprintf("Peer %x %v", p.rws[ConnDefault].id[:8], p.RemoteAddr())
@@ -191,7 +216,7 @@ func (p *Peer) String() string {
 
 // Inbound returns true if the peer is an inbound connection
 func (p *Peer) Inbound() bool {
-	return p.rws[ConnDefault].flags&inboundConn != 0
+	return p.rws[ConnDefault].is(inboundConn)
 }
 
 // GetNumberInboundAndOutbound returns the number of
@@ -199,7 +224,7 @@ func (p *Peer) Inbound() bool {
 func (p *Peer) GetNumberInboundAndOutbound() (int, int) {
 	inbound, outbound := 0, 0
 	for _, rw := range p.rws {
-		if rw.flags&inboundConn != 0 {
+		if rw.is(inboundConn) {
 			inbound++
 		} else {
 			outbound++
@@ -226,6 +251,7 @@ func newPeer(conns []*conn, protocols []Protocol, tc RWTimerConfig) (*Peer, erro
 		protoErr: make(chan error, len(protomap)+len(conns)), // protocols + pingLoop
 		closed:   make(chan struct{}),
 		logger:   logger.NewWith("id", conns[ConnDefault].id, "conn", conns[ConnDefault].flags),
+		traffic:  newPeerTraffic(),
 	}
 	return p, nil
 }
@@ -409,6 +435,11 @@ func (p *Peer) readLoop(connectionOrder int, rw *conn, errc chan<- error) {
 			return
 		}
 		msg.ReceivedAt = time.Now()
+		if err = rw.ingress.wait(msg.Size, p.closed); err != nil {
+			errc <- err
+			logger.Debug(fmt.Sprintf("readLoop stopped, peer: %v", p.ID()))
+			return
+		}
 		if err = p.handle(connectionOrder, rw, msg); err != nil {
 			errc <- err
 			logger.Debug(fmt.Sprintf("readLoop stopped, peer: %v", p.ID()))
@@ -437,6 +468,7 @@ func (p *Peer) handle(connectionOrder int, rw *conn, msg Msg) error {
 		if err != nil {
 			return fmt.Errorf("msg code out of range: %v", msg.Code)
 		}
+		p.traffic.markIngress(proto.Name, msg.Code-proto.offset, msg.Size)
 		select {
 		case proto.in <- msg:
 			return nil
@@ -505,6 +537,8 @@ func (p *Peer) startProtocols(writeStart <-chan struct{}, writeErr chan<- error)
 		proto.wstart = writeStart
 		proto.werr = writeErr
 		proto.tc = defaultRWTimerConfig
+		proto.traffic = p.traffic
+		proto.limiter = p.rws[ConnDefault].egress
 		var rw MsgReadWriter = proto
 		if p.events != nil {
 			rw = newMsgEventer(rw, p.events, p.ID(), proto.Name)
@@ -542,6 +576,10 @@ func (p *Peer) startProtocolsWithRWs(writeStarts []chan struct{}, writeErrs []ch
 				writeErrs[i] <- errors.New("WriteStartsChannelSize")
 			}
 			proto.werr = writeErrs[i]
+			proto.traffic = p.traffic
+			if i < len(p.rws) {
+				proto.limiter = p.rws[i].egress
+			}
 
 			var rw MsgReadWriter = proto
 			if p.events != nil {
@@ -596,12 +634,19 @@ type protoRW struct {
 	w      MsgWriter
 	count  uint64 // count the number of WriteMsg calls
 	tc     RWTimerConfig
+
+	traffic *peerTraffic // meters the written messages if set
+	limiter *rateLimiter // caps the egress traffic of the connection if set
 }
 
 func (rw *protoRW) WriteMsg(msg Msg) (err error) {
 	if msg.Code >= rw.Length {
 		return newPeerError(errInvalidMsgCode, "not handled, (code %x) (size %d)", msg.Code, msg.Size)
 	}
+	code, size := msg.Code, msg.Size
+	if err = rw.limiter.wait(size, rw.closed); err != nil {
+		return fmt.Errorf("shutting down")
+	}
 	msg.Code += rw.offset
 	rwCount := atomic.AddUint64(&rw.count, 1)
 	if rwCount%rw.tc.Interval == 0 {
@@ -634,6 +679,9 @@ func (rw *protoRW) WriteMsg(msg Msg) (err error) {
 	case rw.werr <- err:
 	default:
 	}
+	if err == nil {
+		rw.traffic.markEgress(rw.Name, code, size)
+	}
 	return err
 }
 
@@ -666,6 +714,7 @@ type PeerInfo struct {
 	Caps      []string               `json:"caps"`      // Sum-protocols advertised by this particular peer
 	Networks  []NetworkInfo          `json:"networks"`  // Networks is all the NetworkInfo associated with the peer
 	Protocols map[string]interface{} `json:"protocols"` // Sub-protocol specific metadata fields
+	Traffic   *TrafficInfo           `json:"traffic"`   // Sub-protocol traffic exchanged with the peer
 }
 
 // Info gathers and returns a collection of metadata known about a peer.
@@ -690,18 +739,7 @@ func (p *Peer) Info() *PeerInfo {
 		network.Inbound = rw.is(inboundConn)
 		network.Trusted = rw.is(trustedConn)
 		network.Static = rw.is(staticDialedConn)
-		switch rw.conntype {
-		case common.CONSENSUSNODE:
-			network.NodeType = "cn"
-		case common.ENDPOINTNODE:
-			network.NodeType = "en"
-		case common.PROXYNODE:
-			network.NodeType = "pn"
-		case common.BOOTNODE:
-			network.NodeType = "bn"
-		default:
-			network.NodeType = "unknown"
-		}
+		network.NodeType = connTypeName(rw.conntype)
 		info.Networks = append(info.Networks, network)
 	}
 
@@ -717,6 +755,15 @@ func (p *Peer) Info() *PeerInfo {
 		}
 		info.Protocols[proto[ConnDefault].Name] = protoInfo
 	}
+
+	// Gather the traffic and the limits of the default connection
+	info.Traffic = p.traffic.info()
+	if limiter := p.rws[ConnDefault].ingress; limiter != nil {
+		info.Traffic.IngressLimit = uint64(limiter.rate)
+	}
+	if limiter := p.rws[ConnDefault].egress; limiter != nil {
+		info.Traffic.EgressLimit = uint64(limiter.rate)
+	}
 	return info
 }
 
@@ -0,0 +1,366 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/networks/p2p/discover"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// Misbehavior is a kind of peer misbehavior reported by the protocol handlers.
+// Each kind adds a penalty to the score of the peer, and the peer is banned
+// when its score reaches banScoreThreshold.
+type Misbehavior uint8
+
+const (
+	MisbehaviorInvalidMsg   Misbehavior = iota // undecodable or unexpected message
+	MisbehaviorInvalidBlock                    // block failing verification
+	MisbehaviorInvalidTx                       // malformed transaction
+	MisbehaviorTimeout                         // request not answered in time
+	MisbehaviorSpam                            // oversized or excessive messages
+)
+
+var misbehaviorInfo = [...]struct {
+	name    string
+	penalty float64
+}{
+	MisbehaviorInvalidMsg:   {"invalid message", 25},
+	MisbehaviorInvalidBlock: {"invalid block", 50},
+	MisbehaviorInvalidTx:    {"invalid transaction", 20},
+	MisbehaviorTimeout:      {"timeout", 10},
+	MisbehaviorSpam:         {"spam", 34},
+}
+
+func (m Misbehavior) String() string {
+	if int(m) >= len(misbehaviorInfo) {
+		return fmt.Sprintf("unknown misbehavior %d", m)
+	}
+	return misbehaviorInfo[m].name
+}
+
+func (m Misbehavior) penalty() float64 {
+	if int(m) >= len(misbehaviorInfo) {
+		return 0
+	}
+	return misbehaviorInfo[m].penalty
+}
+
+const (
+	// banScoreThreshold is the score at which a peer is banned automatically.
+	banScoreThreshold = 100
+
+	// scoreHalfLife is the time in which the score of a peer halves.
+	scoreHalfLife = 10 * time.Minute
+
+	// scorePruneThreshold is the score below which a peer is forgotten. The
+	// scores are pruned at most once per scoreHalfLife.
+	scorePruneThreshold = 1
+
+	// tempBanDuration is the duration of the first automatic ban of a node.
+	// It doubles on every following automatic ban.
+	tempBanDuration = time.Hour
+
+	// maxTempBans is the number of temporary bans after which a node is
+	// banned permanently.
+	maxTempBans = 4
+
+	// banHistoryExpiration is the time after which an expired ban is
+	// forgotten, so the next ban of the node starts from tempBanDuration.
+	banHistoryExpiration = 7 * 24 * time.Hour
+)
+
+var errInvalidBanTarget = errors.New("invalid ban target, want a kni URL, a node ID or an IP address")
+
+// banStore persists the bans. It is implemented by the node database, through
+// the discovery table or discover.BanDB if the discovery is disabled.
+type banStore interface {
+	GetBans() map[string][]byte
+	PutBan(target string, blob []byte) error
+	DeleteBan(target string) error
+}
+
+// BanInfo describes a ban of a node ID or an IP address.
+type BanInfo struct {
+	Target  string     `json:"target"`            // hex node ID or IP address
+	Reason  string     `json:"reason"`            // reason of the ban
+	Created time.Time  `json:"created"`           // time of the ban
+	Expires *time.Time `json:"expires,omitempty"` // nil if the ban is permanent
+	Count   uint64     `json:"count"`             // number of automatic bans of the target
+}
+
+// banEntry is the stored form of a ban.
+type banEntry struct {
+	Target  string
+	Reason  string
+	Created uint64 // unix time
+	Expires uint64 // unix time, 0 if permanent
+	Count   uint64
+}
+
+func (b *banEntry) active(now time.Time) bool {
+	return b.Expires == 0 || now.Unix() < int64(b.Expires)
+}
+
+func (b *banEntry) info() *BanInfo {
+	info := &BanInfo{
+		Target:  b.Target,
+		Reason:  b.Reason,
+		Created: time.Unix(int64(b.Created), 0),
+		Count:   b.Count,
+	}
+	if b.Expires != 0 {
+		expires := time.Unix(int64(b.Expires), 0)
+		info.Expires = &expires
+	}
+	return info
+}
+
+type peerScore struct {
+	value   float64
+	updated time.Time
+}
+
+// decayed returns the score at the given time.
+func (s *peerScore) decayed(now time.Time) float64 {
+	return s.value * math.Pow(0.5, float64(now.Sub(s.updated))/float64(scoreHalfLife))
+}
+
+// reputation keeps the scores of the peers, and the bans of node IDs and IP
+// addresses.
+type reputation struct {
+	mu        sync.Mutex
+	scores    map[discover.NodeID]*peerScore
+	lastPrune time.Time            // last time the decayed scores were pruned
+	bans      map[string]*banEntry // keyed by target
+	store     banStore             // nil if the bans are not persisted
+	now       func() time.Time
+}
+
+// newReputation creates a reputation, loading the bans from the store if
+// it's not nil.
+func newReputation(store banStore) *reputation {
+	r := &reputation{
+		scores: make(map[discover.NodeID]*peerScore),
+		bans:   make(map[string]*banEntry),
+		store:  store,
+		now:    time.Now,
+	}
+	if store == nil {
+		return r
+	}
+	for target, blob := range store.GetBans() {
+		b := new(banEntry)
+		if err := rlp.DecodeBytes(blob, b); err != nil || b.Target != target {
+			logger.Warn("Dropping invalid peer ban", "target", target, "err", err)
+			store.DeleteBan(target)
+			continue
+		}
+		r.bans[target] = b
+	}
+	r.expireLocked()
+	return r
+}
+
+// parseBanTarget returns the ban target of the given kni URL, node ID or IP
+// address.
+func parseBanTarget(s string) (string, error) {
+	if ip := net.ParseIP(s); ip != nil {
+		return ip.String(), nil
+	}
+	n, err := discover.ParseNode(s)
+	if err != nil {
+		return "", errInvalidBanTarget
+	}
+	return n.ID.String(), nil
+}
+
+// penalize adds the penalty of the misbehavior to the score of the node, and
+// bans it if the score reaches the threshold. It returns whether the node is
+// banned.
+func (r *reputation) penalize(id discover.NodeID, m Misbehavior) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	r.pruneScoresLocked(now)
+	s := r.scores[id]
+	if s == nil {
+		s = &peerScore{updated: now}
+		r.scores[id] = s
+	}
+	s.value = s.decayed(now) + m.penalty()
+	s.updated = now
+	if s.value < banScoreThreshold {
+		return false
+	}
+	delete(r.scores, id)
+
+	// Each automatic ban lasts twice as long as the previous one.
+	target := id.String()
+	count := uint64(1)
+	if prev := r.bans[target]; prev != nil {
+		if prev.active(now) {
+			return true
+		}
+		count = prev.Count + 1
+	}
+	var duration time.Duration
+	if count <= maxTempBans {
+		duration = tempBanDuration << (count - 1)
+	}
+	r.banLocked(&banEntry{Target: target, Reason: m.String(), Count: count}, duration)
+	logger.Info("Banned misbehaving peer", "id", id, "reason", m, "count", count, "duration", duration)
+	return true
+}
+
+// pruneScoresLocked forgets the peers whose scores have decayed below
+// scorePruneThreshold. It does nothing if it ran within scoreHalfLife.
+func (r *reputation) pruneScoresLocked(now time.Time) {
+	if now.Sub(r.lastPrune) < scoreHalfLife {
+		return
+	}
+	r.lastPrune = now
+	for id, s := range r.scores {
+		if s.decayed(now) < scorePruneThreshold {
+			delete(r.scores, id)
+		}
+	}
+}
+
+// ban bans the target for the given duration. A zero duration bans the target
+// permanently.
+func (r *reputation) ban(target string, duration time.Duration, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b := &banEntry{Target: target, Reason: reason}
+	if prev := r.bans[target]; prev != nil {
+		b.Count = prev.Count
+	}
+	r.banLocked(b, duration)
+}
+
+func (r *reputation) banLocked(b *banEntry, duration time.Duration) {
+	now := r.now()
+	b.Created = uint64(now.Unix())
+	if duration > 0 {
+		b.Expires = uint64(now.Add(duration).Unix())
+	}
+	r.bans[b.Target] = b
+	peerBanCounter.Inc(1)
+
+	if r.store != nil {
+		blob, err := rlp.EncodeToBytes(b)
+		if err == nil {
+			err = r.store.PutBan(b.Target, blob)
+		}
+		if err != nil {
+			logger.Warn("Failed to store peer ban", "target", b.Target, "err", err)
+		}
+	}
+}
+
+// unban lifts the ban of the target, and forgets its previous bans. It
+// returns whether the target was banned.
+func (r *reputation) unban(target string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b := r.bans[target]
+	if b == nil {
+		return false
+	}
+	delete(r.bans, target)
+	if id, err := discover.HexID(target); err == nil {
+		delete(r.scores, id)
+	}
+	if r.store != nil {
+		if err := r.store.DeleteBan(target); err != nil {
+			logger.Warn("Failed to delete peer ban", "target", target, "err", err)
+		}
+	}
+	return b.active(r.now())
+}
+
+// isBanned returns whether the node ID or the IP address is banned. The IP
+// address may be nil.
+func (r *reputation) isBanned(id discover.NodeID, ip net.IP) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if b := r.bans[id.String()]; b != nil && b.active(now) {
+		return true
+	}
+	return ip != nil && r.isIPBannedLocked(ip, now)
+}
+
+// isIPBanned returns whether the IP address is banned.
+func (r *reputation) isIPBanned(ip net.IP) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.isIPBannedLocked(ip, r.now())
+}
+
+func (r *reputation) isIPBannedLocked(ip net.IP, now time.Time) bool {
+	b := r.bans[ip.String()]
+	return b != nil && b.active(now)
+}
+
+// list returns the active bans ordered by their creation time.
+func (r *reputation) list() []*BanInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.expireLocked()
+	now := r.now()
+	bans := make([]*BanInfo, 0, len(r.bans))
+	for _, b := range r.bans {
+		if b.active(now) {
+			bans = append(bans, b.info())
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		if !bans[i].Created.Equal(bans[j].Created) {
+			return bans[i].Created.Before(bans[j].Created)
+		}
+		return bans[i].Target < bans[j].Target
+	})
+	return bans
+}
+
+// expireLocked forgets the bans expired longer than banHistoryExpiration ago.
+func (r *reputation) expireLocked() {
+	threshold := r.now().Add(-banHistoryExpiration).Unix()
+	for target, b := range r.bans {
+		if b.Expires != 0 && int64(b.Expires) < threshold {
+			delete(r.bans, target)
+			if r.store != nil {
+				r.store.DeleteBan(target)
+			}
+		}
+	}
+}
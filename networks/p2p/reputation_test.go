@@ -0,0 +1,250 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/networks/p2p/discover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memBanStore is an in-memory banStore.
+type memBanStore map[string][]byte
+
+func (s memBanStore) GetBans() map[string][]byte {
+	bans := make(map[string][]byte, len(s))
+	for k, v := range s {
+		bans[k] = v
+	}
+	return bans
+}
+
+func (s memBanStore) PutBan(target string, blob []byte) error {
+	s[target] = blob
+	return nil
+}
+
+func (s memBanStore) DeleteBan(target string) error {
+	delete(s, target)
+	return nil
+}
+
+func newTestReputation(store banStore, now *time.Time) *reputation {
+	r := newReputation(store)
+	r.now = func() time.Time { return *now }
+	return r
+}
+
+func TestReputationPenalize(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	r := newTestReputation(nil, &now)
+	id := randomID()
+
+	// Timeouts add up to a ban.
+	for i := 0; i < 9; i++ {
+		assert.False(t, r.penalize(id, MisbehaviorTimeout))
+	}
+	assert.False(t, r.isBanned(id, nil))
+	assert.True(t, r.penalize(id, MisbehaviorTimeout))
+	assert.True(t, r.isBanned(id, nil))
+	assert.False(t, r.isBanned(randomID(), nil))
+
+	bans := r.list()
+	require.Len(t, bans, 1)
+	assert.Equal(t, id.String(), bans[0].Target)
+	assert.Equal(t, "timeout", bans[0].Reason)
+	assert.Equal(t, now.Add(tempBanDuration), *bans[0].Expires)
+
+	// The ban expires.
+	now = now.Add(tempBanDuration)
+	assert.False(t, r.isBanned(id, nil))
+	assert.Empty(t, r.list())
+}
+
+func TestReputationDecay(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	r := newTestReputation(nil, &now)
+	id := randomID()
+
+	// The score halves in scoreHalfLife, so slow misbehaviors are tolerated.
+	for i := 0; i < 100; i++ {
+		assert.False(t, r.penalize(id, MisbehaviorInvalidMsg))
+		now = now.Add(scoreHalfLife)
+	}
+	assert.False(t, r.isBanned(id, nil))
+}
+
+// penalizeInvalidBlocks reports two invalid blocks of the node, which is
+// enough to ban it.
+func penalizeInvalidBlocks(r *reputation, id discover.NodeID) bool {
+	r.penalize(id, MisbehaviorInvalidBlock)
+	return r.penalize(id, MisbehaviorInvalidBlock)
+}
+
+func TestReputationInvalidBlock(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	r := newTestReputation(nil, &now)
+	id := randomID()
+
+	// A single invalid block doesn't ban the node.
+	assert.False(t, r.penalize(id, MisbehaviorInvalidBlock))
+	assert.False(t, r.isBanned(id, nil))
+	assert.True(t, r.penalize(id, MisbehaviorInvalidBlock))
+	assert.True(t, r.isBanned(id, nil))
+}
+
+func TestReputationEscalation(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	r := newTestReputation(nil, &now)
+	id := randomID()
+
+	for i := 1; i <= maxTempBans; i++ {
+		require.True(t, penalizeInvalidBlocks(r, id))
+		bans := r.list()
+		require.Len(t, bans, 1)
+		assert.Equal(t, uint64(i), bans[0].Count)
+		assert.Equal(t, now.Add(tempBanDuration<<(i-1)), *bans[0].Expires)
+
+		// Penalties of a banned node don't extend the ban.
+		assert.True(t, penalizeInvalidBlocks(r, id))
+		assert.Equal(t, uint64(i), r.list()[0].Count)
+		now = now.Add(tempBanDuration << (i - 1))
+	}
+	require.True(t, penalizeInvalidBlocks(r, id))
+	bans := r.list()
+	require.Len(t, bans, 1)
+	assert.Nil(t, bans[0].Expires)
+
+	// Unbanning forgives the node.
+	assert.True(t, r.unban(id.String()))
+	assert.False(t, r.isBanned(id, nil))
+	assert.False(t, r.unban(id.String()))
+	require.True(t, penalizeInvalidBlocks(r, id))
+	assert.Equal(t, uint64(1), r.list()[0].Count)
+}
+
+func TestReputationBanHistoryExpiration(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	r := newTestReputation(nil, &now)
+	id := randomID()
+
+	require.True(t, penalizeInvalidBlocks(r, id))
+	now = now.Add(tempBanDuration + banHistoryExpiration + time.Second)
+	assert.Empty(t, r.list())
+	require.True(t, penalizeInvalidBlocks(r, id))
+	assert.Equal(t, uint64(1), r.list()[0].Count)
+}
+
+func TestReputationPruneScores(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	r := newTestReputation(nil, &now)
+	ids := []discover.NodeID{randomID(), randomID()}
+
+	for _, id := range ids {
+		r.penalize(id, MisbehaviorTimeout)
+	}
+	assert.Len(t, r.scores, 2)
+
+	// The decayed scores are dropped by the next penalty.
+	now = now.Add(10 * scoreHalfLife)
+	r.penalize(ids[0], MisbehaviorInvalidMsg)
+	require.Len(t, r.scores, 1)
+	assert.Equal(t, float64(MisbehaviorInvalidMsg.penalty()), r.scores[ids[0]].value)
+}
+
+func TestReputationIPBan(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	r := newTestReputation(nil, &now)
+	ip := net.IP{10, 0, 0, 1}
+
+	r.ban(ip.String(), 0, "test")
+	assert.True(t, r.isIPBanned(ip))
+	assert.True(t, r.isBanned(randomID(), ip))
+	assert.False(t, r.isBanned(randomID(), net.IP{10, 0, 0, 2}))
+
+	// Permanent bans don't expire.
+	now = now.Add(365 * 24 * time.Hour)
+	assert.True(t, r.isIPBanned(ip))
+}
+
+func TestReputationPersistence(t *testing.T) {
+	// The bans are loaded with the current time.
+	now := time.Now().Truncate(time.Second)
+	store := make(memBanStore)
+	r := newTestReputation(store, &now)
+	id := randomID()
+
+	require.True(t, penalizeInvalidBlocks(r, id))
+	r.ban("10.0.0.1", time.Hour, "test")
+	store["invalid"] = []byte{0x01}
+
+	r = newReputation(store)
+	r.now = func() time.Time { return now }
+	assert.True(t, r.isBanned(id, nil))
+	assert.True(t, r.isIPBanned(net.IP{10, 0, 0, 1}))
+	assert.Len(t, r.list(), 2)
+	assert.NotContains(t, store, "invalid")
+
+	r.unban("10.0.0.1")
+	assert.NotContains(t, store, "10.0.0.1")
+	assert.Contains(t, store, id.String())
+}
+
+func TestParseBanTarget(t *testing.T) {
+	id := randomID()
+	tests := []struct {
+		input, target string
+		err           error
+	}{
+		{input: "10.0.0.1", target: "10.0.0.1"},
+		{input: "::ffff:10.0.0.1", target: "10.0.0.1"},
+		{input: id.String(), target: id.String()},
+		{input: discover.NewNode(id, net.IP{10, 0, 0, 1}, 32323, 32323, nil, discover.NodeTypeEN).String(), target: id.String()},
+		{input: "example.org", err: errInvalidBanTarget},
+		{input: "", err: errInvalidBanTarget},
+	}
+	for _, tt := range tests {
+		target, err := parseBanTarget(tt.input)
+		assert.Equal(t, tt.err, err, tt.input)
+		assert.Equal(t, tt.target, target, tt.input)
+	}
+}
+
+func TestPeerPenalize(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	r := newTestReputation(nil, &now)
+
+	p := NewPeer(randomID(), "test", nil)
+	p.reputation = r
+	p.Penalize(MisbehaviorInvalidBlock)
+	p.Penalize(MisbehaviorInvalidBlock)
+	assert.True(t, r.isBanned(p.ID(), nil))
+
+	// Trusted and static peers are not penalized.
+	for _, flag := range []connFlag{trustedConn, staticDialedConn} {
+		p = NewPeer(randomID(), "test", nil)
+		p.reputation = r
+		p.rws[ConnDefault].flags |= flag
+		p.Penalize(MisbehaviorInvalidBlock)
+		p.Penalize(MisbehaviorInvalidBlock)
+		assert.False(t, r.isBanned(p.ID(), nil))
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/klaytn/klaytn/common"
@@ -37,6 +38,7 @@ import (
 	"github.com/klaytn/klaytn/event"
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/networks/p2p/discover"
+	"github.com/klaytn/klaytn/networks/p2p/dnsdisc"
 	"github.com/klaytn/klaytn/networks/p2p/nat"
 	"github.com/klaytn/klaytn/networks/p2p/netutil"
 )
@@ -49,6 +51,9 @@ const (
 	defaultMaxPendingPeers = 50
 	defaultDialRatio       = 3
 
+	// Maximum number of DNS discovered nodes added to lookup results.
+	dnsLookupNodes = 16
+
 	// Maximum time allowed for reading a complete message.
 	// This is effectively the amount of time a connection can be idle.
 	frameReadTimeout = 30 * time.Second
@@ -88,6 +93,26 @@ type Config struct {
 	// Disabling is useful for protocol debugging (manual topology).
 	NoDiscovery bool
 
+	// DiscoveryV5 specifies whether the discovery v5 protocol, which exchanges
+	// signed node records, should be started alongside the legacy one. Both
+	// protocols share the same UDP port.
+	DiscoveryV5 bool `toml:",omitempty"`
+
+	// ChainID is announced in the node record of discovery v5, so nodes of
+	// other chains are not discovered. Zero means NetworkID.
+	ChainID uint64 `toml:",omitempty"`
+
+	// DNSDiscovery is a list of URLs of DNS trees, whose nodes are used as
+	// dial candidates in addition to the discovered ones.
+	DNSDiscovery []string `toml:",omitempty"`
+
+	// IngressLimits and EgressLimits cap the traffic of each connection in
+	// bytes per second, keyed by the node type of the remote peer ("cn", "pn",
+	// "en", "bn" or "unknown"). Connections between consensus nodes are never
+	// limited, so the consensus traffic is not starved.
+	IngressLimits map[string]uint64 `toml:",omitempty"`
+	EgressLimits  map[string]uint64 `toml:",omitempty"`
+
 	// Name sets the node name of this server.
 	// Use common.MakeName to create a name that follows existing conventions.
 	Name string `toml:"-"`
@@ -96,11 +121,6 @@ type Config struct {
 	// with the rest of the network.
 	BootstrapNodes []*discover.Node
 
-	//// BootstrapNodesV5 are used to establish connectivity
-	//// with the rest of the network using the V5 discovery
-	//// protocol.
-	//BootstrapNodesV5 []*discv5.Node `toml:",omitempty"`
-
 	// Static nodes are used as pre-configured connections which are always
 	// maintained and re-connected on disconnects.
 	StaticNodes []*discover.Node
@@ -243,6 +263,13 @@ type Server interface {
 	// RemovePeer disconnects from the given node.
 	RemovePeer(node *discover.Node)
 
+	// AddTrustedPeer adds the given node to the trusted node set, which is
+	// always allowed to connect, even above the peer limit.
+	AddTrustedPeer(node *discover.Node)
+
+	// RemoveTrustedPeer removes the given node from the trusted node set.
+	RemoveTrustedPeer(node *discover.Node)
+
 	// SubscribePeers subscribes the given channel to peer events.
 	SubscribeEvents(ch chan *PeerEvent) event.Subscription
 
@@ -273,6 +300,18 @@ type Server interface {
 	// Peers returns all connected peers.
 	Peers() []*Peer
 
+	// BanPeer bans the node ID or the IP address of the target, which is a
+	// kni URL, a node ID or an IP address, and disconnects the matching peers.
+	// A zero duration bans the target permanently.
+	BanPeer(target string, duration time.Duration, reason string) error
+
+	// UnbanPeer lifts the ban of the target. It returns whether the target
+	// was banned.
+	UnbanPeer(target string) (bool, error)
+
+	// Bans returns the active bans of node IDs and IP addresses.
+	Bans() ([]*BanInfo, error)
+
 	// NodeDialer is used to connect to nodes in the network, typically by using
 	// an underlying net.Dialer but also using net.Pipe in tests.
 	NodeDialer
@@ -322,6 +361,8 @@ func (srv *MultiChannelServer) Start() (err error) {
 	srv.posthandshake = make(chan *conn)
 	srv.addstatic = make(chan *discover.Node)
 	srv.removestatic = make(chan *discover.Node)
+	srv.addtrusted = make(chan *discover.Node)
+	srv.removetrusted = make(chan *discover.Node)
 	srv.peerOp = make(chan peerOpFunc)
 	srv.peerOpDone = make(chan struct{})
 	srv.discpeer = make(chan discover.NodeID)
@@ -351,6 +392,9 @@ func (srv *MultiChannelServer) Start() (err error) {
 				realaddr = &net.UDPAddr{IP: ext, Port: realaddr.Port}
 			}
 		}
+		if srv.DiscoveryV5 {
+			unhandled = make(chan discover.ReadPacket, 100)
+		}
 	}
 
 	// node table
@@ -374,9 +418,31 @@ func (srv *MultiChannelServer) Start() (err error) {
 			return err
 		}
 		srv.ntab = ntab
+
+		if srv.DiscoveryV5 {
+			cfg.TCPs = listenPorts(srv.ListenAddrs, realaddr.Port)
+			if err := srv.startDiscoveryV5(&sharedUDPConn{conn, unhandled}, &cfg); err != nil {
+				return err
+			}
+		}
+		if len(srv.DNSDiscovery) > 0 {
+			set, err := dnsdisc.NewClient(dnsdisc.Config{}).NewNodeSet(srv.DNSDiscovery...)
+			if err != nil {
+				return err
+			}
+			srv.dnsdisc = set
+		}
 	}
 
+	// Load the bans from the node database.
+	store, err := srv.openBanStore()
+	if err != nil {
+		return err
+	}
+	srv.reputation = newReputation(store)
+
 	dialer := newDialState(srv.StaticNodes, srv.BootstrapNodes, srv.ntab, srv.maxDialedConns(), srv.NetRestrict, srv.PrivateKey, srv.getTypeStatics())
+	dialer.reputation = srv.reputation
 
 	// handshake
 	srv.ourHandshake = &protoHandshake{Version: baseProtocolVersion, Name: srv.Name(), ID: discover.PubkeyID(&srv.PrivateKey.PublicKey), Multichannel: true}
@@ -482,6 +548,15 @@ func (srv *MultiChannelServer) listenLoop(listener net.Listener) {
 			}
 		}
 
+		// Reject connections from banned IP addresses.
+		if tcp, ok := fd.RemoteAddr().(*net.TCPAddr); ok && srv.reputation.isIPBanned(tcp.IP) {
+			srv.logger.Debug("Rejected conn (banned IP address)", "addr", fd.RemoteAddr())
+			bannedRejectCounter.Inc(1)
+			fd.Close()
+			slots <- struct{}{}
+			continue
+		}
+
 		fd = newMeteredConn(fd, true)
 		srv.logger.Trace("Accepted connection", "addr", fd.RemoteAddr())
 		go func() {
@@ -615,8 +690,8 @@ func (srv *MultiChannelServer) run(dialstate dialer) {
 		queuedTasks   []task // tasks that can't run yet
 	)
 	// Put trusted nodes into a map to speed up checks.
-	// Trusted peers are loaded on startup and cannot be
-	// modified while the server is running.
+	// Trusted peers are loaded on startup and can be
+	// modified by AddTrustedPeer and RemoveTrustedPeer.
 	for _, n := range srv.TrustedNodes {
 		trusted[n.ID] = true
 	}
@@ -674,6 +749,22 @@ running:
 			if p, ok := peers[n.ID]; ok {
 				p.Disconnect(DiscRequested)
 			}
+		case n := <-srv.addtrusted:
+			// This channel is used by AddTrustedPeer to add a node
+			// to the trusted node set.
+			srv.logger.Debug("Adding trusted node", "node", n)
+			trusted[n.ID] = true
+			if p, ok := peers[n.ID]; ok {
+				p.setTrusted(true)
+			}
+		case n := <-srv.removetrusted:
+			// This channel is used by RemoveTrustedPeer to remove a node
+			// from the trusted node set.
+			srv.logger.Debug("Removing trusted node", "node", n)
+			delete(trusted, n.ID)
+			if p, ok := peers[n.ID]; ok {
+				p.setTrusted(false)
+			}
 		case op := <-srv.peerOp:
 			// This channel is used by Peers and PeerCount.
 			op(peers)
@@ -690,7 +781,7 @@ running:
 			// the remote identity is known (but hasn't been verified yet).
 			if trusted[c.id] {
 				// Ensure that the trusted flag is set before checking against MaxPhysicalConnections.
-				c.flags |= trustedConn
+				c.set(trustedConn, true)
 			}
 			// TODO: track in-progress inbound node IDs (pre-Peer) to avoid dialing them.
 			select {
@@ -725,11 +816,17 @@ running:
 
 					if count == 0 {
 						p, e = newPeer(connSet, srv.Protocols, srv.Config.RWTimerConfig)
+						if p != nil {
+							srv.setupPeer(p)
+						}
 						srv.CandidateConns[c.id] = nil
 					}
 				} else {
 					// The handshakes are done and it passed all checks.
 					p, e = newPeer([]*conn{c}, srv.Protocols, srv.Config.RWTimerConfig)
+					if p != nil {
+						srv.setupPeer(p)
+					}
 				}
 
 				if e != nil {
@@ -779,9 +876,15 @@ running:
 	if srv.ntab != nil {
 		srv.ntab.Close()
 	}
-	//if srv.DiscV5 != nil {
-	//	srv.DiscV5.Close()
-	//}
+	if srv.banDB != nil {
+		srv.banDB.Close()
+	}
+	if srv.discv5 != nil {
+		srv.discv5.Close()
+	}
+	if srv.dnsdisc != nil {
+		srv.dnsdisc.Close()
+	}
 	// Disconnect all peers.
 	for _, p := range peers {
 		p.Disconnect(DiscQuitting)
@@ -924,7 +1027,10 @@ type BaseServer struct {
 	ourHandshake *protoHandshake
 	lastLookup   time.Time
 	lastLookupMu sync.Mutex
-	// DiscV5       *discv5.Network
+	discv5       *discover.UDPv5
+	dnsdisc      *dnsdisc.NodeSet
+	reputation   *reputation
+	banDB        *discover.BanDB // node database of the bans if the discovery is disabled
 
 	// These are for Peers, PeerCount (and nothing else).
 	peerOp     chan peerOpFunc
@@ -933,6 +1039,8 @@ type BaseServer struct {
 	quit          chan struct{}
 	addstatic     chan *discover.Node
 	removestatic  chan *discover.Node
+	addtrusted    chan *discover.Node
+	removetrusted chan *discover.Node
 	posthandshake chan *conn
 	addpeer       chan *conn
 	delpeer       chan peerDrop
@@ -950,7 +1058,7 @@ type peerDrop struct {
 	requested bool // true if signaled by the peer
 }
 
-type connFlag int
+type connFlag int32
 
 const (
 	dynDialedConn connFlag = 1 << iota
@@ -978,6 +1086,8 @@ type conn struct {
 	name         string          // valid after the protocol handshake
 	portOrder    PortOrder       // portOrder is the order of the ports that should be connected in multi-channel.
 	multiChannel bool            // multiChannel is whether the peer is using multi-channel.
+	ingress      *rateLimiter    // ingress caps the received traffic if set, valid after the conntype handshake
+	egress       *rateLimiter    // egress caps the sent traffic if set, valid after the conntype handshake
 }
 
 type transport interface {
@@ -996,7 +1106,7 @@ type transport interface {
 }
 
 func (c *conn) String() string {
-	s := c.flags.String()
+	s := c.loadFlags().String()
 	s += " " + c.conntype.String()
 	if (c.id != discover.NodeID{}) {
 		s += " " + c.id.String()
@@ -1006,7 +1116,7 @@ func (c *conn) String() string {
 }
 
 func (c *conn) Inbound() bool {
-	return c.flags&inboundConn != 0
+	return c.is(inboundConn)
 }
 
 func (f connFlag) String() string {
@@ -1030,7 +1140,28 @@ func (f connFlag) String() string {
 }
 
 func (c *conn) is(f connFlag) bool {
-	return c.flags&f != 0
+	return c.loadFlags()&f != 0
+}
+
+func (c *conn) loadFlags() connFlag {
+	return connFlag(atomic.LoadInt32((*int32)(&c.flags)))
+}
+
+// set sets or clears the given flags. It is safe to be called while the
+// connection is used by a running peer.
+func (c *conn) set(f connFlag, val bool) {
+	for {
+		oldFlags := c.loadFlags()
+		flags := oldFlags
+		if val {
+			flags |= f
+		} else {
+			flags &= ^f
+		}
+		if atomic.CompareAndSwapInt32((*int32)(&c.flags), int32(oldFlags), int32(flags)) {
+			return
+		}
+	}
 }
 
 // GetProtocols returns a slice of protocols.
@@ -1107,6 +1238,24 @@ func (srv *BaseServer) RemovePeer(node *discover.Node) {
 	}
 }
 
+// AddTrustedPeer adds the given node to the trusted node set. A connected peer
+// of the node is marked as trusted immediately.
+func (srv *BaseServer) AddTrustedPeer(node *discover.Node) {
+	select {
+	case srv.addtrusted <- node:
+	case <-srv.quit:
+	}
+}
+
+// RemoveTrustedPeer removes the given node from the trusted node set. A
+// connected peer of the node stays connected, but loses the trusted flag.
+func (srv *BaseServer) RemoveTrustedPeer(node *discover.Node) {
+	select {
+	case srv.removetrusted <- node:
+	case <-srv.quit:
+	}
+}
+
 // SubscribePeers subscribes the given channel to peer events.
 func (srv *BaseServer) SubscribeEvents(ch chan *PeerEvent) event.Subscription {
 	return srv.peerFeed.Subscribe(ch)
@@ -1191,6 +1340,37 @@ func (s *sharedUDPConn) Close() error {
 	return nil
 }
 
+// startDiscoveryV5 starts the discovery v5 protocol on the UDP connection
+// shared with the legacy discovery protocol.
+func (srv *BaseServer) startDiscoveryV5(conn *sharedUDPConn, cfg *discover.Config) error {
+	cfg.ChainID = srv.ChainID
+	if cfg.ChainID == 0 {
+		cfg.ChainID = srv.NetworkID
+	}
+	discv5, err := discover.ListenV5(conn, cfg)
+	if err != nil {
+		return err
+	}
+	srv.discv5 = discv5
+	return nil
+}
+
+// listenPorts returns the ports of the given listen addresses. Unspecified
+// ports are replaced with the given default port.
+func listenPorts(addrs []string, defaultPort int) []uint16 {
+	ports := make([]uint16, 0, len(addrs))
+	for _, addr := range addrs {
+		port := defaultPort
+		if _, p, err := net.SplitHostPort(addr); err == nil {
+			if n, err := strconv.Atoi(p); err == nil && n != 0 {
+				port = n
+			}
+		}
+		ports = append(ports, uint16(port))
+	}
+	return ports
+}
+
 // Start starts running the server.
 // Servers can not be re-used after stopping.
 func (srv *BaseServer) Start() (err error) {
@@ -1227,6 +1407,8 @@ func (srv *BaseServer) Start() (err error) {
 	srv.posthandshake = make(chan *conn)
 	srv.addstatic = make(chan *discover.Node)
 	srv.removestatic = make(chan *discover.Node)
+	srv.addtrusted = make(chan *discover.Node)
+	srv.removetrusted = make(chan *discover.Node)
 	srv.peerOp = make(chan peerOpFunc)
 	srv.peerOpDone = make(chan struct{})
 	srv.discpeer = make(chan discover.NodeID)
@@ -1256,6 +1438,9 @@ func (srv *BaseServer) Start() (err error) {
 				realaddr = &net.UDPAddr{IP: ext, Port: realaddr.Port}
 			}
 		}
+		if srv.DiscoveryV5 {
+			unhandled = make(chan discover.ReadPacket, 100)
+		}
 	}
 
 	// node table
@@ -1284,9 +1469,31 @@ func (srv *BaseServer) Start() (err error) {
 			return err
 		}
 		srv.ntab = ntab
+
+		if srv.DiscoveryV5 {
+			cfg.TCPs = listenPorts([]string{srv.ListenAddr}, realaddr.Port)
+			if err := srv.startDiscoveryV5(&sharedUDPConn{conn, unhandled}, &cfg); err != nil {
+				return err
+			}
+		}
+		if len(srv.DNSDiscovery) > 0 {
+			set, err := dnsdisc.NewClient(dnsdisc.Config{}).NewNodeSet(srv.DNSDiscovery...)
+			if err != nil {
+				return err
+			}
+			srv.dnsdisc = set
+		}
 	}
 
+	// Load the bans from the node database.
+	store, err := srv.openBanStore()
+	if err != nil {
+		return err
+	}
+	srv.reputation = newReputation(store)
+
 	dialer := newDialState(srv.StaticNodes, srv.BootstrapNodes, srv.ntab, srv.maxDialedConns(), srv.NetRestrict, srv.PrivateKey, srv.getTypeStatics())
+	dialer.reputation = srv.reputation
 
 	// handshake
 	srv.ourHandshake = &protoHandshake{Version: baseProtocolVersion, Name: srv.Name(), ID: discover.PubkeyID(&srv.PrivateKey.PublicKey), Multichannel: false}
@@ -1354,8 +1561,8 @@ func (srv *BaseServer) run(dialstate dialer) {
 		queuedTasks  []task // tasks that can't run yet
 	)
 	// Put trusted nodes into a map to speed up checks.
-	// Trusted peers are loaded on startup and cannot be
-	// modified while the server is running.
+	// Trusted peers are loaded on startup and can be
+	// modified by AddTrustedPeer and RemoveTrustedPeer.
 	for _, n := range srv.TrustedNodes {
 		trusted[n.ID] = true
 	}
@@ -1413,6 +1620,22 @@ running:
 			if p, ok := peers[n.ID]; ok {
 				p.Disconnect(DiscRequested)
 			}
+		case n := <-srv.addtrusted:
+			// This channel is used by AddTrustedPeer to add a node
+			// to the trusted node set.
+			srv.logger.Debug("Adding trusted node", "node", n)
+			trusted[n.ID] = true
+			if p, ok := peers[n.ID]; ok {
+				p.setTrusted(true)
+			}
+		case n := <-srv.removetrusted:
+			// This channel is used by RemoveTrustedPeer to remove a node
+			// from the trusted node set.
+			srv.logger.Debug("Removing trusted node", "node", n)
+			delete(trusted, n.ID)
+			if p, ok := peers[n.ID]; ok {
+				p.setTrusted(false)
+			}
 		case op := <-srv.peerOp:
 			// This channel is used by Peers and PeerCount.
 			op(peers)
@@ -1429,7 +1652,7 @@ running:
 			// the remote identity is known (but hasn't been verified yet).
 			if trusted[c.id] {
 				// Ensure that the trusted flag is set before checking against MaxPhysicalConnections.
-				c.flags |= trustedConn
+				c.set(trustedConn, true)
 			}
 			// TODO: track in-progress inbound node IDs (pre-Peer) to avoid dialing them.
 			select {
@@ -1448,6 +1671,7 @@ running:
 				if err != nil {
 					srv.logger.Error("Fail make a new peer", "err", err)
 				} else {
+					srv.setupPeer(p)
 					// If message events are enabled, pass the peerFeed
 					// to the peer
 					if srv.EnableMsgEvents {
@@ -1501,9 +1725,15 @@ running:
 	if srv.ntab != nil {
 		srv.ntab.Close()
 	}
-	//if srv.DiscV5 != nil {
-	//	srv.DiscV5.Close()
-	//}
+	if srv.banDB != nil {
+		srv.banDB.Close()
+	}
+	if srv.discv5 != nil {
+		srv.discv5.Close()
+	}
+	if srv.dnsdisc != nil {
+		srv.dnsdisc.Close()
+	}
 	// Disconnect all peers.
 	for _, p := range peers {
 		p.Disconnect(DiscQuitting)
@@ -1538,11 +1768,23 @@ func (srv *BaseServer) encHandshakeChecks(peers map[discover.NodeID]*Peer, inbou
 		return DiscAlreadyConnected
 	case c.id == srv.Self().ID:
 		return DiscSelf
+	case srv.reputation != nil && srv.reputation.isBanned(c.id, remoteIP(c.fd)):
+		bannedRejectCounter.Inc(1)
+		return DiscUselessPeer
 	default:
 		return nil
 	}
 }
 
+// remoteIP returns the IP address of the remote end of the connection, or nil
+// if it's not a TCP connection.
+func remoteIP(fd net.Conn) net.IP {
+	if tcp, ok := fd.RemoteAddr().(*net.TCPAddr); ok {
+		return tcp.IP
+	}
+	return nil
+}
+
 func (srv *BaseServer) maxInboundConns() int {
 	return srv.Config.MaxPhysicalConnections - srv.maxDialedConns()
 }
@@ -1641,6 +1883,15 @@ func (srv *BaseServer) listenLoop() {
 			}
 		}
 
+		// Reject connections from banned IP addresses.
+		if tcp, ok := fd.RemoteAddr().(*net.TCPAddr); ok && srv.reputation.isIPBanned(tcp.IP) {
+			srv.logger.Debug("Rejected conn (banned IP address)", "addr", fd.RemoteAddr())
+			bannedRejectCounter.Inc(1)
+			fd.Close()
+			slots <- struct{}{}
+			continue
+		}
+
 		fd = newMeteredConn(fd, true)
 		srv.logger.Trace("Accepted connection", "addr", fd.RemoteAddr())
 		go func() {
@@ -1857,6 +2108,103 @@ func (srv *BaseServer) Disconnect(destID discover.NodeID) {
 	srv.discpeer <- destID
 }
 
+// BanPeer bans the node ID or the IP address of the target, which is a kni
+// URL, a node ID or an IP address, and disconnects the matching peers. A zero
+// duration bans the target permanently.
+func (srv *BaseServer) BanPeer(target string, duration time.Duration, reason string) error {
+	rep := srv.getReputation()
+	if rep == nil {
+		return errServerStopped
+	}
+	key, err := parseBanTarget(target)
+	if err != nil {
+		return err
+	}
+	rep.ban(key, duration, reason)
+	srv.logger.Info("Banned peer", "target", key, "duration", duration, "reason", reason)
+	for _, p := range srv.Peers() {
+		if p.ID().String() == key || remoteIP(p.rws[ConnDefault].fd).String() == key {
+			p.Disconnect(DiscUselessPeer)
+		}
+	}
+	return nil
+}
+
+// UnbanPeer lifts the ban of the target, which is a kni URL, a node ID or an
+// IP address. It returns whether the target was banned.
+func (srv *BaseServer) UnbanPeer(target string) (bool, error) {
+	rep := srv.getReputation()
+	if rep == nil {
+		return false, errServerStopped
+	}
+	key, err := parseBanTarget(target)
+	if err != nil {
+		return false, err
+	}
+	return rep.unban(key), nil
+}
+
+// Bans returns the active bans of node IDs and IP addresses.
+func (srv *BaseServer) Bans() ([]*BanInfo, error) {
+	rep := srv.getReputation()
+	if rep == nil {
+		return nil, errServerStopped
+	}
+	return rep.list(), nil
+}
+
+// openBanStore returns the store persisting the peer bans. It is the node
+// database of the discovery table, or the node database opened on its own if
+// the discovery is disabled, so the bans survive restarts either way.
+func (srv *BaseServer) openBanStore() (banStore, error) {
+	if srv.ntab != nil {
+		store, _ := srv.ntab.(banStore)
+		return store, nil
+	}
+	db, err := discover.OpenBanDB(srv.NodeDatabase, discover.PubkeyID(&srv.PrivateKey.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	srv.banDB = db
+	return db, nil
+}
+
+// setupPeer attaches the reputation and the bandwidth limiters of the server
+// to a new peer before it is run.
+func (srv *BaseServer) setupPeer(p *Peer) {
+	// The consensus nodes are neither penalized nor limited by each other.
+	if !srv.isConsensusLink(p.ConnType()) {
+		p.reputation = srv.reputation
+	}
+	for _, c := range p.rws {
+		if c == nil || srv.isConsensusLink(c.conntype) {
+			continue
+		}
+		name := connTypeName(c.conntype)
+		if limit := srv.IngressLimits[name]; limit > 0 {
+			c.ingress = newRateLimiter(limit, ingressThrottleCounter)
+		}
+		if limit := srv.EgressLimits[name]; limit > 0 {
+			c.egress = newRateLimiter(limit, egressThrottleCounter)
+		}
+	}
+}
+
+// isConsensusLink returns whether a connection of the given type links this
+// consensus node to another one.
+func (srv *BaseServer) isConsensusLink(ct common.ConnType) bool {
+	return srv.ConnectionType == common.CONSENSUSNODE && ct == common.CONSENSUSNODE
+}
+
+func (srv *BaseServer) getReputation() *reputation {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+	if !srv.running {
+		return nil
+	}
+	return srv.reputation
+}
+
 // CheckNilNetworkTable returns whether network table is nil.
 func (srv *BaseServer) CheckNilNetworkTable() bool {
 	return srv.ntab == nil
@@ -1868,17 +2216,56 @@ func (srv *BaseServer) CheckNilNetworkTable() bool {
 // The given target does not need to be an actual node
 // identifier.
 func (srv *BaseServer) Lookup(target discover.NodeID, nType discover.NodeType) []*discover.Node {
-	return srv.ntab.Lookup(target, nType)
+	nodes := srv.ntab.Lookup(target, nType)
+	if srv.discv5 != nil {
+		nodes = appendDistinctNodes(nodes, srv.discv5.Lookup(target, nType), 0)
+	}
+	if srv.dnsdisc != nil {
+		nodes = appendDistinctNodes(nodes, srv.dnsdisc.GetNodes(nType, dnsLookupNodes), 0)
+	}
+	return nodes
 }
 
 // Resolve searches for a specific node with the given ID and NodeType.
 // It returns nil if the node could not be found.
 func (srv *BaseServer) Resolve(target discover.NodeID, nType discover.NodeType) *discover.Node {
-	return srv.ntab.Resolve(target, nType)
+	if n := srv.ntab.Resolve(target, nType); n != nil {
+		return n
+	}
+	if srv.discv5 != nil {
+		return srv.discv5.Resolve(target, nType)
+	}
+	return nil
 }
 
 func (srv *BaseServer) GetNodes(nType discover.NodeType, max int) []*discover.Node {
-	return srv.ntab.GetNodes(nType, max)
+	nodes := srv.ntab.GetNodes(nType, max)
+	if srv.discv5 != nil && len(nodes) < max {
+		nodes = appendDistinctNodes(nodes, srv.discv5.GetNodes(nType, max), max)
+	}
+	if srv.dnsdisc != nil && len(nodes) < max {
+		nodes = appendDistinctNodes(nodes, srv.dnsdisc.GetNodes(nType, max), max)
+	}
+	return nodes
+}
+
+// appendDistinctNodes appends the nodes not in the list, keeping at most max
+// nodes if max is positive.
+func appendDistinctNodes(list, nodes []*discover.Node, max int) []*discover.Node {
+	seen := make(map[discover.NodeID]bool, len(list))
+	for _, n := range list {
+		seen[n.ID] = true
+	}
+	for _, n := range nodes {
+		if max > 0 && len(list) >= max {
+			break
+		}
+		if !seen[n.ID] {
+			seen[n.ID] = true
+			list = append(list, n)
+		}
+	}
+	return list
 }
 
 // Name returns name of server.
@@ -27,6 +27,7 @@ import (
 	"errors"
 	"math/rand"
 	"net"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -36,6 +37,8 @@ import (
 	"github.com/klaytn/klaytn/crypto/sha3"
 	"github.com/klaytn/klaytn/networks/p2p/discover"
 	"github.com/klaytn/klaytn/networks/p2p/rlpx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func init() {
@@ -651,3 +654,157 @@ func randomID() (id discover.NodeID) {
 	}
 	return id
 }
+
+// TestServerDiscoveryV5 tests whether discovery v5 runs on the UDP port shared
+// with the legacy discovery, and finds nodes through a bootnode.
+func TestServerDiscoveryV5(t *testing.T) {
+	boot := startTestServer(t, randomID(), nil, &Config{ConnectionType: common.BOOTNODE, DiscoveryV5: true, NetworkID: 1})
+	defer boot.Stop()
+	bootnode := boot.(*SingleChannelServer).discv5.Self()
+
+	pn := startTestServer(t, randomID(), nil, &Config{
+		ConnectionType: common.PROXYNODE, DiscoveryV5: true, NetworkID: 1,
+		BootstrapNodes: []*discover.Node{bootnode},
+	})
+	defer pn.Stop()
+	pnRecord := pn.(*SingleChannelServer).discv5.LocalRecord()
+	assert.Equal(t, discover.NodeTypePN, pnRecord.NType)
+	assert.Equal(t, uint64(1), pnRecord.ChainID)
+
+	en := startTestServer(t, randomID(), nil, &Config{
+		ConnectionType: common.ENDPOINTNODE, DiscoveryV5: true, NetworkID: 1,
+		BootstrapNodes: []*discover.Node{bootnode},
+	})
+	defer en.Stop()
+
+	var found bool
+	for deadline := time.Now().Add(10 * time.Second); !found && time.Now().Before(deadline); {
+		for _, n := range en.(*SingleChannelServer).discv5.GetNodes(discover.NodeTypePN, 10) {
+			found = found || n.ID == pnRecord.ID()
+		}
+		if !found {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	assert.True(t, found, "PN not found by discovery v5")
+}
+
+func TestServerBanPeer(t *testing.T) {
+	srv := &SingleChannelServer{
+		BaseServer: &BaseServer{
+			Config: Config{
+				PrivateKey:             newkey(),
+				MaxPhysicalConnections: 10,
+				NoDial:                 true,
+				NoDiscovery:            true,
+			},
+		},
+	}
+	require.NoError(t, srv.Start())
+
+	newconn := func(id discover.NodeID) *conn {
+		fd, _ := net.Pipe()
+		tx := newTestTransport(id, fd, nil, false)
+		return &conn{fd: fd, transport: tx, flags: inboundConn, conntype: common.ConnTypeUndefined, id: id, cont: make(chan error)}
+	}
+
+	// Banning a connected peer disconnects it.
+	banned := randomID()
+	require.NoError(t, srv.checkpoint(newconn(banned), srv.addpeer))
+	require.NoError(t, srv.BanPeer(banned.String(), time.Hour, "test"))
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.PeerCount() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 0, srv.PeerCount())
+
+	// The banned node can't connect again.
+	assert.Equal(t, DiscUselessPeer, srv.checkpoint(newconn(banned), srv.posthandshake))
+	assert.NoError(t, srv.checkpoint(newconn(randomID()), srv.posthandshake))
+
+	bans, err := srv.Bans()
+	require.NoError(t, err)
+	require.Len(t, bans, 1)
+	assert.Equal(t, banned.String(), bans[0].Target)
+	assert.Equal(t, "test", bans[0].Reason)
+
+	unbanned, err := srv.UnbanPeer(banned.String())
+	require.NoError(t, err)
+	assert.True(t, unbanned)
+	assert.NoError(t, srv.checkpoint(newconn(banned), srv.posthandshake))
+
+	assert.Equal(t, errInvalidBanTarget, srv.BanPeer("invalid", 0, "test"))
+
+	srv.Stop()
+	assert.Equal(t, errServerStopped, srv.BanPeer(banned.String(), 0, "test"))
+}
+
+// TestServerBansPersistedWithoutDiscovery checks the bans are kept in the node
+// database across restarts even if the discovery is disabled.
+func TestServerBansPersistedWithoutDiscovery(t *testing.T) {
+	config := Config{
+		PrivateKey:             newkey(),
+		MaxPhysicalConnections: 10,
+		NoDial:                 true,
+		NoDiscovery:            true,
+		NodeDatabase:           filepath.Join(t.TempDir(), "nodes"),
+	}
+	banned := randomID()
+
+	srv := &SingleChannelServer{BaseServer: &BaseServer{Config: config}}
+	require.NoError(t, srv.Start())
+	require.NoError(t, srv.BanPeer(banned.String(), time.Hour, "test"))
+	srv.Stop()
+
+	srv = &SingleChannelServer{BaseServer: &BaseServer{Config: config}}
+	require.NoError(t, srv.Start())
+	defer srv.Stop()
+	bans, err := srv.Bans()
+	require.NoError(t, err)
+	require.Len(t, bans, 1)
+	assert.Equal(t, banned.String(), bans[0].Target)
+	assert.Equal(t, "test", bans[0].Reason)
+}
+
+func TestServerTrustedPeers(t *testing.T) {
+	srv := &SingleChannelServer{
+		BaseServer: &BaseServer{
+			Config: Config{
+				PrivateKey:             newkey(),
+				MaxPhysicalConnections: 1,
+				NoDial:                 true,
+				NoDiscovery:            true,
+			},
+		},
+	}
+	require.NoError(t, srv.Start())
+	defer srv.Stop()
+
+	newconn := func(id discover.NodeID) *conn {
+		fd, _ := net.Pipe()
+		tx := newTestTransport(id, fd, nil, false)
+		return &conn{fd: fd, transport: tx, flags: inboundConn, conntype: common.ConnTypeUndefined, id: id, cont: make(chan error)}
+	}
+	trusted := func() bool {
+		infos := srv.PeersInfo()
+		require.Len(t, infos, 1)
+		return infos[0].Networks[ConnDefault].Trusted
+	}
+
+	// The connected peer is marked and unmarked as trusted live.
+	connected := randomID()
+	require.NoError(t, srv.checkpoint(newconn(connected), srv.addpeer))
+	assert.False(t, trusted())
+	srv.AddTrustedPeer(&discover.Node{ID: connected})
+	assert.True(t, trusted())
+	srv.RemoveTrustedPeer(&discover.Node{ID: connected})
+	assert.False(t, trusted())
+
+	// A trusted node is allowed to connect above the peer limit.
+	other := randomID()
+	assert.Equal(t, DiscTooManyPeers, srv.checkpoint(newconn(other), srv.posthandshake))
+	srv.AddTrustedPeer(&discover.Node{ID: other})
+	assert.NoError(t, srv.checkpoint(newconn(other), srv.posthandshake))
+	srv.RemoveTrustedPeer(&discover.Node{ID: other})
+	assert.Equal(t, DiscTooManyPeers, srv.checkpoint(newconn(other), srv.posthandshake))
+}
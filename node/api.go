@@ -93,6 +93,59 @@ func (api *PrivateAdminAPI) RemovePeer(url string) (bool, error) {
 	return true, nil
 }
 
+// BanPeer bans the node ID or the IP address of the target, which is a kni URL,
+// a node ID or an IP address, and disconnects the matching peers. The ban lasts
+// for the given number of seconds, or permanently if it is omitted or zero.
+func (api *PrivateAdminAPI) BanPeer(target string, seconds *uint64, reason *string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	var (
+		duration time.Duration
+		why      = "banned by admin"
+	)
+	if seconds != nil {
+		duration = time.Duration(*seconds) * time.Second
+	}
+	if reason != nil && *reason != "" {
+		why = *reason
+	}
+	if err := server.BanPeer(target, duration, why); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UnbanPeer lifts the ban of the target, which is a kni URL, a node ID or an IP
+// address. It returns whether the target was banned.
+func (api *PrivateAdminAPI) UnbanPeer(target string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	return server.UnbanPeer(target)
+}
+
+// ListBans returns the active bans of node IDs and IP addresses.
+func (api *PrivateAdminAPI) ListBans() ([]*p2p.BanInfo, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	return server.Bans()
+}
+
+// ReloadPeerLists reloads the static and the trusted node lists from the data
+// directory, dials the new static nodes, drops the removed ones and updates the
+// trusted node set. It returns the URLs of the added and removed nodes.
+func (api *PrivateAdminAPI) ReloadPeerLists() (*PeerListChanges, error) {
+	return api.node.reloadPeerLists()
+}
+
 // PeerEvents creates an RPC subscription which receives peer events from the
 // node's p2p.Server
 func (api *PrivateAdminAPI) PeerEvents(ctx context.Context) (*rpc.Subscription, error) {
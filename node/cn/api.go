@@ -41,6 +41,7 @@ import (
 	"github.com/klaytn/klaytn/networks/rpc"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
 	"github.com/klaytn/klaytn/storage/statedb"
 	"github.com/klaytn/klaytn/work"
 )
@@ -406,6 +407,13 @@ func (api *PrivateDebugAPI) Preimage(ctx context.Context, hash common.Hash) (hex
 	return nil, errors.New("unknown preimage")
 }
 
+// CreateCheckpoint copies the databases of the running node into the given
+// directory, which must not exist. A node started from the copy begins at the
+// head block at the time of the call.
+func (api *PrivateDebugAPI) CreateCheckpoint(dir string) (*database.CheckpointInfo, error) {
+	return api.cn.ChainDB().CreateCheckpoint(dir)
+}
+
 // TODO-Kaia: Rearrange PublicDebugAPI and PrivateDebugAPI receivers
 // GetBadBLocks returns a list of the last 'bad blocks' that the client has seen on the network
 // and returns them as a JSON list of block-hashes
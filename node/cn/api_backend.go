@@ -30,6 +30,7 @@ import (
 
 	kaia "github.com/klaytn/klaytn"
 	"github.com/klaytn/klaytn/accounts"
+	"github.com/klaytn/klaytn/api"
 	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/bloombits"
 	"github.com/klaytn/klaytn/blockchain/state"
@@ -159,6 +160,9 @@ func (b *CNAPIBackend) BlockByNumber(ctx context.Context, blockNr rpc.BlockNumbe
 	}
 	block := b.cn.blockchain.GetBlockByNumber(uint64(blockNr))
 	if block == nil {
+		if err := api.CheckPrunedHistory(b, uint64(blockNr)); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("the block does not exist (block number: %d)", blockNr)
 	}
 	return block, nil
@@ -214,6 +218,11 @@ func (b *CNAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNr
 func (b *CNAPIBackend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
 	block := b.cn.blockchain.GetBlockByHash(hash)
 	if block == nil {
+		if number := b.ChainDB().ReadHeaderNumber(hash); number != nil {
+			if err := api.CheckPrunedHistory(b, *number); err != nil {
+				return nil, err
+			}
+		}
 		return nil, fmt.Errorf("the block does not exist (block hash: %s)", hash.String())
 	}
 	return block, nil
@@ -230,7 +239,15 @@ func (b *CNAPIBackend) GetBlockReceipts(ctx context.Context, hash common.Hash) t
 }
 
 func (b *CNAPIBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types.Log, error) {
-	return b.cn.blockchain.GetLogsByHash(hash), nil
+	logs := b.cn.blockchain.GetLogsByHash(hash)
+	if logs == nil {
+		if number := b.ChainDB().ReadHeaderNumber(hash); number != nil {
+			if err := api.CheckPrunedHistory(b, *number); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return logs, nil
 }
 
 func (b *CNAPIBackend) GetTd(blockHash common.Hash) *big.Int {
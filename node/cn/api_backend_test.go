@@ -52,7 +52,7 @@ func newCNAPIBackend(t *testing.T) (*gomock.Controller, *mocks.MockBlockChain, *
 	mockBlockChain := mocks.NewMockBlockChain(mockCtrl)
 	mockMiner := mocks2.NewMockMiner(mockCtrl)
 
-	cn := &CN{blockchain: mockBlockChain, miner: mockMiner}
+	cn := &CN{blockchain: mockBlockChain, miner: mockMiner, chainDB: database.NewMemoryDBManager()}
 
 	return mockCtrl, mockBlockChain, mockMiner, &CNAPIBackend{cn: cn}
 }
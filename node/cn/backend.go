@@ -53,6 +53,7 @@ import (
 	"github.com/klaytn/klaytn/node/cn/filters"
 	"github.com/klaytn/klaytn/node/cn/gasprice"
 	"github.com/klaytn/klaytn/node/cn/tracers"
+	_ "github.com/klaytn/klaytn/node/cn/tracers/native" // register native tracers
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/reward"
 	"github.com/klaytn/klaytn/rlp"
@@ -261,6 +262,7 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 			BlockInterval:        config.TrieBlockInterval,
 			TriesInMemory:        config.TriesInMemory,
 			LivePruningRetention: config.LivePruningRetention,
+			HistoryRetention:     config.HistoryRetention,
 			TrieNodeCacheConfig:  &config.TrieNodeCacheConfig,
 			SenderTxHashIndexing: config.SenderTxHashIndexing,
 			SnapshotCacheSize:    config.SnapshotCacheSize,
@@ -441,7 +443,7 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 		go cn.blockchain.BlockSubscriptionLoop(cn.txPool.(*blockchain.TxPool))
 	}
 
-	if config.DBType == database.RocksDB && config.RocksDBConfig.Secondary {
+	if config.ReadOnly || (config.DBType == database.RocksDB && config.RocksDBConfig.Secondary) {
 		go cn.blockchain.CurrentBlockUpdateLoop(cn.txPool.(*blockchain.TxPool))
 	}
 
@@ -500,6 +502,7 @@ func CreateDB(ctx *node.ServiceContext, config *Config, name string) database.DB
 		Dir: name, DBType: config.DBType, ParallelDBWrite: config.ParallelDBWrite, SingleDB: config.SingleDB, NumStateTrieShards: config.NumStateTrieShards,
 		LevelDBCacheSize: config.LevelDBCacheSize, OpenFilesLimit: database.GetOpenFilesLimit(), LevelDBCompression: config.LevelDBCompression,
 		LevelDBBufferPool: config.LevelDBBufferPool, EnableDBPerfMetrics: config.EnableDBPerfMetrics, RocksDBConfig: &config.RocksDBConfig, DynamoDBConfig: &config.DynamoDBConfig,
+		AncientThreshold: config.AncientThreshold, ReadOnly: config.ReadOnly,
 	}
 	return ctx.OpenDatabase(dbc)
 }
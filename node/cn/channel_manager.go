@@ -70,6 +70,7 @@ func NewChannelManager(channelSize int) *ChannelManager {
 	channelMgr.RegisterMsgCode(MiscChannel, NodeDataMsg)
 	channelMgr.RegisterMsgCode(MiscChannel, StakingInfoRequestMsg)
 	channelMgr.RegisterMsgCode(MiscChannel, StakingInfoMsg)
+	channelMgr.RegisterMsgCode(MiscChannel, HistoryTailMsg)
 
 	return channelMgr
 }
@@ -113,6 +113,7 @@ type Config struct {
 
 	// Database options
 	DBType               database.DBType
+	ReadOnly             bool
 	SkipBcVersionCheck   bool `toml:"-"`
 	SingleDB             bool
 	NumStateTrieShards   uint
@@ -120,6 +121,8 @@ type Config struct {
 	LevelDBCompression   database.LevelDBCompressionType
 	LevelDBBufferPool    bool
 	LevelDBCacheSize     int
+	AncientThreshold     uint64
+	HistoryRetention     uint64
 	DynamoDBConfig       database.DynamoDBConfig
 	RocksDBConfig        database.RocksDBConfig
 	TrieCacheSize        int
@@ -76,6 +76,10 @@ const (
 
 	// ExtraNonSnapPeers is the number of non-snap peers allowed to connect more than snap peers.
 	ExtraNonSnapPeers = 5
+
+	// historyTailAnnounceInterval is the period of checking whether the history
+	// pruning has advanced the local history tail to announce it to the peers.
+	historyTailAnnounceInterval = time.Minute
 )
 
 // errIncompatibleConfig is returned if the requested protocols and configs are
@@ -87,8 +91,36 @@ var (
 	errUnsupportedEnginePolicy = errors.New("unsupported engine or policy")
 )
 
+// protocolError is an error caused by a message of the remote peer.
+type protocolError struct {
+	code errCode
+	msg  string
+}
+
+func (e *protocolError) Error() string {
+	return fmt.Sprintf("%v - %v", e.code, e.msg)
+}
+
 func errResp(code errCode, format string, v ...interface{}) error {
-	return fmt.Errorf("%v - %v", code, fmt.Sprintf(format, v...))
+	return &protocolError{code: code, msg: fmt.Sprintf(format, v...)}
+}
+
+// misbehaviorOf returns the misbehavior of the peer which caused the error, if
+// the error is caused by the peer.
+func misbehaviorOf(err error) (p2p.Misbehavior, bool) {
+	var perr *protocolError
+	if !errors.As(err, &perr) {
+		return 0, false
+	}
+	switch perr.code {
+	case ErrMsgTooLarge:
+		return p2p.MisbehaviorSpam, true
+	case ErrDecode, ErrInvalidMsgCode, ErrExtraStatusMsg:
+		return p2p.MisbehaviorInvalidMsg, true
+	case ErrInvalidTx, ErrUnexpectedTxType:
+		return p2p.MisbehaviorInvalidTx, true
+	}
+	return 0, false
 }
 
 type ProtocolManager struct {
@@ -100,6 +132,7 @@ type ProtocolManager struct {
 
 	txpool      work.TxPool
 	blockchain  work.BlockChain
+	chaindb     database.DBManager
 	chainconfig *params.ChainConfig
 	maxPeers    int
 
@@ -149,6 +182,7 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 		eventMux:          mux,
 		txpool:            txpool,
 		blockchain:        blockchain,
+		chaindb:           chainDB,
 		chainconfig:       config,
 		peers:             newPeerSet(),
 		newPeerCh:         make(chan Peer),
@@ -310,7 +344,7 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 		if config.Istanbul != nil {
 			proposerPolicy = config.Istanbul.ProposerPolicy
 		}
-		manager.downloader = downloader.New(mode, chainDB, stateBloom, manager.eventMux, blockchain, nil, manager.removePeer, proposerPolicy)
+		manager.downloader = downloader.New(mode, chainDB, stateBloom, manager.eventMux, blockchain, nil, manager.dropStallingPeer, proposerPolicy)
 	}
 
 	// Create and set fetcher
@@ -332,7 +366,7 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 			atomic.StoreUint32(&manager.acceptTxs, 1) // Mark initial sync done on any fetcher import
 			return manager.blockchain.InsertChain(blocks)
 		}
-		manager.fetcher = fetcher.New(blockchain.GetBlockByHash, validator, manager.BroadcastBlock, manager.BroadcastBlockHash, heighter, inserter, manager.removePeer)
+		manager.fetcher = fetcher.New(blockchain.GetBlockByHash, validator, manager.BroadcastBlock, manager.BroadcastBlockHash, heighter, inserter, manager.dropInvalidBlockPeer)
 	}
 
 	if manager.useTxResend() {
@@ -372,11 +406,41 @@ func (pm *ProtocolManager) removePeer(id string) {
 	}
 }
 
+// penalizePeer reports the misbehavior of the peer to the p2p server, which
+// bans the peer if it misbehaves repeatedly.
+func (pm *ProtocolManager) penalizePeer(id string, m p2p.Misbehavior) {
+	if peer := pm.peers.Peer(id); peer != nil {
+		peer.GetP2PPeer().Penalize(m)
+	}
+}
+
+// dropInvalidBlockPeer penalizes and removes the peer which propagated an
+// invalid block.
+func (pm *ProtocolManager) dropInvalidBlockPeer(id string) {
+	pm.penalizePeer(id, p2p.MisbehaviorInvalidBlock)
+	pm.removePeer(id)
+}
+
+// dropStallingPeer penalizes and removes the peer which failed to serve the
+// downloader in time or served an invalid chain.
+func (pm *ProtocolManager) dropStallingPeer(id string) {
+	pm.penalizePeer(id, p2p.MisbehaviorTimeout)
+	pm.removePeer(id)
+}
+
 // getChainID returns the current chain id.
 func (pm *ProtocolManager) getChainID() *big.Int {
 	return pm.blockchain.Config().ChainID
 }
 
+// historyTail returns the first block whose bodies and receipts are still kept locally.
+func (pm *ProtocolManager) historyTail() uint64 {
+	if pm.chaindb == nil {
+		return 0
+	}
+	return pm.chaindb.ReadHistoryTail()
+}
+
 func (pm *ProtocolManager) Start(maxPeers int) {
 	pm.maxPeers = maxPeers
 
@@ -392,6 +456,9 @@ func (pm *ProtocolManager) Start(maxPeers int) {
 	// start sync handlers
 	go pm.syncer()
 	go pm.txsyncLoop()
+
+	// announce the history tail advanced by the history pruning
+	go pm.historyTailAnnounceLoop()
 }
 
 func (pm *ProtocolManager) Stop() {
@@ -494,7 +561,7 @@ func (pm *ProtocolManager) handle(p Peer) error {
 		td      = pm.blockchain.GetTd(hash, number)
 	)
 
-	if err := p.Handshake(pm.networkId, pm.getChainID(), td, hash, genesis.Hash()); err != nil {
+	if err := p.Handshake(pm.networkId, pm.getChainID(), td, hash, genesis.Hash(), pm.historyTail()); err != nil {
 		p.GetP2PPeer().Log().Debug("Kaia peer handshake failed", "err", err)
 		return err
 	}
@@ -569,6 +636,7 @@ func (pm *ProtocolManager) handle(p Peer) error {
 		if msg.Size > ProtocolMaxMsgSize {
 			err := errResp(ErrMsgTooLarge, "%v > %v", msg.Size, ProtocolMaxMsgSize)
 			p.GetP2PPeer().Log().Warn("ProtocolManager over max msg size", "err", err)
+			p.GetP2PPeer().Penalize(p2p.MisbehaviorSpam)
 			return err
 		}
 
@@ -606,6 +674,9 @@ func (pm *ProtocolManager) processMsg(msgCh <-chan p2p.Msg, p Peer, addr common.
 		for msg := range msgCh {
 			if err := pm.handleMsg(p, addr, msg); err != nil {
 				p.GetP2PPeer().Log().Error("ProtocolManager failed to handle message", "msg", msg, "err", err)
+				if m, ok := misbehaviorOf(err); ok {
+					p.GetP2PPeer().Penalize(m)
+				}
 				errCh <- err
 				return
 			}
@@ -718,6 +789,11 @@ func (pm *ProtocolManager) handleMsg(p Peer, addr common.Address, msg p2p.Msg) e
 			return err
 		}
 
+	case p.GetVersion() >= kaia66 && msg.Code == HistoryTailMsg:
+		if err := handleHistoryTailMsg(pm, p, msg); err != nil {
+			return err
+		}
+
 	case msg.Code == NewBlockHashesMsg:
 		if err := handleNewBlockHashesMsg(pm, p, msg); err != nil {
 			return err
@@ -1088,6 +1164,17 @@ func handleStakingInfoMsg(pm *ProtocolManager, p Peer, msg p2p.Msg) error {
 	return nil
 }
 
+// handleHistoryTailMsg handles the history tail announced by a peer which
+// pruned its block history after the handshake.
+func handleHistoryTailMsg(pm *ProtocolManager, p Peer, msg p2p.Msg) error {
+	var tail uint64
+	if err := msg.Decode(&tail); err != nil {
+		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	}
+	p.SetHistoryTail(tail)
+	return nil
+}
+
 // handleNewBlockHashesMsg handles new block hashes message.
 func handleNewBlockHashesMsg(pm *ProtocolManager, p Peer, msg p2p.Msg) error {
 	var (
@@ -1236,7 +1323,7 @@ func handleTxMsg(pm *ProtocolManager, p Peer, msg p2p.Msg) error {
 	for i, tx := range txs {
 		// Validate and mark the remote transaction
 		if tx == nil {
-			err = errResp(ErrDecode, "transaction %d is nil", i)
+			err = errResp(ErrInvalidTx, "transaction %d is nil", i)
 			continue
 		}
 		p.AddToKnownTxs(tx.Hash())
@@ -1448,6 +1535,42 @@ func (pm *ProtocolManager) minedBroadcastLoop() {
 	}
 }
 
+// historyTailAnnounceLoop announces the local history tail to the kaia/66
+// peers whenever the history pruning advances it, so that they stop
+// requesting the bodies and receipts which are no longer served.
+func (pm *ProtocolManager) historyTailAnnounceLoop() {
+	ticker := time.NewTicker(historyTailAnnounceInterval)
+	defer ticker.Stop()
+
+	announced := pm.historyTail()
+	for {
+		select {
+		case <-ticker.C:
+			tail := pm.historyTail()
+			if tail <= announced {
+				continue
+			}
+			pm.BroadcastHistoryTail(tail)
+			announced = tail
+		case <-pm.quitSync:
+			return
+		}
+	}
+}
+
+// BroadcastHistoryTail announces the given history tail to the kaia/66 peers.
+func (pm *ProtocolManager) BroadcastHistoryTail(tail uint64) {
+	for _, peer := range pm.peers.Peers() {
+		if peer.GetVersion() < kaia66 {
+			continue
+		}
+		if err := peer.SendHistoryTail(tail); err != nil {
+			peer.GetP2PPeer().Log().Debug("Failed to announce the history tail", "tail", tail, "err", err)
+		}
+	}
+	logger.Debug("Announced the history tail", "tail", tail)
+}
+
 func (pm *ProtocolManager) txBroadcastLoop() {
 	for {
 		select {
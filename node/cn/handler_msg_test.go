@@ -420,6 +420,32 @@ func TestHandleReceiptMsg(t *testing.T) {
 	}
 }
 
+func TestHandleHistoryTailMsg(t *testing.T) {
+	// Decoding the message failed, an error is returned.
+	{
+		mockCtrl := gomock.NewController(t)
+		mockPeer := NewMockPeer(mockCtrl)
+		mockPeer.EXPECT().GetVersion().Return(kaia66).AnyTimes()
+
+		pm := &ProtocolManager{}
+		msg := generateMsg(t, HistoryTailMsg, []common.Hash{hash1}) // use message data as a list, not a number
+		assert.Error(t, pm.handleMsg(mockPeer, addrs[0], msg))
+		mockCtrl.Finish()
+	}
+	// The announced history tail is stored in the peer.
+	{
+		mockCtrl := gomock.NewController(t)
+		mockPeer := NewMockPeer(mockCtrl)
+		mockPeer.EXPECT().GetVersion().Return(kaia66).AnyTimes()
+		mockPeer.EXPECT().SetHistoryTail(uint64(100)).Times(1)
+
+		pm := &ProtocolManager{}
+		msg := generateMsg(t, HistoryTailMsg, uint64(100))
+		assert.NoError(t, pm.handleMsg(mockPeer, addrs[0], msg))
+		mockCtrl.Finish()
+	}
+}
+
 func TestHandleNodeDataMsg(t *testing.T) {
 	// Decoding the message failed, an error is returned.
 	{
@@ -413,6 +413,24 @@ func TestBroadcastBlockHash(t *testing.T) {
 	}
 }
 
+func TestBroadcastHistoryTail(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Only the kaia/66 peers are announced the history tail.
+	oldPeer := NewMockPeer(mockCtrl)
+	oldPeer.EXPECT().GetVersion().Return(kaia65).AnyTimes()
+	newPeer := NewMockPeer(mockCtrl)
+	newPeer.EXPECT().GetVersion().Return(kaia66).AnyTimes()
+	newPeer.EXPECT().SendHistoryTail(uint64(100)).Return(nil).Times(1)
+
+	mockPeers := NewMockPeerSet(mockCtrl)
+	mockPeers.EXPECT().Peers().Return(map[string]Peer{"old": oldPeer, "new": newPeer}).Times(1)
+
+	pm := &ProtocolManager{peers: mockPeers}
+	pm.BroadcastHistoryTail(100)
+}
+
 func TestProtocolManager_txBroadcastLoop_FromCN_CN_NotExists(t *testing.T) {
 	pm := &ProtocolManager{}
 	pm.nodetype = common.CONSENSUSNODE
@@ -1189,3 +1207,24 @@ func createAndRegisterPeers(mockCtrl *gomock.Controller, peers *peerSet) (*MockP
 
 	return cnPeer, pnPeer, enPeer
 }
+
+func TestMisbehaviorOf(t *testing.T) {
+	tests := []struct {
+		err error
+		m   p2p.Misbehavior
+		ok  bool
+	}{
+		{errResp(ErrMsgTooLarge, "%v > %v", 2, 1), p2p.MisbehaviorSpam, true},
+		{errResp(ErrDecode, "msg"), p2p.MisbehaviorInvalidMsg, true},
+		{errResp(ErrInvalidMsgCode, "%v", 0xff), p2p.MisbehaviorInvalidMsg, true},
+		{errResp(ErrInvalidTx, "transaction %d is nil", 0), p2p.MisbehaviorInvalidTx, true},
+		{fmt.Errorf("wrapped: %w", errResp(ErrDecode, "msg")), p2p.MisbehaviorInvalidMsg, true},
+		{errResp(ErrFailedToGetStateDB, "local error"), 0, false},
+		{errUnknownProcessingError, 0, false},
+	}
+	for _, tt := range tests {
+		m, ok := misbehaviorOf(tt.err)
+		assert.Equal(t, tt.ok, ok, tt.err)
+		assert.Equal(t, tt.m, m, tt.err)
+	}
+}
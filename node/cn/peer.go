@@ -181,7 +181,17 @@ type Peer interface {
 
 	// Handshake executes the Kaia protocol handshake, negotiating version number,
 	// network IDs, difficulties, head, and genesis blocks and returning error.
-	Handshake(network uint64, chainID, td *big.Int, head common.Hash, genesis common.Hash) error
+	// historyTail is the first block whose bodies and receipts are still served locally.
+	Handshake(network uint64, chainID, td *big.Int, head common.Hash, genesis common.Hash, historyTail uint64) error
+
+	// HistoryTail returns the first block whose bodies and receipts the peer still serves.
+	HistoryTail() uint64
+
+	// SetHistoryTail updates the first block whose bodies and receipts the peer still serves.
+	SetHistoryTail(tail uint64)
+
+	// SendHistoryTail announces the first block whose bodies and receipts are still served locally.
+	SendHistoryTail(tail uint64) error
 
 	// ConnType returns the conntype of the peer.
 	ConnType() common.ConnType
@@ -256,9 +266,10 @@ type basePeer struct {
 	version  int         // Protocol version negotiated
 	forkDrop *time.Timer // Timed connection dropper if forks aren't validated in time
 
-	head common.Hash
-	td   *big.Int
-	lock sync.RWMutex
+	head        common.Hash
+	td          *big.Int
+	historyTail uint64 // First block whose bodies and receipts the peer still serves
+	lock        sync.RWMutex
 
 	knownTxsCache    common.Cache              // FIFO cache of transaction hashes known to be known by this peer
 	knownBlocksCache common.Cache              // FIFO cache of block hashes known to be known by this peer
@@ -326,6 +337,9 @@ var ChannelOfMessage = map[uint64]int{
 	// Protocol messages belonging to kaia/65
 	StakingInfoRequestMsg: p2p.ConnDefault,
 	StakingInfoMsg:        p2p.ConnDefault,
+
+	// Protocol messages belonging to kaia/66
+	HistoryTailMsg: p2p.ConnDefault,
 }
 
 var ConcurrentOfChannel = []int{
@@ -426,6 +440,22 @@ func (p *basePeer) Head() (hash common.Hash, td *big.Int) {
 	return hash, new(big.Int).Set(p.td)
 }
 
+// HistoryTail returns the first block whose bodies and receipts the peer still serves.
+func (p *basePeer) HistoryTail() uint64 {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.historyTail
+}
+
+// SetHistoryTail updates the first block whose bodies and receipts the peer still serves.
+func (p *basePeer) SetHistoryTail(tail uint64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.historyTail = tail
+}
+
 // SetHead updates the head hash and total blockscore of the peer.
 func (p *basePeer) SetHead(hash common.Hash, td *big.Int) {
 	p.lock.Lock()
@@ -566,6 +596,12 @@ func (p *basePeer) SendStakingInfoRLP(stakingInfos []rlp.RawValue) error {
 	return p2p.Send(p.rw, StakingInfoMsg, stakingInfos)
 }
 
+// SendHistoryTail announces the first block whose bodies and receipts are
+// still served locally.
+func (p *basePeer) SendHistoryTail(tail uint64) error {
+	return p2p.Send(p.rw, HistoryTailMsg, tail)
+}
+
 // FetchBlockHeader is a wrapper around the header query functions to fetch a
 // single header. It is used solely by the fetcher.
 func (p *basePeer) FetchBlockHeader(hash common.Hash) error {
@@ -622,20 +658,25 @@ func (p *basePeer) RequestStakingInfo(hashes []common.Hash) error {
 
 // Handshake executes the Kaia protocol handshake, negotiating version number,
 // network IDs, difficulties, head and genesis blocks.
-func (p *basePeer) Handshake(network uint64, chainID, td *big.Int, head common.Hash, genesis common.Hash) error {
+func (p *basePeer) Handshake(network uint64, chainID, td *big.Int, head common.Hash, genesis common.Hash, historyTail uint64) error {
 	// Send out own handshake in a new thread
 	errc := make(chan error, 2)
 	var status statusData // safe to read after two values have been received from errc
 
+	ownStatus := &statusData{
+		ProtocolVersion: uint32(p.version),
+		NetworkId:       network,
+		TD:              td,
+		CurrentBlock:    head,
+		GenesisBlock:    genesis,
+		ChainID:         chainID,
+	}
+	// Peers older than kaia/66 reject a status message with the extra field.
+	if p.version >= kaia66 {
+		ownStatus.HistoryTail = historyTail
+	}
 	go func() {
-		errc <- p2p.Send(p.rw, StatusMsg, &statusData{
-			ProtocolVersion: uint32(p.version),
-			NetworkId:       network,
-			TD:              td,
-			CurrentBlock:    head,
-			GenesisBlock:    genesis,
-			ChainID:         chainID,
-		})
+		errc <- p2p.Send(p.rw, StatusMsg, ownStatus)
 	}()
 	go func() {
 		errc <- p.readStatus(network, &status, genesis, chainID)
@@ -653,6 +694,7 @@ func (p *basePeer) Handshake(network uint64, chainID, td *big.Int, head common.H
 		}
 	}
 	p.td, p.head, p.chainID = status.TD, status.CurrentBlock, status.ChainID
+	p.historyTail = status.HistoryTail
 	return nil
 }
 
@@ -923,6 +965,12 @@ func (p *multiChannelPeer) SendStakingInfoRLP(stakingInfos []rlp.RawValue) error
 	return p.msgSender(StakingInfoMsg, stakingInfos)
 }
 
+// SendHistoryTail announces the first block whose bodies and receipts are
+// still served locally.
+func (p *multiChannelPeer) SendHistoryTail(tail uint64) error {
+	return p.msgSender(HistoryTailMsg, tail)
+}
+
 // FetchBlockHeader is a wrapper around the header query functions to fetch a
 // single header. It is used solely by the fetcher.
 func (p *multiChannelPeer) FetchBlockHeader(hash common.Hash) error {
@@ -1089,7 +1137,7 @@ func (p *multiChannelPeer) Handle(pm *ProtocolManager) error {
 		td      = pm.blockchain.GetTd(hash, number)
 	)
 
-	if err := p.Handshake(pm.networkId, pm.getChainID(), td, hash, genesis.Hash()); err != nil {
+	if err := p.Handshake(pm.networkId, pm.getChainID(), td, hash, genesis.Hash(), pm.historyTail()); err != nil {
 		p.GetP2PPeer().Log().Debug("Kaia peer handshake failed", "err", err)
 		return err
 	}
@@ -317,17 +317,31 @@ func (mr *MockPeerMockRecorder) Handle(arg0 interface{}) *gomock.Call {
 }
 
 // Handshake mocks base method
-func (m *MockPeer) Handshake(arg0 uint64, arg1, arg2 *big.Int, arg3, arg4 common.Hash) error {
+func (m *MockPeer) Handshake(arg0 uint64, arg1, arg2 *big.Int, arg3, arg4 common.Hash, arg5 uint64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Handshake", arg0, arg1, arg2, arg3, arg4)
+	ret := m.ctrl.Call(m, "Handshake", arg0, arg1, arg2, arg3, arg4, arg5)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Handshake indicates an expected call of Handshake
-func (mr *MockPeerMockRecorder) Handshake(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+func (mr *MockPeerMockRecorder) Handshake(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Handshake", reflect.TypeOf((*MockPeer)(nil).Handshake), arg0, arg1, arg2, arg3, arg4)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Handshake", reflect.TypeOf((*MockPeer)(nil).Handshake), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// HistoryTail mocks base method
+func (m *MockPeer) HistoryTail() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HistoryTail")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// HistoryTail indicates an expected call of HistoryTail
+func (mr *MockPeerMockRecorder) HistoryTail() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HistoryTail", reflect.TypeOf((*MockPeer)(nil).HistoryTail))
 }
 
 // Head mocks base method
@@ -597,6 +611,20 @@ func (mr *MockPeerMockRecorder) SendFetchedBlockHeader(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendFetchedBlockHeader", reflect.TypeOf((*MockPeer)(nil).SendFetchedBlockHeader), arg0)
 }
 
+// SendHistoryTail mocks base method
+func (m *MockPeer) SendHistoryTail(arg0 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHistoryTail", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHistoryTail indicates an expected call of SendHistoryTail
+func (mr *MockPeerMockRecorder) SendHistoryTail(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHistoryTail", reflect.TypeOf((*MockPeer)(nil).SendHistoryTail), arg0)
+}
+
 // SendNewBlock mocks base method
 func (m *MockPeer) SendNewBlock(arg0 *types.Block, arg1 *big.Int) error {
 	m.ctrl.T.Helper()
@@ -705,6 +733,18 @@ func (mr *MockPeerMockRecorder) SetHead(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHead", reflect.TypeOf((*MockPeer)(nil).SetHead), arg0, arg1)
 }
 
+// SetHistoryTail mocks base method
+func (m *MockPeer) SetHistoryTail(arg0 uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetHistoryTail", arg0)
+}
+
+// SetHistoryTail indicates an expected call of SetHistoryTail
+func (mr *MockPeerMockRecorder) SetHistoryTail(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHistoryTail", reflect.TypeOf((*MockPeer)(nil).SetHistoryTail), arg0)
+}
+
 // UpdateRWImplementationVersion mocks base method
 func (m *MockPeer) UpdateRWImplementationVersion() {
 	m.ctrl.T.Helper()
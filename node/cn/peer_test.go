@@ -363,3 +363,53 @@ func TestBasePeer_RequestReceipts(t *testing.T) {
 
 	assert.Equal(t, sentHashes, receivedHashes)
 }
+
+func TestBasePeer_HandshakeHistoryTail(t *testing.T) {
+	var (
+		chainID = big.NewInt(1)
+		td      = big.NewInt(1)
+		genesis = hash1
+	)
+	// legacyStatusData is the status message known to the peers older than kaia/66.
+	type legacyStatusData struct {
+		ProtocolVersion uint32
+		NetworkId       uint64
+		TD              *big.Int
+		CurrentBlock    common.Hash
+		GenesisBlock    common.Hash
+		ChainID         *big.Int
+	}
+	for _, tc := range []struct {
+		version  int
+		sentTail uint64
+	}{
+		{kaia65, 0},
+		{kaia66, 100},
+	} {
+		pipe1, pipe2 := p2p.MsgPipe()
+		peer := newPeer(tc.version, p2pPeers[0], pipe1)
+		errc := make(chan error, 1)
+		go func() {
+			errc <- peer.Handshake(1, chainID, td, hash1, genesis, 100)
+		}()
+
+		msg, err := pipe2.ReadMsg()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.version < kaia66 {
+			// The status message must be decodable by the legacy peers.
+			var legacy legacyStatusData
+			assert.NoError(t, msg.Decode(&legacy))
+		} else {
+			var status statusData
+			assert.NoError(t, msg.Decode(&status))
+			assert.Equal(t, tc.sentTail, status.HistoryTail)
+		}
+
+		reply := &statusData{ProtocolVersion: uint32(tc.version), NetworkId: 1, TD: td, CurrentBlock: hashes[1], GenesisBlock: genesis, ChainID: chainID, HistoryTail: tc.sentTail}
+		assert.NoError(t, p2p.Send(pipe2, StatusMsg, reply))
+		assert.NoError(t, <-errc)
+		assert.Equal(t, tc.sentTail, peer.HistoryTail())
+	}
+}
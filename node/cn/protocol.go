@@ -43,6 +43,7 @@ import (
 const (
 	kaia63 = 63
 	kaia65 = 65
+	kaia66 = 66
 )
 
 const ProtocolMaxMsgSize = 12 * 1024 * 1024 // Maximum cap on the size of a protocol message
@@ -78,7 +79,10 @@ const (
 	StakingInfoRequestMsg = 0x12
 	StakingInfoMsg        = 0x13
 
-	MsgCodeEnd = 0x14
+	// Protocol messages belonging to kaia/66
+	HistoryTailMsg = 0x14
+
+	MsgCodeEnd = 0x15
 )
 
 type errCode int
@@ -97,6 +101,7 @@ const (
 	ErrUnexpectedTxType
 	ErrFailedToGetStateDB
 	ErrUnsupportedEnginePolicy
+	ErrInvalidTx
 )
 
 func (e errCode) String() string {
@@ -118,6 +123,7 @@ var errorToString = map[int]string{
 	ErrUnexpectedTxType:        "Unexpected tx type",
 	ErrFailedToGetStateDB:      "Failed to get stateDB",
 	ErrUnsupportedEnginePolicy: "Unsupported engine or policy",
+	ErrInvalidTx:               "Invalid transaction",
 }
 
 // ProtocolManagerDownloader is an interface of downloader.Downloader used by ProtocolManager.
@@ -164,6 +170,7 @@ type statusData struct {
 	CurrentBlock    common.Hash
 	GenesisBlock    common.Hash
 	ChainID         *big.Int // ChainID to sign a transaction.
+	HistoryTail     uint64   `rlp:"optional"` // First block whose bodies and receipts are served. Sent on kaia/66 or later only.
 }
 
 // newBlockHashesData is the network packet for the block announcements.
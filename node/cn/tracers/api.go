@@ -21,9 +21,9 @@
 package tracers
 
 import (
-	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -190,9 +190,52 @@ func (api *CommonAPI) blockByNumberAndHash(ctx context.Context, number rpc.Block
 type TraceConfig struct {
 	*vm.LogConfig
 	Tracer        *string
+	TracerConfig  json.RawMessage // Config specific to the selected native tracer
 	Timeout       *string
 	LoggerTimeout *string
 	Reexec        *uint64
+	// Parallelism is the number of workers tracing transactions (or blocks for
+	// chain tracing) concurrently. It defaults to the number of CPUs, which is also
+	// its limit in the public debug API. If it is 1, transactions are traced one by
+	// one on a single reused state.
+	Parallelism *int
+	// StreamToFile makes the struct logger write its logs incrementally into a JSONL
+	// file in the temp directory, instead of returning them in the result.
+	StreamToFile bool
+	// Gzip compresses the file written by StreamToFile.
+	Gzip bool
+}
+
+// traceParallelism returns the number of tracing workers for the given number of jobs.
+// The public debug API can't use more workers than the number of CPUs.
+func (api *CommonAPI) traceParallelism(config *TraceConfig, jobs int) int {
+	threads := runtime.NumCPU()
+	if config != nil && config.Parallelism != nil && *config.Parallelism > 0 {
+		if api.unsafeTrace || *config.Parallelism < threads {
+			threads = *config.Parallelism
+		}
+	}
+	if threads > jobs {
+		threads = jobs
+	}
+	if threads < 1 {
+		threads = 1
+	}
+	return threads
+}
+
+// TraceCallConfig holds extra parameters to the call trace functions.
+type TraceCallConfig struct {
+	TraceConfig
+	StateOverrides *kaiaapi.EthStateOverride
+	BlockOverrides *kaiaapi.EthBlockOverrides
+}
+
+// Bundle is a list of calls traced by TraceCallMany, executed in a block
+// context optionally overridden by BlockOverride.
+type Bundle struct {
+	Transactions  []kaiaapi.CallArgs         `json:"transactions"`
+	BlockOverride *kaiaapi.EthBlockOverrides `json:"blockOverride"`
 }
 
 // StdTraceConfig holds extra parameters to standard-json trace functions.
@@ -200,6 +243,15 @@ type StdTraceConfig struct {
 	*vm.LogConfig
 	Reexec *uint64
 	TxHash common.Hash
+	Gzip   bool
+}
+
+// streamTraceResult is the result of a struct log trace which is streamed into a file.
+type streamTraceResult struct {
+	Gas         uint64 `json:"gas"`
+	Failed      bool   `json:"failed"`
+	ReturnValue string `json:"returnValue"`
+	File        string `json:"file"`
 }
 
 // txTraceResult is the result of a single transaction trace.
@@ -288,10 +340,7 @@ func (api *CommonAPI) traceChain(start, end *types.Block, config *TraceConfig, n
 	}
 	// Execute all the transaction contained within the chain concurrently for each block
 	blocks := int(end.NumberU64() - start.NumberU64())
-	threads := runtime.NumCPU()
-	if threads > blocks {
-		threads = blocks
-	}
+	threads := api.traceParallelism(config, blocks)
 	var (
 		pend     = new(sync.WaitGroup)
 		tasks    = make(chan *blockTraceTask, threads)
@@ -602,24 +651,66 @@ func (api *CommonAPI) traceBlock(ctx context.Context, block *types.Block, config
 	if err != nil {
 		return nil, err
 	}
-	// Execute all the transaction contained within the block concurrently
+	threads := api.traceParallelism(config, len(block.Transactions()))
+	if threads == 1 {
+		return api.traceBlockSequential(ctx, block, statedb, config)
+	}
+	return api.traceBlockParallel(ctx, block, statedb, config, threads)
+}
+
+// traceBlockSequential traces all the transactions of the block one by one on top of
+// the given parent state. The state is reused for all the transactions, so each
+// transaction is executed only once.
+func (api *CommonAPI) traceBlockSequential(ctx context.Context, block *types.Block, statedb *state.StateDB, config *TraceConfig) ([]*txTraceResult, error) {
+	var (
+		signer   = types.MakeSigner(api.backend.ChainConfig(), block.Number())
+		txs      = block.Transactions()
+		results  = make([]*txTraceResult, len(txs))
+		blockCtx = blockchain.NewEVMBlockContext(block.Header(), newChainContext(ctx, api.backend), nil)
+	)
+	for i, tx := range txs {
+		// The following transactions can't be traced without applying this one,
+		// so abort as traceBlockParallel does.
+		msg, err := tx.AsMessageWithAccountKeyPicker(signer, statedb, block.NumberU64())
+		if err != nil {
+			logger.Warn("Tracing failed", "hash", tx.Hash(), "block", block.NumberU64(), "err", err)
+			return nil, err
+		}
+
+		txCtx := blockchain.NewEVMTxContext(msg, block.Header(), api.backend.ChainConfig())
+		res, err := api.traceTx(ctx, msg, blockCtx, txCtx, statedb, config)
+		if err != nil {
+			results[i] = &txTraceResult{TxHash: tx.Hash(), Error: err.Error()}
+		} else {
+			results[i] = &txTraceResult{TxHash: tx.Hash(), Result: res}
+		}
+
+		// Finalize the state so any modifications are written to the trie
+		statedb.Finalise(true, true)
+	}
+	return results, nil
+}
+
+// traceBlockParallel traces all the transactions of the block concurrently with the
+// given number of workers. The intermediate states are computed once by executing
+// the transactions without tracing, and each of them is handed over to a worker.
+func (api *CommonAPI) traceBlockParallel(ctx context.Context, block *types.Block, statedb *state.StateDB, config *TraceConfig, threads int) ([]*txTraceResult, error) {
 	var (
 		signer  = types.MakeSigner(api.backend.ChainConfig(), block.Number())
 		txs     = block.Transactions()
 		results = make([]*txTraceResult, len(txs))
 
 		pend = new(sync.WaitGroup)
-		jobs = make(chan *txTraceTask, len(txs))
+		jobs = make(chan *txTraceTask, threads)
 	)
-	threads := runtime.NumCPU()
-	if threads > len(txs) {
-		threads = len(txs)
-	}
 	for th := 0; th < threads; th++ {
 		pend.Add(1)
 		go func() {
 			defer pend.Done()
 
+			// The block context is not shared among the workers since its block hash cache is not thread-safe.
+			blockCtx := blockchain.NewEVMBlockContext(block.Header(), newChainContext(ctx, api.backend), nil)
+
 			// Fetch and execute the next transaction trace tasks
 			for task := range jobs {
 				msg, err := txs[task.index].AsMessageWithAccountKeyPicker(signer, task.statedb, block.NumberU64())
@@ -630,7 +721,6 @@ func (api *CommonAPI) traceBlock(ctx context.Context, block *types.Block, config
 				}
 
 				txCtx := blockchain.NewEVMTxContext(msg, block.Header(), api.backend.ChainConfig())
-				blockCtx := blockchain.NewEVMBlockContext(block.Header(), newChainContext(ctx, api.backend), nil)
 				res, err := api.traceTx(ctx, msg, blockCtx, txCtx, task.statedb, config)
 				if err != nil {
 					results[task.index] = &txTraceResult{TxHash: txs[task.index].Hash(), Error: err.Error()}
@@ -641,10 +731,19 @@ func (api *CommonAPI) traceBlock(ctx context.Context, block *types.Block, config
 		}()
 	}
 	// Feed the transactions into the tracers and return
-	var failed error
+	var (
+		failed   error
+		blockCtx = blockchain.NewEVMBlockContext(block.Header(), newChainContext(ctx, api.backend), nil)
+	)
+feed:
 	for i, tx := range txs {
 		// Send the trace task over for execution
-		jobs <- &txTraceTask{statedb: statedb.Copy(), index: i}
+		select {
+		case jobs <- &txTraceTask{statedb: statedb.Copy(), index: i}:
+		case <-ctx.Done():
+			failed = ctx.Err()
+			break feed
+		}
 
 		// Generate the next state snapshot fast without tracing
 		msg, err := tx.AsMessageWithAccountKeyPicker(signer, statedb, block.NumberU64())
@@ -655,7 +754,6 @@ func (api *CommonAPI) traceBlock(ctx context.Context, block *types.Block, config
 		}
 
 		txCtx := blockchain.NewEVMTxContext(msg, block.Header(), api.backend.ChainConfig())
-		blockCtx := blockchain.NewEVMBlockContext(block.Header(), newChainContext(ctx, api.backend), nil)
 		vmenv := vm.NewEVM(blockCtx, txCtx, statedb, api.backend.ChainConfig(), &vm.Config{})
 		if _, err = blockchain.ApplyMessage(vmenv, msg); err != nil {
 			failed = err
@@ -703,12 +801,14 @@ func (api *CommonAPI) standardTraceBlockToFile(ctx context.Context, block *types
 	var (
 		logConfig vm.LogConfig
 		txHash    common.Hash
+		compress  bool
 	)
 	if config != nil {
 		if config.LogConfig != nil {
 			logConfig = *config.LogConfig
 		}
 		txHash = config.TxHash
+		compress = config.Gzip
 	}
 	logConfig.Debug = true
 
@@ -730,15 +830,15 @@ func (api *CommonAPI) standardTraceBlockToFile(ctx context.Context, block *types
 			blockCtx = blockchain.NewEVMBlockContext(block.Header(), newChainContext(ctx, api.backend), nil)
 
 			vmConf vm.Config
-			dump   *os.File
+			dump   *traceFile
 		)
 
 		// If the transaction needs tracing, swap out the configs
 		if tx.Hash() == txHash || common.EmptyHash(txHash) {
 			// Generate a unique temporary file to dump it into
-			prefix := fmt.Sprintf("block_%#x-%d-%#x-", block.Hash().Bytes()[:4], i, tx.Hash().Bytes()[:4])
+			prefix := fmt.Sprintf("block_%#x-%d-%#x-*", block.Hash().Bytes()[:4], i, tx.Hash().Bytes()[:4])
 
-			dump, err = os.CreateTemp(os.TempDir(), prefix)
+			dump, err = newTraceFile(prefix, compress)
 			if err != nil {
 				return nil, err
 			}
@@ -747,7 +847,7 @@ func (api *CommonAPI) standardTraceBlockToFile(ctx context.Context, block *types
 			// Swap out the noop logger to the standard tracer
 			vmConf = vm.Config{
 				Debug:                   true,
-				Tracer:                  vm.NewJSONLogger(&logConfig, bufio.NewWriter(dump)),
+				Tracer:                  vm.NewJSONLogger(&logConfig, dump),
 				EnablePreimageRecording: true,
 			}
 		}
@@ -756,7 +856,9 @@ func (api *CommonAPI) standardTraceBlockToFile(ctx context.Context, block *types
 		_, err = blockchain.ApplyMessage(vmenv, msg)
 
 		if dump != nil {
-			dump.Close()
+			if closeErr := dump.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
 			logger.Info("Wrote standard trace", "file", dump.Name())
 		}
 		if err != nil {
@@ -822,7 +924,8 @@ func (api *CommonAPI) TraceTransaction(ctx context.Context, hash common.Hash, co
 // TraceCall lets you trace a given kaia_call. It collects the structured logs
 // created during the execution of EVM if the given transaction was added on
 // top of the provided block and returns them as a JSON object.
-func (api *CommonAPI) TraceCall(ctx context.Context, args kaiaapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (interface{}, error) {
+// The state and the block context may be overridden before the execution.
+func (api *CommonAPI) TraceCall(ctx context.Context, args kaiaapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) (interface{}, error) {
 	if !api.unsafeTrace {
 		if atomic.LoadInt32(&heavyAPIRequestCount) >= HeavyAPIRequestLimit {
 			return nil, fmt.Errorf("heavy debug api requests exceed the limit: %d", int64(HeavyAPIRequestLimit))
@@ -845,41 +948,176 @@ func (api *CommonAPI) TraceCall(ctx context.Context, args kaiaapi.CallArgs, bloc
 	if err != nil {
 		return nil, err
 	}
+	var traceConfig *TraceConfig
+	if config != nil {
+		traceConfig = &config.TraceConfig
+	}
 	// try to recompute the state
 	reexec := defaultTraceReexec
-	if config != nil && config.Reexec != nil {
-		reexec = *config.Reexec
+	if traceConfig != nil && traceConfig.Reexec != nil {
+		reexec = *traceConfig.Reexec
 	}
 	statedb, err := api.backend.StateAtBlock(ctx, block, reexec, nil, true, false)
 	if err != nil {
 		return nil, err
 	}
+	header := block.Header()
+	if config != nil {
+		if err := config.StateOverrides.Apply(statedb); err != nil {
+			return nil, err
+		}
+		header = config.BlockOverrides.MakeHeader(header)
+	}
 
 	// Execute the trace
-	intrinsicGas, err := types.IntrinsicGas(args.InputData(), nil, args.To == nil, api.backend.ChainConfig().Rules(block.Number()))
+	msg, err := api.callMessage(args, header)
 	if err != nil {
 		return nil, err
 	}
-	basefee := new(big.Int).SetUint64(params.ZeroBaseFee)
-	if block.Header().BaseFee != nil {
-		basefee = block.Header().BaseFee
+	// Add gas fee to sender for estimating gasLimit/computing cost or calling a function by insufficient balance sender.
+	statedb.AddBalance(msg.ValidatedSender(), callGasFee(msg, header))
+	// The block context is built from the real header since the coinbase is derived from its seal.
+	txCtx := blockchain.NewEVMTxContext(msg, header, api.backend.ChainConfig())
+	blockCtx := blockchain.NewEVMBlockContext(block.Header(), newChainContext(ctx, api.backend), nil)
+	if config != nil {
+		config.BlockOverrides.Apply(&blockCtx, block.Header())
 	}
-	gasCap := uint64(0)
-	if rpcGasCap := api.backend.RPCGasCap(); rpcGasCap != nil {
-		gasCap = rpcGasCap.Uint64()
+
+	return api.traceTx(ctx, msg, blockCtx, txCtx, statedb, traceConfig)
+}
+
+// TraceCallMany lets you trace an ordered list of call bundles. Each call is
+// executed on top of the state left by the previous one, starting from the
+// state of the provided block with the optional state overrides applied.
+// The block overrides of the config apply to every bundle, and each bundle may
+// further override the block context its calls are executed in.
+// The traces are returned grouped by bundle, in the order they were given.
+func (api *CommonAPI) TraceCallMany(ctx context.Context, bundles []Bundle, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) ([][]interface{}, error) {
+	if !api.unsafeTrace {
+		if atomic.LoadInt32(&heavyAPIRequestCount) >= HeavyAPIRequestLimit {
+			return nil, fmt.Errorf("heavy debug api requests exceed the limit: %d", int64(HeavyAPIRequestLimit))
+		}
+		atomic.AddInt32(&heavyAPIRequestCount, 1)
+		defer atomic.AddInt32(&heavyAPIRequestCount, -1)
+	}
+	if len(bundles) == 0 {
+		return nil, errors.New("empty bundle list")
 	}
-	msg, err := args.ToMessage(gasCap, basefee, intrinsicGas)
+	// Try to retrieve the specified block
+	var (
+		err   error
+		block *types.Block
+	)
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		block, err = api.blockByHash(ctx, hash)
+	} else if number, ok := blockNrOrHash.Number(); ok {
+		block, err = api.blockByNumber(ctx, number)
+	} else {
+		return nil, errors.New("invalid arguments; neither block nor hash specified")
+	}
+	if err != nil {
+		return nil, err
+	}
+	var traceConfig *TraceConfig
+	if config != nil {
+		traceConfig = &config.TraceConfig
+	}
+	// try to recompute the state
+	reexec := defaultTraceReexec
+	if traceConfig != nil && traceConfig.Reexec != nil {
+		reexec = *traceConfig.Reexec
+	}
+	statedb, err := api.backend.StateAtBlock(ctx, block, reexec, nil, true, false)
 	if err != nil {
 		return nil, err
 	}
+	if config != nil {
+		if err := config.StateOverrides.Apply(statedb); err != nil {
+			return nil, err
+		}
+	}
 
-	// Add gas fee to sender for estimating gasLimit/computing cost or calling a function by insufficient balance sender.
-	statedb.AddBalance(msg.ValidatedSender(), new(big.Int).Mul(new(big.Int).SetUint64(msg.Gas()), basefee))
+	results := make([][]interface{}, len(bundles))
+	for i, bundle := range bundles {
+		header := block.Header()
+		blockCtx := blockchain.NewEVMBlockContext(header, newChainContext(ctx, api.backend), nil)
+		if config != nil {
+			config.BlockOverrides.Apply(&blockCtx, block.Header())
+			header = config.BlockOverrides.MakeHeader(header)
+		}
+		bundle.BlockOverride.Apply(&blockCtx, block.Header())
+		header = bundle.BlockOverride.MakeHeader(header)
 
-	txCtx := blockchain.NewEVMTxContext(msg, block.Header(), api.backend.ChainConfig())
-	blockCtx := blockchain.NewEVMBlockContext(block.Header(), newChainContext(ctx, api.backend), nil)
+		results[i] = make([]interface{}, len(bundle.Transactions))
+		for j, args := range bundle.Transactions {
+			msg, err := api.callMessage(args, header)
+			if err != nil {
+				return nil, fmt.Errorf("bundle %d, call %d: %v", i, j, err)
+			}
+			// Unlike TraceCall, only the shortfall of the gas fee is credited, since
+			// the balance is carried over to the following calls.
+			feePayer := msg.ValidatedFeePayer()
+			topUp := new(big.Int).Sub(callGasFee(msg, header), statedb.GetBalance(feePayer))
+			if topUp.Sign() > 0 {
+				statedb.AddBalance(feePayer, topUp)
+			} else {
+				topUp.SetUint64(0)
+			}
+			txCtx := blockchain.NewEVMTxContext(msg, header, api.backend.ChainConfig())
+			res, err := api.traceTx(ctx, msg, blockCtx, txCtx, statedb, traceConfig)
+			if err != nil {
+				return nil, fmt.Errorf("bundle %d, call %d: %v", i, j, err)
+			}
+			results[i][j] = res
 
-	return api.traceTx(ctx, msg, blockCtx, txCtx, statedb, config)
+			// Take back the unspent top-up so the following calls see the real balance
+			if balance := statedb.GetBalance(feePayer); balance.Cmp(topUp) < 0 {
+				topUp = balance
+			}
+			statedb.SubBalance(feePayer, topUp)
+
+			// Finalize the state so the next call sees the modifications
+			statedb.Finalise(true, true)
+		}
+	}
+	return results, nil
+}
+
+// callMessage converts the given call arguments into a message executed on top
+// of the given header. The call arguments have no fee payer, so the sender of
+// the message pays for its gas.
+func (api *CommonAPI) callMessage(args kaiaapi.CallArgs, header *types.Header) (*types.Transaction, error) {
+	intrinsicGas, err := types.IntrinsicGas(args.InputData(), nil, args.To == nil, api.backend.ChainConfig().Rules(header.Number))
+	if err != nil {
+		return nil, err
+	}
+	basefee := new(big.Int).SetUint64(params.ZeroBaseFee)
+	if header.BaseFee != nil {
+		basefee = header.BaseFee
+	}
+	gasCap := uint64(0)
+	if rpcGasCap := api.backend.RPCGasCap(); rpcGasCap != nil {
+		gasCap = rpcGasCap.Uint64()
+	}
+	return args.ToMessage(gasCap, basefee, intrinsicGas)
+}
+
+// callGasFee returns the fee for the gas limit of a call message executed on
+// top of the given header.
+func callGasFee(msg *types.Transaction, header *types.Header) *big.Int {
+	basefee := new(big.Int).SetUint64(params.ZeroBaseFee)
+	if header.BaseFee != nil {
+		basefee = header.BaseFee
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(msg.Gas()), basefee)
+}
+
+// traceTimeout returns the timeout of a single transaction trace.
+func traceTimeout(config *TraceConfig) (time.Duration, error) {
+	if config.Timeout == nil {
+		return defaultTraceTimeout, nil
+	}
+	return time.ParseDuration(*config.Timeout)
 }
 
 // traceTx configures a new tracer according to the provided configuration, and
@@ -888,21 +1126,42 @@ func (api *CommonAPI) TraceCall(ctx context.Context, args kaiaapi.CallArgs, bloc
 func (api *CommonAPI) traceTx(ctx context.Context, message blockchain.Message, blockCtx vm.BlockContext, txCtx vm.TxContext, statedb *state.StateDB, config *TraceConfig) (interface{}, error) {
 	// Assemble the structured logger or the JavaScript tracer
 	var (
-		tracer vm.Tracer
-		err    error
+		tracer  vm.Tracer
+		dump    *traceFile
+		timeout time.Duration
+		err     error
 	)
 	switch {
+	case config != nil && config.StreamToFile && config.Tracer != nil:
+		return nil, errors.New("streaming to file is only supported by the struct logger")
+
+	case config != nil && config.StreamToFile:
+		// Writing files on the node is only allowed to the private debug API
+		if !api.unsafeTrace {
+			return nil, errors.New("streaming to file is only supported by the private debug API")
+		}
+		if timeout, err = traceTimeout(config); err != nil {
+			return nil, err
+		}
+		// Stream the struct logs into a file instead of collecting them in memory
+		if dump, err = newTraceFile("trace-*.jsonl", config.Gzip); err != nil {
+			return nil, err
+		}
+		tracer = vm.NewJSONLogger(config.LogConfig, dump)
+
 	case config != nil && config.Tracer != nil:
 		// Define a meaningful timeout of a single transaction trace
-		timeout := defaultTraceTimeout
-		if config.Timeout != nil {
-			if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
-				return nil, err
-			}
+		if timeout, err = traceTimeout(config); err != nil {
+			return nil, err
 		}
 
 		if *config.Tracer == fastCallTracer {
 			tracer = vm.NewInternalTxTracer()
+		} else if ctor, ok := nativeTracer(*config.Tracer); ok {
+			// Native tracers take precedence over the JavaScript ones of the same name
+			if tracer, err = ctor(new(Context), config.TracerConfig); err != nil {
+				return nil, err
+			}
 		} else {
 			// Construct the JavaScript tracer to execute with
 			if tracer, err = New(*config.Tracer, new(Context), api.unsafeTrace); err != nil {
@@ -919,6 +1178,8 @@ func (api *CommonAPI) traceTx(ctx context.Context, message blockchain.Message, b
 					t.Stop(errors.New("execution timeout"))
 				case *vm.InternalTxTracer:
 					t.Stop(errors.New("execution timeout"))
+				case NativeTracer:
+					t.Stop(errors.New("execution timeout"))
 				default:
 					logger.Warn("unknown tracer type", "type", reflect.TypeOf(t).String())
 				}
@@ -932,15 +1193,47 @@ func (api *CommonAPI) traceTx(ctx context.Context, message blockchain.Message, b
 	default:
 		tracer = vm.NewStructLogger(config.LogConfig)
 	}
+	if t, ok := tracer.(StateTracer); ok {
+		t.CapturePreState(statedb, message)
+	}
 	// Run the transaction with tracing enabled.
 	vmenv := vm.NewEVM(blockCtx, txCtx, statedb, api.backend.ChainConfig(), &vm.Config{Debug: true, Tracer: tracer})
 
+	if dump != nil {
+		// The JSON logger can't be stopped, so abort the execution on timeout
+		deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
+		go func() {
+			<-deadlineCtx.Done()
+			if errors.Is(deadlineCtx.Err(), context.DeadlineExceeded) {
+				vmenv.Cancel(vm.CancelByCtxDone)
+			}
+		}()
+		defer cancel()
+	}
 	ret, err := blockchain.ApplyMessage(vmenv, message)
+	if dump != nil && err == nil && vmenv.Cancelled() {
+		err = errors.New("execution timeout")
+	}
 	if err != nil {
+		if dump != nil {
+			dump.Remove()
+		}
 		return nil, fmt.Errorf("tracing failed: %v", err)
 	}
 	// Depending on the tracer type, format and return the output
 	switch tracer := tracer.(type) {
+	case *vm.JSONLogger:
+		if err := dump.Close(); err != nil {
+			return nil, err
+		}
+		logger.Info("Wrote standard trace", "file", dump.Name())
+		return &streamTraceResult{
+			Gas:         ret.UsedGas,
+			Failed:      ret.Failed(),
+			ReturnValue: fmt.Sprintf("%x", ret.Return()),
+			File:        dump.Name(),
+		}, nil
+
 	case *vm.StructLogger:
 		loggerTimeout := defaultLoggerTimeout
 		if config != nil && config.LoggerTimeout != nil {
@@ -963,6 +1256,8 @@ func (api *CommonAPI) traceTx(ctx context.Context, message blockchain.Message, b
 		return tracer.GetResult()
 	case *vm.InternalTxTracer:
 		return tracer.GetResult()
+	case NativeTracer:
+		return tracer.GetResult()
 
 	default:
 		panic(fmt.Sprintf("bad tracer type %T", tracer))
@@ -20,20 +20,28 @@
 package tracers
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/ecdsa"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	"os"
 	"reflect"
+	"runtime"
 	"sort"
+	"strings"
 	"testing"
 
 	kaiaapi "github.com/klaytn/klaytn/api"
 	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/state"
 	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
 	"github.com/klaytn/klaytn/blockchain/vm"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/hexutil"
@@ -202,11 +210,12 @@ func TestTraceCall(t *testing.T) {
 		assert.NoError(t, err)
 		b.AddTx(tx)
 	}))
+	overrideBalance := (*hexutil.Big)(big.NewInt(1000))
 
 	testSuite := []struct {
 		blockNumber rpc.BlockNumber
 		call        kaiaapi.CallArgs
-		config      *TraceConfig
+		config      *TraceCallConfig
 		expectErr   error
 		expect      interface{}
 	}{
@@ -222,6 +231,25 @@ func TestTraceCall(t *testing.T) {
 			expectErr: errors.New("tracing failed: insufficient balance for transfer"),
 			expect:    nil,
 		},
+		// Standard JSON trace upon the genesis with the balance overridden, plain transfer.
+		{
+			blockNumber: rpc.BlockNumber(0),
+			call: kaiaapi.CallArgs{
+				From:  accounts[0].addr,
+				To:    &accounts[1].addr,
+				Value: (hexutil.Big)(*big.NewInt(1000)),
+			},
+			config: &TraceCallConfig{StateOverrides: &kaiaapi.EthStateOverride{
+				accounts[0].addr: kaiaapi.EthOverrideAccount{Balance: &overrideBalance},
+			}},
+			expectErr: nil,
+			expect: &kaiaapi.ExecutionResult{
+				Gas:         params.TxGas,
+				Failed:      false,
+				ReturnValue: "",
+				StructLogs:  []kaiaapi.StructLogRes{},
+			},
+		},
 		// Standard JSON trace upon the head, plain transfer.
 		{
 			blockNumber: rpc.BlockNumber(genBlocks),
@@ -293,6 +321,120 @@ func TestTraceCall(t *testing.T) {
 	}
 }
 
+func TestTraceCallMany(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts
+	accounts := newAccounts(3)
+	genesis := &blockchain.Genesis{Alloc: blockchain.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(0)},
+		accounts[1].addr: {Balance: big.NewInt(1000 * 10)},
+		accounts[2].addr: {Balance: big.NewInt(0)},
+	}}
+	genBlocks := 10
+	signer := types.LatestSignerForChainID(params.TestChainConfig.ChainID)
+	api := NewAPI(newTestBackend(t, genBlocks, genesis, func(i int, b *blockchain.BlockGen) {
+		// Transfer from account[1] to account[0]
+		//    value: 1000 kei
+		//    fee:   0 kei
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), accounts[0].addr, big.NewInt(1000), params.TxGas, big.NewInt(0), nil), signer, accounts[1].key)
+		assert.NoError(t, err)
+		b.AddTx(tx)
+	}))
+	transfer := &kaiaapi.ExecutionResult{
+		Gas:         params.TxGas,
+		Failed:      false,
+		ReturnValue: "",
+		StructLogs:  []kaiaapi.StructLogRes{},
+	}
+	head := rpc.NewBlockNumberOrHashWithNumber(rpc.BlockNumber(genBlocks))
+
+	// account[2] can only pay account[1] after receiving from account[0] in the previous bundle.
+	bundles := []Bundle{
+		{Transactions: []kaiaapi.CallArgs{{From: accounts[0].addr, To: &accounts[2].addr, Value: (hexutil.Big)(*big.NewInt(5000))}}},
+		{Transactions: []kaiaapi.CallArgs{
+			{From: accounts[2].addr, To: &accounts[1].addr, Value: (hexutil.Big)(*big.NewInt(3000))},
+			{From: accounts[2].addr, To: &accounts[1].addr, Value: (hexutil.Big)(*big.NewInt(2000))},
+		}},
+	}
+	result, err := api.TraceCallMany(context.Background(), bundles, head, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{transfer}, {transfer, transfer}}, result)
+
+	// The whole balance of account[2] has been spent, so another transfer fails.
+	bundles = append(bundles, Bundle{Transactions: []kaiaapi.CallArgs{{From: accounts[2].addr, To: &accounts[1].addr, Value: (hexutil.Big)(*big.NewInt(1))}}})
+	_, err = api.TraceCallMany(context.Background(), bundles, head, nil)
+	assert.Equal(t, errors.New("bundle 2, call 0: tracing failed: insufficient balance for transfer"), err)
+
+	// State overrides are applied before the first bundle.
+	balance := (*hexutil.Big)(big.NewInt(1))
+	config := &TraceCallConfig{StateOverrides: &kaiaapi.EthStateOverride{
+		accounts[2].addr: kaiaapi.EthOverrideAccount{Balance: &balance},
+	}}
+	result, err = api.TraceCallMany(context.Background(), bundles, head, config)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{transfer}, {transfer, transfer}, {transfer}}, result)
+
+	// Empty bundle list is rejected.
+	_, err = api.TraceCallMany(context.Background(), nil, head, nil)
+	assert.Error(t, err)
+}
+
+func TestTraceCallManyTopUp(t *testing.T) {
+	t.Parallel()
+
+	// The contract returns the balance of its caller.
+	accounts := newAccounts(1)
+	contract := common.HexToAddress("0x000000000000000000000000000000000000ba1a")
+	genesis := &blockchain.Genesis{Alloc: blockchain.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(10000)},
+		contract:         {Balance: big.NewInt(0), Code: common.FromHex("0x333160005260206000f3")},
+	}}
+	api := NewAPI(newTestBackend(t, 1, genesis, func(i int, b *blockchain.BlockGen) {}))
+	head := rpc.NewBlockNumberOrHashWithNumber(rpc.BlockNumber(1))
+
+	// The fee of each call exceeds the balance of the sender, which is topped
+	// up for every call without accumulating across the calls.
+	gas := hexutil.Uint64(50000)
+	call := kaiaapi.CallArgs{From: accounts[0].addr, To: &contract, Gas: gas}
+	bundles := []Bundle{{
+		Transactions:  []kaiaapi.CallArgs{call, call},
+		BlockOverride: &kaiaapi.EthBlockOverrides{BaseFee: (*hexutil.Big)(big.NewInt(1))},
+	}}
+	result, err := api.TraceCallMany(context.Background(), bundles, head, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, result, 1) && assert.Len(t, result[0], 2) {
+		first, second := result[0][0].(*kaiaapi.ExecutionResult), result[0][1].(*kaiaapi.ExecutionResult)
+		assert.Equal(t, fmt.Sprintf("%064x", 0), first.ReturnValue)
+		assert.Equal(t, first.ReturnValue, second.ReturnValue)
+	}
+}
+
+func TestTraceCallTopUp(t *testing.T) {
+	t.Parallel()
+
+	// The contract returns the balance of its caller.
+	accounts := newAccounts(1)
+	contract := common.HexToAddress("0x000000000000000000000000000000000000ba1a")
+	genesis := &blockchain.Genesis{Alloc: blockchain.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(10000)},
+		contract:         {Balance: big.NewInt(0), Code: common.FromHex("0x333160005260206000f3")},
+	}}
+	api := NewAPI(newTestBackend(t, 1, genesis, func(i int, b *blockchain.BlockGen) {}))
+	head := rpc.NewBlockNumberOrHashWithNumber(rpc.BlockNumber(1))
+
+	// Unlike TraceCallMany, the whole gas fee is credited to the sender, so the
+	// sender keeps its own balance after paying for the gas.
+	gas := hexutil.Uint64(50000)
+	call := kaiaapi.CallArgs{From: accounts[0].addr, To: &contract, Gas: gas}
+	config := &TraceCallConfig{BlockOverrides: &kaiaapi.EthBlockOverrides{BaseFee: (*hexutil.Big)(big.NewInt(1))}}
+	result, err := api.TraceCall(context.Background(), call, head, config)
+	assert.NoError(t, err)
+	if res, ok := result.(*kaiaapi.ExecutionResult); assert.True(t, ok) {
+		assert.Equal(t, fmt.Sprintf("%064x", 10000), res.ReturnValue)
+	}
+}
+
 func TestTraceTransaction(t *testing.T) {
 	t.Parallel()
 
@@ -326,6 +468,97 @@ func TestTraceTransaction(t *testing.T) {
 	}
 }
 
+func TestTraceTransactionStreamToFile(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts, a contract storing 1 at slot 0 and an endless loop
+	accounts := newAccounts(1)
+	contract := common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+	loop := common.HexToAddress("0x00000000000000000000000000000000cafebabe")
+	genesis := &blockchain.Genesis{Alloc: blockchain.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.KAIA)},
+		contract: {
+			Balance: big.NewInt(0),
+			Code:    []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.SSTORE)},
+		},
+		loop: {
+			Balance: big.NewInt(0),
+			Code:    []byte{byte(vm.JUMPDEST), byte(vm.PUSH1), 0x00, byte(vm.JUMP)},
+		},
+	}}
+	target, loopTarget := common.Hash{}, common.Hash{}
+	signer := types.LatestSignerForChainID(params.TestChainConfig.ChainID)
+	backend := newTestBackend(t, 1, genesis, func(i int, b *blockchain.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(accounts[0].addr), contract, big.NewInt(0), 100000, big.NewInt(1), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+		target = tx.Hash()
+		tx, _ = types.SignTx(types.NewTransaction(b.TxNonce(accounts[0].addr), loop, big.NewInt(0), 10000000, big.NewInt(1), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+		loopTarget = tx.Hash()
+	})
+	api := NewUnsafeAPI(backend)
+
+	for _, compress := range []bool{false, true} {
+		result, err := api.TraceTransaction(context.Background(), target, &TraceConfig{StreamToFile: true, Gzip: compress})
+		if err != nil {
+			t.Fatalf("failed to trace transaction: %v", err)
+		}
+		res, ok := result.(*streamTraceResult)
+		if !ok {
+			t.Fatalf("unexpected result type %T", result)
+		}
+		defer os.Remove(res.File)
+		assert.False(t, res.Failed)
+		assert.Equal(t, compress, strings.HasSuffix(res.File, ".gz"))
+
+		f, err := os.Open(res.File)
+		if err != nil {
+			t.Fatalf("failed to open trace file: %v", err)
+		}
+		defer f.Close()
+		var r io.Reader = f
+		if compress {
+			if r, err = gzip.NewReader(f); err != nil {
+				t.Fatalf("failed to open gzip reader: %v", err)
+			}
+		}
+
+		// Each line is a struct log of an opcode, followed by the summary line.
+		var lines []map[string]interface{}
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			var line map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+			}
+			lines = append(lines, line)
+		}
+		assert.NoError(t, scanner.Err())
+		if assert.Len(t, lines, 5) {
+			assert.Equal(t, "PUSH1", lines[0]["opName"])
+			assert.Equal(t, "SSTORE", lines[2]["opName"])
+			assert.Equal(t, "STOP", lines[3]["opName"])
+			assert.Contains(t, lines[4], "gasUsed")
+		}
+	}
+
+	// Streaming to file is not supported by the other tracers.
+	tracer := "callTracer"
+	_, err := api.TraceTransaction(context.Background(), target, &TraceConfig{Tracer: &tracer, StreamToFile: true})
+	assert.Error(t, err)
+
+	// Nor by the public debug API.
+	_, err = NewAPI(backend).TraceTransaction(context.Background(), target, &TraceConfig{StreamToFile: true})
+	assert.Error(t, err)
+
+	// The execution is aborted on timeout.
+	timeout := "10ms"
+	_, err = api.TraceTransaction(context.Background(), loopTarget, &TraceConfig{StreamToFile: true, Timeout: &timeout})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "execution timeout")
+	}
+}
+
 func TestTraceBlock(t *testing.T) {
 	t.Parallel()
 
@@ -442,6 +675,166 @@ func TestTraceBlock(t *testing.T) {
 	}
 }
 
+func TestTraceBlockInvalidSender(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts
+	accounts := newAccounts(2)
+	genesis := &blockchain.Genesis{Alloc: blockchain.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.KAIA)},
+	}}
+	genBlocks, txsPerBlock := 1, 4
+	signer := types.LatestSignerForChainID(params.TestChainConfig.ChainID)
+	backend := newTestBackend(t, genBlocks, genesis, func(i int, b *blockchain.BlockGen) {
+		for j := 0; j < txsPerBlock; j++ {
+			tx, _ := types.NewTransactionWithMap(types.TxTypeValueTransfer, map[types.TxValueKeyType]interface{}{
+				types.TxValueKeyNonce:    uint64(j),
+				types.TxValueKeyFrom:     accounts[0].addr,
+				types.TxValueKeyTo:       accounts[1].addr,
+				types.TxValueKeyAmount:   big.NewInt(int64(1000 + j)),
+				types.TxValueKeyGasLimit: params.TxGas,
+				types.TxValueKeyGasPrice: big.NewInt(0),
+			})
+			tx.SignWithKeys(signer, []*ecdsa.PrivateKey{accounts[0].key})
+			b.AddTx(tx)
+		}
+	})
+	api := NewUnsafeAPI(backend)
+
+	block, err := api.blockByNumber(context.Background(), rpc.BlockNumber(genBlocks))
+	if err != nil {
+		t.Fatalf("failed to get block: %v", err)
+	}
+	parent, err := api.blockByNumber(context.Background(), rpc.BlockNumber(genBlocks-1))
+	if err != nil {
+		t.Fatalf("failed to get parent block: %v", err)
+	}
+	// The signatures of the sender can't be validated anymore, so the block is
+	// not traceable regardless of the parallelism.
+	invalidState := func() *state.StateDB {
+		statedb, err := backend.StateAtBlock(context.Background(), parent, defaultTraceReexec, nil, true, false)
+		if err != nil {
+			t.Fatalf("failed to get parent state: %v", err)
+		}
+		if err := statedb.UpdateKey(accounts[0].addr, accountkey.NewAccountKeyFail(), block.NumberU64()); err != nil {
+			t.Fatalf("failed to update the key: %v", err)
+		}
+		return statedb
+	}
+	if _, err := api.traceBlockSequential(context.Background(), block, invalidState(), nil); err == nil {
+		t.Error("sequential tracing must fail")
+	}
+	if _, err := api.traceBlockParallel(context.Background(), block, invalidState(), nil, 2); err == nil {
+		t.Error("parallel tracing must fail")
+	}
+}
+
+func TestTraceBlockParallelism(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts
+	accounts := newAccounts(3)
+	genesis := &blockchain.Genesis{Alloc: blockchain.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.KAIA)},
+		accounts[1].addr: {Balance: big.NewInt(params.KAIA)},
+		accounts[2].addr: {Balance: big.NewInt(params.KAIA)},
+	}}
+	genBlocks, txsPerBlock := 2, 8
+	signer := types.LatestSignerForChainID(params.TestChainConfig.ChainID)
+	api := NewAPI(newTestBackend(t, genBlocks, genesis, func(i int, b *blockchain.BlockGen) {
+		// Transfer from account[0] to account[1] and account[2] alternately
+		for j := 0; j < txsPerBlock; j++ {
+			nonce := uint64(i*txsPerBlock + j)
+			tx, _ := types.SignTx(types.NewTransaction(nonce, accounts[1+j%2].addr, big.NewInt(int64(1000+j)), params.TxGas, big.NewInt(0), nil), signer, accounts[0].key)
+			b.AddTx(tx)
+		}
+	}))
+
+	tracer := "callTracer"
+	trace := func(parallelism *int) []*txTraceResult {
+		result, err := api.TraceBlockByNumber(context.Background(), rpc.BlockNumber(genBlocks), &TraceConfig{Tracer: &tracer, Parallelism: parallelism})
+		if err != nil {
+			t.Fatalf("failed to trace block: %v", err)
+		}
+		if len(result) != txsPerBlock {
+			t.Fatalf("result length mismatch, want %d, get %d", txsPerBlock, len(result))
+		}
+		return result
+	}
+
+	// All the tracing results must be the same regardless of the parallelism.
+	expected := trace(nil)
+	for _, parallelism := range []int{1, 2, 3, 64} {
+		p := parallelism
+		result := trace(&p)
+		for idx, r := range result {
+			if r.Error != "" {
+				t.Errorf("parallelism %d: tx %d failed: %s", p, idx, r.Error)
+			}
+			if r.TxHash != expected[idx].TxHash {
+				t.Errorf("parallelism %d: tx hash mismatch, want %v, get %v", p, expected[idx].TxHash, r.TxHash)
+			}
+			if !reflect.DeepEqual(r.Result, expected[idx].Result) {
+				t.Errorf("parallelism %d: result mismatch, want %v, get %v", p, expected[idx].Result, r.Result)
+			}
+		}
+	}
+
+	// The parallelism is limited to the number of CPUs in the public API only.
+	p, jobs := runtime.NumCPU()+1, runtime.NumCPU()+2
+	assert.Equal(t, runtime.NumCPU(), api.traceParallelism(&TraceConfig{Parallelism: &p}, jobs))
+	assert.Equal(t, p, NewUnsafeAPI(api.backend).traceParallelism(&TraceConfig{Parallelism: &p}, jobs))
+}
+
+func TestTraceBlockTxError(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts
+	accounts := newAccounts(2)
+	genesis := &blockchain.Genesis{Alloc: blockchain.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.KAIA)},
+	}}
+	genBlocks, txsPerBlock := 1, 4
+	signer := types.LatestSignerForChainID(params.TestChainConfig.ChainID)
+	api := NewUnsafeAPI(newTestBackend(t, genBlocks, genesis, func(i int, b *blockchain.BlockGen) {
+		for j := 0; j < txsPerBlock; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(uint64(j), accounts[1].addr, big.NewInt(int64(1000+j)), params.TxGas, big.NewInt(0), nil), signer, accounts[0].key)
+			b.AddTx(tx)
+		}
+	}))
+
+	// The tracer fails only for the transaction transferring 1001 kei.
+	tracer := `{step: function() {}, fault: function() {}, result: function(ctx) { if (ctx.value.toString() == "1001") { throw "boom"; } return ctx.value.toString(); }}`
+	block, err := api.blockByNumber(context.Background(), rpc.BlockNumber(genBlocks))
+	if err != nil {
+		t.Fatalf("failed to get block: %v", err)
+	}
+	for _, parallelism := range []int{1, 2} {
+		p := parallelism
+		result, err := api.traceBlock(context.Background(), block, &TraceConfig{Tracer: &tracer, Parallelism: &p})
+		if err != nil {
+			t.Fatalf("parallelism %d: failed to trace block: %v", p, err)
+		}
+		if len(result) != txsPerBlock {
+			t.Fatalf("parallelism %d: result length mismatch, want %d, get %d", p, txsPerBlock, len(result))
+		}
+		for idx, r := range result {
+			if idx == 1 {
+				if r.Error == "" || r.Result != nil {
+					t.Errorf("parallelism %d: tx %d must fail, get %v", p, idx, r.Result)
+				}
+				continue
+			}
+			if r.Error != "" {
+				t.Errorf("parallelism %d: tx %d failed: %s", p, idx, r.Error)
+			}
+			if want := fmt.Sprintf(`"%d"`, 1000+idx); !reflect.DeepEqual(r.Result, json.RawMessage(want)) {
+				t.Errorf("parallelism %d: tx %d result mismatch, want %s, get %s", p, idx, want, r.Result)
+			}
+		}
+	}
+}
+
 type Account struct {
 	key  *ecdsa.PrivateKey
 	addr common.Address
@@ -23,5 +23,8 @@ Source Files
   - tracer.go  : implementation of Tracer
   - tracers.go : provides managing functions of tracers
   - api.go     : provides private debug API related to trace chain, block and state
+
+Native Go tracers registered by the package native take precedence over the
+JavaScript tracers of the same name.
 */
 package tracers
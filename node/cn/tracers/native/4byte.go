@@ -0,0 +1,155 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/node/cn/tracers"
+)
+
+func init() {
+	tracers.RegisterNativeTracer("4byteTracer", newFourByteTracer)
+}
+
+// fourByteTracer is a go implementation of 4byte_tracer.js. It searches for
+// 4byte-identifiers, and collects them for post-processing.
+// It collects the methods identifiers along with the size of the supplied data, so
+// a reversed signature can be matched against the size of the data.
+//
+// Example:
+//
+//	> debug.traceTransaction( "0x214e597e35da083692f5386141e69f47e973b2c56e7a8073b1ea08fd7571e9de", {tracer: "4byteTracer"})
+//	{
+//	  0x27dc297e-128: 1,
+//	  0x38cc4831-0: 2,
+//	  0x524f3889-96: 1,
+//	  0xadf59f99-288: 1,
+//	  0xc281d19e-0: 1
+//	}
+type fourByteTracer struct {
+	ids   map[string]int // ids aggregates the 4byte ids found
+	keys  []string       // keys of ids in the order they were found
+	input []byte         // input of the outer call
+
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+// newFourByteTracer returns a native go tracer which collects
+// 4 byte-identifiers of a tx, and implements vm.Tracer.
+func newFourByteTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.NativeTracer, error) {
+	return &fourByteTracer{ids: make(map[string]int)}, nil
+}
+
+// store saves the given identifier and datasize.
+func (t *fourByteTracer) store(id []byte, size int) {
+	key := hexutil.Encode(id) + "-" + strconv.Itoa(size)
+	if _, ok := t.ids[key]; !ok {
+		t.keys = append(t.keys, key)
+	}
+	t.ids[key] += 1
+}
+
+func (t *fourByteTracer) CaptureTxStart(gasLimit uint64) {}
+
+func (t *fourByteTracer) CaptureTxEnd(restGas uint64) {}
+
+// CaptureStart implements the vm.Tracer interface to initialize the tracing operation.
+func (t *fourByteTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.input = common.CopyBytes(input)
+}
+
+func (t *fourByteTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+
+func (t *fourByteTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+func (t *fourByteTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+// CaptureState implements the vm.Tracer interface to trace a single step of VM execution.
+func (t *fourByteTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+	// Skip if tracing was interrupted
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		return
+	}
+	// Skip any opcodes that are not internal calls. The offset points to
+	// the input memory offset of the call on the stack.
+	var offset int
+	switch op {
+	case vm.CALL, vm.CALLCODE:
+		offset = 3 // gas, addr, val, memin, meminsz, memout, memoutsz
+	case vm.DELEGATECALL, vm.STATICCALL:
+		offset = 2 // gas, addr, memin, meminsz, memout, memoutsz
+	default:
+		return
+	}
+	stack := scope.Stack
+	if len(stack.Data()) < offset+2 {
+		return
+	}
+	// Skip any pre-compile invocations, those are just fancy opcodes
+	if _, ok := vm.PrecompiledContractsByzantium[common.Address(stack.Back(1).Bytes20())]; ok {
+		return
+	}
+	// Gather internal call details
+	inSize := stack.Back(offset + 1)
+	if !inSize.IsUint64() || inSize.Uint64() < 4 {
+		return
+	}
+	inOff := stack.Back(offset)
+	if !inOff.IsUint64() || uint64(scope.Memory.Len()) < inOff.Uint64()+4 {
+		// Out of bound memory access yields an empty identifier, like the JavaScript tracer
+		t.store(nil, int(inSize.Uint64()-4))
+		return
+	}
+	t.store(scope.Memory.GetCopy(int64(inOff.Uint64()), 4), int(inSize.Uint64()-4))
+}
+
+func (t *fourByteTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// GetResult returns the json-encoded 4byte-identifiers with their call counts,
+// and any error arising from the encoding or forceful termination (via `Stop`).
+func (t *fourByteTracer) GetResult() (json.RawMessage, error) {
+	// Save the outer calldata also
+	if len(t.input) >= 4 {
+		t.store(t.input[:4], len(t.input)-4)
+	}
+	// Keep the ids in the order they were found, as the JavaScript tracer does
+	counts := make([]interface{}, len(t.keys))
+	for i, key := range t.keys {
+		counts[i] = t.ids[key]
+	}
+	res, err := marshalOrdered(t.keys, counts)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *fourByteTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
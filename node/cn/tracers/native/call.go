@@ -0,0 +1,118 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/node/cn/tracers"
+)
+
+func init() {
+	tracers.RegisterNativeTracer("callTracer", newCallTracer)
+}
+
+// callTracer is a go implementation of call_tracer.js. It shares the tracing
+// logic with the fastCallTracer, but returns the json-encoded result as the
+// JavaScript tracer does.
+type callTracer struct {
+	*vm.InternalTxTracer
+}
+
+// callFrame is a call trace laid out with the fields and the field order the
+// JavaScript tracer reports.
+type callFrame struct {
+	Type     string          `json:"type"`
+	From     *common.Address `json:"from,omitempty"`
+	To       *common.Address `json:"to,omitempty"`
+	Value    string          `json:"value,omitempty"`
+	Gas      *hexutil.Uint64 `json:"gas,omitempty"`
+	GasUsed  *hexutil.Uint64 `json:"gasUsed,omitempty"`
+	Input    string          `json:"input,omitempty"`
+	Output   string          `json:"output,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Time     *int64          `json:"time,omitempty"`
+	Calls    []*callFrame    `json:"calls,omitempty"`
+	Reverted *revertedFrame  `json:"reverted,omitempty"`
+}
+
+type revertedFrame struct {
+	Contract *common.Address `json:"contract"`
+	Message  *string         `json:"message,omitempty"`
+}
+
+// newCallTracer returns a new call tracer.
+func newCallTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.NativeTracer, error) {
+	return &callTracer{InternalTxTracer: vm.NewInternalTxTracer()}, nil
+}
+
+// GetResult returns the json-encoded nested list of call traces, and any
+// error arising from the encoding or forceful termination (via `Stop`).
+func (t *callTracer) GetResult() (json.RawMessage, error) {
+	result, err := t.InternalTxTracer.GetResult()
+	if err != nil {
+		return nil, err
+	}
+	// The outer call always reports the gas and the elapsed time, which the
+	// JavaScript tracer never measures.
+	frame := newCallFrame(result)
+	gas, gasUsed, elapsed := hexutil.Uint64(result.Gas), hexutil.Uint64(result.GasUsed), int64(0)
+	frame.Gas, frame.GasUsed, frame.Time = &gas, &gasUsed, &elapsed
+	if result.Reverted != nil {
+		// The message is reported only if the output is encoded as Error(string)
+		frame.Reverted = &revertedFrame{Contract: result.Reverted.Contract}
+		if strings.HasPrefix(result.Output, "0x08c379a0") {
+			frame.Reverted.Message = &result.Reverted.Message
+		}
+	}
+	return json.Marshal(frame)
+}
+
+// newCallFrame converts the given inner call trace into a callFrame.
+func newCallFrame(trace *vm.InternalTxTrace) *callFrame {
+	frame := &callFrame{
+		Type:   trace.Type,
+		From:   trace.From,
+		To:     trace.To,
+		Value:  trace.Value,
+		Input:  trace.Input,
+		Output: trace.Output,
+	}
+	if trace.Error != nil {
+		frame.Error = trace.Error.Error()
+	}
+	// The gas is known only for calls executing code. The gas used is known
+	// for those, and for the creations which didn't fault.
+	if trace.Gas != 0 {
+		gas := hexutil.Uint64(trace.Gas)
+		frame.Gas = &gas
+	}
+	created := (trace.Type == vm.CREATE.String() || trace.Type == vm.CREATE2.String()) &&
+		(frame.Error == "" || frame.Error == "internal failure" || frame.Error == "execution reverted")
+	if trace.Gas != 0 || trace.GasUsed != 0 || created {
+		gasUsed := hexutil.Uint64(trace.GasUsed)
+		frame.GasUsed = &gasUsed
+	}
+	for _, call := range trace.Calls {
+		frame.Calls = append(frame.Calls, newCallFrame(call))
+	}
+	return frame
+}
@@ -0,0 +1,29 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package native provides Go implementations of the built in JavaScript tracers.
+The tracers register themselves to the tracers package on import, and are then
+selected by the same name as their JavaScript counterparts, producing the same output.
+
+Source Files
+
+  - 4byte.go    : implementation of 4byteTracer
+  - call.go     : implementation of callTracer
+  - noop.go     : implementation of noopTracer
+  - prestate.go : implementation of prestateTracer
+*/
+package native
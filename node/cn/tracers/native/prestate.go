@@ -0,0 +1,389 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/node/cn/tracers"
+)
+
+func init() {
+	tracers.RegisterNativeTracer("prestateTracer", newPrestateTracer)
+}
+
+type prestate = map[common.Address]*account
+
+type account struct {
+	Balance string                      `json:"balance"`
+	Nonce   uint64                      `json:"nonce"`
+	Code    string                      `json:"code"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+
+	slots []common.Hash // keys of Storage in the order they were accessed
+}
+
+// MarshalJSON encodes the account with the storage slots in the order they
+// were accessed, as the JavaScript tracer does.
+func (a *account) MarshalJSON() ([]byte, error) {
+	keys, values := make([]string, len(a.slots)), make([]interface{}, len(a.slots))
+	for i, slot := range a.slots {
+		keys[i], values[i] = slot.Hex(), a.Storage[slot]
+	}
+	storage, err := marshalOrdered(keys, values)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&struct {
+		Balance string          `json:"balance"`
+		Nonce   uint64          `json:"nonce"`
+		Code    string          `json:"code"`
+		Storage json.RawMessage `json:"storage"`
+	}{a.Balance, a.Nonce, a.Code, storage})
+}
+
+// diffAccount is an account reported in diff mode. Only the modified fields
+// are set for the post state, and the account key is reported only when it
+// has been changed, e.g. by TxTypeAccountUpdate.
+type diffAccount struct {
+	Balance *hexutil.Big                     `json:"balance,omitempty"`
+	Nonce   uint64                           `json:"nonce,omitempty"`
+	Code    hexutil.Bytes                    `json:"code,omitempty"`
+	Key     *accountkey.AccountKeySerializer `json:"key,omitempty"`
+	Storage map[common.Hash]common.Hash      `json:"storage,omitempty"`
+}
+
+type diffResult struct {
+	Pre  map[common.Address]*diffAccount `json:"pre"`
+	Post map[common.Address]*diffAccount `json:"post"`
+}
+
+type prestateTracerConfig struct {
+	DiffMode bool `json:"diffMode"` // If true, this tracer will return state modifications
+}
+
+// prestateTracer is a go implementation of prestate_tracer.js. It collects
+// the state of the accounts and storage slots touched by a transaction as it
+// was before the execution.
+// In diff mode, it reports both the state before and after the execution of
+// the accounts and storage slots modified by the transaction instead.
+type prestateTracer struct {
+	env      *vm.EVM
+	prestate prestate
+	accounts []common.Address // keys of prestate in the order they were accessed
+	create   bool
+	from     common.Address
+	to       common.Address
+	value    *big.Int
+	config   prestateTracerConfig
+
+	// Fields below are used in diff mode only
+	pre     *state.StateDB                              // Copy of the state before the transaction
+	post    *state.StateDB                              // State the transaction is applied to
+	touched map[common.Address]map[common.Hash]struct{} // Accounts and storage slots accessed by the transaction
+
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+// newPrestateTracer returns a new prestate tracer.
+func newPrestateTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.NativeTracer, error) {
+	var config prestateTracerConfig
+	if cfg != nil {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
+	return &prestateTracer{
+		prestate: prestate{},
+		config:   config,
+		touched:  make(map[common.Address]map[common.Hash]struct{}),
+	}, nil
+}
+
+// CapturePreState implements the tracers.StateTracer interface. In diff mode,
+// it keeps a copy of the state before the transaction, and marks the accounts
+// whose balance, nonce or key may change without entering the EVM.
+func (t *prestateTracer) CapturePreState(statedb *state.StateDB, msg blockchain.Message) {
+	if !t.config.DiffMode {
+		return
+	}
+	t.pre = statedb.Copy()
+	t.post = statedb
+
+	t.lookupAccount(msg.ValidatedSender())
+	t.lookupAccount(msg.ValidatedFeePayer())
+	if to := msg.To(); to != nil {
+		t.lookupAccount(*to)
+	}
+}
+
+func (t *prestateTracer) CaptureTxStart(gasLimit uint64) {}
+
+func (t *prestateTracer) CaptureTxEnd(restGas uint64) {}
+
+// CaptureStart implements the vm.Tracer interface to initialize the tracing operation.
+func (t *prestateTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+	t.create = create
+	t.from = from
+	t.to = to
+	t.value = value
+
+	// Balance will potentially be wrong here, since this will include the value
+	// sent along with the message. We fix that in GetResult.
+	t.lookupAccount(to)
+}
+
+func (t *prestateTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+
+func (t *prestateTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+func (t *prestateTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+// CaptureState implements the vm.Tracer interface to trace a single step of VM execution.
+func (t *prestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+	// Skip if tracing was interrupted
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		return
+	}
+	stack := scope.Stack
+	stackLen := len(stack.Data())
+	caller := scope.Contract.Address()
+
+	// Whenever new state is accessed, add it to the prestate
+	switch {
+	case stackLen >= 1 && (op == vm.EXTCODECOPY || op == vm.EXTCODESIZE || op == vm.BALANCE):
+		t.lookupAccount(common.Address(stack.Back(0).Bytes20()))
+	case op == vm.CREATE:
+		t.lookupAccount(crypto.CreateAddress(caller, env.StateDB.GetNonce(caller)))
+	case stackLen >= 4 && op == vm.CREATE2:
+		// stack: endowment, offset, size, salt
+		offset, size := stack.Back(1).Uint64(), stack.Back(2).Uint64()
+		var code []byte
+		if uint64(scope.Memory.Len()) >= offset+size {
+			code = scope.Memory.GetCopy(int64(offset), int64(size))
+		}
+		salt := stack.Back(3).Bytes32()
+		t.lookupAccount(crypto.CreateAddress2(caller, salt, crypto.Keccak256(code)))
+	case stackLen >= 2 && (op == vm.CALL || op == vm.CALLCODE || op == vm.DELEGATECALL || op == vm.STATICCALL):
+		t.lookupAccount(common.Address(stack.Back(1).Bytes20()))
+	case stackLen >= 1 && (op == vm.SSTORE || op == vm.SLOAD):
+		t.lookupStorage(caller, common.Hash(stack.Back(0).Bytes32()))
+	case stackLen >= 1 && op == vm.SELFDESTRUCT && t.config.DiffMode:
+		t.lookupAccount(common.Address(stack.Back(0).Bytes20()))
+	}
+}
+
+func (t *prestateTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// GetResult returns the json-encoded prestate of the touched accounts, and any
+// error arising from the encoding or forceful termination (via `Stop`).
+func (t *prestateTracer) GetResult() (json.RawMessage, error) {
+	if t.config.DiffMode {
+		return t.diffResult()
+	}
+	if t.env == nil {
+		return json.RawMessage(`{}`), t.reason
+	}
+	// At this point, we need to deduct the 'value' from the
+	// outer transaction, and move it back to the origin
+	t.lookupAccount(t.from)
+
+	fromBal := hexutil.MustDecodeBig(t.prestate[t.from].Balance)
+	toBal := hexutil.MustDecodeBig(t.prestate[t.to].Balance)
+	t.prestate[t.to].Balance = hexutil.EncodeBig(new(big.Int).Sub(toBal, t.value))
+	t.prestate[t.from].Balance = hexutil.EncodeBig(new(big.Int).Add(fromBal, t.value))
+
+	// Decrement the caller's nonce, and remove empty create targets
+	t.prestate[t.from].Nonce--
+	if t.create {
+		// We can blindly delete the contract prestate, as any existing state would
+		// have caused the transaction to be rejected as invalid in the first place.
+		delete(t.prestate, t.to)
+	}
+	// Keep the accounts in the order they were accessed, as the JavaScript tracer does
+	var (
+		keys   []string
+		values []interface{}
+	)
+	for _, addr := range t.accounts {
+		if acc, ok := t.prestate[addr]; ok {
+			keys, values = append(keys, hexutil.Encode(addr.Bytes())), append(values, acc)
+		}
+	}
+	res, err := marshalOrdered(keys, values)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *prestateTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// diffResult returns the json-encoded state before and after the transaction
+// of the modified accounts.
+func (t *prestateTracer) diffResult() (json.RawMessage, error) {
+	if t.pre == nil {
+		return nil, errors.New("prestateTracer in diff mode requires the state before the transaction")
+	}
+	result := diffResult{
+		Pre:  make(map[common.Address]*diffAccount),
+		Post: make(map[common.Address]*diffAccount),
+	}
+	for addr, slots := range t.touched {
+		existed := t.pre.Exist(addr)
+		exists := t.post.Exist(addr) && !t.post.HasSelfDestructed(addr)
+
+		var (
+			pre      = &diffAccount{Storage: make(map[common.Hash]common.Hash)}
+			post     = &diffAccount{Storage: make(map[common.Hash]common.Hash)}
+			modified = existed != exists
+		)
+		if existed {
+			pre.Balance = (*hexutil.Big)(t.pre.GetBalance(addr))
+			pre.Nonce = t.pre.GetNonce(addr)
+			pre.Code = t.pre.GetCode(addr)
+		}
+		if exists {
+			if balance := t.post.GetBalance(addr); !existed || balance.Cmp(pre.Balance.ToInt()) != 0 {
+				post.Balance, modified = (*hexutil.Big)(balance), true
+			}
+			if nonce := t.post.GetNonce(addr); !existed || nonce != pre.Nonce {
+				post.Nonce, modified = nonce, true
+			}
+			if code := t.post.GetCode(addr); !existed || !bytes.Equal(code, pre.Code) {
+				post.Code, modified = code, true
+			}
+			if preKey, postKey := t.pre.GetKey(addr), t.post.GetKey(addr); !existed || !preKey.Equal(postKey) {
+				if existed {
+					pre.Key = accountkey.NewAccountKeySerializerWithAccountKey(preKey)
+				}
+				post.Key, modified = accountkey.NewAccountKeySerializerWithAccountKey(postKey), true
+			}
+		}
+		for key := range slots {
+			preVal, postVal := t.pre.GetState(addr, key), common.Hash{}
+			if exists {
+				postVal = t.post.GetState(addr, key)
+			}
+			if preVal == postVal {
+				continue
+			}
+			if existed {
+				pre.Storage[key] = preVal
+			}
+			if exists {
+				post.Storage[key] = postVal
+			}
+			modified = true
+		}
+		if !modified {
+			continue
+		}
+		if existed {
+			result.Pre[addr] = pre
+		}
+		if exists {
+			result.Post[addr] = post
+		}
+	}
+	res, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// lookupAccount fetches details of an account and adds it to the prestate
+// if it doesn't exist there. In diff mode, it only marks the account as touched.
+func (t *prestateTracer) lookupAccount(addr common.Address) {
+	if t.config.DiffMode {
+		if _, ok := t.touched[addr]; !ok {
+			t.touched[addr] = make(map[common.Hash]struct{})
+		}
+		return
+	}
+	if _, ok := t.prestate[addr]; ok {
+		return
+	}
+	db := t.env.StateDB
+	t.accounts = append(t.accounts, addr)
+	t.prestate[addr] = &account{
+		Balance: hexutil.EncodeBig(db.GetBalance(addr)),
+		Nonce:   db.GetNonce(addr),
+		Code:    hexutil.Encode(db.GetCode(addr)),
+		Storage: make(map[common.Hash]common.Hash),
+	}
+}
+
+// lookupStorage fetches the requested storage slot and adds
+// it to the prestate of the given contract. In diff mode, it only marks the
+// storage slot as touched.
+func (t *prestateTracer) lookupStorage(addr common.Address, key common.Hash) {
+	t.lookupAccount(addr)
+	if t.config.DiffMode {
+		t.touched[addr][key] = struct{}{}
+		return
+	}
+	if _, ok := t.prestate[addr].Storage[key]; ok {
+		return
+	}
+	t.prestate[addr].Storage[key] = t.env.StateDB.GetState(addr, key)
+	t.prestate[addr].slots = append(t.prestate[addr].slots, key)
+}
+
+// marshalOrdered encodes the given keys and values as a json object, keeping
+// the keys in the given order.
+func marshalOrdered(keys []string, values []interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		enc, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(enc)
+		buf.WriteByte(':')
+		if enc, err = json.Marshal(values[i]); err != nil {
+			return nil, err
+		}
+		buf.Write(enc)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,296 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/accountkey"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/common/math"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/node/cn/tracers"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type callContext struct {
+	Number     math.HexOrDecimal64   `json:"number"`
+	BlockScore *math.HexOrDecimal256 `json:"blockScore"`
+	Time       math.HexOrDecimal64   `json:"timestamp"`
+	GasLimit   math.HexOrDecimal64   `json:"gasLimit"`
+	Miner      common.Address        `json:"miner"`
+}
+
+// tracerTest defines a single test in the tracers testdata directory.
+type tracerTest struct {
+	Genesis     *blockchain.Genesis `json:"genesis"`
+	Context     *callContext        `json:"context"`
+	Input       string              `json:"input,omitempty"`
+	Transaction map[string]string   `json:"transaction,omitempty"`
+	Result      json.RawMessage     `json:"result"`
+}
+
+// callTrace is the result of a callTracer run, used to compare the results
+// regardless of the omitted empty fields.
+type callTrace struct {
+	Type     string               `json:"type"`
+	From     *common.Address      `json:"from"`
+	To       *common.Address      `json:"to"`
+	Input    hexutil.Bytes        `json:"input"`
+	Output   hexutil.Bytes        `json:"output"`
+	Gas      math.HexOrDecimal64  `json:"gas,omitempty"`
+	GasUsed  math.HexOrDecimal64  `json:"gasUsed,omitempty"`
+	Value    math.HexOrDecimal256 `json:"value,omitempty"`
+	Error    string               `json:"error,omitempty"`
+	Calls    []callTrace          `json:"calls,omitempty"`
+	Reverted *struct {
+		Contract *common.Address `json:"contract"`
+		Message  string          `json:"message"`
+	} `json:"reverted,omitempty"`
+}
+
+// runTracer executes the transaction of the given test with the given tracer
+// and returns the tracing result.
+func runTracer(t *testing.T, test *tracerTest, tracer vm.Tracer) {
+	signer := types.MakeSigner(test.Genesis.Config, new(big.Int).SetUint64(uint64(test.Context.Number)))
+	tx := new(types.Transaction)
+	if test.Input != "" {
+		require.NoError(t, rlp.DecodeBytes(common.FromHex(test.Input), tx))
+	} else {
+		value, gasPrice := new(big.Int), new(big.Int)
+		require.NoError(t, value.UnmarshalJSON([]byte(test.Transaction["value"])))
+		require.NoError(t, gasPrice.UnmarshalJSON([]byte(test.Transaction["gasPrice"])))
+		nonce, ok := math.ParseUint64(test.Transaction["nonce"])
+		require.True(t, ok)
+		gas, ok := math.ParseUint64(test.Transaction["gas"])
+		require.True(t, ok)
+
+		to := common.HexToAddress(test.Transaction["to"])
+		tx = types.NewTransaction(nonce, to, value, gas, gasPrice, common.FromHex(test.Transaction["input"]))
+
+		testKey, err := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		require.NoError(t, err)
+		require.NoError(t, tx.Sign(signer, testKey))
+	}
+	origin, _ := signer.Sender(tx)
+
+	txContext := vm.TxContext{
+		Origin:   origin,
+		GasPrice: tx.GasPrice(),
+	}
+	blockContext := vm.BlockContext{
+		CanTransfer: blockchain.CanTransfer,
+		Transfer:    blockchain.Transfer,
+		BlockNumber: new(big.Int).SetUint64(uint64(test.Context.Number)),
+		Time:        new(big.Int).SetUint64(uint64(test.Context.Time)),
+		BlockScore:  (*big.Int)(test.Context.BlockScore),
+		GasLimit:    uint64(test.Context.GasLimit),
+	}
+	statedb := tests.MakePreState(database.NewMemoryDBManager(), test.Genesis.Alloc)
+	evm := vm.NewEVM(blockContext, txContext, statedb, test.Genesis.Config, &vm.Config{Debug: true, Tracer: tracer})
+
+	fork.SetHardForkBlockNumberConfig(test.Genesis.Config)
+	msg, err := tx.AsMessageWithAccountKeyPicker(signer, statedb, blockContext.BlockNumber.Uint64())
+	require.NoError(t, err)
+
+	_, err = blockchain.NewStateTransition(evm, msg).TransitionDb()
+	require.NoError(t, err)
+}
+
+// jsonEqual compares JSON representations for human-friendly diffs.
+func jsonEqual(t *testing.T, x, y interface{}) {
+	xj, err := json.MarshalIndent(x, "", "  ")
+	require.NoError(t, err)
+
+	yj, err := json.MarshalIndent(y, "", "  ")
+	require.NoError(t, err)
+
+	assert.Equal(t, string(xj), string(yj))
+}
+
+func loadTracerTests(t *testing.T) map[string]*tracerTest {
+	files, err := os.ReadDir(filepath.Join("..", "testdata"))
+	require.NoError(t, err)
+
+	testcases := make(map[string]*tracerTest)
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		blob, err := os.ReadFile(filepath.Join("..", "testdata", file.Name()))
+		require.NoError(t, err)
+
+		test := new(tracerTest)
+		require.NoError(t, json.Unmarshal(blob, test))
+		testcases[strings.TrimSuffix(file.Name(), ".json")] = test
+	}
+	return testcases
+}
+
+// TestCallTracerNative checks the native callTracer against the results in the testdata.
+func TestCallTracerNative(t *testing.T) {
+	for name, test := range loadTracerTests(t) {
+		t.Run(name, func(t *testing.T) {
+			tracer, err := newCallTracer(new(tracers.Context), nil)
+			require.NoError(t, err)
+			runTracer(t, test, tracer)
+
+			res, err := tracer.GetResult()
+			require.NoError(t, err)
+
+			have, want := new(callTrace), new(callTrace)
+			require.NoError(t, json.Unmarshal(res, have))
+			require.NoError(t, json.Unmarshal(test.Result, want))
+			jsonEqual(t, want, have)
+		})
+	}
+}
+
+// jsTracerFailures are the expected native results of the tests the
+// JavaScript tracers fail on. The JavaScript prestateTracer collects nothing
+// before the first executed opcode, so it fails on transactions not executing
+// any code.
+var jsTracerFailures = map[string]string{
+	"call_tracer_to_eoa/prestateTracer": `{"0x072d51ab13cee591d38f527c589c36669c0a6854":{"balance":"0x0","nonce":0,"code":"0x","storage":{}},"0x337160455646d3b876cc3768e701a41bbc21ed3a":{"balance":"0xd3c21bcb11f573fc6000","nonce":121,"code":"0x","storage":{}}}`,
+}
+
+// TestNativeTracersCompatibility checks that the native tracers produce the
+// same bytes as the JavaScript tracers of the same name.
+func TestNativeTracersCompatibility(t *testing.T) {
+	ctors := map[string]tracers.NativeTracerCtor{
+		"callTracer":     newCallTracer,
+		"prestateTracer": newPrestateTracer,
+		"4byteTracer":    newFourByteTracer,
+	}
+	for name, test := range loadTracerTests(t) {
+		for tracerName, ctor := range ctors {
+			t.Run(name+"/"+tracerName, func(t *testing.T) {
+				jsTracer, err := tracers.New(tracerName, new(tracers.Context), false)
+				require.NoError(t, err)
+				runTracer(t, test, jsTracer)
+				jsRes, jsErr := jsTracer.GetResult()
+
+				nativeTracer, err := ctor(new(tracers.Context), nil)
+				require.NoError(t, err)
+				runTracer(t, test, nativeTracer)
+				nativeRes, nativeErr := nativeTracer.GetResult()
+
+				require.NoError(t, nativeErr)
+
+				if want, ok := jsTracerFailures[name+"/"+tracerName]; ok {
+					assert.Error(t, jsErr)
+					assert.Equal(t, want, string(nativeRes))
+					return
+				}
+				require.NoError(t, jsErr)
+				assert.Equal(t, string(jsRes), string(nativeRes))
+			})
+		}
+	}
+}
+
+func TestNoopTracer(t *testing.T) {
+	tracer, err := newNoopTracer(new(tracers.Context), nil)
+	require.NoError(t, err)
+	for name, test := range loadTracerTests(t) {
+		runTracer(t, test, tracer)
+		res, err := tracer.GetResult()
+		require.NoError(t, err, name)
+		assert.JSONEq(t, `{}`, string(res), name)
+	}
+}
+
+// TestPrestateTracerDiffMode checks that the account key changed by an account
+// update transaction, which never enters the EVM, is reported in diff mode.
+func TestPrestateTracerDiffMode(t *testing.T) {
+	var (
+		config    = params.TestChainConfig
+		signer    = types.LatestSignerForChainID(config.ChainID)
+		key, _    = crypto.GenerateKey()
+		newKey, _ = crypto.GenerateKey()
+		from      = crypto.PubkeyToAddress(key.PublicKey)
+		balance   = big.NewInt(params.KAIA)
+	)
+	fork.SetHardForkBlockNumberConfig(config)
+	defer fork.ClearHardForkBlockNumberConfig()
+
+	tx, err := types.NewTransactionWithMap(types.TxTypeAccountUpdate, map[types.TxValueKeyType]interface{}{
+		types.TxValueKeyNonce:      uint64(0),
+		types.TxValueKeyFrom:       from,
+		types.TxValueKeyGasLimit:   uint64(100000),
+		types.TxValueKeyGasPrice:   big.NewInt(1),
+		types.TxValueKeyAccountKey: accountkey.NewAccountKeyPublicWithValue(&newKey.PublicKey),
+	})
+	require.NoError(t, err)
+	require.NoError(t, tx.SignWithKeys(signer, []*ecdsa.PrivateKey{key}))
+
+	statedb := tests.MakePreState(database.NewMemoryDBManager(), blockchain.GenesisAlloc{from: {Balance: balance}})
+	msg, err := tx.AsMessageWithAccountKeyPicker(signer, statedb, 0)
+	require.NoError(t, err)
+
+	tracer, err := newPrestateTracer(new(tracers.Context), json.RawMessage(`{"diffMode": true}`))
+	require.NoError(t, err)
+	tracer.(tracers.StateTracer).CapturePreState(statedb, msg)
+
+	blockContext := vm.BlockContext{
+		CanTransfer: blockchain.CanTransfer,
+		Transfer:    blockchain.Transfer,
+		BlockNumber: new(big.Int),
+		Time:        new(big.Int),
+		BlockScore:  new(big.Int),
+	}
+	evm := vm.NewEVM(blockContext, blockchain.NewEVMTxContext(msg, &types.Header{Number: new(big.Int)}, config), statedb, config, &vm.Config{Debug: true, Tracer: tracer})
+	_, err = blockchain.NewStateTransition(evm, msg).TransitionDb()
+	require.NoError(t, err)
+
+	res, err := tracer.GetResult()
+	require.NoError(t, err)
+
+	var result struct {
+		Pre  map[common.Address]*diffAccount `json:"pre"`
+		Post map[common.Address]*diffAccount `json:"post"`
+	}
+	require.NoError(t, json.Unmarshal(res, &result))
+	require.Len(t, result.Pre, 1)
+	require.Len(t, result.Post, 1)
+
+	pre, post := result.Pre[from], result.Post[from]
+	require.NotNil(t, pre)
+	require.NotNil(t, post)
+	assert.Equal(t, balance, pre.Balance.ToInt())
+	assert.Equal(t, uint64(0), pre.Nonce)
+	assert.Equal(t, uint64(1), post.Nonce)
+	assert.True(t, post.Balance.ToInt().Cmp(balance) < 0)
+	assert.Equal(t, accountkey.AccountKeyTypeLegacy, pre.Key.GetKey().Type())
+	assert.True(t, post.Key.GetKey().Equal(accountkey.NewAccountKeyPublicWithValue(&newKey.PublicKey)))
+}
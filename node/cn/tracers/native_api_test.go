@@ -0,0 +1,112 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/node/cn/tracers"
+	_ "github.com/klaytn/klaytn/node/cn/tracers/native"
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storeCode stores 1 at the storage slot 0 of the contract.
+var storeCode = common.FromHex("0x600160005500")
+
+type prestateAccount struct {
+	Balance string                      `json:"balance"`
+	Nonce   uint64                      `json:"nonce"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+// newNativeTracerTest creates a chain with a transaction of the sender calling
+// the store contract and returns an RPC client serving the debug namespace.
+func newNativeTracerTest(t *testing.T) (client *rpc.Client, sender, contract common.Address, txHash common.Hash) {
+	key, _ := crypto.GenerateKey()
+	sender = crypto.PubkeyToAddress(key.PublicKey)
+	contract = common.HexToAddress("0x00000000000000000000000000000000000c0de")
+	genesis := &blockchain.Genesis{Alloc: blockchain.GenesisAlloc{
+		sender:   {Balance: big.NewInt(params.KAIA)},
+		contract: {Balance: common.Big0, Code: storeCode},
+	}}
+	signer := types.LatestSignerForChainID(params.TestChainConfig.ChainID)
+	backend := tracers.NewTestBackend(t, 1, genesis, func(i int, b *blockchain.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), contract, common.Big0, 100000, big.NewInt(1), nil), signer, key)
+		b.AddTx(tx)
+		txHash = tx.Hash()
+	})
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("debug", tracers.NewAPI(backend)))
+	client = rpc.DialInProc(server)
+	t.Cleanup(client.Close)
+	return client, sender, contract, txHash
+}
+
+func TestTraceTransactionNativePrestate(t *testing.T) {
+	client, sender, contract, txHash := newNativeTracerTest(t)
+
+	var result map[common.Address]*prestateAccount
+	err := client.CallContext(context.Background(), &result, "debug_traceTransaction", txHash,
+		map[string]interface{}{"tracer": "prestateTracer"})
+	require.NoError(t, err)
+
+	require.Contains(t, result, sender)
+	balance, err := hexutil.DecodeBig(result[sender].Balance)
+	require.NoError(t, err)
+	assert.True(t, balance.Sign() > 0 && balance.Cmp(big.NewInt(params.KAIA)) <= 0, balance)
+	assert.Equal(t, uint64(0), result[sender].Nonce)
+	require.Contains(t, result, contract)
+	assert.Equal(t, map[common.Hash]common.Hash{{}: {}}, result[contract].Storage)
+
+	// The tracer config is passed to the native tracer, which rejects it.
+	err = client.CallContext(context.Background(), &result, "debug_traceTransaction", txHash,
+		map[string]interface{}{"tracer": "prestateTracer", "tracerConfig": json.RawMessage(`{"diffMode":1}`)})
+	assert.Error(t, err)
+}
+
+func TestTraceTransactionNativePrestateDiffMode(t *testing.T) {
+	client, sender, contract, txHash := newNativeTracerTest(t)
+
+	var result struct {
+		Pre  map[common.Address]*prestateAccount `json:"pre"`
+		Post map[common.Address]*prestateAccount `json:"post"`
+	}
+	err := client.CallContext(context.Background(), &result, "debug_traceTransaction", txHash,
+		map[string]interface{}{"tracer": "prestateTracer", "tracerConfig": map[string]interface{}{"diffMode": true}})
+	require.NoError(t, err)
+
+	// The sender pays the fee and bumps the nonce, the contract sets the slot.
+	require.Contains(t, result.Pre, sender)
+	require.Contains(t, result.Post, sender)
+	assert.Equal(t, uint64(1), result.Post[sender].Nonce)
+	require.Contains(t, result.Post, contract)
+	slot := common.Hash{}
+	assert.Equal(t, common.BigToHash(common.Big1), result.Post[contract].Storage[slot])
+	require.Contains(t, result.Pre, contract)
+	assert.Equal(t, common.Hash{}, result.Pre[contract].Storage[slot])
+}
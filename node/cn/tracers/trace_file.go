@@ -0,0 +1,82 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+)
+
+// traceFile is a temporary file which traces are streamed into. The written data is
+// buffered and optionally gzip-compressed, so that the whole trace is never held in memory.
+type traceFile struct {
+	file *os.File
+	buf  *bufio.Writer
+	gz   *gzip.Writer // nil if the file is not compressed
+	w    io.Writer
+}
+
+// newTraceFile creates a new trace file in the temp directory. The pattern follows
+// the rule of os.CreateTemp, and ".gz" is appended to it if compress is true.
+func newTraceFile(pattern string, compress bool) (*traceFile, error) {
+	if compress {
+		pattern += ".gz"
+	}
+	file, err := os.CreateTemp(os.TempDir(), pattern)
+	if err != nil {
+		return nil, err
+	}
+	f := &traceFile{file: file, buf: bufio.NewWriter(file)}
+	f.w = f.buf
+	if compress {
+		f.gz = gzip.NewWriter(f.buf)
+		f.w = f.gz
+	}
+	return f, nil
+}
+
+// Name returns the path of the trace file.
+func (f *traceFile) Name() string {
+	return f.file.Name()
+}
+
+func (f *traceFile) Write(p []byte) (int, error) {
+	return f.w.Write(p)
+}
+
+// Close flushes all the buffered data and closes the trace file.
+func (f *traceFile) Close() error {
+	if f.gz != nil {
+		if err := f.gz.Close(); err != nil {
+			f.file.Close()
+			return err
+		}
+	}
+	if err := f.buf.Flush(); err != nil {
+		f.file.Close()
+		return err
+	}
+	return f.file.Close()
+}
+
+// Remove closes and deletes the trace file. It is used to clean up a file of a failed trace.
+func (f *traceFile) Remove() {
+	f.Close()
+	os.Remove(f.file.Name())
+}
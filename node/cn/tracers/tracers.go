@@ -18,19 +18,58 @@
 // This file is derived from eth/tracers/tracers.go (2018/06/04).
 // Modified and improved for the klaytn development.
 
-// Package tracers is a collection of JavaScript transaction tracers.
+// Package tracers is a collection of JavaScript and native transaction tracers.
 package tracers
 
 import (
+	"encoding/json"
 	"strings"
 	"unicode"
 
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/vm"
 	"github.com/klaytn/klaytn/node/cn/tracers/internal/tracers"
 )
 
 // all contains all the built in JavaScript tracers by name.
 var all = make(map[string]string)
 
+// NativeTracer is a tracer written in Go which can be selected by name in the
+// same way as the built in JavaScript tracers.
+type NativeTracer interface {
+	vm.Tracer
+	// GetResult returns the json-encoded result collected during the tracing.
+	GetResult() (json.RawMessage, error)
+	// Stop terminates execution of the tracer at the first opportune moment.
+	Stop(err error)
+}
+
+// StateTracer is a NativeTracer which additionally inspects the state right
+// before a message is applied. Unlike the vm.Tracer hooks, it is invoked for
+// every transaction type, including the ones never entering the EVM such as
+// TxTypeAccountUpdate.
+type StateTracer interface {
+	NativeTracer
+	// CapturePreState is called with the state the message is about to be applied to.
+	CapturePreState(statedb *state.StateDB, msg blockchain.Message)
+}
+
+// NativeTracerCtor creates a native tracer with the given context and the
+// tracer specific configuration, which can be nil.
+type NativeTracerCtor func(ctx *Context, cfg json.RawMessage) (NativeTracer, error)
+
+// nativeTracers contains all the registered native tracers by name.
+var nativeTracers = make(map[string]NativeTracerCtor)
+
+// RegisterNativeTracer makes a native tracer available by the given name.
+// If a JavaScript tracer with the same name exists, the native one takes
+// precedence. It is meant to be called from the init function of the package
+// implementing the tracer.
+func RegisterNativeTracer(name string, ctor NativeTracerCtor) {
+	nativeTracers[name] = ctor
+}
+
 // camel converts a snake cased input string into a camel cased output.
 func camel(str string) string {
 	pieces := strings.Split(str, "_")
@@ -48,6 +87,12 @@ func init() {
 	}
 }
 
+// nativeTracer retrieves the constructor of a specific native tracer by name.
+func nativeTracer(name string) (NativeTracerCtor, bool) {
+	ctor, ok := nativeTracers[name]
+	return ctor, ok
+}
+
 // tracer retrieves a specific JavaScript tracer by name.
 func tracer(name string) (string, bool) {
 	if tracer, ok := all[name]; ok {
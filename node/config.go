@@ -67,7 +67,7 @@ type Config struct {
 	// in the devp2p node identifier.
 	Version string `toml:"-"`
 
-	// key-value database type [LevelDB, RocksDB, BadgerDB, MemoryDB, DynamoDB]
+	// key-value database type [LevelDB, RocksDB, BadgerDB, PebbleDB, MemoryDB, DynamoDB]
 	DBType database.DBType
 
 	// DataDir is the file system folder the node should use for any data storage
@@ -437,14 +437,24 @@ func (c *Config) parsePersistentNodes(path string) []*discover.Node {
 	if c.DataDir == "" {
 		return nil
 	}
-	if _, err := os.Stat(path); err != nil {
+	nodes, err := loadPersistentNodes(path)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Can't load node file %s: %v", path, err))
 		return nil
 	}
+	return nodes
+}
+
+// loadPersistentNodes loads a list of discovery node URLs from a .json file.
+// A missing file is an empty list, while invalid URLs are logged and skipped.
+func loadPersistentNodes(path string) ([]*discover.Node, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil
+	}
 	// Load the nodes from the config file.
 	var nodelist []string
 	if err := common.LoadJSON(path, &nodelist); err != nil {
-		logger.Error(fmt.Sprintf("Can't load node file %s: %v", path, err))
-		return nil
+		return nil, err
 	}
 	// Interpret the list as a discovery node array
 	var nodes []*discover.Node
@@ -459,7 +469,7 @@ func (c *Config) parsePersistentNodes(path string) []*discover.Node {
 		}
 		nodes = append(nodes, node)
 	}
-	return nodes
+	return nodes, nil
 }
 
 // AccountConfig determines the settings for scrypt and keydirectory
@@ -62,6 +62,7 @@ type Node struct {
 
 	serverConfig p2p.Config
 	server       p2p.Server
+	peerLists    *peerListWatcher // Applies the modifications of the node list files
 
 	coreServiceFuncs []ServiceConstructor
 	serviceFuncs     []ServiceConstructor
@@ -186,10 +187,12 @@ func (n *Node) Start() error {
 	n.serverConfig.PrivateKey = n.config.NodeKey()
 	n.serverConfig.Name = n.config.NodeName()
 	n.serverConfig.Logger = n.logger
-	if n.serverConfig.StaticNodes == nil {
+	// Only the node lists loaded from the data directory are watched
+	watchStatic, watchTrusted := n.serverConfig.StaticNodes == nil, n.serverConfig.TrustedNodes == nil
+	if watchStatic {
 		n.serverConfig.StaticNodes = n.config.StaticNodes()
 	}
-	if n.serverConfig.TrustedNodes == nil {
+	if watchTrusted {
 		n.serverConfig.TrustedNodes = n.config.TrustedNodes()
 	}
 	if n.serverConfig.NodeDatabase == "" {
@@ -276,6 +279,18 @@ func (n *Node) Start() error {
 	n.server = p2pServer
 	n.stop = make(chan struct{})
 
+	// Watch the node lists to apply their modifications without a restart
+	if n.config.DataDir != "" && (watchStatic || watchTrusted) {
+		n.peerLists = newPeerListWatcher(p2pServer)
+		if watchStatic {
+			n.peerLists.watch(n.config.ResolvePath(datadirStaticNodes), false, n.serverConfig.StaticNodes)
+		}
+		if watchTrusted {
+			n.peerLists.watch(n.config.ResolvePath(datadirTrustedNodes), true, n.serverConfig.TrustedNodes)
+		}
+		n.peerLists.start()
+	}
+
 	// Register a labeled metric containing version and build information
 	// e.g.) klaytn_build_info{version="v1.8.4+b3ab199674" cpu_arch="darwin-arm64" go_version="go1.18.2"} 1
 	if metricutils.Enabled {
@@ -563,6 +578,10 @@ func (n *Node) Stop() error {
 			failure.Services[kind] = err
 		}
 	}
+	if n.peerLists != nil {
+		n.peerLists.stop()
+		n.peerLists = nil
+	}
 	n.server.Stop()
 	n.services = nil
 	n.server = nil
@@ -651,6 +670,22 @@ func (n *Node) Server() p2p.Server {
 	return n.server
 }
 
+// reloadPeerLists reloads the node list files from the data directory and
+// applies the difference to the p2p server.
+func (n *Node) reloadPeerLists() (*PeerListChanges, error) {
+	n.lock.RLock()
+	server, peerLists := n.server, n.peerLists
+	n.lock.RUnlock()
+
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	if peerLists == nil {
+		return nil, errNoPeerLists
+	}
+	return peerLists.reload(true)
+}
+
 // Service retrieves a currently running service registered of a specific type.
 func (n *Node) Service(service interface{}) error {
 	n.lock.RLock()
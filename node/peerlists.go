@@ -0,0 +1,219 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/networks/p2p/discover"
+)
+
+// peerListsPollInterval is the interval the node list files are checked for
+// modifications.
+const peerListsPollInterval = 5 * time.Second
+
+var errNoPeerLists = errors.New("no node list is loaded from the data directory")
+
+// peerListServer is the part of p2p.Server the node list files are applied to.
+type peerListServer interface {
+	AddPeer(node *discover.Node)
+	RemovePeer(node *discover.Node)
+	AddTrustedPeer(node *discover.Node)
+	RemoveTrustedPeer(node *discover.Node)
+}
+
+// PeerListChanges lists the node URLs added to and removed from the static and
+// the trusted node lists by a reload.
+type PeerListChanges struct {
+	StaticAdded    []string `json:"staticAdded"`
+	StaticRemoved  []string `json:"staticRemoved"`
+	TrustedAdded   []string `json:"trustedAdded"`
+	TrustedRemoved []string `json:"trustedRemoved"`
+}
+
+// peerListFile is a node list file and the nodes last applied from it.
+type peerListFile struct {
+	path    string
+	trusted bool
+	nodes   map[string]*discover.Node // Applied nodes by their key
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+// key returns the key identifying a node of the list. Static nodes are keyed
+// by the URL so that an address change redials the node, while trusted nodes
+// are keyed by the node ID.
+func (f *peerListFile) key(n *discover.Node) string {
+	if f.trusted {
+		return n.ID.String()
+	}
+	return n.String()
+}
+
+// stat updates the file information and reports whether it has changed.
+func (f *peerListFile) stat() bool {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		changed := f.exists
+		f.exists, f.modTime, f.size = false, time.Time{}, 0
+		return changed
+	}
+	changed := !f.exists || !info.ModTime().Equal(f.modTime) || info.Size() != f.size
+	f.exists, f.modTime, f.size = true, info.ModTime(), info.Size()
+	return changed
+}
+
+// peerListWatcher watches the static-nodes.json and trusted-nodes.json files
+// and applies the difference to the p2p server when they are modified, so the
+// topology can be changed without restarting the node.
+type peerListWatcher struct {
+	server peerListServer
+	files  []*peerListFile
+	lock   sync.Mutex
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newPeerListWatcher(server peerListServer) *peerListWatcher {
+	return &peerListWatcher{server: server, quit: make(chan struct{})}
+}
+
+// watch adds a node list file to the watcher with the nodes which have been
+// applied from the file already.
+func (w *peerListWatcher) watch(path string, trusted bool, nodes []*discover.Node) {
+	f := &peerListFile{path: path, trusted: trusted, nodes: make(map[string]*discover.Node)}
+	for _, n := range nodes {
+		f.nodes[f.key(n)] = n
+	}
+	f.stat()
+	w.files = append(w.files, f)
+}
+
+func (w *peerListWatcher) start() {
+	w.wg.Add(1)
+	go w.loop()
+}
+
+func (w *peerListWatcher) stop() {
+	close(w.quit)
+	w.wg.Wait()
+}
+
+func (w *peerListWatcher) loop() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(peerListsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := w.reload(false); err != nil {
+				logger.Error("Failed to reload node lists", "err", err)
+			}
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// reload loads the node lists, all of them if force is set or otherwise only
+// the modified ones, and applies the difference to the server. A list which
+// fails to load is kept as it is.
+func (w *peerListWatcher) reload(force bool) (*PeerListChanges, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if len(w.files) == 0 {
+		return nil, errNoPeerLists
+	}
+	changes := &PeerListChanges{
+		StaticAdded:    []string{},
+		StaticRemoved:  []string{},
+		TrustedAdded:   []string{},
+		TrustedRemoved: []string{},
+	}
+	var lastErr error
+	for _, f := range w.files {
+		if !f.stat() && !force {
+			continue
+		}
+		nodes, err := loadPersistentNodes(f.path)
+		if err != nil {
+			// The observed modification is kept, so the load is retried on
+			// the next modification of the file rather than on every poll.
+			lastErr = err
+			continue
+		}
+		added, removed := w.apply(f, nodes)
+		if f.trusted {
+			changes.TrustedAdded = append(changes.TrustedAdded, added...)
+			changes.TrustedRemoved = append(changes.TrustedRemoved, removed...)
+		} else {
+			changes.StaticAdded = append(changes.StaticAdded, added...)
+			changes.StaticRemoved = append(changes.StaticRemoved, removed...)
+		}
+		if len(added) > 0 || len(removed) > 0 {
+			logger.Info("Reloaded node list", "path", f.path, "added", len(added), "removed", len(removed))
+		}
+	}
+	return changes, lastErr
+}
+
+// apply applies the difference between the nodes of the file and the given
+// ones to the server, and returns the URLs of the added and removed nodes.
+func (w *peerListWatcher) apply(f *peerListFile, nodes []*discover.Node) (added, removed []string) {
+	next := make(map[string]*discover.Node, len(nodes))
+	for _, n := range nodes {
+		next[f.key(n)] = n
+	}
+	// Remove the nodes first, so a static node whose address has changed is
+	// dialed again at the new address.
+	for key, n := range f.nodes {
+		if _, ok := next[key]; ok {
+			continue
+		}
+		if f.trusted {
+			w.server.RemoveTrustedPeer(n)
+		} else {
+			w.server.RemovePeer(n)
+		}
+		removed = append(removed, n.String())
+	}
+	for key, n := range next {
+		if _, ok := f.nodes[key]; ok {
+			continue
+		}
+		if f.trusted {
+			w.server.AddTrustedPeer(n)
+		} else {
+			w.server.AddPeer(n)
+		}
+		added = append(added, n.String())
+	}
+	f.nodes = next
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
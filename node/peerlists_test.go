@@ -0,0 +1,134 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/networks/p2p/discover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPeerListServer records the node list operations applied to it.
+type testPeerListServer struct {
+	ops []string
+}
+
+func (s *testPeerListServer) AddPeer(n *discover.Node) {
+	s.ops = append(s.ops, "addStatic "+n.String())
+}
+
+func (s *testPeerListServer) RemovePeer(n *discover.Node) {
+	s.ops = append(s.ops, "removeStatic "+n.String())
+}
+
+func (s *testPeerListServer) AddTrustedPeer(n *discover.Node) {
+	s.ops = append(s.ops, "addTrusted "+n.String())
+}
+
+func (s *testPeerListServer) RemoveTrustedPeer(n *discover.Node) {
+	s.ops = append(s.ops, "removeTrusted "+n.String())
+}
+
+func testNodeURL(t *testing.T, port int) (*discover.Node, string) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	url := fmt.Sprintf("kni://%x@127.0.0.1:%d", crypto.FromECDSAPub(&key.PublicKey)[1:], port)
+	n, err := discover.ParseNode(url)
+	require.NoError(t, err)
+	return n, url
+}
+
+func writeNodeList(t *testing.T, path string, urls ...string) {
+	blob, err := json.Marshal(urls)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, blob, 0o600))
+	// Make sure the modification is noticed regardless of the time resolution.
+	modTime := time.Now().Add(time.Duration(len(urls)+1) * time.Second)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestPeerListWatcher(t *testing.T) {
+	var (
+		dir         = t.TempDir()
+		staticPath  = filepath.Join(dir, datadirStaticNodes)
+		trustedPath = filepath.Join(dir, datadirTrustedNodes)
+		server      = &testPeerListServer{}
+		w           = newPeerListWatcher(server)
+	)
+	a, aURL := testNodeURL(t, 30000)
+	b, bURL := testNodeURL(t, 30001)
+	c, cURL := testNodeURL(t, 30002)
+
+	writeNodeList(t, staticPath, aURL, bURL)
+	w.watch(staticPath, false, []*discover.Node{a, b})
+	w.watch(trustedPath, true, nil)
+
+	// Nothing is applied while the files are unchanged.
+	changes, err := w.reload(false)
+	require.NoError(t, err)
+	assert.Empty(t, changes.StaticAdded)
+	assert.Empty(t, server.ops)
+
+	// A new static node is dialed, a removed one dropped and a moved one redialed.
+	bMovedURL := fmt.Sprintf("kni://%x@127.0.0.2:30001", b.ID[:])
+	writeNodeList(t, staticPath, bMovedURL, cURL)
+	writeNodeList(t, trustedPath, aURL)
+	changes, err = w.reload(false)
+	require.NoError(t, err)
+	bMoved, err := discover.ParseNode(bMovedURL)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{bMoved.String(), c.String()}, changes.StaticAdded)
+	assert.ElementsMatch(t, []string{a.String(), b.String()}, changes.StaticRemoved)
+	assert.Equal(t, []string{a.String()}, changes.TrustedAdded)
+	assert.Empty(t, changes.TrustedRemoved)
+	assert.ElementsMatch(t, []string{
+		"removeStatic " + a.String(), "removeStatic " + b.String(),
+		"addStatic " + bMoved.String(), "addStatic " + c.String(),
+		"addTrusted " + a.String(),
+	}, server.ops)
+	assert.Equal(t, "removeStatic", server.ops[0][:12])
+
+	// A broken file is reported and keeps the applied list.
+	server.ops = nil
+	require.NoError(t, os.WriteFile(trustedPath, []byte("["), 0o600))
+	_, err = w.reload(false)
+	assert.Error(t, err)
+	assert.Empty(t, server.ops)
+
+	// The broken file is not loaded again until it's modified.
+	_, err = w.reload(false)
+	assert.NoError(t, err)
+
+	// Removing a file empties its list, and a forced reload applies all files.
+	require.NoError(t, os.Remove(trustedPath))
+	changes, err = w.reload(true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{a.String()}, changes.TrustedRemoved)
+	assert.Empty(t, changes.StaticAdded)
+	assert.Equal(t, []string{"removeTrusted " + a.String()}, server.ops)
+
+	_, err = newPeerListWatcher(server).reload(true)
+	assert.Equal(t, errNoPeerLists, err)
+}
@@ -150,6 +150,15 @@ const (
 	Bn256ParingBaseComputationCost                 = 2000000
 	Bn256ParingPerPointComputationCost             = 1000000
 	BlobTxPointEvaluationPrecompileComputationCost = 2200000
+	Bls12381G1AddComputationCost                   = 20000
+	Bls12381G1MulComputationCost                   = 500000
+	Bls12381G2AddComputationCost                   = 30000
+	Bls12381G2MulComputationCost                   = 1000000
+	Bls12381PairingBaseComputationCost             = 1700000
+	Bls12381PairingPerPairComputationCost          = 1500000
+	Bls12381MapG1ComputationCost                   = 250000
+	Bls12381MapG2ComputationCost                   = 1100000
+	P256VerifyComputationCost                      = 120000
 	VMLogPerByteComputationCost                    = 0
 	VMLogBaseComputationCost                       = 10
 	FeePayerComputationCost                        = 10
@@ -212,6 +212,8 @@ type ChainConfig struct {
 	ShanghaiCompatibleBlock  *big.Int `json:"shanghaiCompatibleBlock,omitempty"`  // ShanghaiCompatible switch block (nil = no fork, 0 already on shanghai)
 	CancunCompatibleBlock    *big.Int `json:"cancunCompatibleBlock,omitempty"`    // CancunCompatible switch block (nil = no fork, 0 already on Cancun)
 	KaiaCompatibleBlock      *big.Int `json:"kaiaCompatibleBlock,omitempty"`      // KaiaCompatible switch block (nil = no fork, 0 already on Kaia)
+	PragueCompatibleBlock    *big.Int `json:"pragueCompatibleBlock,omitempty"`    // PragueCompatible switch block (nil = no fork, 0 already on Prague)
+	OsakaCompatibleBlock     *big.Int `json:"osakaCompatibleBlock,omitempty"`     // OsakaCompatible switch block (nil = no fork, 0 already on Osaka)
 
 	// Kip103 is a special purpose hardfork feature that can be executed only once
 	// Both Kip103CompatibleBlock and Kip103ContractAddress should be specified to enable KIP103
@@ -335,7 +337,7 @@ func (c *ChainConfig) String() string {
 	kip160 := fmt.Sprintf("KIP160CompatibleBlock: %v KIP160ContractAddress %s", c.Kip160CompatibleBlock, c.Kip160ContractAddress.String())
 
 	if c.Istanbul != nil {
-		return fmt.Sprintf("{ChainID: %v IstanbulCompatibleBlock: %v LondonCompatibleBlock: %v EthTxTypeCompatibleBlock: %v MagmaCompatibleBlock: %v KoreCompatibleBlock: %v ShanghaiCompatibleBlock: %v CancunCompatibleBlock: %v KaiaCompatibleBlock: %v RandaoCompatibleBlock: %v %s %s SubGroupSize: %d UnitPrice: %d DeriveShaImpl: %d Engine: %v}",
+		return fmt.Sprintf("{ChainID: %v IstanbulCompatibleBlock: %v LondonCompatibleBlock: %v EthTxTypeCompatibleBlock: %v MagmaCompatibleBlock: %v KoreCompatibleBlock: %v ShanghaiCompatibleBlock: %v CancunCompatibleBlock: %v KaiaCompatibleBlock: %v PragueCompatibleBlock: %v OsakaCompatibleBlock: %v RandaoCompatibleBlock: %v %s %s SubGroupSize: %d UnitPrice: %d DeriveShaImpl: %d Engine: %v}",
 			c.ChainID,
 			c.IstanbulCompatibleBlock,
 			c.LondonCompatibleBlock,
@@ -345,6 +347,8 @@ func (c *ChainConfig) String() string {
 			c.ShanghaiCompatibleBlock,
 			c.CancunCompatibleBlock,
 			c.KaiaCompatibleBlock,
+			c.PragueCompatibleBlock,
+			c.OsakaCompatibleBlock,
 			c.RandaoCompatibleBlock,
 			kip103,
 			kip160,
@@ -354,7 +358,7 @@ func (c *ChainConfig) String() string {
 			engine,
 		)
 	} else {
-		return fmt.Sprintf("{ChainID: %v IstanbulCompatibleBlock: %v LondonCompatibleBlock: %v EthTxTypeCompatibleBlock: %v MagmaCompatibleBlock: %v KoreCompatibleBlock: %v ShanghaiCompatibleBlock: %v CancunCompatibleBlock: %v KaiaCompatibleBlock: %v RandaoCompatibleBlock: %v %s %s UnitPrice: %d DeriveShaImpl: %d Engine: %v }",
+		return fmt.Sprintf("{ChainID: %v IstanbulCompatibleBlock: %v LondonCompatibleBlock: %v EthTxTypeCompatibleBlock: %v MagmaCompatibleBlock: %v KoreCompatibleBlock: %v ShanghaiCompatibleBlock: %v CancunCompatibleBlock: %v KaiaCompatibleBlock: %v PragueCompatibleBlock: %v OsakaCompatibleBlock: %v RandaoCompatibleBlock: %v %s %s UnitPrice: %d DeriveShaImpl: %d Engine: %v }",
 			c.ChainID,
 			c.IstanbulCompatibleBlock,
 			c.LondonCompatibleBlock,
@@ -364,6 +368,8 @@ func (c *ChainConfig) String() string {
 			c.ShanghaiCompatibleBlock,
 			c.CancunCompatibleBlock,
 			c.KaiaCompatibleBlock,
+			c.PragueCompatibleBlock,
+			c.OsakaCompatibleBlock,
 			c.RandaoCompatibleBlock,
 			kip103,
 			kip160,
@@ -421,6 +427,16 @@ func (c *ChainConfig) IsKaiaForkEnabled(num *big.Int) bool {
 	return isForked(c.KaiaCompatibleBlock, num)
 }
 
+// IsPragueForkEnabled returns whether num is either equal to the prague block or greater.
+func (c *ChainConfig) IsPragueForkEnabled(num *big.Int) bool {
+	return isForked(c.PragueCompatibleBlock, num)
+}
+
+// IsOsakaForkEnabled returns whether num is either equal to the osaka block or greater.
+func (c *ChainConfig) IsOsakaForkEnabled(num *big.Int) bool {
+	return isForked(c.OsakaCompatibleBlock, num)
+}
+
 // IsRandaoForkEnabled returns whether num is either equal to the randao block or greater.
 func (c *ChainConfig) IsRandaoForkEnabled(num *big.Int) bool {
 	return isForked(c.RandaoCompatibleBlock, num)
@@ -488,6 +504,8 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 		{name: "cancunBlock", block: c.CancunCompatibleBlock},
 		{name: "randaoBlock", block: c.RandaoCompatibleBlock, optional: true},
 		{name: "kaiaBlock", block: c.KaiaCompatibleBlock},
+		{name: "pragueBlock", block: c.PragueCompatibleBlock},
+		{name: "osakaBlock", block: c.OsakaCompatibleBlock},
 	} {
 		if lastFork.name != "" {
 			// Next one must be higher number
@@ -537,6 +555,12 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.KaiaCompatibleBlock, newcfg.KaiaCompatibleBlock, head) {
 		return newCompatError("Kaia Block", c.KaiaCompatibleBlock, newcfg.KaiaCompatibleBlock)
 	}
+	if isForkIncompatible(c.PragueCompatibleBlock, newcfg.PragueCompatibleBlock, head) {
+		return newCompatError("Prague Block", c.PragueCompatibleBlock, newcfg.PragueCompatibleBlock)
+	}
+	if isForkIncompatible(c.OsakaCompatibleBlock, newcfg.OsakaCompatibleBlock, head) {
+		return newCompatError("Osaka Block", c.OsakaCompatibleBlock, newcfg.OsakaCompatibleBlock)
+	}
 	if isForkIncompatible(c.RandaoCompatibleBlock, newcfg.RandaoCompatibleBlock, head) {
 		return newCompatError("Randao Block", c.RandaoCompatibleBlock, newcfg.RandaoCompatibleBlock)
 	}
@@ -677,6 +701,8 @@ type Rules struct {
 	IsShanghai  bool
 	IsCancun    bool
 	IsKaia      bool
+	IsPrague    bool
+	IsOsaka     bool
 	IsRandao    bool
 }
 
@@ -696,6 +722,8 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 		IsShanghai:  c.IsShanghaiForkEnabled(num),
 		IsCancun:    c.IsCancunForkEnabled(num),
 		IsKaia:      c.IsKaiaForkEnabled(num),
+		IsPrague:    c.IsPragueForkEnabled(num),
+		IsOsaka:     c.IsOsakaForkEnabled(num),
 		IsRandao:    c.IsRandaoForkEnabled(num),
 	}
 }
@@ -24,6 +24,7 @@ package snapshot
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math"
 	"runtime"
@@ -45,15 +46,59 @@ type trieKV struct {
 
 type (
 	// trieGeneratorFn is the interface of trie generation which can
-	// be implemented by different trie algorithm.
-	trieGeneratorFn func(in chan (trieKV), out chan (common.Hash))
+	// be implemented by different trie algorithm. It always delivers a root
+	// through out and reports any failure in writing the trie as an error.
+	trieGeneratorFn func(db database.DBManager, in chan (trieKV), out chan (common.Hash)) error
 
 	// leafCallbackFn is the callback invoked at the leaves of the trie,
 	// returns the subtrie root with the specified subtrie identifier.
 	leafCallbackFn func(accountHash, codeHash common.Hash, stat *generateStats) (common.Hash, error)
 )
 
-// TODO-Kaia-Snapshot port GenerateAccountTrieRoot/GenerateStorageTrieRoot/GenerateTrie
+// TODO-Kaia-Snapshot port GenerateAccountTrieRoot/GenerateStorageTrieRoot
+
+// GenerateTrie takes the whole snapshot tree as the input, traverses all the
+// accounts as well as the corresponding storages and regenerate the whole state
+// (account trie + all storage tries). All the regenerated trie nodes and the
+// referenced contract codes are written into the given destination database.
+func GenerateTrie(snaptree *Tree, root common.Hash, src database.DBManager, dst database.DBManager) error {
+	// Traverse all state by snapshot, re-generate the whole state trie
+	acctIt, err := snaptree.AccountIterator(root, common.Hash{})
+	if err != nil {
+		return err // The required snapshot might not exist.
+	}
+	defer acctIt.Release()
+
+	got, err := generateTrieRoot(dst, acctIt, common.Hash{}, stackTrieGenerate, func(accountHash, codeHash common.Hash, stat *generateStats) (common.Hash, error) {
+		// Migrate the code first, commit the contract code into the dst db.
+		if codeHash != emptyCode {
+			code := src.ReadCode(codeHash)
+			if len(code) == 0 {
+				return common.Hash{}, errors.New("failed to read contract code")
+			}
+			dst.WriteCode(codeHash, code)
+		}
+		// Then migrate all storage trie nodes into the dst db.
+		storageIt, err := snaptree.StorageIterator(root, accountHash, common.Hash{})
+		if err != nil {
+			return common.Hash{}, err
+		}
+		defer storageIt.Release()
+
+		hash, err := generateTrieRoot(dst, storageIt, accountHash, stackTrieGenerate, nil, stat, false)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		return hash, nil
+	}, newGenerateStats(), true)
+	if err != nil {
+		return err
+	}
+	if got != root {
+		return fmt.Errorf("state root hash mismatch: got %x, want %x", got, root)
+	}
+	return nil
+}
 
 // generateStats is a collection of statistics gathered by the trie generator
 // for logging purposes.
@@ -194,18 +239,19 @@ func runReport(stats *generateStats, stop chan bool) {
 // generateTrieRoot generates the trie hash based on the snapshot iterator.
 // It can be used for generating account trie, storage trie or even the
 // whole state which connects the accounts and the corresponding storages.
-func generateTrieRoot(it Iterator, accountHash common.Hash, generatorFn trieGeneratorFn, leafCallback leafCallbackFn, stats *generateStats, report bool) (common.Hash, error) {
+func generateTrieRoot(db database.DBManager, it Iterator, accountHash common.Hash, generatorFn trieGeneratorFn, leafCallback leafCallbackFn, stats *generateStats, report bool) (common.Hash, error) {
 	var (
 		in      = make(chan trieKV)         // chan to pass leaves
 		out     = make(chan common.Hash, 1) // chan to collect result
 		stoplog = make(chan bool, 1)        // 1-size buffer, works when logging is not enabled
+		genErr  error                       // error of the trie generator, read after wg.Wait
 		wg      sync.WaitGroup
 	)
 	// Spin up a go-routine for trie hash re-generation
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		generatorFn(in, out)
+		genErr = generatorFn(db, in, out)
 	}()
 	// Spin up a go-routine for progress logging
 	if report && stats != nil {
@@ -236,6 +282,9 @@ func generateTrieRoot(it Iterator, accountHash common.Hash, generatorFn trieGene
 		stoplog <- fail == nil
 
 		wg.Wait()
+		if fail == nil {
+			fail = genErr
+		}
 		return result, fail
 	}
 	var (
@@ -267,8 +316,8 @@ func generateTrieRoot(it Iterator, accountHash common.Hash, generatorFn trieGene
 				}
 				acc := serializer.GetAccount()
 				go func(hash common.Hash) {
-					contract, ok := acc.(*account.SmartContractAccount)
-					if !ok {
+					contract := account.GetProgramAccount(acc)
+					if contract == nil {
 						results <- nil
 						return
 					}
@@ -317,7 +366,7 @@ func generateTrieRoot(it Iterator, accountHash common.Hash, generatorFn trieGene
 	return stop(nil)
 }
 
-func trieGenerate(in chan trieKV, out chan common.Hash) {
+func trieGenerate(_ database.DBManager, in chan trieKV, out chan common.Hash) error {
 	db := statedb.NewDatabase(database.NewMemoryDBManager())
 	t, _ := statedb.NewTrie(common.Hash{}, db, nil)
 	for leaf := range in {
@@ -330,4 +379,30 @@ func trieGenerate(in chan trieKV, out chan common.Hash) {
 		root, _ = t.Commit(nil)
 	}
 	out <- root
+	return nil
+}
+
+func stackTrieGenerate(db database.DBManager, in chan trieKV, out chan common.Hash) error {
+	var (
+		t    = statedb.NewStackTrie(db)
+		root common.Hash
+		err  error
+	)
+	// Keep draining the leaves after a failure so that the feeder never blocks.
+	for leaf := range in {
+		if err == nil {
+			err = t.TryUpdate(leaf.key[:], leaf.value)
+		}
+	}
+	if err != nil {
+		out <- root
+		return err
+	}
+	if db == nil {
+		root = t.Hash()
+	} else {
+		root, err = t.Commit()
+	}
+	out <- root
+	return err
 }
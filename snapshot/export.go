@@ -0,0 +1,566 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/VictoriaMetrics/fastcache"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/account"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+const (
+	// exportVersion is the version of the exported snapshot file set.
+	exportVersion = 1
+
+	// ManifestFile is the name of the file describing an exported snapshot.
+	ManifestFile = "manifest.json"
+
+	// BlockFile is the name of the file holding the block of an exported snapshot.
+	BlockFile = "block.rlp"
+
+	// DefaultExportChunkSize is the default amount of uncompressed data
+	// written into a single chunk file.
+	DefaultExportChunkSize = 256 * 1024 * 1024
+
+	// maxExportEntrySize is the maximum size of an entry of a chunk, far above
+	// the size of any account, storage slot or contract code. The checksums of
+	// the chunks are listed in the manifest, which is not authenticated.
+	maxExportEntrySize = 16 * 1024 * 1024
+)
+
+// Kinds of the entries in an exported snapshot.
+const (
+	exportAccount uint8 = iota // Account hash and the serialized account
+	exportStorage              // Account hash, slot hash and the slot value
+	exportCode                 // Code hash and the contract code
+)
+
+var errExportExists = errors.New("snapshot export already exists")
+
+// exportEntry is a single RLP encoded item of an exported chunk.
+type exportEntry struct {
+	Kind    uint8
+	Account common.Hash // Account hash for accounts and storage slots, code hash for codes
+	Key     common.Hash // Slot hash for storage slots, empty otherwise
+	Value   []byte
+}
+
+// ExportChunk describes a single compressed chunk file of an exported snapshot.
+type ExportChunk struct {
+	Name    string `json:"name"`
+	Size    uint64 `json:"size"`    // Size of the compressed file
+	SHA256  string `json:"sha256"`  // Checksum of the compressed file
+	Entries uint64 `json:"entries"` // Number of the entries in the chunk
+}
+
+// ExportBlock describes the block whose state is exported. The block is stored
+// as BlockFile with its receipts and total blockscore, so that the importing
+// node can start from it.
+type ExportBlock struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+	SHA256 string      `json:"sha256"` // Checksum of the block file
+}
+
+// exportBlockData is the content of the block file.
+type exportBlockData struct {
+	Block    *types.Block
+	Receipts []*types.ReceiptForStorage
+	TD       *big.Int
+}
+
+// ExportManifest describes an exported snapshot, stored as ManifestFile
+// alongside the chunk files.
+type ExportManifest struct {
+	Version  uint64         `json:"version"`
+	Root     common.Hash    `json:"root"`
+	Block    *ExportBlock   `json:"block"`
+	Accounts uint64         `json:"accounts"`
+	Slots    uint64         `json:"slots"`
+	Codes    uint64         `json:"codes"`
+	Chunks   []*ExportChunk `json:"chunks"`
+}
+
+// chunkWriter writes the export entries into gzip compressed chunk files,
+// switching to a new file every chunkSize bytes of uncompressed data.
+type chunkWriter struct {
+	dir       string
+	chunkSize uint64
+	manifest  *ExportManifest
+
+	file   *os.File
+	hasher hash.Hash
+	buf    *bufio.Writer
+	gz     *gzip.Writer
+	chunk  *ExportChunk
+	size   uint64 // Uncompressed bytes written into the current chunk
+}
+
+func (w *chunkWriter) write(entry *exportEntry) error {
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+	blob, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := w.gz.Write(blob); err != nil {
+		return err
+	}
+	w.chunk.Entries++
+	w.size += uint64(len(blob))
+	if w.size >= w.chunkSize {
+		return w.close()
+	}
+	return nil
+}
+
+func (w *chunkWriter) open() error {
+	name := fmt.Sprintf("chunk-%06d.rlp.gz", len(w.manifest.Chunks))
+	file, err := os.Create(filepath.Join(w.dir, name))
+	if err != nil {
+		return err
+	}
+	w.file, w.hasher = file, sha256.New()
+	w.buf = bufio.NewWriter(io.MultiWriter(file, w.hasher))
+	w.gz = gzip.NewWriter(w.buf)
+	w.chunk, w.size = &ExportChunk{Name: name}, 0
+	return nil
+}
+
+// close finishes the current chunk file and records it in the manifest.
+func (w *chunkWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	defer func() { w.file = nil }()
+
+	if err := w.gz.Close(); err != nil {
+		w.file.Close()
+		return err
+	}
+	if err := w.buf.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	info, err := w.file.Stat()
+	if err != nil {
+		w.file.Close()
+		return err
+	}
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.chunk.Size = uint64(info.Size())
+	w.chunk.SHA256 = hex.EncodeToString(w.hasher.Sum(nil))
+	w.manifest.Chunks = append(w.manifest.Chunks, w.chunk)
+	return nil
+}
+
+// Export dumps the accounts, storage slots and contract codes of the state of
+// the given block into a set of chunked, checksummed and gzip compressed files
+// in the given directory, along with the block itself. The codes, receipts and
+// total blockscore are read from db.
+func Export(snaptree *Tree, block *types.Block, db database.DBManager, dir string, chunkSize uint64) (*ExportManifest, error) {
+	if chunkSize == 0 {
+		chunkSize = DefaultExportChunkSize
+	}
+	if _, err := os.Stat(filepath.Join(dir, ManifestFile)); err == nil {
+		return nil, errExportExists
+	}
+	root := block.Root()
+	acctIt, err := snaptree.AccountIterator(root, common.Hash{})
+	if err != nil {
+		return nil, err // The required snapshot might not exist.
+	}
+	defer acctIt.Release()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	exportedBlock, err := exportBlock(db, block, dir)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		manifest = &ExportManifest{Version: exportVersion, Root: root, Block: exportedBlock}
+		w        = &chunkWriter{dir: dir, chunkSize: chunkSize, manifest: manifest}
+		codes    = make(map[common.Hash]struct{})
+		start    = time.Now()
+		logged   = time.Now()
+	)
+	defer w.close()
+
+	for acctIt.Next() {
+		accHash, data := acctIt.Hash(), acctIt.Account()
+		if err := w.write(&exportEntry{Kind: exportAccount, Account: accHash, Value: data}); err != nil {
+			return nil, err
+		}
+		manifest.Accounts++
+
+		serializer := account.NewAccountSerializer()
+		if err := rlp.DecodeBytes(data, serializer); err != nil {
+			return nil, err
+		}
+		// Both contracts and EOAs with a delegation carry code and storage.
+		if contract := account.GetProgramAccount(serializer.GetAccount()); contract != nil {
+			codeHash := common.BytesToHash(contract.GetCodeHash())
+			if _, ok := codes[codeHash]; !ok && codeHash != emptyCode {
+				code := db.ReadCode(codeHash)
+				if len(code) == 0 {
+					return nil, fmt.Errorf("missing contract code %x", codeHash)
+				}
+				if err := w.write(&exportEntry{Kind: exportCode, Account: codeHash, Value: code}); err != nil {
+					return nil, err
+				}
+				codes[codeHash] = struct{}{}
+				manifest.Codes++
+			}
+			storageIt, err := snaptree.StorageIterator(root, accHash, common.Hash{})
+			if err != nil {
+				return nil, err
+			}
+			for storageIt.Next() {
+				if err := w.write(&exportEntry{Kind: exportStorage, Account: accHash, Key: storageIt.Hash(), Value: storageIt.Slot()}); err != nil {
+					storageIt.Release()
+					return nil, err
+				}
+				manifest.Slots++
+			}
+			err = storageIt.Error()
+			storageIt.Release()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			logger.Info("Exporting snapshot", "at", accHash, "accounts", manifest.Accounts, "slots", manifest.Slots,
+				"codes", manifest.Codes, "chunks", len(manifest.Chunks), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := acctIt.Error(); err != nil {
+		return nil, err
+	}
+	if err := w.close(); err != nil {
+		return nil, err
+	}
+	if err := writeManifest(dir, manifest); err != nil {
+		return nil, err
+	}
+	logger.Info("Exported snapshot", "root", root, "accounts", manifest.Accounts, "slots", manifest.Slots,
+		"codes", manifest.Codes, "chunks", len(manifest.Chunks), "elapsed", common.PrettyDuration(time.Since(start)))
+	return manifest, nil
+}
+
+// exportBlock writes the given block with its receipts and total blockscore
+// into the block file.
+func exportBlock(db database.DBManager, block *types.Block, dir string) (*ExportBlock, error) {
+	hash, number := block.Hash(), block.NumberU64()
+	td := db.ReadTd(hash, number)
+	if td == nil {
+		return nil, fmt.Errorf("missing total blockscore of block %d", number)
+	}
+	receipts := db.ReadReceipts(hash, number)
+	if receipts == nil && block.Transactions().Len() > 0 {
+		return nil, fmt.Errorf("missing receipts of block %d", number)
+	}
+	storage := make([]*types.ReceiptForStorage, len(receipts))
+	for i, receipt := range receipts {
+		storage[i] = (*types.ReceiptForStorage)(receipt)
+	}
+	blob, err := rlp.EncodeToBytes(&exportBlockData{Block: block, Receipts: storage, TD: td})
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, BlockFile), blob, 0o644); err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(blob)
+	return &ExportBlock{Number: number, Hash: hash, SHA256: hex.EncodeToString(sum[:])}, nil
+}
+
+func writeManifest(dir string, manifest *ExportManifest) error {
+	blob, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ManifestFile), blob, 0o644)
+}
+
+// ReadExportManifest reads the manifest of the snapshot exported into dir.
+func ReadExportManifest(dir string) (*ExportManifest, error) {
+	blob, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	manifest := new(ExportManifest)
+	if err := json.Unmarshal(blob, manifest); err != nil {
+		return nil, fmt.Errorf("invalid snapshot manifest: %v", err)
+	}
+	if manifest.Version != exportVersion {
+		return nil, fmt.Errorf("unsupported snapshot export version %d (want %d)", manifest.Version, exportVersion)
+	}
+	if manifest.Block == nil {
+		return nil, errors.New("missing block in snapshot manifest")
+	}
+	return manifest, nil
+}
+
+// Import writes the snapshot exported into dir to the given database as its
+// disk layer, rebuilds the state trie and the contract codes from it and
+// verifies that the root of the rebuilt state matches the exported one. The
+// exported files are trusted only if the exported block has the given
+// checkpoint hash, obtained from a trusted source. Only then the exported
+// block is written as a canonical block, and the snapshot root is written
+// last, so a failed import can be retried. The head markers are advanced to
+// the exported block only if its parent is the local canonical block, so the
+// head never skips missing headers. types.DeriveSha should be initialized with
+// the chain config beforehand. The database must be initialized with the
+// genesis block and must not contain a snapshot yet.
+func Import(db database.DBManager, dir string, checkpoint common.Hash) (common.Hash, error) {
+	manifest, err := ReadExportManifest(dir)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if manifest.Block.Hash != checkpoint {
+		return common.Hash{}, fmt.Errorf("checkpoint mismatch: exported block %d has hash %x, want %x", manifest.Block.Number, manifest.Block.Hash, checkpoint)
+	}
+	if root := db.ReadSnapshotRoot(); root != (common.Hash{}) {
+		return common.Hash{}, fmt.Errorf("database already contains the snapshot of %x", root)
+	}
+	if db.ReadCanonicalHash(0) == (common.Hash{}) {
+		return common.Hash{}, errors.New("database is not initialized")
+	}
+	block, err := readExportedBlock(db, dir, manifest)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid %s: %v", BlockFile, err)
+	}
+	// Delete the leftovers of a failed import, which are not covered by any root.
+	if err := wipeKeyRange(db, "accounts", database.SnapshotAccountPrefix, nil, nil, len(database.SnapshotAccountPrefix)+common.HashLength, nil, true); err != nil {
+		return common.Hash{}, err
+	}
+	if err := wipeKeyRange(db, "storage", database.SnapshotStoragePrefix, nil, nil, len(database.SnapshotStoragePrefix)+2*common.HashLength, nil, true); err != nil {
+		return common.Hash{}, err
+	}
+	start := time.Now()
+	for i, chunk := range manifest.Chunks {
+		if err := importChunk(db, dir, chunk); err != nil {
+			return common.Hash{}, fmt.Errorf("failed to import %s: %v", chunk.Name, err)
+		}
+		logger.Info("Imported snapshot chunk", "chunk", chunk.Name, "entries", chunk.Entries,
+			"progress", fmt.Sprintf("%d/%d", i+1, len(manifest.Chunks)), "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	// Regenerate the state trie out of the imported snapshot, which also
+	// verifies the storage root of every program account and that the
+	// imported state matches the exported root. The imported disk layer is
+	// complete, so it is used as is.
+	triedb := statedb.NewDatabase(db)
+	snaptree := &Tree{
+		diskdb: db,
+		triedb: triedb,
+		cache:  256,
+		layers: map[common.Hash]snapshot{
+			manifest.Root: &diskLayer{diskdb: db, triedb: triedb, cache: fastcache.New(256 * 1024 * 1024), root: manifest.Root},
+		},
+	}
+	if err := GenerateTrie(snaptree, manifest.Root, db, db); err != nil {
+		return common.Hash{}, err
+	}
+	writeExportedBlock(db, block)
+
+	// Mark the imported disk layer as fully generated.
+	batch := db.NewSnapshotDBBatch()
+	defer batch.Release()
+
+	batch.WriteSnapshotRoot(manifest.Root)
+	journalProgress(batch, nil, nil)
+	if err := batch.Write(); err != nil {
+		return common.Hash{}, err
+	}
+	logger.Info("Imported snapshot", "root", manifest.Root, "number", block.Block.NumberU64(), "hash", block.Block.Hash(),
+		"accounts", manifest.Accounts, "slots", manifest.Slots, "codes", manifest.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return manifest.Root, nil
+}
+
+// readExportedBlock reads the block file and checks it against the manifest
+// and the local canonical chain.
+func readExportedBlock(db database.DBManager, dir string, manifest *ExportManifest) (*exportBlockData, error) {
+	blob, err := os.ReadFile(filepath.Join(dir, BlockFile))
+	if err != nil {
+		return nil, err
+	}
+	if sum := sha256.Sum256(blob); hex.EncodeToString(sum[:]) != manifest.Block.SHA256 {
+		return nil, fmt.Errorf("checksum mismatch: have %x, want %s", sum, manifest.Block.SHA256)
+	}
+	data := new(exportBlockData)
+	if err := rlp.DecodeBytes(blob, data); err != nil {
+		return nil, err
+	}
+	var (
+		block  = data.Block
+		header = block.Header()
+		number = block.Number()
+	)
+	if block.Hash() != manifest.Block.Hash || block.NumberU64() != manifest.Block.Number {
+		return nil, fmt.Errorf("block mismatch: have %d/%x, want %d/%x", block.NumberU64(), block.Hash(), manifest.Block.Number, manifest.Block.Hash)
+	}
+	if block.Root() != manifest.Root {
+		return nil, fmt.Errorf("state root mismatch: have %x, want %x", block.Root(), manifest.Root)
+	}
+	if hash := types.DeriveSha(block.Transactions(), number); hash != header.TxHash {
+		return nil, fmt.Errorf("transaction root mismatch: have %x, want %x", hash, header.TxHash)
+	}
+	receipts := make(types.Receipts, len(data.Receipts))
+	for i, receipt := range data.Receipts {
+		receipts[i] = (*types.Receipt)(receipt)
+	}
+	if hash := types.DeriveSha(receipts, number); hash != header.ReceiptHash {
+		return nil, fmt.Errorf("receipt root mismatch: have %x, want %x", hash, header.ReceiptHash)
+	}
+	if canon := db.ReadCanonicalHash(block.NumberU64()); canon != (common.Hash{}) && canon != block.Hash() {
+		return nil, fmt.Errorf("block %d conflicts with the canonical block: have %x, want %x", block.NumberU64(), block.Hash(), canon)
+	}
+	return data, nil
+}
+
+// writeExportedBlock writes the exported block as a canonical block, and
+// advances the head block to it unless the local chain is already ahead or
+// the parent of the block is missing from the local canonical chain.
+func writeExportedBlock(db database.DBManager, data *exportBlockData) {
+	block := data.Block
+	hash, number := block.Hash(), block.NumberU64()
+	receipts := make(types.Receipts, len(data.Receipts))
+	for i, receipt := range data.Receipts {
+		receipts[i] = (*types.Receipt)(receipt)
+	}
+	db.WriteBlock(block)
+	db.WriteReceipts(hash, number, receipts)
+	db.WriteTd(hash, number, data.TD)
+	db.WriteCanonicalHash(hash, number)
+	db.WriteTxLookupEntries(block)
+
+	if number > 0 && (db.ReadCanonicalHash(number-1) != block.ParentHash() || db.ReadHeader(block.ParentHash(), number-1) == nil) {
+		logger.Warn("Keeping the head markers, parent of the imported block is missing", "number", number, "hash", hash)
+		return
+	}
+	if current := db.ReadHeaderNumber(db.ReadHeadBlockHash()); current == nil || *current < number {
+		db.WriteHeadBlockHash(hash)
+	}
+	if current := db.ReadHeaderNumber(db.ReadHeadHeaderHash()); current == nil || *current < number {
+		db.WriteHeadHeaderHash(hash)
+	}
+	if current := db.ReadHeaderNumber(db.ReadHeadFastBlockHash()); current == nil || *current < number {
+		db.WriteHeadFastBlockHash(hash)
+	}
+}
+
+// importChunk verifies the checksum of a chunk file and writes its entries
+// into the database.
+func importChunk(db database.DBManager, dir string, chunk *ExportChunk) error {
+	file, err := os.Open(filepath.Join(dir, filepath.Base(chunk.Name)))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return err
+	}
+	if uint64(size) != chunk.Size {
+		return fmt.Errorf("size mismatch: have %d, want %d", size, chunk.Size)
+	}
+	if sum := hex.EncodeToString(hasher.Sum(nil)); sum != chunk.SHA256 {
+		return fmt.Errorf("checksum mismatch: have %s, want %s", sum, chunk.SHA256)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	gz, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	batch := db.NewSnapshotDBBatch()
+	defer batch.Release()
+
+	var (
+		stream  = rlp.NewStream(gz, 0)
+		entries uint64
+	)
+	for {
+		var entry exportEntry
+		if _, size, err := stream.Kind(); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		} else if size > maxExportEntrySize {
+			return fmt.Errorf("chunk entry too large: %d bytes, limit %d", size, maxExportEntrySize)
+		}
+		if err := stream.Decode(&entry); err != nil {
+			return err
+		}
+		switch entry.Kind {
+		case exportAccount:
+			batch.WriteAccountSnapshot(entry.Account, entry.Value)
+		case exportStorage:
+			batch.WriteStorageSnapshot(entry.Account, entry.Key, entry.Value)
+		case exportCode:
+			if codeHash := crypto.Keccak256Hash(entry.Value); codeHash != entry.Account {
+				return fmt.Errorf("contract code hash mismatch: have %x, want %x", codeHash, entry.Account)
+			}
+			db.WriteCode(entry.Account, entry.Value)
+		default:
+			return fmt.Errorf("unknown entry kind %d", entry.Kind)
+		}
+		entries++
+		if batch.ValueSize() > database.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if entries != chunk.Entries {
+		return fmt.Errorf("entry count mismatch: have %d, want %d", entries, chunk.Entries)
+	}
+	return batch.Write()
+}
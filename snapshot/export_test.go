@@ -0,0 +1,271 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/derivesha"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newExportTestTree creates a state with a few accounts, contracts and storage
+// slots, and returns the snapshot tree built on top of it.
+func newExportTestTree(t *testing.T) (*testHelper, *Tree, common.Hash) {
+	helper := newHelper()
+	code := []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+	codeHash := crypto.Keccak256Hash(code)
+	helper.diskdb.WriteCode(codeHash, code)
+
+	stRoot := helper.makeStorageTrie([]string{"key-1", "key-2", "key-3"}, []string{"val-1", "val-2", "val-3"})
+	for i, key := range []string{"acc-1", "acc-2", "acc-3"} {
+		acc, _ := genSmartContractAccount(uint64(i), big.NewInt(int64(i)), stRoot, codeHash.Bytes())
+		helper.addTrieAccount(key, acc)
+	}
+	for i, key := range []string{"acc-4", "acc-5"} {
+		acc, _ := genExternallyOwnedAccount(uint64(i), big.NewInt(int64(i)))
+		helper.addTrieAccount(key, acc)
+	}
+	// An EOA delegated to the contract above, holding its own storage.
+	delegation := append([]byte{0xef, 0x01, 0x00}, common.HexToAddress("0xc0de").Bytes()...)
+	delegationHash := crypto.Keccak256Hash(delegation)
+	helper.diskdb.WriteCode(delegationHash, delegation)
+	delegatedRoot := helper.makeStorageTrie([]string{"key-4"}, []string{"val-4"})
+	acc, _ := genDelegatedAccount(0, big.NewInt(6), delegatedRoot, delegationHash.Bytes())
+	helper.addTrieAccount("acc-6", acc)
+	root, snap := helper.Generate()
+	select {
+	case <-snap.genPending:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Snapshot generation failed")
+	}
+	return helper, &Tree{diskdb: helper.diskdb, triedb: helper.triedb, layers: map[common.Hash]snapshot{root: snap}}, root
+}
+
+// exportTestGenesis is the genesis block of the exporting and importing chains.
+var exportTestGenesis = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0), BlockScore: big.NewInt(1), Time: big.NewInt(0)})
+
+// newExportTestBlock returns a block of the given number on top of the given
+// state root, storing its total blockscore. The block of number 1 is the
+// child of the genesis block, while the others have an unknown parent.
+func newExportTestBlock(db database.DBManager, root common.Hash, number int64) *types.Block {
+	derivesha.InitDeriveSha(params.TestChainConfig, nil)
+	header := &types.Header{Number: big.NewInt(number), Root: root, BlockScore: big.NewInt(1), Time: big.NewInt(0)}
+	if number == 1 {
+		header.ParentHash = exportTestGenesis.Hash()
+	} else {
+		header.ParentHash = common.HexToHash("0xdead")
+	}
+	block := types.NewBlock(header, nil, nil)
+	db.WriteTd(block.Hash(), block.NumberU64(), big.NewInt(number+1))
+	return block
+}
+
+// newImportTestDB returns a database initialized with a genesis block.
+func newImportTestDB() database.DBManager {
+	db := database.NewMemoryDBManager()
+	db.WriteBlock(exportTestGenesis)
+	db.WriteCanonicalHash(exportTestGenesis.Hash(), 0)
+	return db
+}
+
+func TestExportImport(t *testing.T) {
+	helper, snaptree, root := newExportTestTree(t)
+	block := newExportTestBlock(helper.diskdb, root, 1)
+	dir := t.TempDir()
+
+	// A tiny chunk size splits the export into many chunks.
+	manifest, err := Export(snaptree, block, helper.diskdb, dir, 128)
+	require.NoError(t, err)
+	assert.Equal(t, root, manifest.Root)
+	assert.Equal(t, block.Hash(), manifest.Block.Hash)
+	assert.Equal(t, uint64(6), manifest.Accounts)
+	assert.Equal(t, uint64(10), manifest.Slots)
+	assert.Equal(t, uint64(2), manifest.Codes)
+	assert.Greater(t, len(manifest.Chunks), 1)
+
+	// Exporting into the same directory twice is rejected.
+	_, err = Export(snaptree, block, helper.diskdb, dir, 128)
+	assert.Equal(t, errExportExists, err)
+
+	db := newImportTestDB()
+	imported, err := Import(db, dir, block.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, root, imported)
+
+	// The exported block becomes the head block, matching the snapshot.
+	assert.Equal(t, block.Hash(), db.ReadHeadBlockHash())
+	assert.Equal(t, block.Hash(), db.ReadHeadHeaderHash())
+	assert.Equal(t, block.Hash(), db.ReadCanonicalHash(block.NumberU64()))
+	assert.Equal(t, big.NewInt(2), db.ReadTd(block.Hash(), block.NumberU64()))
+
+	// The state trie, the codes and the snapshot are all restored.
+	ok, err := db.HasTrieNode(root.ExtendZero())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, db.HasCode(crypto.Keccak256Hash([]byte{0x60, 0x00, 0x60, 0x00, 0xf3})))
+	assert.Equal(t, root, db.ReadSnapshotRoot())
+	accHash := hashData([]byte("acc-1"))
+	assert.Equal(t, helper.diskdb.ReadAccountSnapshot(accHash), db.ReadAccountSnapshot(accHash))
+	slotHash := hashData([]byte("key-2"))
+	assert.Equal(t, []byte("val-2"), db.ReadStorageSnapshot(accHash, slotHash))
+	for _, hash := range []string{"acc-4", "acc-5"} {
+		assert.NotNil(t, db.ReadAccountSnapshot(hashData([]byte(hash))))
+	}
+	// The storage and the delegation of the delegated EOA are restored as well.
+	assert.Equal(t, []byte("val-4"), db.ReadStorageSnapshot(hashData([]byte("acc-6")), hashData([]byte("key-4"))))
+	assert.True(t, db.HasCode(crypto.Keccak256Hash(append([]byte{0xef, 0x01, 0x00}, common.HexToAddress("0xc0de").Bytes()...))))
+
+	// Importing into a database with a snapshot is rejected.
+	_, err = Import(db, dir, block.Hash())
+	assert.Error(t, err)
+}
+
+func TestImportCheckpoint(t *testing.T) {
+	helper, snaptree, root := newExportTestTree(t)
+	block := newExportTestBlock(helper.diskdb, root, 1)
+	dir := t.TempDir()
+
+	_, err := Export(snaptree, block, helper.diskdb, dir, 0)
+	require.NoError(t, err)
+
+	// An export not matching the trusted block hash is rejected untouched.
+	db := newImportTestDB()
+	_, err = Import(db, dir, common.HexToHash("0x1234"))
+	if assert.Error(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "checkpoint mismatch"), err.Error())
+	}
+	assert.Equal(t, common.Hash{}, db.ReadSnapshotRoot())
+	assert.Equal(t, common.Hash{}, db.ReadCanonicalHash(block.NumberU64()))
+	assert.Nil(t, db.ReadAccountSnapshot(hashData([]byte("acc-1"))))
+}
+
+func TestImportHeaderGap(t *testing.T) {
+	helper, snaptree, root := newExportTestTree(t)
+	block := newExportTestBlock(helper.diskdb, root, 10)
+	dir := t.TempDir()
+
+	_, err := Export(snaptree, block, helper.diskdb, dir, 0)
+	require.NoError(t, err)
+
+	// The parent of the exported block is missing, so the state and the block
+	// are imported while the head markers stay at the genesis block.
+	db := newImportTestDB()
+	db.WriteHeadBlockHash(exportTestGenesis.Hash())
+	db.WriteHeadHeaderHash(exportTestGenesis.Hash())
+	db.WriteHeadFastBlockHash(exportTestGenesis.Hash())
+	imported, err := Import(db, dir, block.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, root, imported)
+	assert.Equal(t, block.Hash(), db.ReadCanonicalHash(block.NumberU64()))
+	assert.Equal(t, exportTestGenesis.Hash(), db.ReadHeadBlockHash())
+	assert.Equal(t, exportTestGenesis.Hash(), db.ReadHeadHeaderHash())
+	assert.Equal(t, exportTestGenesis.Hash(), db.ReadHeadFastBlockHash())
+}
+
+func TestImportCorruptedChunk(t *testing.T) {
+	helper, snaptree, root := newExportTestTree(t)
+	dir := t.TempDir()
+
+	block := newExportTestBlock(helper.diskdb, root, 1)
+	manifest, err := Export(snaptree, block, helper.diskdb, dir, 0)
+	require.NoError(t, err)
+	require.Len(t, manifest.Chunks, 1)
+
+	path := filepath.Join(dir, manifest.Chunks[0].Name)
+	blob, err := os.ReadFile(path)
+	require.NoError(t, err)
+	blob = bytes.Clone(blob)
+	blob[len(blob)/2] ^= 0xff
+	require.NoError(t, os.WriteFile(path, blob, 0o644))
+
+	_, err = Import(newImportTestDB(), dir, block.Hash())
+	if assert.Error(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "checksum mismatch"), err.Error())
+	}
+}
+
+func TestImportOversizedEntry(t *testing.T) {
+	helper, snaptree, root := newExportTestTree(t)
+	dir := t.TempDir()
+
+	block := newExportTestBlock(helper.diskdb, root, 1)
+	manifest, err := Export(snaptree, block, helper.diskdb, dir, 0)
+	require.NoError(t, err)
+	require.Len(t, manifest.Chunks, 1)
+
+	// Replace the entries by a list header claiming 4 GiB, with a valid checksum.
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err = gz.Write([]byte{0xfb, 0xff, 0xff, 0xff, 0xff})
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	chunk := manifest.Chunks[0]
+	require.NoError(t, os.WriteFile(filepath.Join(dir, chunk.Name), buf.Bytes(), 0o644))
+
+	sum := sha256.Sum256(buf.Bytes())
+	chunk.Size, chunk.SHA256 = uint64(buf.Len()), hex.EncodeToString(sum[:])
+	require.NoError(t, writeManifest(dir, manifest))
+
+	_, err = Import(newImportTestDB(), dir, block.Hash())
+	if assert.Error(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "chunk entry too large"), err.Error())
+	}
+}
+
+func TestImportRetry(t *testing.T) {
+	helper, snaptree, root := newExportTestTree(t)
+	block := newExportTestBlock(helper.diskdb, root, 1)
+	dir := t.TempDir()
+
+	manifest, err := Export(snaptree, block, helper.diskdb, dir, 128)
+	require.NoError(t, err)
+	require.Greater(t, len(manifest.Chunks), 1)
+
+	// Drop the last chunk, so the imported state does not match the root.
+	complete := *manifest
+	manifest.Chunks = manifest.Chunks[:len(manifest.Chunks)-1]
+	require.NoError(t, writeManifest(dir, manifest))
+
+	db := newImportTestDB()
+	_, err = Import(db, dir, block.Hash())
+	require.Error(t, err)
+	assert.Equal(t, common.Hash{}, db.ReadSnapshotRoot())
+	assert.Equal(t, common.Hash{}, db.ReadHeadBlockHash())
+
+	// The failed import is not mistaken for an imported snapshot.
+	require.NoError(t, writeManifest(dir, &complete))
+	imported, err := Import(db, dir, block.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, root, imported)
+	assert.Equal(t, block.Hash(), db.ReadHeadBlockHash())
+}
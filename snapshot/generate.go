@@ -648,10 +648,10 @@ func (dl *diskLayer) generate(stats *generatorStats) {
 		if err := checkAndFlush(marker); err != nil {
 			return err
 		}
-		// If the iterated account is the contract, create a further loop to
-		// verify or regenerate the contract storage.
-		contractAcc, ok := acc.(*account.SmartContractAccount)
-		if !ok {
+		// If the iterated account has a program (a contract or an EOA with a
+		// delegation), create a further loop to verify or regenerate its storage.
+		contractAcc := account.GetProgramAccount(acc)
+		if contractAcc == nil {
 			// If the root is empty, we still need to ensure that any previous snapshot
 			// storage values are cleared
 			// TODO: investigate if this can be avoided, this will be very costly since it
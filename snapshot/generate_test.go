@@ -62,6 +62,18 @@ func genSmartContractAccount(nonce uint64, balance *big.Int, storageRoot common.
 	})
 }
 
+func genDelegatedAccount(nonce uint64, balance *big.Int, storageRoot common.Hash, codeHash []byte) (account.Account, error) {
+	return account.NewAccountWithMap(account.ExternallyOwnedAccountType, map[account.AccountValueKeyType]interface{}{
+		account.AccountValueKeyNonce:         nonce,
+		account.AccountValueKeyBalance:       balance,
+		account.AccountValueKeyHumanReadable: false,
+		account.AccountValueKeyAccountKey:    accountkey.NewAccountKeyLegacy(),
+		account.AccountValueKeyStorageRoot:   storageRoot,
+		account.AccountValueKeyCodeHash:      codeHash,
+		account.AccountValueKeyCodeInfo:      params.CodeInfo(0),
+	})
+}
+
 // Tests that snapshot generation from an empty database.
 func TestGeneration(t *testing.T) {
 	log.EnableLogForTest(log.LvlCrit, log.LvlTrace)
@@ -193,12 +205,12 @@ func checkSnapRoot(t *testing.T, snap *diskLayer, trieRoot common.Hash) {
 	t.Helper()
 	accIt := snap.AccountIterator(common.Hash{})
 	defer accIt.Release()
-	snapRoot, err := generateTrieRoot(accIt, common.Hash{}, trieGenerate,
+	snapRoot, err := generateTrieRoot(nil, accIt, common.Hash{}, trieGenerate,
 		func(accountHash, codeHash common.Hash, stat *generateStats) (common.Hash, error) {
 			storageIt, _ := snap.StorageIterator(accountHash, common.Hash{})
 			defer storageIt.Release()
 
-			hash, err := generateTrieRoot(storageIt, accountHash, trieGenerate, nil, stat, false)
+			hash, err := generateTrieRoot(nil, storageIt, accountHash, trieGenerate, nil, stat, false)
 			if err != nil {
 				return common.Hash{}, err
 			}
@@ -907,3 +919,59 @@ func TestGenerateWithIncompleteStorage(t *testing.T) {
 	snap.genAbort <- stop
 	<-stop
 }
+
+// Tests that the storage of an EOA with a delegation (EIP-7702) is generated
+// into the snapshot like the storage of a contract, and that the state trie
+// regenerated from the snapshot includes it.
+func TestGenerateDelegatedAccountStorage(t *testing.T) {
+	helper := newHelper()
+	code := append([]byte{0xef, 0x01, 0x00}, common.HexToAddress("0xc0de").Bytes()...)
+	codeHash := hashData(code)
+	helper.diskdb.WriteCode(codeHash, code)
+	stRoot := helper.makeStorageTrie([]string{"key-1", "key-2", "key-3"}, []string{"val-1", "val-2", "val-3"})
+
+	// The delegated account misses a slot in the snapshot, which must be regenerated.
+	acc1, _ := genDelegatedAccount(0, big.NewInt(1), stRoot, codeHash.Bytes())
+	helper.addAccount("acc-1", acc1)
+	helper.addSnapStorage("acc-1", []string{"key-1", "key-2"}, []string{"val-1", "val-2"})
+
+	acc2, _ := genExternallyOwnedAccount(0, big.NewInt(2))
+	helper.addAccount("acc-2", acc2)
+
+	root, snap := helper.Generate()
+	select {
+	case <-snap.genPending:
+		// Snapshot generation succeeded
+
+	case <-time.After(3 * time.Second):
+		t.Fatal("Snapshot generation failed")
+	}
+	checkSnapRoot(t, snap, root)
+	for i, key := range []string{"key-1", "key-2", "key-3"} {
+		want := fmt.Sprintf("val-%d", i+1)
+		if have := helper.diskdb.ReadStorageSnapshot(hashData([]byte("acc-1")), hashData([]byte(key))); string(have) != want {
+			t.Errorf("storage snapshot %s mismatch: have %q, want %q", key, have, want)
+		}
+	}
+
+	dst := database.NewMemoryDBManager()
+	snaptree := &Tree{diskdb: helper.diskdb, triedb: helper.triedb, layers: map[common.Hash]snapshot{root: snap}}
+	if err := GenerateTrie(snaptree, root, helper.diskdb, dst); err != nil {
+		t.Fatalf("Failed to regenerate the state trie: %v", err)
+	}
+	stTrie, err := statedb.NewSecureTrie(stRoot, statedb.NewDatabase(dst), nil)
+	if err != nil {
+		t.Fatalf("Missing storage trie of the delegated account: %v", err)
+	}
+	if val, err := stTrie.TryGet([]byte("key-3")); err != nil || string(val) != "val-3" {
+		t.Errorf("storage trie mismatch: have %q (err %v), want %q", val, err, "val-3")
+	}
+	if have := dst.ReadCode(codeHash); string(have) != string(code) {
+		t.Errorf("code mismatch: have %x, want %x", have, code)
+	}
+
+	// Signal abortion to the generator and wait for it to tear down
+	stop := make(chan *generatorStats)
+	snap.genAbort <- stop
+	<-stop
+}
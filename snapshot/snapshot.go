@@ -786,14 +786,14 @@ func (t *Tree) Verify(root common.Hash) error {
 	}
 	defer acctIt.Release()
 
-	got, err := generateTrieRoot(acctIt, common.Hash{}, trieGenerate, func(accountHash, codeHash common.Hash, stat *generateStats) (common.Hash, error) {
+	got, err := generateTrieRoot(nil, acctIt, common.Hash{}, trieGenerate, func(accountHash, codeHash common.Hash, stat *generateStats) (common.Hash, error) {
 		storageIt, err := t.StorageIterator(root, accountHash, common.Hash{})
 		if err != nil {
 			return common.Hash{}, err
 		}
 		defer storageIt.Release()
 
-		hash, err := generateTrieRoot(storageIt, accountHash, trieGenerate, nil, stat, false)
+		hash, err := generateTrieRoot(nil, storageIt, accountHash, trieGenerate, nil, stat, false)
 		if err != nil {
 			return common.Hash{}, err
 		}
@@ -0,0 +1,515 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/rcrowley/go-metrics"
+)
+
+const (
+	ancientHashTable    = "hashes"
+	ancientHeaderTable  = "headers"
+	ancientBodyTable    = "bodies"
+	ancientReceiptTable = "receipts"
+
+	ancientDir = "ancient"
+
+	// ancientRecheckInterval is the frequency to check the key-value database for
+	// chain progression that might permit new blocks to be frozen into the ancient store.
+	ancientRecheckInterval = time.Minute
+
+	// ancientBatchLimit is the maximum number of blocks to freeze in one batch
+	// before doing an fsync and deleting them from the key-value database.
+	ancientBatchLimit = 30000
+)
+
+var ancientTables = []string{ancientHashTable, ancientHeaderTable, ancientBodyTable, ancientReceiptTable}
+
+var ancientFrozenGauge = metrics.NewRegisteredGauge("klay/db/ancient/frozen", nil)
+
+// ancientStore is an append-only store of finalized blocks older than the
+// configured threshold. Canonical hashes, headers, bodies and receipts are kept
+// in flat files per kind, so immutable chain data doesn't get re-compacted by
+// the key-value databases.
+type ancientStore struct {
+	frozen atomic.Uint64 // number of blocks already frozen
+	tables map[string]*ancientTable
+
+	writeLock sync.Mutex // protects the tables from concurrent appends and truncations
+	readOnly  bool       // whether the tables are written by a primary instance in another process
+}
+
+var errAncientReadOnly = errors.New("ancient store is read-only")
+
+// newAncientStore opens the ancient store in the given directory. Tables are
+// aligned to the shortest one, in case of an unclean shutdown in the middle of
+// appending a block.
+func newAncientStore(dir string) (*ancientStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	store := &ancientStore{tables: make(map[string]*ancientTable)}
+	for _, name := range ancientTables {
+		table, err := openAncientTable(dir, name)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+		store.tables[name] = table
+	}
+
+	frozen := store.tables[ancientHashTable].Items()
+	for _, table := range store.tables {
+		if items := table.Items(); items < frozen {
+			frozen = items
+		}
+	}
+	if err := store.truncate(frozen); err != nil {
+		store.Close()
+		return nil, err
+	}
+	store.frozen.Store(frozen)
+	ancientFrozenGauge.Update(int64(frozen))
+	logger.Info("Opened ancient store", "dir", dir, "frozen", frozen)
+	return store, nil
+}
+
+// newAncientStoreReadOnly opens the ancient store in the given directory
+// without modifying it, to follow the blocks frozen by a primary instance.
+func newAncientStoreReadOnly(dir string) (*ancientStore, error) {
+	store := &ancientStore{tables: make(map[string]*ancientTable), readOnly: true}
+	for _, name := range ancientTables {
+		table, err := openAncientTableReadOnly(dir, name)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+		store.tables[name] = table
+	}
+	if err := store.Refresh(); err != nil {
+		store.Close()
+		return nil, err
+	}
+	logger.Info("Opened read-only ancient store", "dir", dir, "frozen", store.Ancients())
+	return store, nil
+}
+
+// Refresh reloads the number of blocks of a read-only store, which is the
+// number of blocks completely appended to all the tables by the primary.
+func (s *ancientStore) Refresh() error {
+	frozen := uint64(math.MaxUint64)
+	for _, name := range ancientTables {
+		table := s.tables[name]
+		if err := table.Refresh(); err != nil {
+			return err
+		}
+		if items := table.Items(); items < frozen {
+			frozen = items
+		}
+	}
+	s.frozen.Store(frozen)
+	return nil
+}
+
+// Ancients returns the number of blocks frozen in the store.
+func (s *ancientStore) Ancients() uint64 {
+	return s.frozen.Load()
+}
+
+// Ancient retrieves the blob of the given kind of the given block number.
+func (s *ancientStore) Ancient(kind string, number uint64) ([]byte, error) {
+	table, ok := s.tables[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown ancient table %s", kind)
+	}
+	if number >= s.Ancients() {
+		return nil, errAncientOutOfBounds
+	}
+	return table.Retrieve(number)
+}
+
+// AppendAncient stores the block data of the given number, which must be the
+// next block to be frozen. If any table fails, all tables are rolled back.
+func (s *ancientStore) AppendAncient(number uint64, hash common.Hash, header, body, receipts []byte) error {
+	if s.readOnly {
+		return errAncientReadOnly
+	}
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if frozen := s.frozen.Load(); number != frozen {
+		return fmt.Errorf("appending ancient block %d out of order, next is %d", number, frozen)
+	}
+	blobs := map[string][]byte{
+		ancientHashTable:    hash.Bytes(),
+		ancientHeaderTable:  header,
+		ancientBodyTable:    body,
+		ancientReceiptTable: receipts,
+	}
+	for _, name := range ancientTables {
+		if err := s.tables[name].Append(number, blobs[name]); err != nil {
+			if rerr := s.truncate(number); rerr != nil {
+				logger.Error("Failed to roll back ancient tables", "number", number, "err", rerr)
+			}
+			return err
+		}
+	}
+	s.frozen.Store(number + 1)
+	ancientFrozenGauge.Update(int64(number + 1))
+	return nil
+}
+
+// TruncateAncients discards the blocks from the given number onwards.
+func (s *ancientStore) TruncateAncients(items uint64) error {
+	if s.readOnly {
+		return errAncientReadOnly
+	}
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if items >= s.frozen.Load() {
+		return nil
+	}
+	if err := s.truncate(items); err != nil {
+		return err
+	}
+	s.frozen.Store(items)
+	ancientFrozenGauge.Update(int64(items))
+	return nil
+}
+
+func (s *ancientStore) truncate(items uint64) error {
+	for _, table := range s.tables {
+		if err := table.Truncate(items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sync flushes all tables to the disk.
+func (s *ancientStore) Sync() error {
+	var errs []error
+	for _, table := range s.tables {
+		if err := table.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Checkpoint copies all tables into the given directory. Appends are blocked
+// meanwhile, so the copied tables hold the same number of blocks.
+func (s *ancientStore) Checkpoint(dir string) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, name := range ancientTables {
+		if err := s.tables[name].copyTo(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes all tables.
+func (s *ancientStore) Close() {
+	for name, table := range s.tables {
+		if err := table.Close(); err != nil {
+			logger.Error("Failed to close ancient table", "table", name, "err", err)
+		}
+	}
+}
+
+// readAncient retrieves the blob of the given kind of the block from the
+// ancient store. Nil is returned if the block is not frozen or the canonical
+// hash of the frozen block doesn't match.
+func (dbm *databaseManager) readAncient(kind string, hash common.Hash, number uint64) []byte {
+	ancient := dbm.ancient.Load()
+	if ancient == nil || number >= ancient.Ancients() {
+		return nil
+	}
+	frozenHash, err := ancient.Ancient(ancientHashTable, number)
+	if err != nil || !bytes.Equal(frozenHash, hash.Bytes()) {
+		return nil
+	}
+	data, err := ancient.Ancient(kind, number)
+	if err != nil {
+		logger.Error("Failed to read ancient data", "kind", kind, "number", number, "hash", hash, "err", err)
+		return nil
+	}
+	return data
+}
+
+// hasAncient returns true if the block is frozen in the ancient store.
+func (dbm *databaseManager) hasAncient(hash common.Hash, number uint64) bool {
+	ancient := dbm.ancient.Load()
+	if ancient == nil || number >= ancient.Ancients() {
+		return false
+	}
+	frozenHash, err := ancient.Ancient(ancientHashTable, number)
+	return err == nil && bytes.Equal(frozenHash, hash.Bytes())
+}
+
+// Ancients returns the number of blocks frozen in the ancient store.
+func (dbm *databaseManager) Ancients() uint64 {
+	ancient := dbm.ancient.Load()
+	if ancient == nil {
+		return 0
+	}
+	return ancient.Ancients()
+}
+
+// truncateAncients discards the frozen blocks from the given number onwards.
+// It is called when the canonical chain is rewound below the frozen blocks.
+func (dbm *databaseManager) truncateAncients(number uint64) {
+	ancient := dbm.ancient.Load()
+	if ancient == nil || ancient.readOnly || number >= ancient.Ancients() {
+		return
+	}
+	if err := ancient.TruncateAncients(number); err != nil {
+		logger.Crit("Failed to truncate ancient store", "number", number, "err", err)
+	}
+	logger.Warn("Truncated ancient store", "frozen", number)
+}
+
+// openAncientStore opens the ancient store and starts the background loop
+// moving finalized blocks from the key-value databases into it. An existing
+// ancient store is opened even if the threshold is not set, so that the
+// blocks frozen before are still readable, but no more blocks are frozen.
+func (dbm *databaseManager) openAncientStore() error {
+	if dbm.config.DBType == MemoryDB {
+		return nil
+	}
+	if dbm.config.ReadOnly {
+		return dbm.openAncientStoreReadOnly()
+	}
+	if dbm.config.AncientThreshold == 0 {
+		if _, err := os.Stat(dbm.ancientDir()); os.IsNotExist(err) {
+			return nil
+		}
+	}
+	store, err := newAncientStore(dbm.ancientDir())
+	if err != nil {
+		return err
+	}
+	dbm.ancient.Store(store)
+	dbm.ancientQuit = make(chan struct{})
+	if dbm.config.AncientThreshold == 0 {
+		return nil
+	}
+
+	dbm.ancientWg.Add(1)
+	go func() {
+		defer dbm.ancientWg.Done()
+		dbm.freeze(ancientRecheckInterval)
+	}()
+	return nil
+}
+
+// openAncientStoreReadOnly opens the ancient store of the primary instance
+// if the primary has created it. Otherwise nothing is opened, and it's
+// retried by TryCatchUpWithPrimary until the primary creates the store.
+func (dbm *databaseManager) openAncientStoreReadOnly() error {
+	if dbm.config.DBType == MemoryDB {
+		return nil
+	}
+	store, err := newAncientStoreReadOnly(dbm.ancientDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil // The primary might be creating the tables right now.
+	} else if err != nil {
+		return err
+	}
+	if !dbm.ancient.CompareAndSwap(nil, store) {
+		store.Close()
+	}
+	return nil
+}
+
+func (dbm *databaseManager) ancientDir() string {
+	return filepath.Join(dbm.config.Dir, ancientDir)
+}
+
+// AncientStoreExists returns true if the ancient store has been created in the
+// given database directory.
+func AncientStoreExists(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ancientDir))
+	return err == nil
+}
+
+// closeAncientStore stops the background freezing and closes the ancient store.
+func (dbm *databaseManager) closeAncientStore() {
+	ancient := dbm.ancient.Load()
+	if ancient == nil {
+		return
+	}
+	if dbm.ancientQuit != nil {
+		close(dbm.ancientQuit)
+	}
+	dbm.ancientWg.Wait()
+	ancient.Close()
+}
+
+// freeze is a background loop which periodically moves the blocks older than
+// the ancient threshold from the key-value databases into the ancient store.
+func (dbm *databaseManager) freeze(recheck time.Duration) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-dbm.ancientQuit:
+			logger.Info("Stopped freezing ancient blocks")
+			return
+		case <-timer.C:
+		}
+
+		frozen, err := dbm.freezeBatch()
+		if err != nil {
+			logger.Error("Failed to freeze ancient blocks", "err", err)
+		}
+		// Continue right away if the batch was full, there may be more to freeze.
+		if frozen == ancientBatchLimit {
+			timer.Reset(0)
+		} else {
+			timer.Reset(recheck)
+		}
+	}
+}
+
+// freezeBatch moves up to ancientBatchLimit blocks below the threshold into
+// the ancient store, then deletes them from the key-value databases. It
+// returns the number of blocks frozen.
+func (dbm *databaseManager) freezeBatch() (int, error) {
+	headHash := dbm.ReadHeadBlockHash()
+	if common.EmptyHash(headHash) {
+		return 0, nil
+	}
+	head := dbm.ReadHeaderNumber(headHash)
+	if head == nil || *head < dbm.config.AncientThreshold {
+		return 0, nil
+	}
+	var (
+		limit  = *head - dbm.config.AncientThreshold
+		first  = dbm.ancient.Load().Ancients()
+		hashes []common.Hash
+	)
+freezing:
+	for number := first; number <= limit && len(hashes) < ancientBatchLimit; number++ {
+		select {
+		case <-dbm.ancientQuit:
+			break freezing // stop early, but still sync and clean up what's frozen so far
+		default:
+		}
+		hash, err := dbm.freezeBlock(number)
+		if err != nil {
+			if len(hashes) == 0 {
+				return 0, err
+			}
+			logger.Error("Stopped freezing ancient blocks", "number", number, "err", err)
+			break
+		}
+		hashes = append(hashes, hash)
+	}
+	if len(hashes) == 0 {
+		return 0, nil
+	}
+	if err := dbm.ancient.Load().Sync(); err != nil {
+		return 0, err
+	}
+
+	// The blocks are safe in the ancient store, remove them from the key-value
+	// databases. The genesis block is always kept in the key-value databases.
+	var (
+		headerBatch   = dbm.NewBatch(headerDB)
+		bodyBatch     = dbm.NewBatch(BodyDB)
+		receiptsBatch = dbm.NewBatch(ReceiptsDB)
+	)
+	defer headerBatch.Release()
+	defer bodyBatch.Release()
+	defer receiptsBatch.Release()
+
+	for i, hash := range hashes {
+		number := first + uint64(i)
+		if number == 0 {
+			continue
+		}
+		headerBatch.Delete(headerKey(number, hash))
+		bodyBatch.Delete(blockBodyKey(number, hash))
+		receiptsBatch.Delete(blockReceiptsKey(number, hash))
+		if headerBatch.ValueSize() > IdealBatchSize {
+			if _, err := WriteBatches(headerBatch, bodyBatch, receiptsBatch); err != nil {
+				return len(hashes), err
+			}
+		}
+	}
+	if _, err := WriteBatches(headerBatch, bodyBatch, receiptsBatch); err != nil {
+		return len(hashes), err
+	}
+	logger.Info("Froze ancient blocks", "from", first, "to", first+uint64(len(hashes))-1, "frozen", dbm.ancient.Load().Ancients())
+	return len(hashes), nil
+}
+
+// freezeBlock appends the canonical block of the given number into the
+// ancient store after checking its integrity.
+func (dbm *databaseManager) freezeBlock(number uint64) (common.Hash, error) {
+	hash := dbm.ReadCanonicalHash(number)
+	if common.EmptyHash(hash) {
+		return common.Hash{}, fmt.Errorf("canonical hash missing, number %d", number)
+	}
+	header, _ := dbm.getDatabase(headerDB).Get(headerKey(number, hash))
+	if len(header) == 0 {
+		return common.Hash{}, fmt.Errorf("block header missing, number %d", number)
+	}
+	body, _ := dbm.getDatabase(BodyDB).Get(blockBodyKey(number, hash))
+	if len(body) == 0 {
+		return common.Hash{}, fmt.Errorf("block body missing, number %d", number)
+	}
+	receipts, _ := dbm.getDatabase(ReceiptsDB).Get(blockReceiptsKey(number, hash))
+	if len(receipts) == 0 {
+		return common.Hash{}, fmt.Errorf("block receipts missing, number %d", number)
+	}
+
+	// Make sure the header matches the canonical hash before it becomes immutable.
+	decoded := new(types.Header)
+	if err := rlp.DecodeBytes(header, decoded); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid block header RLP, number %d: %v", number, err)
+	}
+	if decoded.Number.Uint64() != number || decoded.Hash() != hash {
+		return common.Hash{}, fmt.Errorf("block header mismatch, number %d, hash %x", number, hash)
+	}
+	if err := dbm.ancient.Load().AppendAncient(number, hash, header, body, receipts); err != nil {
+		return common.Hash{}, err
+	}
+	return hash, nil
+}
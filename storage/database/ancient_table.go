@@ -0,0 +1,341 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ancientIndexEntrySize is the size of an index entry of an ancient table.
+// Each entry holds the end offset of the item in the data file (8 bytes) and
+// the CRC32 checksum of the item (4 bytes).
+const ancientIndexEntrySize = 12
+
+var (
+	errAncientOutOfBounds = errors.New("ancient item out of bounds")
+	errAncientCorrupted   = errors.New("ancient item corrupted")
+	errAncientClosed      = errors.New("ancient table already closed")
+)
+
+// ancientTable is an append-only flat file storing the blobs of a single kind
+// indexed by their item number. Items are only appended at the end and removed
+// from the end, so a table never needs compaction.
+type ancientTable struct {
+	name  string
+	data  *os.File // data file holding the concatenated items
+	index *os.File // index file holding ancientIndexEntrySize bytes per item
+
+	items uint64 // number of items stored in the table
+	size  uint64 // size of the data file in bytes
+
+	lock sync.RWMutex
+}
+
+// openAncientTable opens the table with the given name under dir, creating the
+// files if they don't exist. A partially written tail left by an unclean
+// shutdown is cut off so that the index and data files are consistent.
+func openAncientTable(dir, name string) (*ancientTable, error) {
+	t, err := openAncientTableFiles(dir, name, os.O_RDWR|os.O_CREATE)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.repair(); err != nil {
+		t.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// openAncientTableReadOnly opens the table with the given name under dir
+// without modifying it, to follow the appends of a primary instance.
+func openAncientTableReadOnly(dir, name string) (*ancientTable, error) {
+	t, err := openAncientTableFiles(dir, name, os.O_RDONLY)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Refresh(); err != nil {
+		t.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+func openAncientTableFiles(dir, name string, flag int) (*ancientTable, error) {
+	data, err := os.OpenFile(filepath.Join(dir, name+".dat"), flag, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	index, err := os.OpenFile(filepath.Join(dir, name+".idx"), flag, 0o644)
+	if err != nil {
+		data.Close()
+		return nil, err
+	}
+	return &ancientTable{name: name, data: data, index: index}, nil
+}
+
+// scan returns the number of items completely written in both the index and
+// data files, and the end offset of the last one, along with the file sizes.
+func (t *ancientTable) scan() (items, end uint64, indexSize, dataSize int64, err error) {
+	indexStat, err := t.index.Stat()
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	dataStat, err := t.data.Stat()
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	items = uint64(indexStat.Size()) / ancientIndexEntrySize
+
+	// Drop the index entries pointing beyond the end of the data file.
+	for ; items > 0; items-- {
+		if end, _, err = t.readIndex(items - 1); err != nil {
+			return 0, 0, 0, 0, err
+		}
+		if end <= uint64(dataStat.Size()) {
+			break
+		}
+	}
+	if items == 0 {
+		end = 0
+	}
+	return items, end, indexStat.Size(), dataStat.Size(), nil
+}
+
+// repair truncates the index and data files to the last item which is
+// completely written in both of them.
+func (t *ancientTable) repair() error {
+	items, end, indexSize, dataSize, err := t.scan()
+	if err != nil {
+		return err
+	}
+	if uint64(indexSize) != items*ancientIndexEntrySize || uint64(dataSize) != end {
+		logger.Warn("Repairing ancient table", "table", t.name, "items", items,
+			"indexSize", indexSize, "dataSize", dataSize, "truncatedDataSize", end)
+		if err := t.index.Truncate(int64(items * ancientIndexEntrySize)); err != nil {
+			return err
+		}
+		if err := t.data.Truncate(int64(end)); err != nil {
+			return err
+		}
+	}
+	t.items, t.size = items, end
+	return nil
+}
+
+// readIndex returns the end offset and the checksum of the given item.
+func (t *ancientTable) readIndex(item uint64) (uint64, uint32, error) {
+	var entry [ancientIndexEntrySize]byte
+	if _, err := t.index.ReadAt(entry[:], int64(item*ancientIndexEntrySize)); err != nil {
+		return 0, 0, err
+	}
+	return binary.BigEndian.Uint64(entry[:8]), binary.BigEndian.Uint32(entry[8:]), nil
+}
+
+// Refresh reloads the number of items of a table written by another process.
+// A partially appended tail is ignored.
+func (t *ancientTable) Refresh() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errAncientClosed
+	}
+	items, end, _, _, err := t.scan()
+	if err != nil {
+		return err
+	}
+	t.items, t.size = items, end
+	return nil
+}
+
+// Items returns the number of items stored in the table.
+func (t *ancientTable) Items() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.items
+}
+
+// Size returns the total size of the data and index files in bytes.
+func (t *ancientTable) Size() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.size + t.items*ancientIndexEntrySize
+}
+
+// Append stores the blob as the given item, which must be the next item of
+// the table.
+func (t *ancientTable) Append(item uint64, blob []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errAncientClosed
+	}
+	if item != t.items {
+		return fmt.Errorf("ancient table %s: appending item %d out of order, next is %d", t.name, item, t.items)
+	}
+	if _, err := t.data.WriteAt(blob, int64(t.size)); err != nil {
+		return err
+	}
+	var entry [ancientIndexEntrySize]byte
+	binary.BigEndian.PutUint64(entry[:8], t.size+uint64(len(blob)))
+	binary.BigEndian.PutUint32(entry[8:], crc32.ChecksumIEEE(blob))
+	if _, err := t.index.WriteAt(entry[:], int64(t.items*ancientIndexEntrySize)); err != nil {
+		return err
+	}
+	t.items++
+	t.size += uint64(len(blob))
+	return nil
+}
+
+// Retrieve returns the blob of the given item after verifying its checksum.
+func (t *ancientTable) Retrieve(item uint64) ([]byte, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil {
+		return nil, errAncientClosed
+	}
+	if item >= t.items {
+		return nil, errAncientOutOfBounds
+	}
+	var start uint64
+	if item > 0 {
+		var err error
+		if start, _, err = t.readIndex(item - 1); err != nil {
+			return nil, err
+		}
+	}
+	end, checksum, err := t.readIndex(item)
+	if err != nil {
+		return nil, err
+	}
+	if end < start || end > t.size {
+		return nil, fmt.Errorf("%w: table %s, item %d has invalid offsets [%d, %d)", errAncientCorrupted, t.name, item, start, end)
+	}
+	blob := make([]byte, end-start)
+	if _, err := t.data.ReadAt(blob, int64(start)); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(blob) != checksum {
+		return nil, fmt.Errorf("%w: table %s, item %d has checksum mismatch", errAncientCorrupted, t.name, item)
+	}
+	return blob, nil
+}
+
+// Truncate discards the items from the given number onwards.
+func (t *ancientTable) Truncate(items uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errAncientClosed
+	}
+	if items >= t.items {
+		return nil
+	}
+	var end uint64
+	if items > 0 {
+		var err error
+		if end, _, err = t.readIndex(items - 1); err != nil {
+			return err
+		}
+	}
+	if err := t.index.Truncate(int64(items * ancientIndexEntrySize)); err != nil {
+		return err
+	}
+	if err := t.data.Truncate(int64(end)); err != nil {
+		return err
+	}
+	t.items, t.size = items, end
+	return nil
+}
+
+// Sync flushes the data file before the index file, so the index never points
+// to data which is not on the disk yet.
+func (t *ancientTable) Sync() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errAncientClosed
+	}
+	if err := t.data.Sync(); err != nil {
+		return err
+	}
+	return t.index.Sync()
+}
+
+// copyTo copies the items stored in the table into new files in the given
+// directory. A partially appended tail is not copied.
+func (t *ancientTable) copyTo(dir string) error {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil {
+		return errAncientClosed
+	}
+	if err := copyFileN(t.data, filepath.Join(dir, t.name+".dat"), int64(t.size)); err != nil {
+		return err
+	}
+	return copyFileN(t.index, filepath.Join(dir, t.name+".idx"), int64(t.items*ancientIndexEntrySize))
+}
+
+// copyFileN copies the first n bytes of src into a new file at path.
+func copyFileN(src *os.File, path string, n int64) error {
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, io.NewSectionReader(src, 0, n)); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Sync(); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// Close closes the files of the table.
+func (t *ancientTable) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return nil
+	}
+	var errs []error
+	if err := t.data.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := t.index.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	t.data, t.index = nil, nil
+	return errors.Join(errs...)
+}
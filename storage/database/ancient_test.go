@@ -0,0 +1,158 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAncientTable(t *testing.T) {
+	dir := t.TempDir()
+
+	table, err := openAncientTable(dir, "test")
+	require.NoError(t, err)
+	for i := uint64(0); i < 10; i++ {
+		require.NoError(t, table.Append(i, bytes.Repeat([]byte{byte(i)}, int(i)+1)))
+	}
+	assert.Error(t, table.Append(11, []byte{0x01}))
+	assert.Equal(t, uint64(10), table.Items())
+
+	blob, err := table.Retrieve(3)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{3, 3, 3, 3}, blob)
+	_, err = table.Retrieve(10)
+	assert.ErrorIs(t, err, errAncientOutOfBounds)
+
+	// Truncate the last items and check they are gone.
+	require.NoError(t, table.Truncate(8))
+	assert.Equal(t, uint64(8), table.Items())
+	_, err = table.Retrieve(8)
+	assert.ErrorIs(t, err, errAncientOutOfBounds)
+	require.NoError(t, table.Close())
+
+	// Cut off the tail of the data file, as if it was partially written.
+	dataPath := filepath.Join(dir, "test.dat")
+	stat, err := os.Stat(dataPath)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(dataPath, stat.Size()-2))
+
+	table, err = openAncientTable(dir, "test")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), table.Items())
+	blob, err = table.Retrieve(6)
+	require.NoError(t, err)
+	assert.Equal(t, bytes.Repeat([]byte{6}, 7), blob)
+	require.NoError(t, table.Close())
+
+	// Flip a byte of an item, it should fail the checksum.
+	data, err := os.ReadFile(dataPath)
+	require.NoError(t, err)
+	data[0] ^= 0xff
+	require.NoError(t, os.WriteFile(dataPath, data, 0o644))
+
+	table, err = openAncientTable(dir, "test")
+	require.NoError(t, err)
+	defer table.Close()
+	_, err = table.Retrieve(0)
+	assert.True(t, errors.Is(err, errAncientCorrupted))
+	_, err = table.Retrieve(1)
+	assert.NoError(t, err)
+}
+
+// TestDBManager_Ancient checks the blocks older than the threshold are moved
+// into the ancient store and still readable after restarting.
+func TestDBManager_Ancient(t *testing.T) {
+	const (
+		blocks    = 10
+		threshold = 3
+	)
+	for _, single := range []bool{false, true} {
+		dbc := &DBConfig{Dir: t.TempDir(), DBType: LevelDB, SingleDB: single, NumStateTrieShards: 1}
+		dbm := NewDBManager(dbc)
+
+		var (
+			hashes   []common.Hash
+			receipts = types.Receipts{{Status: types.ReceiptStatusSuccessful, GasUsed: 21000, Logs: []*types.Log{}}}
+		)
+		parent := common.Hash{}
+		for i := int64(0); i < blocks; i++ {
+			block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(i), ParentHash: parent})
+			dbm.WriteBlock(block)
+			dbm.WriteCanonicalHash(block.Hash(), uint64(i))
+			dbm.WriteReceipts(block.Hash(), uint64(i), receipts)
+			hashes = append(hashes, block.Hash())
+			parent = block.Hash()
+		}
+		dbm.WriteHeadBlockHash(parent)
+
+		// Freeze the blocks manually instead of running the background loop.
+		manager := dbm.(*databaseManager)
+		manager.config.AncientThreshold = threshold
+		store, err := newAncientStore(manager.ancientDir())
+		require.NoError(t, err)
+		manager.ancient.Store(store)
+		manager.ancientQuit = make(chan struct{})
+
+		frozen, err := manager.freezeBatch()
+		require.NoError(t, err)
+		assert.Equal(t, blocks-threshold, frozen)
+		assert.Equal(t, uint64(blocks-threshold), dbm.Ancients())
+
+		// Frozen blocks except the genesis are removed from the key-value databases.
+		has, _ := manager.getDatabase(BodyDB).Has(blockBodyKey(0, hashes[0]))
+		assert.True(t, has)
+		for i := 1; i < blocks; i++ {
+			has, _ := manager.getDatabase(BodyDB).Has(blockBodyKey(uint64(i), hashes[i]))
+			assert.Equal(t, i >= blocks-threshold, has, i)
+		}
+		dbm.Close()
+
+		// Reopen without the threshold, frozen blocks should still be readable.
+		dbc.AncientThreshold = 0
+		dbm = NewDBManager(dbc)
+		assert.Equal(t, uint64(blocks-threshold), dbm.Ancients())
+		for i := 0; i < blocks; i++ {
+			number := uint64(i)
+			assert.True(t, dbm.HasHeader(hashes[i], number))
+			assert.True(t, dbm.HasBody(hashes[i], number))
+			if block := dbm.ReadBlockByNumber(number); assert.NotNil(t, block) {
+				assert.Equal(t, hashes[i], block.Hash())
+			}
+			if r := dbm.ReadReceipts(hashes[i], number); assert.Len(t, r, 1) {
+				assert.Equal(t, receipts[0].GasUsed, r[0].GasUsed)
+			}
+		}
+		// A different hash of a frozen block number is not found.
+		assert.Nil(t, dbm.ReadHeader(common.HexToHash("0x1234"), 1))
+
+		// Rewinding the canonical chain below the frozen blocks truncates them.
+		dbm.DeleteCanonicalHash(4)
+		assert.Equal(t, uint64(4), dbm.Ancients())
+		assert.False(t, dbm.HasBody(hashes[5], 5))
+		dbm.Close()
+	}
+}
@@ -20,6 +20,7 @@ package database
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"time"
 
@@ -31,6 +32,8 @@ import (
 const (
 	gcThreshold      = int64(1 << 30) // GB
 	sizeGCTickerTime = 1 * time.Minute
+
+	badgerCheckpointPendingWrites = 256 // max pending writes while loading a checkpoint
 )
 
 type badgerDB struct {
@@ -182,6 +185,27 @@ func (bg *badgerDB) NewIterator(prefix []byte, start []byte) Iterator {
 	return nil
 }
 
+// Checkpoint streams a consistent backup of the database into a new BadgerDB
+// in the given directory.
+func (bg *badgerDB) Checkpoint(dir string) error {
+	dst, err := badger.Open(getBadgerDBOptions(dir))
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	r, w := io.Pipe()
+	go func() {
+		_, err := bg.db.Backup(w, 0)
+		w.CloseWithError(err)
+	}()
+	if err := dst.Load(r, badgerCheckpointPendingWrites); err != nil {
+		r.CloseWithError(err)
+		return err
+	}
+	return nil
+}
+
 func (bg *badgerDB) Close() {
 	close(bg.closeCh)
 	err := bg.db.Close()
@@ -0,0 +1,140 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+var errCheckpointInMigration = errors.New("checkpoints are not supported during state migration")
+
+// checkpointer is implemented by the databases which can copy themselves into
+// another directory while they are being used.
+type checkpointer interface {
+	Checkpoint(dir string) error
+}
+
+// CheckpointInfo describes a checkpoint created by CreateCheckpoint.
+type CheckpointInfo struct {
+	Dir        string      `json:"dir"`
+	HeadNumber uint64      `json:"headNumber"`
+	HeadHash   common.Hash `json:"headHash"`
+}
+
+func checkpointDatabase(db Database, dir string) error {
+	cp, ok := db.(checkpointer)
+	if !ok {
+		return fmt.Errorf("%v does not support checkpoints", db.Type())
+	}
+	return cp.Checkpoint(dir)
+}
+
+// CreateCheckpoint copies all databases and the ancient store into the given
+// directory, which must not exist, with the same layout as the data directory.
+// The head block is read before the copy starts and written back as the head
+// of the copy, so a node started from it begins at a block which is complete
+// in every database, even though the databases are copied one after another.
+func (dbm *databaseManager) CreateCheckpoint(dir string) (*CheckpointInfo, error) {
+	switch dbm.config.DBType {
+	case MemoryDB, DynamoDB:
+		return nil, fmt.Errorf("%v does not support checkpoints", dbm.config.DBType)
+	}
+	if dbm.InMigration() {
+		return nil, errCheckpointInMigration
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("checkpoint directory %s already exists", dir)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	headHash := dbm.ReadHeadBlockHash()
+	headNumber := dbm.ReadHeaderNumber(headHash)
+	if headNumber == nil {
+		return nil, errors.New("head block not found")
+	}
+	logger.Info("Creating database checkpoint", "dir", dir, "number", *headNumber, "hash", headHash)
+
+	if err := dbm.checkpoint(dir, headHash); err != nil {
+		if rerr := os.RemoveAll(dir); rerr != nil {
+			logger.Error("Failed to remove incomplete checkpoint", "dir", dir, "err", rerr)
+		}
+		return nil, err
+	}
+	logger.Info("Created database checkpoint", "dir", dir, "number", *headNumber, "hash", headHash)
+	return &CheckpointInfo{Dir: dir, HeadNumber: *headNumber, HeadHash: headHash}, nil
+}
+
+func (dbm *databaseManager) checkpoint(dir string, headHash common.Hash) error {
+	if dbm.config.SingleDB {
+		if err := checkpointDatabase(dbm.dbs[0], dir); err != nil {
+			return err
+		}
+	} else {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		for et, db := range dbm.dbs {
+			if db == nil {
+				continue
+			}
+			entryType := DBEntryType(et)
+			if err := checkpointDatabase(db, filepath.Join(dir, dbm.getDBDir(entryType))); err != nil {
+				return fmt.Errorf("failed to checkpoint %v: %w", entryType, err)
+			}
+		}
+	}
+	// The ancient store is copied last. Blocks are deleted from the key-value
+	// databases only after they are frozen, so every block missing from the
+	// copied databases is in the copied ancient store.
+	if ancient := dbm.ancient.Load(); ancient != nil {
+		if err := ancient.Checkpoint(filepath.Join(dir, ancientDir)); err != nil {
+			return fmt.Errorf("failed to checkpoint ancient store: %w", err)
+		}
+	}
+	return dbm.writeCheckpointHead(dir, headHash)
+}
+
+// writeCheckpointHead rewinds the head markers of the checkpoint in the given
+// directory to the given block.
+func (dbm *databaseManager) writeCheckpointHead(dir string, headHash common.Hash) error {
+	dbc := *dbm.config
+	dbc.Dir = dir
+	headerDBC := &dbc
+	if !dbc.SingleDB {
+		headerDBC = getDBEntryConfig(&dbc, headerDB, dbm.getDBDir(headerDB))
+	}
+	db, err := newDatabase(headerDBC, headerDB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	batch := db.NewBatch()
+	defer batch.Release()
+	for _, key := range [][]byte{headHeaderKey, headBlockKey, headBlockBackupKey, headFastBlockKey, headFastBlockBackupKey} {
+		if err := batch.Put(key, headHash.Bytes()); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
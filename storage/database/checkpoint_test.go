@@ -0,0 +1,129 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDBManager_CreateCheckpoint checks a checkpoint holds the blocks, the
+// state and the frozen blocks of the databases, and starts at the head block
+// recorded when the checkpoint was created.
+func TestDBManager_CreateCheckpoint(t *testing.T) {
+	const (
+		blocks    = 10
+		threshold = 3
+		head      = 7
+	)
+	testCases := []struct {
+		name   string
+		dbType DBType
+		single bool
+		shards uint
+	}{
+		{"LevelDB", LevelDB, false, 1},
+		{"ShardedLevelDB", LevelDB, false, 4},
+		{"SingleLevelDB", LevelDB, true, 1},
+		{"PebbleDB", PebbleDB, false, 1},
+		{"SinglePebbleDB", PebbleDB, true, 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dbc := &DBConfig{Dir: t.TempDir(), DBType: tc.dbType, SingleDB: tc.single, NumStateTrieShards: tc.shards}
+			dbm := NewDBManager(dbc)
+			defer dbm.Close()
+
+			var (
+				hashes   []common.Hash
+				receipts = types.Receipts{{Status: types.ReceiptStatusSuccessful, GasUsed: 21000, Logs: []*types.Log{}}}
+			)
+			parent := common.Hash{}
+			for i := int64(0); i < blocks; i++ {
+				block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(i), ParentHash: parent})
+				dbm.WriteBlock(block)
+				dbm.WriteCanonicalHash(block.Hash(), uint64(i))
+				dbm.WriteReceipts(block.Hash(), uint64(i), receipts)
+				hashes = append(hashes, block.Hash())
+				parent = block.Hash()
+			}
+			dbm.WriteHeadBlockHash(hashes[blocks-1])
+
+			// Freeze the old blocks manually instead of running the background loop.
+			manager := dbm.(*databaseManager)
+			manager.config.AncientThreshold = threshold
+			store, err := newAncientStore(manager.ancientDir())
+			require.NoError(t, err)
+			manager.ancient.Store(store)
+			manager.ancientQuit = make(chan struct{})
+			_, err = manager.freezeBatch()
+			require.NoError(t, err)
+
+			// The headers are ahead of the blocks, the checkpoint must start at the block.
+			dbm.WriteHeadBlockHash(hashes[head])
+			dbm.WriteHeadHeaderHash(hashes[blocks-1])
+
+			node := []byte("trie node")
+			nodeHash := common.BytesToHash([]byte("node")).ExtendZero()
+			dbm.WriteTrieNode(nodeHash, node)
+
+			dir := filepath.Join(t.TempDir(), "checkpoint")
+			info, err := dbm.CreateCheckpoint(dir)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(head), info.HeadNumber)
+			assert.Equal(t, hashes[head], info.HeadHash)
+
+			// A checkpoint can't overwrite an existing directory.
+			_, err = dbm.CreateCheckpoint(dir)
+			assert.Error(t, err)
+
+			cp := NewDBManager(&DBConfig{Dir: dir, DBType: tc.dbType, SingleDB: tc.single, NumStateTrieShards: tc.shards})
+			defer cp.Close()
+
+			assert.Equal(t, hashes[head], cp.ReadHeadBlockHash())
+			assert.Equal(t, hashes[head], cp.ReadHeadFastBlockHash())
+			assert.Equal(t, hashes[head], cp.ReadHeadHeaderHash())
+			assert.Equal(t, uint64(blocks-threshold), cp.Ancients())
+			for i := 0; i < blocks; i++ {
+				if block := cp.ReadBlockByNumber(uint64(i)); assert.NotNil(t, block, i) {
+					assert.Equal(t, hashes[i], block.Hash())
+				}
+			}
+			data, err := cp.ReadTrieNode(nodeHash)
+			require.NoError(t, err)
+			assert.Equal(t, node, data)
+		})
+	}
+}
+
+func TestDBManager_CreateCheckpointUnsupported(t *testing.T) {
+	dbm := NewMemoryDBManager()
+	defer dbm.Close()
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0)})
+	dbm.WriteBlock(block)
+	dbm.WriteHeadBlockHash(block.Hash())
+
+	_, err := dbm.CreateCheckpoint(filepath.Join(t.TempDir(), "checkpoint"))
+	assert.Error(t, err)
+}
@@ -71,6 +71,22 @@ func newTestBadgerDB() (Database, func(), string) {
 	}, "badger"
 }
 
+func newTestPebbleDB() (Database, func(), string) {
+	dirName, err := os.MkdirTemp(os.TempDir(), "kaia-test-pebbledb-")
+	if err != nil {
+		panic("failed to create test file: " + err.Error())
+	}
+	db, err := NewPebbleDB(&DBConfig{Dir: dirName})
+	if err != nil {
+		panic("failed to create test database: " + err.Error())
+	}
+
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dirName)
+	}, "pebble"
+}
+
 func newTestMemDB() (Database, func(), string) {
 	db := NewMemDB()
 	return db, func() {
@@ -119,7 +135,7 @@ func TestDatabaseTestSuite(t *testing.T) {
 	// var testDatabases = []func() (Database, func()){newTestLDB, newTestBadgerDB, newTestMemDB, newTestDynamoS3DB}
 
 	// TODO-Kaia-Database Need to add DynamoDB to the below list.
-	testDatabases = append(testDatabases, newTestLDB, newTestBadgerDB, newTestMemDB)
+	testDatabases = append(testDatabases, newTestLDB, newTestBadgerDB, newTestPebbleDB, newTestMemDB)
 	for _, newFn := range testDatabases {
 		suite.Run(t, &commonDatabaseTestSuite{newFn: newFn})
 	}
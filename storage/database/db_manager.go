@@ -30,6 +30,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/dgraph-io/badger"
 	"github.com/klaytn/klaytn/blockchain/types"
@@ -183,6 +184,14 @@ type DBManager interface {
 	WriteLastPrunedBlockNumber(blockNumber uint64)
 	ReadLastPrunedBlockNumber() (uint64, error)
 
+	// History pruning
+	ReadHistoryTail() uint64
+	WriteHistoryTail(number uint64)
+	PruneHistory(number uint64) uint64
+
+	// Checkpoint
+	CreateCheckpoint(dir string) (*CheckpointInfo, error)
+
 	// from accessors_indexes.go
 	ReadTxLookupEntry(hash common.Hash) (common.Hash, uint64, uint64)
 	WriteTxLookupEntries(block *types.Block)
@@ -315,6 +324,9 @@ type DBManager interface {
 
 	TryCatchUpWithPrimary() error
 
+	// Ancient store related functions
+	Ancients() uint64
+
 	Stat(string) (string, error)
 	Compact([]byte, []byte) error
 }
@@ -438,6 +450,10 @@ type databaseManager struct {
 	lockInMigration      sync.RWMutex
 	inMigration          bool
 	migrationBlockNumber uint64
+
+	ancient     atomic.Pointer[ancientStore] // append-only store of finalized blocks, nil if disabled
+	ancientQuit chan struct{}                // stops the background loop freezing blocks into ancient
+	ancientWg   sync.WaitGroup               // waits for the background loop freezing blocks into ancient
 }
 
 func NewMemoryDBManager() DBManager {
@@ -464,7 +480,12 @@ type DBConfig struct {
 	OpenFilesLimit      int
 	EnableDBPerfMetrics bool // If true, read and write performance will be logged
 
-	// LevelDB related configurations.
+	// ReadOnly opens the databases as a secondary of a primary instance
+	// writing them in another process. Writes are ignored and the changes of
+	// the primary are loaded by TryCatchUpWithPrimary.
+	ReadOnly bool
+
+	// LevelDB related configurations. LevelDBCacheSize is also used by PebbleDB.
 	LevelDBCacheSize   int // LevelDBCacheSize = BlockCacheCapacity + WriteBuffer
 	LevelDBCompression LevelDBCompressionType
 	LevelDBBufferPool  bool
@@ -474,6 +495,10 @@ type DBConfig struct {
 
 	// DynamoDB related configurations
 	DynamoDBConfig *DynamoDBConfig
+
+	// AncientThreshold is the number of recent blocks kept in the key-value
+	// databases. Older blocks are moved into the ancient store. 0 disables it.
+	AncientThreshold uint64
 }
 
 const dbMetricPrefix = "klay/db/chaindata/"
@@ -491,6 +516,10 @@ func singleDatabaseDBManager(dbc *DBConfig) (DBManager, error) {
 	for i := 0; i < int(databaseEntryTypeSize); i++ {
 		dbm.dbs[i] = db
 	}
+	if err := dbm.openAncientStore(); err != nil {
+		db.Close()
+		return nil, err
+	}
 	return dbm, nil
 }
 
@@ -548,11 +577,22 @@ func databaseDBManager(dbc *DBConfig) (*databaseManager, error) {
 		dbm.dbs[et] = db
 		db.Meter(dbMetricPrefix + dbBaseDirs[et] + "/") // Each database collects metrics independently.
 	}
+	if err := dbm.openAncientStore(); err != nil {
+		for _, db := range dbm.dbs {
+			if db != nil {
+				db.Close()
+			}
+		}
+		return nil, err
+	}
 	return dbm, nil
 }
 
 // newDatabase returns Database interface with given DBConfig.
 func newDatabase(dbc *DBConfig, entryType DBEntryType) (Database, error) {
+	if dbc.ReadOnly {
+		return newReadOnlyDatabase(dbc)
+	}
 	switch dbc.DBType {
 	case LevelDB:
 		return NewLevelDB(dbc, entryType)
@@ -560,6 +600,8 @@ func newDatabase(dbc *DBConfig, entryType DBEntryType) (Database, error) {
 		return NewRocksDB(dbc.Dir, dbc.RocksDBConfig)
 	case BadgerDB:
 		return NewBadgerDB(dbc.Dir)
+	case PebbleDB:
+		return NewPebbleDB(dbc)
 	case MemoryDB:
 		return NewMemDB(), nil
 	case DynamoDB:
@@ -570,6 +612,24 @@ func newDatabase(dbc *DBConfig, entryType DBEntryType) (Database, error) {
 	}
 }
 
+// newReadOnlyDatabase opens the database of a primary instance running in
+// another process, which can be followed by TryCatchUpWithPrimary.
+func newReadOnlyDatabase(dbc *DBConfig) (Database, error) {
+	switch dbc.DBType {
+	case LevelDB:
+		return NewLevelDBReadOnly(dbc)
+	case RocksDB:
+		rocksDBConfig := *dbc.RocksDBConfig
+		rocksDBConfig.Secondary = true
+		return NewRocksDB(dbc.Dir, &rocksDBConfig)
+	case DynamoDB:
+		dynamoDBConfig := *dbc.DynamoDBConfig
+		return newDynamoDBReadOnly(&dynamoDBConfig)
+	default:
+		return nil, fmt.Errorf("read-only mode is not supported by %v", dbc.DBType)
+	}
+}
+
 // newDatabaseManager returns the pointer of databaseManager with default configuration.
 func newDatabaseManager(dbc *DBConfig) *databaseManager {
 	return &databaseManager{
@@ -863,7 +923,7 @@ func removeDB(dbPath string, endCheck chan struct{}) {
 }
 
 func (dbm *databaseManager) GetStateTrieDB() Database {
-	return dbm.dbs[StateTrieDB]
+	return dbm.getDatabase(StateTrieDB)
 }
 
 func (dbm *databaseManager) GetStateTrieMigrationDB() Database {
@@ -886,6 +946,17 @@ func (dbm *databaseManager) TryCatchUpWithPrimary() error {
 			}
 		}
 	}
+	// The primary deletes frozen blocks from the key-value databases after
+	// appending them to the ancient store, so the ancient store is refreshed
+	// last not to miss the blocks deleted in between. The ancient store is
+	// opened here if the primary has created it after this instance started.
+	ancient := dbm.ancient.Load()
+	if ancient == nil && dbm.config.ReadOnly {
+		return dbm.openAncientStoreReadOnly()
+	}
+	if ancient != nil && ancient.readOnly {
+		return ancient.Refresh()
+	}
 	return nil
 }
 
@@ -954,6 +1025,8 @@ func (dbm *databaseManager) getDatabase(dbEntryType DBEntryType) Database {
 }
 
 func (dbm *databaseManager) Close() {
+	dbm.closeAncientStore()
+
 	// If single DB, only close the first database.
 	if dbm.config.SingleDB {
 		dbm.dbs[0].Close()
@@ -1003,6 +1076,7 @@ func (dbm *databaseManager) DeleteCanonicalHash(number uint64) {
 		logger.Crit("Failed to delete number to hash mapping", "err", err)
 	}
 	dbm.cm.writeCanonicalHashCache(number, common.Hash{})
+	dbm.truncateAncients(number)
 }
 
 // Head Header Hash operations.
@@ -1132,7 +1206,7 @@ func (dbm *databaseManager) HasHeader(hash common.Hash, number uint64) bool {
 
 	db := dbm.getDatabase(headerDB)
 	if has, err := db.Has(headerKey(number, hash)); !has || err != nil {
-		return false
+		return dbm.hasAncient(hash, number)
 	}
 	return true
 }
@@ -1162,6 +1236,9 @@ func (dbm *databaseManager) ReadHeader(hash common.Hash, number uint64) *types.H
 func (dbm *databaseManager) ReadHeaderRLP(hash common.Hash, number uint64) rlp.RawValue {
 	db := dbm.getDatabase(headerDB)
 	data, _ := db.Get(headerKey(number, hash))
+	if len(data) == 0 {
+		return dbm.readAncient(ancientHeaderTable, hash, number)
+	}
 	return data
 }
 
@@ -1233,7 +1310,7 @@ func (dbm *databaseManager) ReadHeaderNumber(hash common.Hash) *uint64 {
 func (dbm *databaseManager) HasBody(hash common.Hash, number uint64) bool {
 	db := dbm.getDatabase(BodyDB)
 	if has, err := db.Has(blockBodyKey(number, hash)); !has || err != nil {
-		return false
+		return dbm.hasAncient(hash, number)
 	}
 	return true
 }
@@ -1283,6 +1360,9 @@ func (dbm *databaseManager) ReadBodyRLP(hash common.Hash, number uint64) rlp.Raw
 	// not found in cache, find body in database
 	db := dbm.getDatabase(BodyDB)
 	data, _ := db.Get(blockBodyKey(number, hash))
+	if len(data) == 0 {
+		data = dbm.readAncient(ancientBodyTable, hash, number)
+	}
 
 	// Write to cache at the end of successful read.
 	dbm.cm.writeBodyRLPCache(hash, data)
@@ -1312,6 +1392,9 @@ func (dbm *databaseManager) ReadBodyRLPByHash(hash common.Hash) rlp.RawValue {
 
 	db := dbm.getDatabase(BodyDB)
 	data, _ := db.Get(blockBodyKey(*number, hash))
+	if len(data) == 0 {
+		data = dbm.readAncient(ancientBodyTable, hash, *number)
+	}
 
 	// Write to cache at the end of successful read.
 	dbm.cm.writeBodyRLPCache(hash, data)
@@ -1431,6 +1514,9 @@ func (dbm *databaseManager) ReadReceipts(blockHash common.Hash, number uint64) t
 	db := dbm.getDatabase(ReceiptsDB)
 	// Retrieve the flattened receipt slice
 	data, _ := db.Get(blockReceiptsKey(number, blockHash))
+	if len(data) == 0 {
+		data = dbm.readAncient(ancientReceiptTable, blockHash, number)
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -41,7 +41,7 @@ import (
 
 var (
 	dbManagers  []DBManager
-	dbConfigs   = make([]*DBConfig, 0, len(baseConfigs)*3)
+	dbConfigs   = make([]*DBConfig, 0, len(baseConfigs)*4)
 	baseConfigs = []*DBConfig{
 		{DBType: LevelDB, SingleDB: false, NumStateTrieShards: 1, ParallelDBWrite: false},
 		{DBType: LevelDB, SingleDB: false, NumStateTrieShards: 1, ParallelDBWrite: true},
@@ -87,6 +87,8 @@ func init() {
 	for _, bc := range baseConfigs {
 		badgerConfig := *bc
 		badgerConfig.DBType = BadgerDB
+		pebbleConfig := *bc
+		pebbleConfig.DBType = PebbleDB
 		memoryConfig := *bc
 		memoryConfig.DBType = MemoryDB
 		rockdbConfig := *bc
@@ -94,6 +96,7 @@ func init() {
 
 		dbConfigs = append(dbConfigs, bc)
 		dbConfigs = append(dbConfigs, &badgerConfig)
+		dbConfigs = append(dbConfigs, &pebbleConfig)
 		dbConfigs = append(dbConfigs, &memoryConfig)
 		if addRocksDB {
 			dbConfigs = append(dbConfigs, &rockdbConfig)
@@ -624,6 +627,55 @@ func TestDBManager_TxLookupEntry(t *testing.T) {
 	}
 }
 
+// TestDBManager_PruneHistory tests that PruneHistory deletes bodies, receipts and
+// tx lookup entries below the given number and keeps the rest.
+func TestDBManager_PruneHistory(t *testing.T) {
+	log.EnableLogForTest(log.LvlCrit, log.LvlTrace)
+	dbm := NewMemoryDBManager()
+	defer dbm.Close()
+
+	blocks := make([]*types.Block, 5)
+	for i := range blocks {
+		tx, err := genTransaction(uint64(i))
+		assert.NoError(t, err, "Failed to generate a transaction")
+
+		header := &types.Header{Number: big.NewInt(int64(i))}
+		block := types.NewBlockWithHeader(header).WithBody(types.Transactions{tx})
+		receipts := types.Receipts{{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash(), GasUsed: 21000}}
+
+		dbm.WriteCanonicalHash(block.Hash(), block.NumberU64())
+		dbm.WriteBlock(block)
+		dbm.WriteReceipts(block.Hash(), block.NumberU64(), receipts)
+		dbm.WriteTxLookupEntries(block)
+		blocks[i] = block
+	}
+	assert.Equal(t, uint64(0), dbm.ReadHistoryTail())
+
+	assert.Equal(t, uint64(2), dbm.PruneHistory(3))
+	assert.Equal(t, uint64(3), dbm.ReadHistoryTail())
+	// Pruning below the tail is a no-op.
+	assert.Equal(t, uint64(0), dbm.PruneHistory(2))
+	assert.Equal(t, uint64(3), dbm.ReadHistoryTail())
+
+	for i, block := range blocks {
+		hash, number := block.Hash(), block.NumberU64()
+		txHash := block.Transactions()[0].Hash()
+		lookupHash, _, _ := dbm.ReadTxLookupEntry(txHash)
+
+		// Headers are always kept and the genesis block is never pruned.
+		assert.NotNil(t, dbm.ReadHeader(hash, number))
+		if i == 1 || i == 2 {
+			assert.Nil(t, dbm.ReadBody(hash, number))
+			assert.Nil(t, dbm.ReadReceipts(hash, number))
+			assert.Equal(t, common.Hash{}, lookupHash)
+		} else {
+			assert.NotNil(t, dbm.ReadBody(hash, number))
+			assert.NotNil(t, dbm.ReadReceipts(hash, number))
+			assert.Equal(t, hash, lookupHash)
+		}
+	}
+}
+
 // TestDBManager_BloomBits tests read, write and delete operations of bloom bits
 func TestDBManager_BloomBits(t *testing.T) {
 	log.EnableLogForTest(log.LvlCrit, log.LvlTrace)
@@ -0,0 +1,100 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"encoding/binary"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+// ReadHistoryTail returns the number of the oldest block whose body, receipts
+// and tx lookup entries are retained. Zero means no history has been pruned.
+func (dbm *databaseManager) ReadHistoryTail() uint64 {
+	db := dbm.getDatabase(MiscDB)
+	data, _ := db.Get(historyTailKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// WriteHistoryTail stores the number of the oldest block whose body, receipts
+// and tx lookup entries are retained.
+func (dbm *databaseManager) WriteHistoryTail(number uint64) {
+	db := dbm.getDatabase(MiscDB)
+	if err := db.Put(historyTailKey, common.Int64ToByteBigEndian(number)); err != nil {
+		logger.Crit("Failed to store the history tail", "err", err)
+	}
+}
+
+// PruneHistory deletes the bodies, receipts and tx lookup entries of the
+// canonical blocks from the current history tail up to (but excluding) the
+// given number, and advances the history tail to it. The genesis block is
+// always retained. It returns the number of the pruned blocks.
+func (dbm *databaseManager) PruneHistory(number uint64) uint64 {
+	tail := dbm.ReadHistoryTail()
+	if tail == 0 {
+		tail = 1
+	}
+	if number <= tail {
+		return 0
+	}
+
+	bodyBatch := dbm.NewBatch(BodyDB)
+	defer bodyBatch.Release()
+	receiptsBatch := dbm.NewBatch(ReceiptsDB)
+	defer receiptsBatch.Release()
+	txLookupBatch := dbm.NewBatch(TxLookUpEntryDB)
+	defer txLookupBatch.Release()
+
+	for n := tail; n < number; n++ {
+		hash := dbm.ReadCanonicalHash(n)
+		if hash == (common.Hash{}) {
+			continue
+		}
+		if body := dbm.ReadBody(hash, n); body != nil {
+			for _, tx := range body.Transactions {
+				txHash := tx.Hash()
+				if err := txLookupBatch.Delete(TxLookupKey(txHash)); err != nil {
+					logger.Crit("Failed to delete tx lookup key", "err", err)
+				}
+				dbm.cm.deleteTxReceiptCache(txHash)
+			}
+		}
+		if err := bodyBatch.Delete(blockBodyKey(n, hash)); err != nil {
+			logger.Crit("Failed to delete block body", "err", err)
+		}
+		if err := receiptsBatch.Delete(blockReceiptsKey(n, hash)); err != nil {
+			logger.Crit("Failed to delete block receipts", "err", err)
+		}
+		dbm.cm.deleteBodyCache(hash)
+		dbm.cm.deleteBlockCache(hash)
+		dbm.cm.deleteBlockReceiptsCache(hash)
+
+		if _, err := WriteBatchesOverThreshold(bodyBatch, receiptsBatch, txLookupBatch); err != nil {
+			logger.Crit("Failed to prune history", "err", err)
+		}
+	}
+	if _, err := WriteBatches(bodyBatch, receiptsBatch, txLookupBatch); err != nil {
+		logger.Crit("Failed to prune history", "err", err)
+	}
+	// The tail is advanced after the deletion, so an interrupted pruning is
+	// resumed from the previous tail.
+	dbm.WriteHistoryTail(number)
+	return number - tail
+}
@@ -0,0 +1,219 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+// InspectStat is the number of keys and their total size of a category of
+// data in a database.
+type InspectStat struct {
+	Database string             `json:"database"`
+	Category string             `json:"category"`
+	Count    uint64             `json:"count"`
+	Size     common.StorageSize `json:"size"`
+}
+
+// inspectCategory classifies the keys of the databases by the schema prefix.
+type inspectCategory struct {
+	name  string
+	match func(key []byte) bool
+}
+
+// hasPrefixAndLength returns a matcher of the keys with the given prefix and length.
+func hasPrefixAndLength(prefix []byte, length int) func([]byte) bool {
+	return func(key []byte) bool {
+		return len(key) == length && bytes.HasPrefix(key, prefix)
+	}
+}
+
+// hasPrefix returns a matcher of the keys with the given prefix.
+func hasPrefix(prefix []byte) func([]byte) bool {
+	return func(key []byte) bool {
+		return bytes.HasPrefix(key, prefix)
+	}
+}
+
+// inspectCategories is the list of the categories of keys defined in the schema.
+// The first matching category is taken, so the longer prefixes come first.
+var inspectCategories = []inspectCategory{
+	{"Headers", hasPrefixAndLength(headerPrefix, len(headerPrefix)+8+common.HashLength)},
+	{"Total difficulties", hasPrefixAndLength(headerPrefix, len(headerPrefix)+8+common.HashLength+len(headerTDSuffix))},
+	{"Canonical hashes", hasPrefixAndLength(headerPrefix, len(headerPrefix)+8+len(headerHashSuffix))},
+	{"Header number mappings", hasPrefixAndLength(headerNumberPrefix, len(headerNumberPrefix)+common.HashLength)},
+	{"Bodies", hasPrefixAndLength(blockBodyPrefix, len(blockBodyPrefix)+8+common.HashLength)},
+	{"Receipts", hasPrefixAndLength(blockReceiptsPrefix, len(blockReceiptsPrefix)+8+common.HashLength)},
+	{"Transaction lookups", hasPrefixAndLength(txLookupPrefix, len(txLookupPrefix)+common.HashLength)},
+	{"Sender tx hash lookups", hasPrefixAndLength(senderTxHashToTxHashPrefix, len(senderTxHashToTxHashPrefix)+common.HashLength)},
+	{"Account snapshots", hasPrefixAndLength(SnapshotAccountPrefix, len(SnapshotAccountPrefix)+common.HashLength)},
+	{"Storage snapshots", hasPrefixAndLength(SnapshotStoragePrefix, len(SnapshotStoragePrefix)+2*common.HashLength)},
+	{"Contract codes", hasPrefixAndLength(codePrefix, len(codePrefix)+common.HashLength)},
+	{"Trie nodes", func(key []byte) bool { return len(key) == common.HashLength || len(key) == common.ExtHashLength }},
+	{"Trie preimages", hasPrefixAndLength(preimagePrefix, len(preimagePrefix)+common.HashLength)},
+	{"Pruning marks", hasPrefixAndLength(pruningMarkPrefix, pruningMarkKeyLen)},
+	{"Bloombits", hasPrefixAndLength(bloomBitsPrefix, len(bloomBitsPrefix)+2+8+common.HashLength)},
+	{"Bloombits index", hasPrefix(BloomBitsIndexPrefix)},
+	{"Consensus snapshots", hasPrefixAndLength(snapshotKeyPrefix, len(snapshotKeyPrefix)+common.HashLength)},
+	{"Chain configs", hasPrefixAndLength(configPrefix, len(configPrefix)+common.HashLength)},
+	{"Governance", hasPrefix(governancePrefix)},
+	{"Staking info", hasPrefix(stakingInfoPrefix)},
+	{"Accumulated rewards", hasPrefix(accRewardPrefix)},
+	{"Service chain", func(key []byte) bool {
+		for _, prefix := range [][]byte{childChainTxHashPrefix, receiptFromParentChainKeyPrefix, valueTransferTxHashPrefix, parentOperatorFeePayerPrefix, childOperatorFeePayerPrefix} {
+			if bytes.HasPrefix(key, prefix) {
+				return true
+			}
+		}
+		return false
+	}},
+	{"Metadata", func(key []byte) bool {
+		for _, meta := range [][]byte{
+			databaseVerisionKey, headHeaderKey, headBlockKey, headBlockBackupKey, headFastBlockKey, headFastBlockBackupKey,
+			fastTrieProgressKey, validSectionKey, snapshotJournalKey, SnapshotGeneratorKey, snapshotDisabledKey,
+			snapshotRecoveryKey, snapshotSyncStatusKey, snapshotRootKey, badBlockKey, pruningEnabledKey,
+			lastPrunedBlockNumberKey, lastServiceChainTxReceiptKey, lastIndexedBlockKey, migrationStatusKey,
+			lastAccRewardBlockNumberKey, chaindatafetcherCheckpointKey, historyTailKey,
+		} {
+			if bytes.Equal(key, meta) {
+				return true
+			}
+		}
+		return bytes.HasPrefix(key, sectionHeadKeyPrefix) || bytes.HasPrefix(key, databaseDirPrefix)
+	}},
+}
+
+const unaccountedCategory = "Unaccounted"
+
+// inspectTarget is a database to be inspected with its name.
+type inspectTarget struct {
+	name string
+	db   Database
+}
+
+// inspectTargets returns the distinct databases of the manager. The shards of
+// a sharded database are inspected separately.
+func inspectTargets(dbm DBManager) []inspectTarget {
+	var (
+		targets []inspectTarget
+		seen    = make(map[Database]bool)
+	)
+	for et := DBEntryType(0); et < databaseEntryTypeSize; et++ {
+		db := dbm.getDatabase(et)
+		if db == nil || seen[db] {
+			continue
+		}
+		seen[db] = true
+
+		name := et.String()
+		if dbm.IsSingle() || dbm.GetDBConfig().DBType == MemoryDB {
+			name = "single"
+		}
+		if sdb, ok := db.(*shardedDB); ok {
+			for i, shard := range sdb.shards {
+				targets = append(targets, inspectTarget{fmt.Sprintf("%s/shard%d", name, i), shard})
+			}
+			continue
+		}
+		targets = append(targets, inspectTarget{name, db})
+	}
+	return targets
+}
+
+// InspectDatabase iterates all keys of the databases in the manager and
+// returns the number of keys and their total size per schema category.
+// Databases which don't support iteration are skipped.
+func InspectDatabase(dbm DBManager) ([]*InspectStat, error) {
+	var stats []*InspectStat
+	for _, target := range inspectTargets(dbm) {
+		if target.db.Type() == BadgerDB || target.db.Type() == DynamoDB {
+			logger.Warn("Skipped inspecting the database not supporting iteration", "database", target.name, "type", target.db.Type())
+			continue
+		}
+		dbStats, err := inspectDB(target.name, target.db)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, dbStats...)
+	}
+	if dbm, ok := dbm.(*databaseManager); ok && dbm.ancient.Load() != nil {
+		ancient := dbm.ancient.Load()
+		for _, name := range ancientTables {
+			table := ancient.tables[name]
+			stats = append(stats, &InspectStat{
+				Database: ancientDir,
+				Category: name,
+				Count:    table.Items(),
+				Size:     common.StorageSize(table.Size()),
+			})
+		}
+	}
+	return stats, nil
+}
+
+// inspectDB iterates all keys of the database and returns the stats of the
+// categories found in the database.
+func inspectDB(name string, db Database) ([]*InspectStat, error) {
+	var (
+		stats  = make(map[string]*InspectStat)
+		count  uint64
+		start  = time.Now()
+		logged = time.Now()
+	)
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		category := unaccountedCategory
+		for _, c := range inspectCategories {
+			if c.match(key) {
+				category = c.name
+				break
+			}
+		}
+		stat, ok := stats[category]
+		if !ok {
+			stat = &InspectStat{Database: name, Category: category}
+			stats[category] = stat
+		}
+		stat.Count++
+		stat.Size += common.StorageSize(len(key) + len(it.Value()))
+
+		count++
+		if time.Since(logged) > 8*time.Second {
+			logger.Info("Inspecting database", "database", name, "count", count, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+
+	// Keep the order of the categories in the schema.
+	result := make([]*InspectStat, 0, len(stats))
+	for _, c := range append(inspectCategories, inspectCategory{name: unaccountedCategory}) {
+		if stat, ok := stats[c.name]; ok {
+			result = append(result, stat)
+		}
+	}
+	return result, nil
+}
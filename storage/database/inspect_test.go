@@ -0,0 +1,64 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectDatabase(t *testing.T) {
+	dbm := NewDBManager(&DBConfig{Dir: t.TempDir(), DBType: LevelDB, NumStateTrieShards: 2})
+	defer dbm.Close()
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+	dbm.WriteBlock(block)
+	dbm.WriteCanonicalHash(block.Hash(), 1)
+	dbm.WriteHeadBlockHash(block.Hash())
+	dbm.WriteReceipts(block.Hash(), 1, types.Receipts{})
+	dbm.WriteCode(common.HexToHash("0x01"), []byte{0x60, 0x00})
+	dbm.WriteTrieNode(common.HexToHash("0x02").ExtendZero(), []byte{0xc0})
+	dbm.WriteAccountSnapshot(common.HexToHash("0x03"), []byte{0x01})
+	dbm.WriteStorageSnapshot(common.HexToHash("0x03"), common.HexToHash("0x04"), []byte{0x01})
+
+	stats, err := InspectDatabase(dbm)
+	require.NoError(t, err)
+
+	found := make(map[string]uint64)
+	for _, stat := range stats {
+		assert.NotZero(t, stat.Size)
+		found[stat.Database+":"+stat.Category] += stat.Count
+	}
+	assert.Equal(t, uint64(1), found["header:Headers"])
+	assert.Equal(t, uint64(1), found["header:Canonical hashes"])
+	assert.Equal(t, uint64(1), found["header:Header number mappings"])
+	assert.Equal(t, uint64(1), found["body:Bodies"])
+	assert.Equal(t, uint64(1), found["receipts:Receipts"])
+	assert.Equal(t, uint64(1), found["header:Metadata"])
+	assert.Equal(t, uint64(1), found["snapshot:Account snapshots"])
+	assert.Equal(t, uint64(1), found["snapshot:Storage snapshots"])
+	assert.Equal(t, uint64(1), found["statetrie/shard0:Contract codes"]+found["statetrie/shard1:Contract codes"])
+	assert.Equal(t, uint64(1), found["statetrie/shard0:Trie nodes"]+found["statetrie/shard1:Trie nodes"])
+	for key := range found {
+		assert.NotContains(t, key, unaccountedCategory)
+	}
+}
@@ -33,6 +33,7 @@ const (
 	LevelDB   DBType = "LevelDB"
 	RocksDB          = "RocksDB"
 	BadgerDB         = "BadgerDB"
+	PebbleDB         = "PebbleDB"
 	MemoryDB         = "MemoryDB"
 	DynamoDB         = "DynamoDBS3"
 	ShardedDB        = "ShardedDB"
@@ -41,7 +42,7 @@ const (
 // ToValid converts DBType to a valid one.
 // If it is unable to convert, "" is returned.
 func (db DBType) ToValid() DBType {
-	validDBType := []DBType{LevelDB, RocksDB, BadgerDB, MemoryDB, DynamoDB}
+	validDBType := []DBType{LevelDB, RocksDB, BadgerDB, PebbleDB, MemoryDB, DynamoDB}
 
 	for _, vdb := range validDBType {
 		if strings.ToLower(string(vdb)) == strings.ToLower(string(db)) {
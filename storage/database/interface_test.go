@@ -24,12 +24,13 @@ import (
 )
 
 func TestDBType_ToValid(t *testing.T) {
-	validDBType := []DBType{LevelDB, BadgerDB, MemoryDB, DynamoDB}
+	validDBType := []DBType{LevelDB, BadgerDB, PebbleDB, MemoryDB, DynamoDB}
 
 	// check acceptable dbtype values
 	acceptableDBTypes := []DBType{
 		"LevelDB", "leveldb", "levelDB", "LevelDB",
 		"BadgerDB", "badgerdb", "Badgerdb", "badgerDB",
+		"PebbleDB", "pebbledb", "Pebbledb", "pebbleDB",
 		"MemoryDB", "memorydb", "Memorydb", "memoryDB",
 		"DynamoDBS3", "dynamodbs3", "Dynamodbs3", "DynamodbS3", "dynamodbS3",
 	}
@@ -41,7 +42,7 @@ func TestDBType_ToValid(t *testing.T) {
 
 	// check not acceptable dbtype values
 	notAcceptableDBTypes := []DBType{
-		"level", "ldb", "badger", "memory",
+		"level", "ldb", "badger", "pebble", "memory",
 		"dynamo", "dynamodb", "dynamos3", "ddb", "ShardedDB",
 	}
 
@@ -321,6 +321,46 @@ func (db *levelDB) NewIterator(prefix []byte, start []byte) Iterator {
 	return db.db.NewIterator(bytesPrefixRange(prefix, start), nil)
 }
 
+// Checkpoint copies the database into a new LevelDB in the given directory.
+// LevelDB has no native checkpoint, so the copy is made from a snapshot, which
+// is consistent without pausing the writes.
+func (db *levelDB) Checkpoint(dir string) error {
+	snap, err := db.db.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snap.Release()
+
+	dst, err := leveldb.OpenFile(dir, &opt.Options{ErrorIfExist: true})
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	it := snap.NewIterator(nil, nil)
+	defer it.Release()
+
+	batch, size := new(leveldb.Batch), 0
+	for it.Next() {
+		batch.Put(it.Key(), it.Value())
+		size += len(it.Key()) + len(it.Value())
+		if size > IdealBatchSize {
+			if err := dst.Write(batch, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+			size = 0
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := dst.Write(batch, nil); err != nil {
+		return err
+	}
+	return dst.Close()
+}
+
 func (db *levelDB) Close() {
 	// Stop the metrics collection to avoid internal database races
 	db.quitLock.Lock()
@@ -0,0 +1,418 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/log"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+)
+
+var errReadOnlyStorage = errors.New("read-only leveldb storage")
+
+// levelDBReadOnlyReopenInterval is the minimum interval between the reopens for
+// the writes of the primary which are only appended to its journal. Reopening
+// replays the whole journal, so it is not done at every catch-up. The flushes
+// and compactions of the primary change the manifest and are followed at once.
+var levelDBReadOnlyReopenInterval = 10 * time.Second
+
+// levelDBReadOnly opens the LevelDB of a primary instance running in another
+// process. The primary holds the lock of the database, so the files are read
+// through readOnlyStorage which doesn't take it. LevelDB can't follow the
+// changes of the files, so TryCatchUpWithPrimary reopens the database if the
+// manifest of the primary has changed since it was opened, or if its journal
+// has grown and levelDBReadOnlyReopenInterval has passed.
+// Calling put, delete, batch put and batch write does nothing and returns no error.
+type levelDBReadOnly struct {
+	fn   string // filename for reporting
+	opts *opt.Options
+
+	gen     *levelDBGeneration
+	genLock sync.RWMutex // protects gen from being swapped while it is used
+
+	catchUpLock sync.Mutex // serializes the catch-ups
+
+	logger log.Logger // Contextual logger tracking the database path
+}
+
+// levelDBGeneration is the database opened at a certain state of the files of
+// the primary. It is closed after all its iterators are released.
+type levelDBGeneration struct {
+	db       *leveldb.DB
+	manifest string    // state of the manifest when opened
+	journal  string    // state of the journals when opened
+	opened   time.Time // time when opened
+	iters    sync.WaitGroup
+}
+
+// NewLevelDBReadOnly opens the LevelDB in the given directory without taking
+// its lock, to read it while the primary instance is writing it.
+func NewLevelDBReadOnly(dbc *DBConfig) (*levelDBReadOnly, error) {
+	if dbc.LevelDBCacheSize < 16 {
+		dbc.LevelDBCacheSize = 16
+	}
+	if dbc.OpenFilesLimit < minFileDescriptorsForLevelDB {
+		dbc.OpenFilesLimit = minFileDescriptorsForLevelDB
+	}
+	opts := getLevelDBOptions(dbc)
+	opts.ReadOnly = true
+	opts.ErrorIfMissing = true
+	// The primary may be in the middle of appending a record to the journal.
+	opts.Strict = opt.DefaultStrict &^ opt.StrictJournalChecksum
+
+	db := &levelDBReadOnly{
+		fn:     dbc.Dir,
+		opts:   opts,
+		logger: logger.NewWith("path", dbc.Dir),
+	}
+	gen, err := db.open()
+	if err != nil {
+		return nil, err
+	}
+	db.gen = gen
+	db.logger.Info("Opened read-only LevelDB", "manifest", gen.manifest, "journal", gen.journal)
+	return db, nil
+}
+
+func (db *levelDBReadOnly) open() (*levelDBGeneration, error) {
+	// Read the state before opening, so a write in between triggers another catch-up.
+	manifest, journal, err := levelDBFilesState(db.fn)
+	if err != nil {
+		return nil, err
+	}
+	opened := time.Now()
+	ldb, err := leveldb.Open(&readOnlyStorage{dir: db.fn}, db.opts)
+	if err != nil {
+		return nil, err
+	}
+	return &levelDBGeneration{db: ldb, manifest: manifest, journal: journal, opened: opened}, nil
+}
+
+func (db *levelDBReadOnly) Type() DBType {
+	return LevelDB
+}
+
+func (db *levelDBReadOnly) Put(key []byte, value []byte) error {
+	return nil
+}
+
+func (db *levelDBReadOnly) Delete(key []byte) error {
+	return nil
+}
+
+func (db *levelDBReadOnly) NewBatch() Batch {
+	return &emptyBatch{}
+}
+
+func (db *levelDBReadOnly) Has(key []byte) (bool, error) {
+	has, err := db.has(key)
+	if errors.Is(err, os.ErrNotExist) {
+		// A table was removed by a compaction of the primary, the database
+		// has to be reopened at the new state.
+		if err := db.TryCatchUpWithPrimary(); err != nil {
+			return false, err
+		}
+		return db.has(key)
+	}
+	return has, err
+}
+
+func (db *levelDBReadOnly) has(key []byte) (bool, error) {
+	db.genLock.RLock()
+	defer db.genLock.RUnlock()
+
+	return db.gen.db.Has(key, nil)
+}
+
+func (db *levelDBReadOnly) Get(key []byte) ([]byte, error) {
+	dat, err := db.get(key)
+	if errors.Is(err, os.ErrNotExist) {
+		if err := db.TryCatchUpWithPrimary(); err != nil {
+			return nil, err
+		}
+		return db.get(key)
+	}
+	return dat, err
+}
+
+func (db *levelDBReadOnly) get(key []byte) ([]byte, error) {
+	db.genLock.RLock()
+	defer db.genLock.RUnlock()
+
+	dat, err := db.gen.db.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, dataNotFoundErr
+	}
+	return dat, err
+}
+
+func (db *levelDBReadOnly) NewIterator(prefix []byte, start []byte) Iterator {
+	db.genLock.RLock()
+	defer db.genLock.RUnlock()
+
+	gen := db.gen
+	gen.iters.Add(1)
+	return &levelDBReadOnlyIterator{
+		Iterator: gen.db.NewIterator(bytesPrefixRange(prefix, start), nil),
+		gen:      gen,
+	}
+}
+
+// TryCatchUpWithPrimary reopens the database if the manifest of the primary has
+// changed since it was opened, or if its journal has grown and the minimum reopen
+// interval has passed. The previous database is closed after its iterators are
+// released.
+func (db *levelDBReadOnly) TryCatchUpWithPrimary() error {
+	db.catchUpLock.Lock()
+	defer db.catchUpLock.Unlock()
+
+	manifest, journal, err := levelDBFilesState(db.fn)
+	if err != nil {
+		return err
+	}
+	db.genLock.RLock()
+	var (
+		manifestChanged = db.gen.manifest != manifest
+		journalChanged  = db.gen.journal != journal && time.Since(db.gen.opened) >= levelDBReadOnlyReopenInterval
+	)
+	db.genLock.RUnlock()
+	if !manifestChanged && !journalChanged {
+		return nil
+	}
+
+	gen, err := db.open()
+	if err != nil {
+		return err
+	}
+	db.genLock.Lock()
+	old := db.gen
+	db.gen = gen
+	db.genLock.Unlock()
+
+	go func() {
+		old.iters.Wait()
+		if err := old.db.Close(); err != nil {
+			db.logger.Error("Failed to close the previous read-only LevelDB", "err", err)
+		}
+	}()
+	return nil
+}
+
+func (db *levelDBReadOnly) Close() {
+	db.catchUpLock.Lock()
+	defer db.catchUpLock.Unlock()
+
+	db.genLock.Lock()
+	defer db.genLock.Unlock()
+
+	if err := db.gen.db.Close(); err == nil {
+		db.logger.Info("Database closed")
+	} else {
+		db.logger.Error("Failed to close database", "err", err)
+	}
+}
+
+func (db *levelDBReadOnly) Stat(property string) (string, error) {
+	if property == "" {
+		property = "leveldb.stats"
+	} else if !strings.HasPrefix(property, "leveldb.") {
+		property = "leveldb." + property
+	}
+	db.genLock.RLock()
+	defer db.genLock.RUnlock()
+
+	return db.gen.db.GetProperty(property)
+}
+
+func (db *levelDBReadOnly) Compact(start []byte, limit []byte) error {
+	return errReadOnlyStorage
+}
+
+// Meter does nothing, the metrics are collected by the primary.
+func (db *levelDBReadOnly) Meter(prefix string) {}
+
+// levelDBReadOnlyIterator releases its generation of the database when it is
+// released.
+type levelDBReadOnlyIterator struct {
+	Iterator
+	gen  *levelDBGeneration
+	once sync.Once
+}
+
+func (it *levelDBReadOnlyIterator) Release() {
+	it.Iterator.Release()
+	it.once.Do(it.gen.iters.Done)
+}
+
+// levelDBFilesState summarizes the manifest and the journals of the LevelDB in
+// the given directory. The manifest changes when the primary flushes or compacts
+// the database, the journals whenever it is written.
+func levelDBFilesState(dir string) (manifest string, journal string, err error) {
+	current, err := os.ReadFile(filepath.Join(dir, "CURRENT"))
+	if err != nil {
+		return "", "", err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", err
+	}
+	var manifestState, journalState strings.Builder
+	manifestState.WriteString(strings.TrimSpace(string(current)))
+	for _, entry := range entries {
+		fd, ok := parseLevelDBFileName(entry.Name())
+		if !ok || fd.Type&(storage.TypeManifest|storage.TypeJournal) == 0 {
+			continue
+		}
+		info, err := entry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return "", "", err
+		}
+		if fd.Type == storage.TypeManifest {
+			fmt.Fprintf(&manifestState, " %s:%d", entry.Name(), info.Size())
+		} else {
+			fmt.Fprintf(&journalState, " %s:%d", entry.Name(), info.Size())
+		}
+	}
+	return manifestState.String(), journalState.String(), nil
+}
+
+// readOnlyStorage reads the files of a LevelDB without taking its lock, which
+// is held by the primary instance. The primary is never modified.
+type readOnlyStorage struct {
+	dir string
+}
+
+type readOnlyLocker struct{}
+
+func (readOnlyLocker) Unlock() {}
+
+func (s *readOnlyStorage) Lock() (storage.Locker, error) {
+	return readOnlyLocker{}, nil
+}
+
+func (s *readOnlyStorage) Log(str string) {}
+
+func (s *readOnlyStorage) SetMeta(fd storage.FileDesc) error {
+	return errReadOnlyStorage
+}
+
+func (s *readOnlyStorage) GetMeta() (storage.FileDesc, error) {
+	current, err := os.ReadFile(filepath.Join(s.dir, "CURRENT"))
+	if err != nil {
+		return storage.FileDesc{}, err
+	}
+	fd, ok := parseLevelDBFileName(strings.TrimSpace(string(current)))
+	if !ok || fd.Type != storage.TypeManifest {
+		return storage.FileDesc{}, fmt.Errorf("invalid CURRENT file of %s: %q", s.dir, current)
+	}
+	return fd, nil
+}
+
+func (s *readOnlyStorage) List(ft storage.FileType) ([]storage.FileDesc, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var fds []storage.FileDesc
+	for _, entry := range entries {
+		if fd, ok := parseLevelDBFileName(entry.Name()); ok && fd.Type&ft != 0 {
+			fds = append(fds, fd)
+		}
+	}
+	return fds, nil
+}
+
+func (s *readOnlyStorage) Open(fd storage.FileDesc) (storage.Reader, error) {
+	f, err := os.Open(filepath.Join(s.dir, levelDBFileName(fd, false)))
+	if os.IsNotExist(err) && fd.Type == storage.TypeTable {
+		f, err = os.Open(filepath.Join(s.dir, levelDBFileName(fd, true)))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *readOnlyStorage) Create(fd storage.FileDesc) (storage.Writer, error) {
+	return nil, errReadOnlyStorage
+}
+
+func (s *readOnlyStorage) Remove(fd storage.FileDesc) error {
+	return errReadOnlyStorage
+}
+
+func (s *readOnlyStorage) Rename(oldfd, newfd storage.FileDesc) error {
+	return errReadOnlyStorage
+}
+
+func (s *readOnlyStorage) Close() error {
+	return nil
+}
+
+// levelDBFileName returns the name of the file in the same format as goleveldb.
+// Old tables may have the .sst extension instead of .ldb.
+func levelDBFileName(fd storage.FileDesc, old bool) string {
+	switch fd.Type {
+	case storage.TypeManifest:
+		return fmt.Sprintf("MANIFEST-%06d", fd.Num)
+	case storage.TypeJournal:
+		return fmt.Sprintf("%06d.log", fd.Num)
+	case storage.TypeTable:
+		if old {
+			return fmt.Sprintf("%06d.sst", fd.Num)
+		}
+		return fmt.Sprintf("%06d.ldb", fd.Num)
+	default:
+		return fmt.Sprintf("%06d.tmp", fd.Num)
+	}
+}
+
+func parseLevelDBFileName(name string) (storage.FileDesc, bool) {
+	var (
+		fd   storage.FileDesc
+		tail string
+	)
+	if _, err := fmt.Sscanf(name, "%d.%s", &fd.Num, &tail); err == nil {
+		switch tail {
+		case "log":
+			fd.Type = storage.TypeJournal
+		case "ldb", "sst":
+			fd.Type = storage.TypeTable
+		case "tmp":
+			fd.Type = storage.TypeTemp
+		default:
+			return fd, false
+		}
+		return fd, true
+	}
+	if n, _ := fmt.Sscanf(name, "MANIFEST-%d%s", &fd.Num, &tail); n == 1 {
+		fd.Type = storage.TypeManifest
+		return fd, true
+	}
+	return fd, false
+}
@@ -0,0 +1,178 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelDBReadOnly(t *testing.T) {
+	defer func(interval time.Duration) { levelDBReadOnlyReopenInterval = interval }(levelDBReadOnlyReopenInterval)
+	levelDBReadOnlyReopenInterval = time.Hour
+
+	dir := t.TempDir()
+	primary, err := NewLevelDB(&DBConfig{Dir: dir}, MiscDB)
+	require.NoError(t, err)
+	defer primary.Close()
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, primary.Put([]byte(fmt.Sprintf("key%03d", i)), []byte{byte(i)}))
+	}
+
+	// The database can be opened while the primary holds the lock.
+	db, err := NewLevelDBReadOnly(&DBConfig{Dir: dir})
+	require.NoError(t, err)
+	defer db.Close()
+
+	val, err := db.Get([]byte("key001"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, val)
+
+	// Writes are ignored.
+	assert.NoError(t, db.Put([]byte("ignored"), []byte{1}))
+	has, err := primary.Has([]byte("ignored"))
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	// The writes of the primary only appended to its journal are not visible
+	// until the minimum reopen interval has passed.
+	it := db.NewIterator([]byte("key"), nil)
+	require.NoError(t, primary.Put([]byte("key100"), []byte{100}))
+	require.NoError(t, primary.Delete([]byte("key000")))
+
+	_, err = db.Get([]byte("key100"))
+	assert.ErrorIs(t, err, dataNotFoundErr)
+	require.NoError(t, db.TryCatchUpWithPrimary())
+	_, err = db.Get([]byte("key100"))
+	assert.ErrorIs(t, err, dataNotFoundErr)
+
+	// They are visible after catching up once it has passed, while an
+	// iterator opened before keeps reading the previous state.
+	levelDBReadOnlyReopenInterval = 0
+	require.NoError(t, db.TryCatchUpWithPrimary())
+	val, err = db.Get([]byte("key100"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{100}, val)
+	has, err = db.Has([]byte("key000"))
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+	assert.NoError(t, it.Error())
+	it.Release()
+	assert.Equal(t, 100, count)
+
+	// A compaction of the primary changes the manifest, so it is followed
+	// regardless of the interval and the rewritten tables are still readable.
+	levelDBReadOnlyReopenInterval = time.Hour
+	require.NoError(t, primary.Put([]byte("key101"), []byte{101}))
+	require.NoError(t, primary.Compact(nil, nil))
+	require.NoError(t, db.TryCatchUpWithPrimary())
+	for i := 1; i <= 101; i++ {
+		val, err := db.Get([]byte(fmt.Sprintf("key%03d", i)))
+		require.NoError(t, err)
+		assert.Equal(t, []byte{byte(i)}, val)
+	}
+}
+
+// TestDBManager_ReadOnly checks a read-only DBManager follows the blocks
+// written and frozen by the primary.
+func TestDBManager_ReadOnly(t *testing.T) {
+	defer func(interval time.Duration) { levelDBReadOnlyReopenInterval = interval }(levelDBReadOnlyReopenInterval)
+	levelDBReadOnlyReopenInterval = 0
+
+	const threshold = 3
+
+	dbc := &DBConfig{Dir: t.TempDir(), DBType: LevelDB, NumStateTrieShards: 1}
+	primary := NewDBManager(dbc)
+	defer primary.Close()
+	manager := primary.(*databaseManager)
+
+	var (
+		hashes   []common.Hash
+		receipts = types.Receipts{{Status: types.ReceiptStatusSuccessful, GasUsed: 21000, Logs: []*types.Log{}}}
+	)
+	writeBlocks := func(n int) {
+		for i := 0; i < n; i++ {
+			number := int64(len(hashes))
+			parent := common.Hash{}
+			if number > 0 {
+				parent = hashes[number-1]
+			}
+			block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(number), ParentHash: parent})
+			primary.WriteBlock(block)
+			primary.WriteCanonicalHash(block.Hash(), uint64(number))
+			primary.WriteReceipts(block.Hash(), uint64(number), receipts)
+			primary.WriteHeadBlockHash(block.Hash())
+			hashes = append(hashes, block.Hash())
+		}
+	}
+	writeBlocks(5)
+
+	// The follower starts before the primary creates the ancient store.
+	db := NewDBManager(&DBConfig{Dir: dbc.Dir, DBType: LevelDB, NumStateTrieShards: 1, ReadOnly: true})
+	defer db.Close()
+	assert.Equal(t, uint64(0), db.Ancients())
+
+	// Freeze the blocks manually instead of running the background loop.
+	manager.config.AncientThreshold = threshold
+	store, err := newAncientStore(manager.ancientDir())
+	require.NoError(t, err)
+	manager.ancient.Store(store)
+	manager.ancientQuit = make(chan struct{})
+	_, err = manager.freezeBatch()
+	require.NoError(t, err)
+
+	// The ancient store created by the primary is opened on catching up.
+	require.NoError(t, db.TryCatchUpWithPrimary())
+	assert.Equal(t, hashes[4], db.ReadHeadBlockHash())
+	assert.Equal(t, uint64(2), db.Ancients())
+	assert.Equal(t, primary.Ancients(), db.Ancients())
+	for i := range hashes {
+		if block := db.ReadBlockByNumber(uint64(i)); assert.NotNil(t, block, i) {
+			assert.Equal(t, hashes[i], block.Hash())
+		}
+	}
+
+	writeBlocks(5)
+	_, err = manager.freezeBatch()
+	require.NoError(t, err)
+	assert.Equal(t, hashes[4], db.ReadHeadBlockHash())
+
+	require.NoError(t, db.TryCatchUpWithPrimary())
+	assert.Equal(t, hashes[9], db.ReadHeadBlockHash())
+	assert.Equal(t, uint64(len(hashes)-threshold), db.Ancients())
+	for i := range hashes {
+		if block := db.ReadBlockByNumber(uint64(i)); assert.NotNil(t, block, i) {
+			assert.Equal(t, hashes[i], block.Hash())
+		}
+		if r := db.ReadReceipts(hashes[i], uint64(i)); assert.Len(t, r, 1, i) {
+			assert.Equal(t, receipts[0].GasUsed, r[0].GasUsed)
+		}
+	}
+}
@@ -52,4 +52,8 @@ var (
 
 	cacheGetCanonicalHashMissMeter = metrics.NewRegisteredMeter("klay/cache/get/canonicalhash/miss", nil)
 	cacheGetCanonicalHashHitMeter  = metrics.NewRegisteredMeter("klay/cache/get/canonicalhash/hit", nil)
+
+	// Write stalls of all pebble databases, marked as soon as a stall ends.
+	pebbleWriteStallCountMeter    = metrics.NewRegisteredMeter("klay/db/pebble/writestall/count", nil)
+	pebbleWriteStallDurationMeter = metrics.NewRegisteredMeter("klay/db/pebble/writestall/duration", nil)
 )
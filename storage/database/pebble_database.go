@@ -0,0 +1,601 @@
+// Modifications Copyright 2024 The Kaia Authors
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+//
+// This file is derived from ethdb/pebble/pebble.go.
+// Modified and improved for the Kaia development.
+
+package database
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/bloom"
+	"github.com/klaytn/klaytn/log"
+	kaiametrics "github.com/klaytn/klaytn/metrics"
+	metricutils "github.com/klaytn/klaytn/metrics/utils"
+	"github.com/rcrowley/go-metrics"
+)
+
+const (
+	// minPebbleCacheSize is the minimum amount of memory in megabytes to allocate
+	// to pebble for caching (split half for caching and half for memtables).
+	minPebbleCacheSize = 16
+
+	// minPebbleOpenFiles is the minimum number of files handles to allocate to
+	// the open database files.
+	minPebbleOpenFiles = 16
+
+	// pebbleMemTableLimit is the number of memtables which can be queued before
+	// the writes are stalled.
+	pebbleMemTableLimit = 2
+
+	// pebbleMaxMemTableSize is the maximum memtable size allowed by pebble.
+	pebbleMaxMemTableSize = 4<<30 - 1
+)
+
+// pebbleDB is a persistent key-value store based on the pebble storage engine,
+// a pure-Go LSM tree which offers RocksDB-class compaction behavior without cgo.
+type pebbleDB struct {
+	fn string     // filename for reporting
+	db *pebble.DB // pebble instance
+
+	compTimer               kaiametrics.HybridTimer // Meter for measuring the total time spent in database compaction
+	compReadMeter           metrics.Meter           // Meter for measuring the data read during compaction
+	compWriteMeter          metrics.Meter           // Meter for measuring the data written during compaction
+	writeDelayCountMeter    metrics.Meter           // Meter for measuring the cumulative number of write delays
+	writeDelayDurationMeter metrics.Meter           // Meter for measuring the cumulative duration of write delays
+	diskSizeGauge           metrics.Gauge           // Gauge for tracking the size of all the levels in the database
+	blockCacheGauge         metrics.Gauge           // Gauge for measuring the current size of block cache
+	memCompGauge            metrics.Gauge           // Gauge for tracking the number of memory compaction
+	level0CompGauge         metrics.Gauge           // Gauge for tracking the number of table compaction in level0
+	nonlevel0CompGauge      metrics.Gauge           // Gauge for tracking the number of table compaction in non0 level
+	levelFilesGauge         []metrics.Gauge         // Gauge for tracking the number of tables in levels
+
+	perfCheck       bool
+	getTimer        kaiametrics.HybridTimer
+	putTimer        kaiametrics.HybridTimer
+	batchWriteTimer kaiametrics.HybridTimer
+
+	activeComp          int           // Current number of active compactions
+	compStartTime       time.Time     // The start time of the earliest currently-active compaction
+	compTime            atomic.Int64  // Total time spent in compaction in ns
+	level0Comp          atomic.Uint32 // Total number of level-zero compactions
+	nonLevel0Comp       atomic.Uint32 // Total number of non level-zero compactions
+	writeDelayStartTime time.Time     // The start time of the latest write stall
+	writeDelayCount     atomic.Int64  // Total number of write stall counts
+	writeDelayTime      atomic.Int64  // Total time spent in write stalls
+	eventLock           sync.Mutex    // Mutex protecting the compaction and write stall tracking
+
+	quitLock sync.Mutex      // Mutex protecting the quit channel access
+	quitChan chan chan error // Quit channel to stop the metrics collection before closing the database
+
+	prefix string     // prefix used for metrics
+	logger log.Logger // Contextual logger tracking the database path
+}
+
+func (db *pebbleDB) onCompactionBegin(info pebble.CompactionInfo) {
+	db.eventLock.Lock()
+	defer db.eventLock.Unlock()
+
+	if db.activeComp == 0 {
+		db.compStartTime = time.Now()
+	}
+	if len(info.Input) > 0 && info.Input[0].Level == 0 {
+		db.level0Comp.Add(1)
+	} else {
+		db.nonLevel0Comp.Add(1)
+	}
+	db.activeComp++
+}
+
+func (db *pebbleDB) onCompactionEnd(info pebble.CompactionInfo) {
+	db.eventLock.Lock()
+	defer db.eventLock.Unlock()
+
+	if db.activeComp == 1 {
+		db.compTime.Add(int64(time.Since(db.compStartTime)))
+	} else if db.activeComp == 0 {
+		db.logger.Error("Compaction ended without a matching start", "jobID", info.JobID)
+	}
+	db.activeComp--
+}
+
+func (db *pebbleDB) onWriteStallBegin(pebble.WriteStallBeginInfo) {
+	db.eventLock.Lock()
+	defer db.eventLock.Unlock()
+
+	db.writeDelayStartTime = time.Now()
+}
+
+func (db *pebbleDB) onWriteStallEnd() {
+	db.eventLock.Lock()
+	defer db.eventLock.Unlock()
+
+	elapsed := time.Since(db.writeDelayStartTime)
+	db.writeDelayTime.Add(int64(elapsed))
+	db.writeDelayCount.Add(1)
+
+	pebbleWriteStallCountMeter.Mark(1)
+	pebbleWriteStallDurationMeter.Mark(int64(elapsed))
+}
+
+// NewPebbleDB returns a wrapped pebble DB object. LevelDBCacheSize and
+// OpenFilesLimit of the given DBConfig are used as the cache size in MiB and
+// the number of file handles respectively.
+func NewPebbleDB(dbc *DBConfig) (*pebbleDB, error) {
+	localLogger := logger.NewWith("path", dbc.Dir)
+
+	if fi, err := os.Stat(dbc.Dir); err == nil {
+		if !fi.IsDir() {
+			return nil, fmt.Errorf("pebble: open %s: not a directory", dbc.Dir)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// Ensure we have some minimal caching and file guarantees
+	cache, handles := dbc.LevelDBCacheSize, dbc.OpenFilesLimit
+	if cache < minPebbleCacheSize {
+		cache = minPebbleCacheSize
+	}
+	if handles < minPebbleOpenFiles {
+		handles = minPebbleOpenFiles
+	}
+
+	// Two memory tables is configured which is identical to leveldb,
+	// including a frozen memory table and another live one.
+	memTableSize := cache * 1024 * 1024 / 2 / pebbleMemTableLimit
+	if memTableSize >= pebbleMaxMemTableSize {
+		memTableSize = pebbleMaxMemTableSize - 1
+	}
+
+	db := &pebbleDB{
+		fn:        dbc.Dir,
+		logger:    localLogger,
+		perfCheck: dbc.EnableDBPerfMetrics,
+	}
+	opt := &pebble.Options{
+		// Pebble has a single combined cache area and the write buffers are
+		// taken from this too. Assign all available memory allowance for cache.
+		Cache:        pebble.NewCache(int64(cache * 1024 * 1024)),
+		MaxOpenFiles: handles,
+
+		// The size of memory table(as well as the write buffer).
+		// Note, there may have more than two memory tables in the system.
+		MemTableSize: uint64(memTableSize),
+
+		// MemTableStopWritesThreshold places a hard limit on the size
+		// of the existent MemTables(including the frozen one).
+		MemTableStopWritesThreshold: pebbleMemTableLimit,
+
+		// The default compaction concurrency(1 thread),
+		// Here use all available CPUs for faster compaction.
+		MaxConcurrentCompactions: runtime.NumCPU,
+
+		// Per-level options. Options for at least one level must be specified. The
+		// options for the last level are used for all subsequent levels.
+		Levels: []pebble.LevelOptions{
+			{TargetFileSize: 2 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 4 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 8 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 16 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 32 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 64 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 128 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+		},
+		EventListener: &pebble.EventListener{
+			CompactionBegin: db.onCompactionBegin,
+			CompactionEnd:   db.onCompactionEnd,
+			WriteStallBegin: db.onWriteStallBegin,
+			WriteStallEnd:   db.onWriteStallEnd,
+		},
+	}
+	// Disable seek compaction explicitly. Check https://github.com/ethereum/go-ethereum/pull/20130
+	// for more details.
+	opt.Experimental.ReadSamplingMultiplier = -1
+
+	localLogger.Info("PebbleDB configurations", "cacheSize(MB)", cache, "openFilesLimit", handles,
+		"memTableSize(MB)", memTableSize/1024/1024, "usePerfCheck", dbc.EnableDBPerfMetrics)
+
+	innerDB, err := pebble.Open(dbc.Dir, opt)
+	if err != nil {
+		return nil, err
+	}
+	db.db = innerDB
+	return db, nil
+}
+
+func (db *pebbleDB) Type() DBType {
+	return PebbleDB
+}
+
+// Path returns the path to the database directory.
+func (db *pebbleDB) Path() string {
+	return db.fn
+}
+
+// Put inserts the given value into the key-value store.
+func (db *pebbleDB) Put(key []byte, value []byte) error {
+	if db.perfCheck {
+		start := time.Now()
+		err := db.put(key, value)
+		db.putTimer.Update(time.Since(start))
+		return err
+	}
+	return db.put(key, value)
+}
+
+func (db *pebbleDB) put(key []byte, value []byte) error {
+	return db.db.Set(key, value, pebble.NoSync)
+}
+
+// Has retrieves if a key is present in the key-value store.
+func (db *pebbleDB) Has(key []byte) (bool, error) {
+	_, closer, err := db.db.Get(key)
+	if err == pebble.ErrNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	closer.Close()
+	return true, nil
+}
+
+// Get retrieves the given key if it's present in the key-value store.
+func (db *pebbleDB) Get(key []byte) ([]byte, error) {
+	if db.perfCheck {
+		start := time.Now()
+		val, err := db.get(key)
+		db.getTimer.Update(time.Since(start))
+		return val, err
+	}
+	return db.get(key)
+}
+
+func (db *pebbleDB) get(key []byte) ([]byte, error) {
+	dat, closer, err := db.db.Get(key)
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return nil, dataNotFoundErr
+		}
+		return nil, err
+	}
+	ret := make([]byte, len(dat))
+	copy(ret, dat)
+	closer.Close()
+	return ret, nil
+}
+
+// Delete removes the key from the key-value store.
+func (db *pebbleDB) Delete(key []byte) error {
+	return db.db.Delete(key, pebble.NoSync)
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset
+// of database content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (db *pebbleDB) NewIterator(prefix []byte, start []byte) Iterator {
+	r := bytesPrefixRange(prefix, start)
+	iter, err := db.db.NewIter(&pebble.IterOptions{
+		LowerBound: r.Start,
+		UpperBound: r.Limit,
+	})
+	if err != nil {
+		return &pebbleIterator{err: err, released: true}
+	}
+	iter.First()
+	return &pebbleIterator{iter: iter, moved: true}
+}
+
+// Checkpoint creates a consistent snapshot of the database in the given
+// directory, which must not exist. The files are hard-linked where possible.
+func (db *pebbleDB) Checkpoint(dir string) error {
+	return db.db.Checkpoint(dir, pebble.WithFlushedWAL())
+}
+
+func (db *pebbleDB) Close() {
+	// Stop the metrics collection to avoid internal database races
+	db.quitLock.Lock()
+	defer db.quitLock.Unlock()
+
+	if db.quitChan != nil {
+		errc := make(chan error)
+		db.quitChan <- errc
+		if err := <-errc; err != nil {
+			db.logger.Error("Metrics collection failed", "err", err)
+		}
+		db.quitChan = nil
+	}
+	err := db.db.Close()
+	if err == nil {
+		db.logger.Info("Database closed")
+	} else {
+		db.logger.Error("Failed to close database", "err", err)
+	}
+}
+
+// Stat returns the internal metrics of pebble in a human-readable format.
+// Only the empty property and "stats" are supported.
+func (db *pebbleDB) Stat(property string) (string, error) {
+	if property != "" && property != "stats" {
+		return "", errors.New("unknown property")
+	}
+	return db.db.Metrics().String(), nil
+}
+
+// Compact flattens the underlying data store for the given key range.
+func (db *pebbleDB) Compact(start []byte, limit []byte) error {
+	// There is no special flag to represent the end of key range
+	// in pebble(nil in leveldb). Use an ugly hack to construct a
+	// large key to represent it.
+	if limit == nil {
+		limit = bytes.Repeat([]byte{0xff}, 64)
+	}
+	return db.db.Compact(start, limit, true)
+}
+
+// Meter configures the database metrics collectors.
+func (db *pebbleDB) Meter(prefix string) {
+	db.prefix = prefix
+
+	// Initialize all the metrics collector at the requested prefix
+	db.writeDelayCountMeter = metrics.NewRegisteredMeter(prefix+"writedelay/count", nil)
+	db.writeDelayDurationMeter = metrics.NewRegisteredMeter(prefix+"writedelay/duration", nil)
+	db.compTimer = kaiametrics.NewRegisteredHybridTimer(prefix+"compaction/time", nil)
+	db.compReadMeter = metrics.NewRegisteredMeter(prefix+"compaction/read", nil)
+	db.compWriteMeter = metrics.NewRegisteredMeter(prefix+"compaction/write", nil)
+	db.diskSizeGauge = metrics.NewRegisteredGauge(prefix+"disk/size", nil)
+	db.blockCacheGauge = metrics.NewRegisteredGauge(prefix+"blockcache", nil)
+
+	db.getTimer = kaiametrics.NewRegisteredHybridTimer(prefix+"get/time", nil)
+	db.putTimer = kaiametrics.NewRegisteredHybridTimer(prefix+"put/time", nil)
+	db.batchWriteTimer = kaiametrics.NewRegisteredHybridTimer(prefix+"batchwrite/time", nil)
+
+	db.memCompGauge = metrics.NewRegisteredGauge(prefix+"compact/memory", nil)
+	db.level0CompGauge = metrics.NewRegisteredGauge(prefix+"compact/level0", nil)
+	db.nonlevel0CompGauge = metrics.NewRegisteredGauge(prefix+"compact/nonlevel0", nil)
+
+	// Short circuit metering if the metrics system is disabled
+	// Above meters are initialized by NilMeter if metricutils.Enabled == false
+	if !metricutils.Enabled {
+		return
+	}
+
+	// Create a quit channel for the periodic collector and run it
+	db.quitLock.Lock()
+	db.quitChan = make(chan chan error)
+	db.quitLock.Unlock()
+
+	go db.meter(3 * time.Second)
+}
+
+// meter periodically retrieves internal pebble counters and reports them to
+// the metrics subsystem.
+func (db *pebbleDB) meter(refresh time.Duration) {
+	var (
+		prevCompTime, prevWriteDelayTime  int64
+		prevWriteDelayCount               int64
+		prevCompRead, prevCompWrite       int64
+		prevLevel0Comp, prevNonLevel0Comp uint32
+		prevMemComp                       int64
+	)
+
+	// Keep collecting stats unless quit is requested
+	for {
+		var (
+			compTime        = db.compTime.Load()
+			writeDelayCount = db.writeDelayCount.Load()
+			writeDelayTime  = db.writeDelayTime.Load()
+			level0Comp      = db.level0Comp.Load()
+			nonLevel0Comp   = db.nonLevel0Comp.Load()
+
+			compWrite int64
+			compRead  int64
+			stats     = db.db.Metrics()
+		)
+		for i, level := range stats.Levels {
+			compWrite += int64(level.BytesCompacted)
+			compRead += int64(level.BytesRead)
+			db.updateLevelStats(i, level.NumFiles)
+		}
+
+		db.writeDelayCountMeter.Mark(writeDelayCount - prevWriteDelayCount)
+		db.writeDelayDurationMeter.Mark(writeDelayTime - prevWriteDelayTime)
+		db.compTimer.Update(time.Duration(compTime - prevCompTime))
+		db.compReadMeter.Mark(compRead - prevCompRead)
+		db.compWriteMeter.Mark(compWrite - prevCompWrite)
+		prevWriteDelayCount, prevWriteDelayTime = writeDelayCount, writeDelayTime
+		prevCompTime, prevCompRead, prevCompWrite = compTime, compRead, compWrite
+
+		db.diskSizeGauge.Update(int64(stats.DiskSpaceUsage()))
+		db.blockCacheGauge.Update(stats.BlockCache.Size)
+
+		db.memCompGauge.Update(stats.Flush.Count - prevMemComp)
+		db.level0CompGauge.Update(int64(level0Comp - prevLevel0Comp))
+		db.nonlevel0CompGauge.Update(int64(nonLevel0Comp - prevNonLevel0Comp))
+		prevMemComp, prevLevel0Comp, prevNonLevel0Comp = stats.Flush.Count, level0Comp, nonLevel0Comp
+
+		// Sleep a bit, then repeat the stats collection
+		select {
+		case errc := <-db.quitChan:
+			// Quit requesting, stop hammering the database
+			errc <- nil
+			return
+		case <-time.After(refresh):
+			// Timeout, gather a new set of stats
+		}
+	}
+}
+
+// updateLevelStats collects level-wise stats.
+func (db *pebbleDB) updateLevelStats(lv int, numFiles int64) {
+	// dynamically creates a new metrics for a new level
+	if len(db.levelFilesGauge) <= lv {
+		prefix := db.prefix + fmt.Sprintf("level%v/", lv)
+		db.levelFilesGauge = append(db.levelFilesGauge, metrics.NewRegisteredGauge(prefix+"tables", nil))
+	}
+	db.levelFilesGauge[lv].Update(numFiles)
+}
+
+func (db *pebbleDB) TryCatchUpWithPrimary() error {
+	return nil
+}
+
+func (db *pebbleDB) NewBatch() Batch {
+	return &pebbleBatch{b: db.db.NewBatch(), db: db}
+}
+
+// pebbleBatch is a write-only batch that commits changes to its host database
+// when Write is called. A batch cannot be used concurrently.
+type pebbleBatch struct {
+	b    *pebble.Batch
+	db   *pebbleDB
+	size int
+}
+
+// Put inserts the given value into the batch for later committing.
+func (b *pebbleBatch) Put(key, value []byte) error {
+	b.b.Set(key, value, nil)
+	b.size += len(value)
+	return nil
+}
+
+// Delete inserts the a key removal into the batch for later committing.
+func (b *pebbleBatch) Delete(key []byte) error {
+	b.b.Delete(key, nil)
+	b.size++
+	return nil
+}
+
+// Write flushes any accumulated data to disk.
+func (b *pebbleBatch) Write() error {
+	if b.db.perfCheck {
+		start := time.Now()
+		err := b.write()
+		b.db.batchWriteTimer.Update(time.Since(start))
+		return err
+	}
+	return b.write()
+}
+
+func (b *pebbleBatch) write() error {
+	return b.b.Commit(pebble.NoSync)
+}
+
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *pebbleBatch) ValueSize() int {
+	return b.size
+}
+
+// Reset resets the batch for reuse.
+func (b *pebbleBatch) Reset() {
+	b.b.Reset()
+	b.size = 0
+}
+
+// Release returns the batch to the pool of pebble.
+func (b *pebbleBatch) Release() {
+	b.b.Close()
+}
+
+// Replay replays the batch contents.
+func (b *pebbleBatch) Replay(w KeyValueWriter) error {
+	reader := b.b.Reader()
+	for {
+		kind, k, v, ok, err := reader.Next()
+		if !ok || err != nil {
+			return err
+		}
+		// The (k,v) slices might be overwritten if the batch is reset/reused,
+		// and the receiver should copy them if they are to be retained long-term.
+		if kind == pebble.InternalKeyKindSet {
+			if err := w.Put(k, v); err != nil {
+				return err
+			}
+		} else if kind == pebble.InternalKeyKindDelete {
+			if err := w.Delete(k); err != nil {
+				return err
+			}
+		} else {
+			return fmt.Errorf("unhandled operation, keytype: %v", kind)
+		}
+	}
+}
+
+// pebbleIterator is a wrapper of underlying iterator in storage engine.
+// The purpose of this structure is to implement the missing APIs.
+type pebbleIterator struct {
+	iter     *pebble.Iterator
+	moved    bool
+	released bool
+	err      error
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (iter *pebbleIterator) Next() bool {
+	if iter.released {
+		return false
+	}
+	if iter.moved {
+		iter.moved = false
+		return iter.iter.Valid()
+	}
+	return iter.iter.Next()
+}
+
+// Error returns any accumulated error. Exhausting all the key/value pairs
+// is not considered to be an error.
+func (iter *pebbleIterator) Error() error {
+	if iter.released {
+		return iter.err
+	}
+	return iter.iter.Error()
+}
+
+// Key returns the key of the current key/value pair, or nil if done.
+func (iter *pebbleIterator) Key() []byte {
+	if iter.released || iter.moved || !iter.iter.Valid() {
+		return nil
+	}
+	return iter.iter.Key()
+}
+
+// Value returns the value of the current key/value pair, or nil if done.
+func (iter *pebbleIterator) Value() []byte {
+	if iter.released || iter.moved || !iter.iter.Valid() {
+		return nil
+	}
+	return iter.iter.Value()
+}
+
+// Release releases associated resources. Release should always succeed and can
+// be called multiple times without causing error.
+func (iter *pebbleIterator) Release() {
+	if !iter.released {
+		iter.err = iter.iter.Close()
+		iter.released = true
+	}
+}
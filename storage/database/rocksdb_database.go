@@ -289,6 +289,17 @@ func (db *rocksDB) NewIterator(prefix []byte, start []byte) Iterator {
 	return &rdbIter{first: true, iter: iter, prefix: prefix, db: db}
 }
 
+// Checkpoint creates a consistent snapshot of the database in the given
+// directory, which must not exist. The files are hard-linked where possible.
+func (db *rocksDB) Checkpoint(dir string) error {
+	cp, err := db.db.NewCheckpoint()
+	if err != nil {
+		return err
+	}
+	defer cp.Destroy()
+	return cp.CreateCheckpoint(dir, 0)
+}
+
 func (db *rocksDB) Close() {
 	close(db.quitCh)
 	db.db.CancelAllBackgroundWork(true)
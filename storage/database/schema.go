@@ -102,6 +102,9 @@ var (
 	pruningMarkKeyLen        = len(pruningMarkPrefix) + 8 + common.ExtHashLength // prefix + num (uint64) + node hash
 	lastPrunedBlockNumberKey = []byte("lastPrunedBlockNumber")
 
+	// historyTailKey tracks the oldest block whose body and receipts are retained.
+	historyTailKey = []byte("HistoryTail")
+
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
 	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
 
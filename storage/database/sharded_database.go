@@ -23,6 +23,7 @@ import (
 	"container/heap"
 	"context"
 	"fmt"
+	"os"
 	"path"
 	"strconv"
 	"sync"
@@ -167,6 +168,20 @@ func (db *shardedDB) Delete(key []byte) error {
 	}
 }
 
+// Checkpoint creates a checkpoint of each shard in its own subdirectory of
+// the given directory, laid out the same as the shards themselves.
+func (db *shardedDB) Checkpoint(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for i, shard := range db.shards {
+		if err := checkpointDatabase(shard, path.Join(dir, strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (db *shardedDB) Close() {
 	close(db.sdbBatchTaskCh)
 
@@ -36,6 +36,8 @@ type TrieNodeCacheConfig struct {
 	LocalCacheSizeMiB         int           // Memory allowance (MiB) to use for caching trie nodes in fast cache
 	FastCacheFileDir          string        // Directory where the persistent fastcache data is stored
 	FastCacheSavePeriod       time.Duration // Period of saving in memory trie cache to file if fastcache is used
+	DiskCacheDir              string        // Directory where the segment files of disk cache are stored
+	DiskCacheSizeMiB          int           // Disk allowance (MiB) to use for caching trie nodes in disk cache
 	RedisEndpoints            []string      // Endpoints of redis cache
 	RedisClusterEnable        bool          // Enable cluster-enabled mode of redis cache
 	RedisPublishBlockEnable   bool          // Enable publishing every inserted block to the redis server
@@ -46,6 +48,9 @@ func (c *TrieNodeCacheConfig) DumpPeriodically() bool {
 	if c.CacheType == CacheTypeLocal && c.LocalCacheSizeMiB > 0 && c.FastCacheSavePeriod > 0 {
 		return true
 	}
+	if c.CacheType == CacheTypeDisk && c.FastCacheSavePeriod > 0 {
+		return true
+	}
 	return false
 }
 
@@ -72,15 +77,17 @@ const (
 	CacheTypeLocal  TrieNodeCacheType = "LocalCache"
 	CacheTypeRedis                    = "RemoteCache"
 	CacheTypeHybrid                   = "HybridCache"
+	CacheTypeDisk                     = "DiskCache"
 )
 
 var (
 	errNotSupportedCacheType  = errors.New("not supported stateDB TrieNodeCache type")
 	errNilTrieNodeCacheConfig = errors.New("TrieNodeCacheConfig is nil")
+	errInvalidDiskCacheSize   = errors.New("both of memory and disk sizes of disk cache should be positive")
 )
 
 func (cacheType TrieNodeCacheType) ToValid() TrieNodeCacheType {
-	validTrieNodeCacheTypes := []TrieNodeCacheType{CacheTypeLocal, CacheTypeRedis, CacheTypeHybrid}
+	validTrieNodeCacheTypes := []TrieNodeCacheType{CacheTypeLocal, CacheTypeRedis, CacheTypeHybrid, CacheTypeDisk}
 	for _, validType := range validTrieNodeCacheTypes {
 		if strings.ToLower(string(cacheType)) == strings.ToLower(string(validType)) {
 			return validType
@@ -104,6 +111,9 @@ func NewTrieNodeCache(config *TrieNodeCacheConfig) (TrieNodeCache, error) {
 	case CacheTypeHybrid:
 		logger.Info("Set hybrid trie node cache using both of localCache (fastCache) and redisCache")
 		return newHybridCache(config)
+	case CacheTypeDisk:
+		logger.Info("Set disk trie node cache using both of localCache (fastCache) and segment files on disk")
+		return newDiskCache(config)
 	default:
 	}
 	logger.Error("Invalid trie node cache type", "cacheType", config.CacheType)
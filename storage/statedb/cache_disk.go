@@ -0,0 +1,147 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package statedb
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/VictoriaMetrics/fastcache"
+	"github.com/alecthomas/units"
+	"github.com/rcrowley/go-metrics"
+)
+
+var (
+	// metrics
+	memcacheDiskLocalHits   = metrics.NewRegisteredGauge("trie/memcache/disk/local/hits", nil)
+	memcacheDiskLocalMisses = metrics.NewRegisteredGauge("trie/memcache/disk/local/misses", nil)
+	memcacheDiskHits        = metrics.NewRegisteredGauge("trie/memcache/disk/hits", nil)
+	memcacheDiskMisses      = metrics.NewRegisteredGauge("trie/memcache/disk/misses", nil)
+	memcacheDiskEntries     = metrics.NewRegisteredGauge("trie/memcache/disk/entries", nil)
+	memcacheDiskBytesSize   = metrics.NewRegisteredGauge("trie/memcache/disk/size", nil)
+)
+
+// DiskCacheStats is the statistics of a DiskCache.
+type DiskCacheStats struct {
+	Local       fastcache.Stats
+	LocalHits   uint64
+	LocalMisses uint64
+	DiskHits    uint64
+	DiskMisses  uint64
+	DiskEntries int
+	DiskSize    int
+}
+
+// DiskCache integrates two tiers of caches: an in-memory fastcache and
+// memory-mapped segment files on the local disk. Items missed in the memory
+// tier are looked up in the disk tier, and promoted to the memory tier.
+// Unlike the fastcache snapshot of LocalCache, the disk tier is persisted as
+// it's written, so it's reused as it is when the node restarts.
+type DiskCache struct {
+	local *FastCache
+	disk  *diskTier
+
+	localHits, localMisses uint64
+	diskHits, diskMisses   uint64
+}
+
+// newDiskCache creates a DiskCache whose memory tier is sized by
+// config.LocalCacheSizeMiB and disk tier by config.DiskCacheSizeMiB.
+func newDiskCache(config *TrieNodeCacheConfig) (TrieNodeCache, error) {
+	if config.LocalCacheSizeMiB == AutoScaling {
+		config.LocalCacheSizeMiB = getTrieNodeCacheSizeMiB()
+	}
+	if config.LocalCacheSizeMiB <= 0 || config.DiskCacheSizeMiB <= 0 {
+		return nil, errInvalidDiskCacheSize
+	}
+
+	logger.Info("Initializing disk trie node cache", "MemoryMiB", config.LocalCacheSizeMiB,
+		"DiskMiB", config.DiskCacheSizeMiB, "dir", config.DiskCacheDir)
+
+	start := time.Now()
+	disk, err := newDiskTier(config.DiskCacheDir, config.DiskCacheSizeMiB*int(units.MiB))
+	if err != nil {
+		return nil, err
+	}
+	entries, size := disk.Stats()
+
+	logger.Info("Initialized disk trie node cache", "LoadedMiB", size/int(units.MiB),
+		"LoadedEntries", entries, "elapsed", time.Since(start))
+
+	return &DiskCache{
+		local: &FastCache{fast: fastcache.New(config.LocalCacheSizeMiB * int(units.MiB))},
+		disk:  disk,
+	}, nil
+}
+
+func (cache *DiskCache) Get(k []byte) []byte {
+	if ret := cache.local.Get(k); ret != nil {
+		atomic.AddUint64(&cache.localHits, 1)
+		return ret
+	}
+	atomic.AddUint64(&cache.localMisses, 1)
+
+	ret := cache.disk.Get(k)
+	if ret == nil {
+		atomic.AddUint64(&cache.diskMisses, 1)
+		return nil
+	}
+	atomic.AddUint64(&cache.diskHits, 1)
+	cache.local.Set(k, ret)
+	return ret
+}
+
+// Set writes data to both of the memory tier and the disk tier.
+func (cache *DiskCache) Set(k, v []byte) {
+	cache.local.Set(k, v)
+	cache.disk.Set(k, v)
+}
+
+func (cache *DiskCache) Has(k []byte) ([]byte, bool) {
+	ret := cache.Get(k)
+	return ret, ret != nil
+}
+
+func (cache *DiskCache) UpdateStats() interface{} {
+	stats := DiskCacheStats{
+		Local:       cache.local.UpdateStats().(fastcache.Stats),
+		LocalHits:   atomic.LoadUint64(&cache.localHits),
+		LocalMisses: atomic.LoadUint64(&cache.localMisses),
+		DiskHits:    atomic.LoadUint64(&cache.diskHits),
+		DiskMisses:  atomic.LoadUint64(&cache.diskMisses),
+	}
+	stats.DiskEntries, stats.DiskSize = cache.disk.Stats()
+
+	memcacheDiskLocalHits.Update(int64(stats.LocalHits))
+	memcacheDiskLocalMisses.Update(int64(stats.LocalMisses))
+	memcacheDiskHits.Update(int64(stats.DiskHits))
+	memcacheDiskMisses.Update(int64(stats.DiskMisses))
+	memcacheDiskEntries.Update(int64(stats.DiskEntries))
+	memcacheDiskBytesSize.Update(int64(stats.DiskSize))
+
+	return stats
+}
+
+// SaveToFile flushes the disk tier instead of taking a snapshot of the memory
+// tier, since the disk tier already holds the items to be reused.
+func (cache *DiskCache) SaveToFile(filePath string, concurrency int) error {
+	return cache.disk.Flush()
+}
+
+func (cache *DiskCache) Close() error {
+	return cache.disk.Close()
+}
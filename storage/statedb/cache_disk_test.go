@@ -0,0 +1,134 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package statedb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getTestDiskCacheConfig(dir string) *TrieNodeCacheConfig {
+	return &TrieNodeCacheConfig{
+		CacheType:         CacheTypeDisk,
+		LocalCacheSizeMiB: 32,
+		DiskCacheDir:      dir,
+		DiskCacheSizeMiB:  16,
+	}
+}
+
+// TestDiskCache_Tiers tests whether items missed in the memory tier are served
+// and promoted from the disk tier, with hits and misses counted per tier.
+func TestDiskCache_Tiers(t *testing.T) {
+	cache, err := NewTrieNodeCache(getTestDiskCacheConfig(t.TempDir()))
+	require.NoError(t, err)
+	defer cache.Close()
+	diskCache := cache.(*DiskCache)
+
+	key, value := common.MakeRandomBytes(32), common.MakeRandomBytes(500)
+	diskCache.disk.Set(key, value)
+
+	assert.Equal(t, value, cache.Get(key))
+	assert.Equal(t, value, diskCache.local.Get(key))
+	assert.Equal(t, value, cache.Get(key))
+	assert.Nil(t, cache.Get(common.MakeRandomBytes(32)))
+
+	stats := cache.UpdateStats().(DiskCacheStats)
+	assert.Equal(t, uint64(1), stats.LocalHits)
+	assert.Equal(t, uint64(2), stats.LocalMisses)
+	assert.Equal(t, uint64(1), stats.DiskHits)
+	assert.Equal(t, uint64(1), stats.DiskMisses)
+	assert.Equal(t, 1, stats.DiskEntries)
+}
+
+// TestDiskCache_Persistence tests whether the disk tier is reused after the
+// cache is reopened.
+func TestDiskCache_Persistence(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewTrieNodeCache(getTestDiskCacheConfig(dir))
+	require.NoError(t, err)
+
+	var keys, values [][]byte
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, common.MakeRandomBytes(32))
+		values = append(values, common.MakeRandomBytes(200))
+		cache.Set(keys[i], values[i])
+	}
+	require.NoError(t, cache.SaveToFile("", 1))
+	require.NoError(t, cache.Close())
+
+	cache, err = NewTrieNodeCache(getTestDiskCacheConfig(dir))
+	require.NoError(t, err)
+	defer cache.Close()
+
+	for i := range keys {
+		assert.Equal(t, values[i], cache.Get(keys[i]))
+	}
+	stats := cache.UpdateStats().(DiskCacheStats)
+	assert.Equal(t, uint64(len(keys)), stats.DiskHits)
+	assert.Equal(t, len(keys), stats.DiskEntries)
+
+	// Items set after reopening are appended after the loaded ones.
+	key, value := common.MakeRandomBytes(32), common.MakeRandomBytes(200)
+	cache.Set(key, value)
+	assert.Equal(t, value, cache.(*DiskCache).disk.Get(key))
+	assert.Equal(t, values[len(values)-1], cache.(*DiskCache).disk.Get(keys[len(keys)-1]))
+}
+
+// TestDiskTier_Eviction tests whether the oldest segment is reused when the
+// disk tier is full, dropping the items stored in it.
+func TestDiskTier_Eviction(t *testing.T) {
+	dir := t.TempDir()
+	tier, err := newDiskTier(dir, diskTierSegments*4096)
+	require.NoError(t, err)
+
+	var keys [][]byte
+	value := bytes.Repeat([]byte{0x1}, 1000)
+	for i := 0; i < 3*diskTierSegments; i++ {
+		keys = append(keys, common.MakeRandomBytes(32))
+		tier.Set(keys[i], value)
+	}
+	// Each segment holds three items, so one more item evicts the first segment.
+	tier.Set(common.MakeRandomBytes(32), value)
+	for i, key := range keys {
+		if i < 3 {
+			assert.Nil(t, tier.Get(key))
+		} else {
+			assert.Equal(t, value, tier.Get(key))
+		}
+	}
+	entries, _ := tier.Stats()
+	assert.Equal(t, 3*diskTierSegments-2, entries)
+
+	// The index is rebuilt from the segments in order after reopening.
+	require.NoError(t, tier.Close())
+	tier, err = newDiskTier(dir, diskTierSegments*4096)
+	require.NoError(t, err)
+	defer tier.Close()
+	assert.Nil(t, tier.Get(keys[0]))
+	assert.Equal(t, value, tier.Get(keys[3]))
+	entries, _ = tier.Stats()
+	assert.Equal(t, 3*diskTierSegments-2, entries)
+
+	// Items which don't fit in a segment are not cached.
+	key := common.MakeRandomBytes(32)
+	tier.Set(key, make([]byte, 4096))
+	assert.Nil(t, tier.Get(key))
+}
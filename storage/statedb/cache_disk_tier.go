@@ -0,0 +1,325 @@
+// Copyright 2024 The Kaia Authors
+// This file is part of the Kaia library.
+//
+// The Kaia library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Kaia library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Kaia library. If not, see <http://www.gnu.org/licenses/>.
+
+package statedb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"hash/maphash"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/edsrzf/mmap-go"
+	"github.com/klaytn/klaytn/common"
+)
+
+const (
+	// diskTierSegments is the number of segment files of the disk tier. When
+	// all segments are full, the oldest one is emptied and reused.
+	diskTierSegments = 16
+
+	diskTierMagic         = 0x4b544e43 // "KTNC"
+	diskTierHeaderSize    = 16         // magic (4), reserved (4), sequence number (8)
+	diskTierRecHeaderSize = 18         // sequence number (8), key size (2), value size (4), checksum (4)
+
+	diskTierOffsetBits = 40
+)
+
+var errDiskTierTooSmall = errors.New("disk tier is too small")
+
+// diskTier is a persistent cache of trie nodes kept in memory-mapped segment
+// files. Records are appended to the current segment, and the oldest segment
+// is overwritten when all of them are full. The location of each key is kept
+// in an in-memory index, which is rebuilt from the segments on startup.
+type diskTier struct {
+	segments []*diskSegment
+	segSize  int
+	head     int    // index of the segment being appended
+	offset   int    // append offset in the head segment
+	nextSeq  uint64 // sequence number of the next reused segment
+
+	index   map[uint64]uint64 // hash of a key => segment index << diskTierOffsetBits | record offset
+	seed    maphash.Seed
+	entries int
+	lock    sync.RWMutex
+}
+
+// diskSegment is a segment file. Each record carries the sequence number of
+// the segment, so that the records left from before the segment was reused
+// are not mistaken for the current ones.
+type diskSegment struct {
+	file *os.File
+	mem  mmap.MMap
+	seq  uint64 // 0 if the segment is empty
+}
+
+// newDiskTier opens the segment files in the given directory, creating them
+// if needed, and indexes the records persisted by the previous run.
+func newDiskTier(dir string, size int) (*diskTier, error) {
+	segSize := size / diskTierSegments
+	if segSize <= diskTierHeaderSize+diskTierRecHeaderSize {
+		return nil, errDiskTierTooSmall
+	}
+	if uint64(segSize) >= 1<<diskTierOffsetBits {
+		return nil, fmt.Errorf("disk tier segment size %d is too large", segSize)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	tier := &diskTier{
+		segSize: segSize,
+		index:   make(map[uint64]uint64),
+		seed:    maphash.MakeSeed(),
+	}
+	for i := 0; i < diskTierSegments; i++ {
+		seg, err := openDiskSegment(filepath.Join(dir, fmt.Sprintf("segment-%02d.dat", i)), segSize)
+		if err != nil {
+			tier.Close()
+			return nil, err
+		}
+		tier.segments = append(tier.segments, seg)
+	}
+
+	// Index the segments from the oldest to the newest, so the newest record
+	// of a key wins, and continue appending to the newest one.
+	order := make([]int, 0, diskTierSegments)
+	for i, seg := range tier.segments {
+		if seg.seq != 0 {
+			order = append(order, i)
+		}
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return tier.segments[order[a]].seq < tier.segments[order[b]].seq
+	})
+	for _, i := range order {
+		tier.offset = tier.scan(i, func(key []byte, offset int) {
+			tier.add(key, i, offset)
+		})
+		tier.head = i
+	}
+	if len(order) == 0 {
+		tier.nextSeq = 1
+		tier.reset(0)
+	} else {
+		tier.nextSeq = tier.segments[tier.head].seq + 1
+	}
+	return tier, nil
+}
+
+func openDiskSegment(path string, size int) (*diskSegment, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	// A segment of a different size was written with another configuration.
+	resized := stat.Size() != int64(size)
+	if resized {
+		if err := file.Truncate(0); err != nil {
+			file.Close()
+			return nil, err
+		}
+		if err := file.Truncate(int64(size)); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	mem, err := mmap.Map(file, mmap.RDWR, 0)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	seg := &diskSegment{file: file, mem: mem}
+	if !resized && binary.BigEndian.Uint32(mem[0:4]) == diskTierMagic {
+		seg.seq = binary.BigEndian.Uint64(mem[8:16])
+	}
+	return seg, nil
+}
+
+// scan calls fn for each valid record of the given segment, and returns the
+// offset after the last one.
+func (t *diskTier) scan(i int, fn func(key []byte, offset int)) int {
+	seg := t.segments[i]
+	offset := diskTierHeaderSize
+	for {
+		key, _, size, ok := t.record(seg, offset)
+		if !ok {
+			return offset
+		}
+		fn(key, offset)
+		offset += size
+	}
+}
+
+// record returns the key and the value of the record at the given offset of
+// the segment if it's a valid record written since the segment was reused.
+func (t *diskTier) record(seg *diskSegment, offset int) (key, value []byte, size int, ok bool) {
+	if seg.seq == 0 || offset+diskTierRecHeaderSize > t.segSize {
+		return nil, nil, 0, false
+	}
+	header := seg.mem[offset : offset+diskTierRecHeaderSize]
+	if binary.BigEndian.Uint64(header[0:8]) != seg.seq {
+		return nil, nil, 0, false
+	}
+	keySize := int(binary.BigEndian.Uint16(header[8:10]))
+	valueSize := int(binary.BigEndian.Uint32(header[10:14]))
+	size = diskTierRecHeaderSize + keySize + valueSize
+	if offset+size > t.segSize {
+		return nil, nil, 0, false
+	}
+	key = seg.mem[offset+diskTierRecHeaderSize : offset+diskTierRecHeaderSize+keySize]
+	value = seg.mem[offset+diskTierRecHeaderSize+keySize : offset+size]
+	if crc32.ChecksumIEEE(seg.mem[offset:offset+14]) != binary.BigEndian.Uint32(header[14:18])^crc32.ChecksumIEEE(seg.mem[offset+diskTierRecHeaderSize:offset+size]) {
+		return nil, nil, 0, false
+	}
+	return key, value, size, true
+}
+
+func (t *diskTier) add(key []byte, i, offset int) {
+	h := maphash.Bytes(t.seed, key)
+	if _, ok := t.index[h]; !ok {
+		t.entries++
+	}
+	t.index[h] = uint64(i)<<diskTierOffsetBits | uint64(offset)
+}
+
+// reset empties the given segment and makes it the head.
+func (t *diskTier) reset(i int) {
+	seg := t.segments[i]
+	if seg.seq != 0 {
+		// Drop the keys whose newest record is in the segment.
+		t.scan(i, func(key []byte, offset int) {
+			h := maphash.Bytes(t.seed, key)
+			if loc, ok := t.index[h]; ok && loc == uint64(i)<<diskTierOffsetBits|uint64(offset) {
+				delete(t.index, h)
+				t.entries--
+			}
+		})
+	}
+	seg.seq = t.nextSeq
+	t.nextSeq++
+	binary.BigEndian.PutUint32(seg.mem[0:4], diskTierMagic)
+	binary.BigEndian.PutUint64(seg.mem[8:16], seg.seq)
+	t.head, t.offset = i, diskTierHeaderSize
+}
+
+// Get returns a copy of the value of the key, or nil if it's not cached.
+func (t *diskTier) Get(k []byte) []byte {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	loc, ok := t.index[maphash.Bytes(t.seed, k)]
+	if !ok {
+		return nil
+	}
+	i, offset := int(loc>>diskTierOffsetBits), int(loc&(1<<diskTierOffsetBits-1))
+	key, value, _, ok := t.record(t.segments[i], offset)
+	if !ok || !bytes.Equal(key, k) {
+		return nil
+	}
+	return common.CopyBytes(value)
+}
+
+// Set appends a record of the key and the value. A record which doesn't fit
+// in a segment is not cached.
+func (t *diskTier) Set(k, v []byte) {
+	size := diskTierRecHeaderSize + len(k) + len(v)
+	if len(k) > 0xffff || size > t.segSize-diskTierHeaderSize {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.segments == nil {
+		return
+	}
+	if t.offset+size > t.segSize {
+		t.reset((t.head + 1) % diskTierSegments)
+	}
+	seg, offset := t.segments[t.head], t.offset
+	rec := seg.mem[offset : offset+size]
+	binary.BigEndian.PutUint64(rec[0:8], seg.seq)
+	binary.BigEndian.PutUint16(rec[8:10], uint16(len(k)))
+	binary.BigEndian.PutUint32(rec[10:14], uint32(len(v)))
+	copy(rec[diskTierRecHeaderSize:], k)
+	copy(rec[diskTierRecHeaderSize+len(k):], v)
+	binary.BigEndian.PutUint32(rec[14:18], crc32.ChecksumIEEE(rec[0:14])^crc32.ChecksumIEEE(rec[diskTierRecHeaderSize:]))
+
+	t.add(k, t.head, offset)
+	t.offset += size
+}
+
+// Stats returns the number of cached keys and the size of the segments in use.
+func (t *diskTier) Stats() (entries int, size int) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	for i, seg := range t.segments {
+		if i == t.head {
+			size += t.offset
+		} else if seg.seq != 0 {
+			size += t.segSize
+		}
+	}
+	return t.entries, size
+}
+
+// Flush writes the modified pages of the segments to the disk.
+func (t *diskTier) Flush() error {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	var errs []error
+	for _, seg := range t.segments {
+		if err := seg.mem.Flush(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close flushes and unmaps the segments.
+func (t *diskTier) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var errs []error
+	for _, seg := range t.segments {
+		if err := seg.mem.Flush(); err != nil {
+			errs = append(errs, err)
+		}
+		if err := seg.mem.Unmap(); err != nil {
+			errs = append(errs, err)
+		}
+		if err := seg.file.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	t.segments = nil
+	t.index = make(map[uint64]uint64)
+	t.entries = 0
+	return errors.Join(errs...)
+}
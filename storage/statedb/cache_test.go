@@ -38,6 +38,7 @@ func TestNewTrieNodeCache(t *testing.T) {
 		{getTestFastCacheConfig(), reflect.TypeOf(&FastCache{}), nil},
 		{getTestRedisConfig(), reflect.TypeOf(&RedisCache{}), nil},
 		{getTestHybridConfig(), reflect.TypeOf(&HybridCache{}), nil},
+		{getTestDiskCacheConfig(t.TempDir()), reflect.TypeOf(&DiskCache{}), nil},
 		{nil, nil, errNilTrieNodeCacheConfig},
 	}
 
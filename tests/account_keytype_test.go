@@ -341,6 +341,26 @@ func generateDefaultTx(sender *TestAccountType, recipient *TestAccountType, txTy
 		values[types.TxValueKeyChainID] = big.NewInt(1)
 		values[types.TxValueKeyData] = dataCode
 		values[types.TxValueKeyAccessList] = types.AccessList{}
+	case types.TxTypeEthereumSetCode:
+		// a fresh authority delegates its code to the contract.
+		auth, err := types.SignSetCode(genTestKeys(1)[0], types.SetCodeAuthorization{
+			ChainID: big.NewInt(0),
+			Address: contractAddr,
+			Nonce:   0,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		values[types.TxValueKeyNonce] = sender.Nonce
+		values[types.TxValueKeyTo] = recipient.Addr
+		values[types.TxValueKeyAmount] = amount
+		values[types.TxValueKeyGasLimit] = gasLimit
+		values[types.TxValueKeyGasFeeCap] = gasFeeCap
+		values[types.TxValueKeyGasTipCap] = gasTipCap
+		values[types.TxValueKeyChainID] = big.NewInt(1)
+		values[types.TxValueKeyData] = dataCode
+		values[types.TxValueKeyAccessList] = types.AccessList{}
+		values[types.TxValueKeyAuthorizationList] = types.AuthorizationList{auth}
 	}
 
 	tx, err := types.NewTransactionWithMap(txType, values)
@@ -417,6 +437,7 @@ func TestDefaultTxsWithDefaultAccountKey(t *testing.T) {
 	bcdata.bc.Config().IstanbulCompatibleBlock = big.NewInt(0)
 	bcdata.bc.Config().LondonCompatibleBlock = big.NewInt(0)
 	bcdata.bc.Config().EthTxTypeCompatibleBlock = big.NewInt(0)
+	bcdata.bc.Config().PragueCompatibleBlock = big.NewInt(0)
 	prof.Profile("main_init_blockchain", time.Now().Sub(start))
 	defer bcdata.Shutdown()
 
@@ -736,6 +736,40 @@ func genMapForDynamicFeeTransaction(from TestAccount, to TestAccount, gasPrice *
 	return values, intrinsic + gasPayload
 }
 
+func genMapForSetCodeTransaction(from TestAccount, to TestAccount, gasPrice *big.Int, txType types.TxType) (map[types.TxValueKeyType]interface{}, uint64) {
+	intrinsic := getIntrinsicGas(txType)
+	amount := big.NewInt(100000)
+	data := []byte{0x11, 0x22}
+	gasPayload := uint64(len(data)) * params.TxDataGas
+	accessList := types.AccessList{{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), StorageKeys: []common.Hash{{0}}}}
+	toAddress := to.GetAddr()
+
+	// a fresh authority delegates its code to the recipient.
+	auth, _ := types.SignSetCode(genTestKeys(1)[0], types.SetCodeAuthorization{
+		Address: toAddress,
+		Nonce:   0,
+	})
+	authList := types.AuthorizationList{auth}
+
+	gasPayload += uint64(len(accessList)) * params.TxAccessListAddressGas
+	gasPayload += uint64(accessList.StorageKeys()) * params.TxAccessListStorageKeyGas
+	gasPayload += uint64(len(authList)) * params.CallNewAccountGas
+
+	values := map[types.TxValueKeyType]interface{}{
+		types.TxValueKeyNonce:             from.GetNonce(),
+		types.TxValueKeyTo:                toAddress,
+		types.TxValueKeyAmount:            amount,
+		types.TxValueKeyData:              data,
+		types.TxValueKeyGasLimit:          gasLimit,
+		types.TxValueKeyGasFeeCap:         gasPrice,
+		types.TxValueKeyGasTipCap:         gasPrice,
+		types.TxValueKeyAccessList:        accessList,
+		types.TxValueKeyAuthorizationList: authList,
+		types.TxValueKeyChainID:           big.NewInt(1),
+	}
+	return values, intrinsic + gasPayload
+}
+
 func genMapForValueTransfer(from TestAccount, to TestAccount, gasPrice *big.Int, txType types.TxType) (map[types.TxValueKeyType]interface{}, uint64) {
 	intrinsic := getIntrinsicGas(txType)
 	amount := big.NewInt(100000)
@@ -1045,6 +1079,8 @@ func getIntrinsicGas(txType types.TxType) uint64 {
 		intrinsic = params.TxGas
 	case types.TxTypeEthereumDynamicFee:
 		intrinsic = params.TxGas
+	case types.TxTypeEthereumSetCode:
+		intrinsic = params.TxGas
 	case types.TxTypeValueTransfer:
 		intrinsic = params.TxGasValueTransfer
 	case types.TxTypeFeeDelegatedValueTransfer: